@@ -0,0 +1,168 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// AltTextImage is an image to describe. Set either URL or Data.
+type AltTextImage struct {
+	Name        string // Identifier used in results, e.g. the blob name
+	URL         string // Publicly reachable or SAS URL of the image
+	Data        []byte // Raw image bytes, sent inline when URL is empty
+	ContentType string // MIME type of Data, e.g. "image/png"
+}
+
+// AltTextOptions configures GenerateAltText.
+type AltTextOptions struct {
+	Model       ai.Model // Vision-capable chat model (required)
+	Concurrency int      // Maximum images described at once. Defaults to 4
+	MaxLength   int      // Maximum alt text length in characters. Defaults to 125
+	Language    string   // Language to write in, e.g. "Spanish". Defaults to English
+}
+
+// AltTextResult is the generated description of one image.
+type AltTextResult struct {
+	Name    string `json:"name"`
+	AltText string `json:"altText"` // Short text for the alt attribute
+	Caption string `json:"caption"` // Longer, visible caption
+	Err     error  `json:"-"`       // Error for this image, if it failed
+}
+
+// altTextOutput is the structured output requested from the model
+type altTextOutput struct {
+	AltText string `json:"altText"`
+	Caption string `json:"caption"`
+}
+
+// GenerateAltText describes images with a vision model using structured output, with at most
+// opts.Concurrency requests in flight. Results are returned in input order; a failure for
+// one image is reported in its result's Err and does not stop the batch.
+func GenerateAltText(ctx context.Context, g *genkit.Genkit, images []AltTextImage, opts AltTextOptions) ([]AltTextResult, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("alt text generation requires a vision model")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.MaxLength <= 0 {
+		opts.MaxLength = 125
+	}
+	if opts.Language == "" {
+		opts.Language = "English"
+	}
+
+	instruction := fmt.Sprintf("Describe this image for people who cannot see it. Write in %s. "+
+		"altText must be at most %d characters, describe the essential content and not start with "+
+		"\"image of\". caption is one or two sentences with more detail.", opts.Language, opts.MaxLength)
+
+	results := make([]AltTextResult, len(images))
+	semaphore := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, image := range images {
+		wg.Add(1)
+		go func(i int, image AltTextImage) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i] = AltTextResult{Name: image.Name, Err: ctx.Err()}
+				return
+			}
+			results[i] = describeImage(ctx, g, opts, instruction, image)
+		}(i, image)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// describeImage generates alt text for a single image
+func describeImage(ctx context.Context, g *genkit.Genkit, opts AltTextOptions, instruction string, image AltTextImage) AltTextResult {
+	result := AltTextResult{Name: image.Name}
+
+	part := imagePart(image.URL, image.Data, image.ContentType)
+	if part == nil {
+		result.Err = fmt.Errorf("image %q has no URL or data", image.Name)
+		return result
+	}
+
+	out, _, err := genkit.GenerateData[altTextOutput](ctx, g,
+		ai.WithModel(opts.Model),
+		ai.WithMessages(ai.NewUserMessage(ai.NewTextPart(instruction), part)),
+	)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to describe image %q: %w", image.Name, err)
+		return result
+	}
+
+	result.AltText = strings.TrimSpace(out.AltText)
+	if len([]rune(result.AltText)) > opts.MaxLength {
+		result.AltText = string([]rune(result.AltText)[:opts.MaxLength])
+	}
+	result.Caption = strings.TrimSpace(out.Caption)
+	return result
+}
+
+// imagePart returns an image as a media part, sending data inline as a data URL when url is
+// empty. It returns nil when there is no image.
+func imagePart(url string, data []byte, contentType string) *ai.Part {
+	if url == "" {
+		if len(data) == 0 {
+			return nil
+		}
+		if contentType == "" {
+			contentType = "image/png"
+		}
+		url = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	}
+	if contentType == "" {
+		contentType = imageContentType(url)
+	}
+	return ai.NewMediaPart(contentType, url)
+}
+
+// WriteAltTextCSV writes results as CSV with a name, alt_text, caption, error header.
+func WriteAltTextCSV(w io.Writer, results []AltTextResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"name", "alt_text", "caption", "error"}); err != nil {
+		return err
+	}
+	for _, result := range results {
+		var errText string
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+		if err := writer.Write([]string{result.Name, result.AltText, result.Caption, errText}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestGenerateAltText(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+
+	var inFlight, maxInFlight atomic.Int32
+	model := genkit.DefineModel(g, "test/vision", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, Media: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			var url string
+			for _, part := range req.Messages[0].Content {
+				if part.IsMedia() {
+					url = part.Text
+				}
+			}
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(
+				`{"altText":"A red bicycle leaning on a wall ` + url[len(url)-5:] + `","caption":"A red bicycle."}`)}, nil
+		})
+
+	images := []AltTextImage{
+		{Name: "a.png", URL: "https://example.com/a.png"},
+		{Name: "b.png", Data: []byte("png-bytes"), ContentType: "image/png"},
+		{Name: "c.png", URL: "https://example.com/c.png"},
+		{Name: "empty.png"},
+	}
+	results, err := GenerateAltText(ctx, g, images, AltTextOptions{Model: model, Concurrency: 2, MaxLength: 40})
+	if err != nil {
+		t.Fatalf("GenerateAltText() error = %v", err)
+	}
+
+	if maxInFlight.Load() > 2 {
+		t.Fatalf("%d requests in flight, want at most 2", maxInFlight.Load())
+	}
+	for i, result := range results[:3] {
+		if result.Name != images[i].Name || result.Err != nil || result.Caption != "A red bicycle." {
+			t.Fatalf("result %d = %+v", i, result)
+		}
+		if len(result.AltText) > 40 {
+			t.Fatalf("alt text %q longer than MaxLength", result.AltText)
+		}
+	}
+	if results[3].Err == nil {
+		t.Fatal("image without URL or data did not fail")
+	}
+
+	var csv strings.Builder
+	if err := WriteAltTextCSV(&csv, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 5 || lines[0] != "name,alt_text,caption,error" {
+		t.Fatalf("csv = %q", csv.String())
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// featureFlagPrefix is the key prefix App Configuration stores feature flags under
+const featureFlagPrefix = ".appconfig.featureflag/"
+
+// AppConfigurationOptions configures AppConfigurationSettings.
+type AppConfigurationOptions struct {
+	Endpoint     string                 // App Configuration endpoint, e.g. "https://myconfig.azconfig.io" (required)
+	Credential   azcore.TokenCredential // Credential with the App Configuration Data Reader role (required)
+	KeyPrefix    string                 // Prefix of the setting keys. Defaults to "genkit:"
+	Label        string                 // Label of the keys to read. Defaults to keys without a label
+	FailoverFlag string                 // Feature flag that enables failover groups. Defaults to "genkit-failover"
+	HTTPClient   *http.Client           // Defaults to http.DefaultClient
+}
+
+// appConfigurationItem is a key-value returned by the App Configuration REST API
+type appConfigurationItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AppConfigurationSettings returns a source that reads runtime settings from Azure App
+// Configuration. The keys under the prefix are defaultModel, maxTemperature and experiments,
+// a JSON object of Experiment values by model name. Failover groups are enabled unless the
+// failover feature flag exists and is disabled. Use it with WatchSettings to refresh them.
+func AppConfigurationSettings(opts AppConfigurationOptions) SettingsSource {
+	if opts.KeyPrefix == "" {
+		opts.KeyPrefix = "genkit:"
+	}
+	if opts.FailoverFlag == "" {
+		opts.FailoverFlag = "genkit-failover"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	endpoint := strings.TrimRight(opts.Endpoint, "/")
+
+	return func(ctx context.Context) (*RuntimeSettings, error) {
+		if endpoint == "" || opts.Credential == nil {
+			return nil, fmt.Errorf("app configuration requires an endpoint and a credential")
+		}
+		token, err := opts.Credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{endpoint + "/.default"}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get app configuration token: %w", err)
+		}
+
+		label := opts.Label
+		if label == "" {
+			label = "\x00" // Keys without a label
+		}
+		query := url.Values{
+			"key":         {opts.KeyPrefix + "*," + featureFlagPrefix + opts.FailoverFlag},
+			"label":       {label},
+			"api-version": {"2023-11-01"},
+		}
+		values := make(map[string]string)
+		for next := "/kv?" + query.Encode(); next != ""; {
+			var page struct {
+				Items    []appConfigurationItem `json:"items"`
+				NextLink string                 `json:"@nextLink"`
+			}
+			if err := getAppConfiguration(ctx, opts.HTTPClient, endpoint+next, token.Token, &page); err != nil {
+				return nil, err
+			}
+			for _, item := range page.Items {
+				values[item.Key] = item.Value
+			}
+			next = page.NextLink
+		}
+		return parseAppConfigurationSettings(values, opts.KeyPrefix, featureFlagPrefix+opts.FailoverFlag)
+	}
+}
+
+// getAppConfiguration fetches a page of key-values
+func getAppConfiguration(ctx context.Context, client *http.Client, url, token string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.microsoft.appconfig.kvset+json, application/problem+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("app configuration request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("app configuration request failed: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode app configuration response: %w", err)
+	}
+	return nil
+}
+
+// parseAppConfigurationSettings converts App Configuration key-values to runtime settings
+func parseAppConfigurationSettings(values map[string]string, prefix, failoverFlag string) (*RuntimeSettings, error) {
+	settings := &RuntimeSettings{DefaultModel: values[prefix+"defaultModel"]}
+	if value, ok := values[prefix+"maxTemperature"]; ok && value != "" {
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %smaxTemperature %q: %w", prefix, value, err)
+		}
+		settings.MaxTemperature = limit
+	}
+	if value, ok := values[prefix+"experiments"]; ok && value != "" {
+		if err := json.Unmarshal([]byte(value), &settings.Experiments); err != nil {
+			return nil, fmt.Errorf("invalid %sexperiments: %w", prefix, err)
+		}
+	}
+	if value, ok := values[failoverFlag]; ok {
+		var flag struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.Unmarshal([]byte(value), &flag); err != nil {
+			return nil, fmt.Errorf("invalid feature flag %s: %w", strings.TrimPrefix(failoverFlag, featureFlagPrefix), err)
+		}
+		settings.DisableFailover = !flag.Enabled
+	}
+	return settings, nil
+}
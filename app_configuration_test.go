@@ -0,0 +1,124 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// staticCredential is a token credential that always returns the same token
+type staticCredential struct {
+	scopes []string
+}
+
+func (c *staticCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.scopes = opts.Scopes
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+func TestAppConfigurationSettings(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		page := map[string]any{}
+		if r.URL.Query().Get("after") == "" {
+			page["items"] = []map[string]any{
+				{"key": "genkit:defaultModel", "value": "gpt-4o"},
+				{"key": "genkit:maxTemperature", "value": "0.7"},
+			}
+			page["@nextLink"] = "/kv?after=2&api-version=2023-11-01"
+		} else {
+			page["items"] = []map[string]any{
+				{"key": "genkit:experiments", "value": `{"gpt-4o":{"deployment":"gpt-4.1","percent":10}}`},
+				{"key": ".appconfig.featureflag/genkit-failover", "value": `{"id":"genkit-failover","enabled":false}`},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	credential := &staticCredential{}
+	source := AppConfigurationSettings(AppConfigurationOptions{Endpoint: server.URL + "/", Credential: credential})
+	settings, err := source(context.Background())
+	if err != nil {
+		t.Fatalf("source() error = %v", err)
+	}
+	want := RuntimeSettings{
+		DefaultModel:    "gpt-4o",
+		MaxTemperature:  0.7,
+		DisableFailover: true,
+		Experiments:     map[string]Experiment{"gpt-4o": {Deployment: "gpt-4.1", Percent: 10}},
+	}
+	if settings.DefaultModel != want.DefaultModel || settings.MaxTemperature != want.MaxTemperature ||
+		settings.DisableFailover != want.DisableFailover || settings.Experiments["gpt-4o"] != want.Experiments["gpt-4o"] {
+		t.Fatalf("settings = %+v, want %+v", settings, want)
+	}
+	if len(credential.scopes) != 1 || credential.scopes[0] != server.URL+"/.default" {
+		t.Fatalf("scopes = %v", credential.scopes)
+	}
+	if len(queries) != 2 || !strings.Contains(queries[0], "key=genkit%3A%2A%2C.appconfig.featureflag%2Fgenkit-failover") ||
+		!strings.Contains(queries[0], "label=%00") {
+		t.Fatalf("queries = %v", queries)
+	}
+}
+
+func TestParseAppConfigurationSettings(t *testing.T) {
+	const flag = featureFlagPrefix + "genkit-failover"
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    RuntimeSettings
+		wantErr string
+	}{
+		{name: "empty", values: map[string]string{}},
+		{name: "enabled flag", values: map[string]string{flag: `{"enabled":true}`}},
+		{name: "temperature", values: map[string]string{"genkit:maxTemperature": "1.2"}, want: RuntimeSettings{MaxTemperature: 1.2}},
+		{name: "invalid temperature", values: map[string]string{"genkit:maxTemperature": "high"}, wantErr: "invalid genkit:maxTemperature"},
+		{name: "invalid experiments", values: map[string]string{"genkit:experiments": "[1]"}, wantErr: "invalid genkit:experiments"},
+		{name: "invalid flag", values: map[string]string{flag: "on"}, wantErr: "invalid feature flag genkit-failover"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAppConfigurationSettings(tt.values, "genkit:", flag)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got.MaxTemperature != tt.want.MaxTemperature || got.DisableFailover != tt.want.DisableFailover {
+				t.Fatalf("settings = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"sort"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// maxMetadataPairs is the number of metadata key-value pairs accepted by the API
+const maxMetadataPairs = 16
+
+// Attribution labels API calls with the flow, feature and team that made them, so usage
+// from products sharing one Azure resource can be charged back.
+type Attribution struct {
+	Flow    string            `json:"flow,omitempty"`    // Genkit flow or workload name
+	Feature string            `json:"feature,omitempty"` // Product feature
+	Team    string            `json:"team,omitempty"`    // Owning team
+	Labels  map[string]string `json:"labels,omitempty"`  // Additional labels
+}
+
+// attributionKey is the context key for Attribution values
+type attributionKey struct{}
+
+// WithAttribution returns a context whose API calls are attributed to the given labels.
+// Non-empty fields override those of an attribution already present in ctx.
+func WithAttribution(ctx context.Context, attribution Attribution) context.Context {
+	merged := AttributionFromContext(ctx)
+	if attribution.Flow != "" {
+		merged.Flow = attribution.Flow
+	}
+	if attribution.Feature != "" {
+		merged.Feature = attribution.Feature
+	}
+	if attribution.Team != "" {
+		merged.Team = attribution.Team
+	}
+	if len(attribution.Labels) > 0 {
+		labels := make(map[string]string, len(merged.Labels)+len(attribution.Labels))
+		for k, v := range merged.Labels {
+			labels[k] = v
+		}
+		for k, v := range attribution.Labels {
+			labels[k] = v
+		}
+		merged.Labels = labels
+	}
+	return context.WithValue(ctx, attributionKey{}, merged)
+}
+
+// AttributionFromContext returns the attribution stored in ctx, if any.
+func AttributionFromContext(ctx context.Context) Attribution {
+	attribution, _ := ctx.Value(attributionKey{}).(Attribution)
+	return attribution
+}
+
+// IsZero reports whether the attribution has no labels.
+func (at Attribution) IsZero() bool {
+	return at.Flow == "" && at.Feature == "" && at.Team == "" && len(at.Labels) == 0
+}
+
+// metadata returns the labels sent in the request "metadata" field. Flow, feature and team
+// take precedence; additional labels are added in key order up to the API limit.
+func (at Attribution) metadata() map[string]string {
+	metadata := make(map[string]string)
+	for key, val := range map[string]string{"flow": at.Flow, "feature": at.Feature, "team": at.Team} {
+		if val != "" {
+			metadata[key] = val
+		}
+	}
+
+	keys := make([]string, 0, len(at.Labels))
+	for key := range at.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if len(metadata) == maxMetadataPairs {
+			break
+		}
+		if _, ok := metadata[key]; !ok {
+			metadata[key] = at.Labels[key]
+		}
+	}
+	return metadata
+}
+
+// endUser returns the end-user ID of a request: the configured one, then the one returned by
+// EndUser. Attribution labels are not end users, so they are never sent as one.
+func (a *AzureAIFoundry) endUser(ctx context.Context, configured string) string {
+	if configured != "" || a.EndUser == nil {
+		return configured
+	}
+	return a.EndUser(ctx)
+}
+
+// applyEndUser sets the chat request's user field from EndUser when the "user" config
+// option did not set it
+func (a *AzureAIFoundry) applyEndUser(ctx context.Context, params *openai.ChatCompletionNewParams) {
+	if params.User.Valid() || a.EndUser == nil {
+		return
+	}
+	if user := a.EndUser(ctx); user != "" {
+		params.User = openai.String(user)
+	}
+}
+
+// applyAttribution records the context attribution in the chat request's metadata field.
+// Azure only accepts metadata with stored completions, so it is left out unless the request
+// enables storage and the data-handling policy does not disable it. Events carry the
+// attribution either way.
+func (a *AzureAIFoundry) applyAttribution(ctx context.Context, params *openai.ChatCompletionNewParams) {
+	attribution := AttributionFromContext(ctx)
+	if attribution.IsZero() || !params.Store.Valid() || !params.Store.Value || a.DataHandling.DisableStorage {
+		return
+	}
+	if params.Metadata == nil {
+		params.Metadata = map[string]string{}
+	}
+	for key, val := range attribution.metadata() {
+		if _, ok := params.Metadata[key]; !ok && len(params.Metadata) < maxMetadataPairs {
+			params.Metadata[key] = val
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+)
+
+func TestWithAttributionMerges(t *testing.T) {
+	ctx := WithAttribution(context.Background(), Attribution{Team: "payments", Labels: map[string]string{"env": "prod"}})
+	ctx = WithAttribution(ctx, Attribution{Flow: "summarize", Labels: map[string]string{"tenant": "acme"}})
+
+	got := AttributionFromContext(ctx)
+	want := Attribution{Flow: "summarize", Team: "payments", Labels: map[string]string{"env": "prod", "tenant": "acme"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("AttributionFromContext() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAttributionRecordedOnChatRequests(t *testing.T) {
+	attribution := Attribution{
+		Flow:    "summarize",
+		Feature: "checkout",
+		Team:    "payments",
+		Labels:  map[string]string{"tenant": "acme"},
+	}
+	generate := func(plugin *AzureAIFoundry, ctx context.Context) error {
+		_, err := plugin.generateText(ctx, "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+		return err
+	}
+	rawStored := func(plugin *AzureAIFoundry, ctx context.Context) error {
+		_, err := plugin.RawChatCompletion(ctx, openai.ChatCompletionNewParams{
+			Model:    "gpt-4o",
+			Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+			Store:    openai.Bool(true),
+		})
+		return err
+	}
+	tests := []struct {
+		name           string
+		generate       func(*AzureAIFoundry, context.Context) error
+		disableStorage bool
+		wantMetadata   any
+	}{
+		{name: "not stored", generate: generate},
+		{
+			name:         "stored",
+			generate:     rawStored,
+			wantMetadata: map[string]any{"flow": "summarize", "feature": "checkout", "team": "payments", "tenant": "acme"},
+		},
+		{name: "storage disabled", generate: rawStored, disableStorage: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+				a.DataHandling.DisableStorage = tt.disableStorage
+			})
+			var completed Event
+			plugin.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+				if event.Type == EventCompleted {
+					completed = event
+				}
+			}))
+
+			if err := tt.generate(plugin, WithAttribution(context.Background(), attribution)); err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			if len(bodies) != 1 {
+				t.Fatalf("got %d requests, want 1", len(bodies))
+			}
+			// Attribution is not an end user
+			if user, ok := bodies[0]["user"]; ok {
+				t.Fatalf("user = %v, want none", user)
+			}
+			if !reflect.DeepEqual(bodies[0]["metadata"], tt.wantMetadata) {
+				t.Fatalf("metadata = %v, want %v", bodies[0]["metadata"], tt.wantMetadata)
+			}
+			if completed.Attribution.Flow != "summarize" || completed.Attribution.Team != "payments" || completed.Usage == nil || completed.Usage.TotalTokens != 6 {
+				t.Fatalf("completed event = %+v", completed)
+			}
+		})
+	}
+}
+
+func TestAttributionMetadataLimit(t *testing.T) {
+	labels := map[string]string{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p"} {
+		labels[key] = key
+	}
+	metadata := Attribution{Flow: "flow", Labels: labels}.metadata()
+	if len(metadata) != maxMetadataPairs || metadata["flow"] != "flow" || metadata["o"] != "o" || metadata["p"] != "" {
+		t.Fatalf("metadata = %v", metadata)
+	}
+}
+
+// endUserKey is the context key the test EndUser extractor reads
+type endUserKey struct{}
+
+func TestEndUser(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		response := chatCompletionJSON
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/generations"):
+			response = `{"created":1,"data":[{"url":"https://example.com/cat.png"}]}`
+		case strings.HasSuffix(r.URL.Path, "/embeddings"):
+			response = `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.5]}],"model":"text-embedding-3-small","usage":{"prompt_tokens":1,"total_tokens":1}}`
+		}
+		captureRequests(&bodies, response)(w, r)
+	})
+	plugin.EndUser = func(ctx context.Context) string {
+		user, _ := ctx.Value(endUserKey{}).(string)
+		return user
+	}
+
+	chat := func(ctx context.Context, config map[string]any) error {
+		_, err := plugin.generateText(ctx, "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}, Config: config}, nil)
+		return err
+	}
+	image := func(ctx context.Context, config map[string]any) error {
+		_, err := plugin.generateText(ctx, "dall-e-3", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("a cat")}, Config: config}, nil)
+		return err
+	}
+	embed := func(ctx context.Context, config map[string]any) error {
+		_, err := plugin.embed(ctx, "text-embedding-3-small", &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText("hi", nil)}, Options: config})
+		return err
+	}
+
+	signedIn := context.WithValue(context.Background(), endUserKey{}, "user-123")
+	attributed := WithAttribution(context.Background(), Attribution{Team: "payments"})
+	tests := []struct {
+		name     string
+		generate func(context.Context, map[string]any) error
+		ctx      context.Context
+		config   map[string]any
+		want     any
+	}{
+		{name: "chat config", generate: chat, ctx: signedIn, config: map[string]any{"user": "user-456"}, want: "user-456"},
+		{name: "chat extractor", generate: chat, ctx: signedIn, want: "user-123"},
+		{name: "chat extractor over attribution", generate: chat, ctx: context.WithValue(attributed, endUserKey{}, "user-123"), want: "user-123"},
+		{name: "chat attribution", generate: chat, ctx: attributed, want: nil},
+		{name: "chat without user", generate: chat, ctx: context.Background(), want: nil},
+		{name: "image config", generate: image, ctx: signedIn, config: map[string]any{"user": "user-456"}, want: "user-456"},
+		{name: "image extractor", generate: image, ctx: signedIn, want: "user-123"},
+		{name: "embedding option", generate: embed, ctx: signedIn, config: map[string]any{"user": "user-456"}, want: "user-456"},
+		{name: "embedding extractor", generate: embed, ctx: signedIn, want: "user-123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			if err := tt.generate(tt.ctx, tt.config); err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			if len(bodies) != 1 {
+				t.Fatalf("got %d requests, want 1", len(bodies))
+			}
+			if got := bodies[0]["user"]; got != tt.want {
+				t.Fatalf("user = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
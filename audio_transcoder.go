@@ -0,0 +1,99 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// AudioTranscoder converts audio the target endpoint does not accept, such as AAC or AMR
+// recordings, into a supported format.
+type AudioTranscoder interface {
+	// Transcode converts data of the given content type to one of the supported content
+	// types and returns the converted data and its content type.
+	Transcode(ctx context.Context, data []byte, contentType string, supported []string) ([]byte, string, error)
+}
+
+// FFmpegTranscoder is an AudioTranscoder that runs the ffmpeg command-line tool. It
+// converts to MP3 when the endpoint accepts it, otherwise to WAV, downmixed to mono 16 kHz,
+// which is enough for speech and keeps uploads small.
+type FFmpegTranscoder struct {
+	Path string   // ffmpeg binary. Defaults to "ffmpeg" on the PATH
+	Args []string // Extra output arguments, e.g. []string{"-af", "loudnorm"}
+}
+
+// ffmpegFormats maps target content types to ffmpeg output options, in order of preference
+var ffmpegFormats = []struct {
+	contentType string
+	args        []string
+}{
+	{"audio/mpeg", []string{"-codec:a", "libmp3lame", "-q:a", "4", "-f", "mp3"}},
+	{"audio/wav", []string{"-codec:a", "pcm_s16le", "-f", "wav"}},
+}
+
+// Transcode implements AudioTranscoder.
+func (f *FFmpegTranscoder) Transcode(ctx context.Context, data []byte, contentType string, supported []string) ([]byte, string, error) {
+	var target string
+	var formatArgs []string
+	for _, format := range ffmpegFormats {
+		if isSupportedMedia(supported, format.contentType) {
+			target, formatArgs = format.contentType, format.args
+			break
+		}
+	}
+	if target == "" {
+		return nil, "", fmt.Errorf("ffmpeg transcoder cannot produce any of %s", strings.Join(supported, ", "))
+	}
+
+	// Read the input from a file: containers such as MP4 cannot always be parsed from a pipe
+	input, err := os.CreateTemp("", "azureaifoundry-audio-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(input.Name())
+	if _, err := input.Write(data); err != nil {
+		input.Close()
+		return nil, "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := input.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	path := f.Path
+	if path == "" {
+		path = "ffmpeg"
+	}
+	args := []string{"-hide_banner", "-loglevel", "error", "-i", input.Name(), "-vn", "-ac", "1", "-ar", "16000"}
+	args = append(args, f.Args...)
+	args = append(args, formatArgs...)
+	args = append(args, "pipe:1")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, "", fmt.Errorf("ffmpeg failed to convert %s: %w: %s", contentType, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), target, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// fakeTranscoder records conversions and returns an MP3 header
+type fakeTranscoder struct {
+	from []string
+}
+
+func (f *fakeTranscoder) Transcode(_ context.Context, _ []byte, contentType string, supported []string) ([]byte, string, error) {
+	f.from = append(f.from, contentType)
+	return []byte("ID3\x04\x00"), "audio/mpeg", nil
+}
+
+func TestAudioTranscoderForTranscription(t *testing.T) {
+	var filenames []string
+	transcoder := &fakeTranscoder{}
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, header, err := r.FormFile("file"); err == nil {
+			filenames = append(filenames, header.Filename)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"hi"}`))
+	}, func(a *AzureAIFoundry) {
+		a.AudioTranscoder = transcoder
+		a.AudioFormats = map[string][]string{"whisper-strict": {"audio/mpeg", "audio/wav"}}
+	})
+
+	tests := []struct {
+		name         string
+		model        string
+		part         *ai.Part
+		wantFilename string
+	}{
+		{"supported format is sent as is", "whisper", ai.NewMediaPart("audio/ogg", dataURL("audio/ogg", []byte("OggS\x00"))), "audio.ogg"},
+		{"format restricted per deployment", "whisper-strict", ai.NewMediaPart("audio/webm", dataURL("audio/webm", []byte{0x1A, 0x45, 0xDF, 0xA3})), "audio.mp3"},
+		{"unsupported detected format", "whisper", ai.NewMediaPart("audio/amr", dataURL("audio/amr", []byte("#!AMR\n"))), "audio.mp3"},
+		{"unrecognized declared format", "whisper", ai.NewMediaPart("audio/3gpp", dataURL("audio/3gpp", []byte("????"))), "audio.mp3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filenames = nil
+			_, err := plugin.generateText(context.Background(), tt.model, &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserMessage(tt.part)},
+			}, nil)
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if len(filenames) != 1 || filenames[0] != tt.wantFilename {
+				t.Fatalf("uploaded %v, want %s", filenames, tt.wantFilename)
+			}
+		})
+	}
+	if strings.Join(transcoder.from, ",") != "audio/webm,audio/amr,audio/3gpp" {
+		t.Fatalf("transcoded %v", transcoder.from)
+	}
+}
+
+func TestFFmpegTranscoder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ffmpeg")
+	}
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\nprintf 'RIFF\\044\\000\\000\\000WAVEfmt '\n"
+	path := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	transcoder := &FFmpegTranscoder{Path: path}
+	data, contentType, err := transcoder.Transcode(context.Background(), []byte("#!AMR\n"), "audio/amr", []string{"audio/wav", "audio/flac"})
+	if err != nil {
+		t.Fatalf("Transcode() error = %v", err)
+	}
+	if contentType != "audio/wav" || SniffMediaType(data) != "audio/wav" {
+		t.Fatalf("Transcode() = %q (%s)", data, contentType)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-ac 1 -ar 16000 -codec:a pcm_s16le -f wav pipe:1") {
+		t.Fatalf("ffmpeg args = %s", args)
+	}
+
+	if _, _, err := transcoder.Transcode(context.Background(), nil, "audio/amr", []string{"audio/flac"}); err == nil {
+		t.Fatal("Transcode() to an unsupported target succeeded")
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// AuditRecord is one entry of the tamper-evident audit trail. Each record includes the hash
+// of the previous record, so removing or editing an entry breaks the chain.
+type AuditRecord struct {
+	Sequence        uint64      `json:"sequence"`
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	Path            string      `json:"path"`
+	Model           string      `json:"model,omitempty"`
+	Operation       string      `json:"operation,omitempty"`
+	Attribution     Attribution `json:"attribution,omitzero"`
+	StatusCode      int         `json:"statusCode,omitempty"`
+	Error           string      `json:"error,omitempty"`
+	RequestHash     string      `json:"requestHash"`            // SHA-256 of the request body
+	ResponseHash    string      `json:"responseHash,omitempty"` // SHA-256 of the response body
+	ContentFiltered bool        `json:"contentFiltered"`        // Whether Azure content filtering blocked or filtered content
+	PrevHash        string      `json:"prevHash"`
+	Hash            string      `json:"hash"` // SHA-256 of this record with Hash empty
+}
+
+// AuditSink stores audit records. Implementations should append only, for example to a local
+// file or an Azure Blob Storage container with an immutability policy.
+type AuditSink interface {
+	WriteAudit(ctx context.Context, record AuditRecord) error
+}
+
+// jsonlAuditSink writes audit records as JSON lines
+type jsonlAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLAuditSink returns a sink that appends one JSON object per line to w.
+func NewJSONLAuditSink(w io.Writer) AuditSink {
+	return &jsonlAuditSink{w: w}
+}
+
+// WriteAudit implements AuditSink.
+func (s *jsonlAuditSink) WriteAudit(_ context.Context, record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// VerifyAuditLog checks the hash chain of a JSON lines audit log written by NewJSONLAuditSink.
+// It returns an error identifying the first record that was modified, removed or reordered.
+func VerifyAuditLog(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	prevHash := ""
+	var sequence uint64
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("audit record %d: %w", sequence+1, err)
+		}
+		sequence++
+		if record.Sequence != sequence {
+			return fmt.Errorf("audit record %d: unexpected sequence %d", sequence, record.Sequence)
+		}
+		if record.PrevHash != prevHash {
+			return fmt.Errorf("audit record %d: chain broken", sequence)
+		}
+		if want := record.computeHash(); record.Hash != want {
+			return fmt.Errorf("audit record %d: hash mismatch", sequence)
+		}
+		prevHash = record.Hash
+	}
+	return scanner.Err()
+}
+
+// computeHash returns the SHA-256 of the record with its Hash field cleared
+func (r AuditRecord) computeHash() string {
+	r.Hash = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditChain links records written by one plugin instance
+type auditChain struct {
+	mu       sync.Mutex
+	sequence uint64
+	prevHash string
+}
+
+// auditMiddleware records a hash-chained audit record for every HTTP request to Azure
+func (a *AzureAIFoundry) auditMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		record := AuditRecord{
+			Time:        time.Now(),
+			Method:      req.Method,
+			Path:        req.URL.Path,
+			Model:       requestModel(req),
+			Operation:   operationFromPath(req.URL.Path),
+			Attribution: AttributionFromContext(req.Context()),
+		}
+
+		requestHash := sha256.New()
+		if req.Body != nil {
+			body, err := io.ReadAll(req.Body)
+			_ = req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			requestHash.Write(body)
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		record.RequestHash = hex.EncodeToString(requestHash.Sum(nil))
+
+		resp, err := next(req)
+		if err != nil {
+			record.Error = err.Error()
+			a.writeAudit(req.Context(), record)
+			return resp, err
+		}
+
+		record.StatusCode = resp.StatusCode
+		resp.Body = &auditBody{
+			ReadCloser: resp.Body,
+			hash:       sha256.New(),
+			done: func(responseHash string, filtered bool) {
+				record.ResponseHash = responseHash
+				record.ContentFiltered = filtered
+				a.writeAudit(req.Context(), record)
+			},
+		}
+		return resp, nil
+	}
+}
+
+// writeAudit links the record into the chain and writes it to the audit sink
+func (a *AzureAIFoundry) writeAudit(ctx context.Context, record AuditRecord) {
+	a.audit.mu.Lock()
+	defer a.audit.mu.Unlock()
+
+	record.Sequence = a.audit.sequence + 1
+	record.PrevHash = a.audit.prevHash
+	record.Hash = record.computeHash()
+	if err := a.AuditSink.WriteAudit(ctx, record); err != nil {
+		logger.FromContext(ctx).Error("azureaifoundry: failed to write audit record", "sequence", record.Sequence, "err", err)
+		return
+	}
+	a.audit.sequence = record.Sequence
+	a.audit.prevHash = record.Hash
+}
+
+// contentFilterMarkers appear in responses whose content was blocked or filtered by Azure
+var contentFilterMarkers = [][]byte{
+	[]byte(`"finish_reason":"content_filter"`),
+	[]byte(`"code":"content_filter"`),
+	[]byte(`"filtered":true`),
+}
+
+// auditBody hashes a response body as it is read and reports it once when closed
+type auditBody struct {
+	io.ReadCloser
+	hash     hash.Hash
+	tail     []byte
+	filtered bool
+	once     sync.Once
+	done     func(responseHash string, filtered bool)
+}
+
+// Read implements io.Reader, hashing and scanning the data read
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.hash.Write(p[:n])
+		if !b.filtered {
+			// Keep a short tail so markers split across reads are still found
+			window := append(b.tail, p[:n]...)
+			for _, marker := range contentFilterMarkers {
+				if bytes.Contains(window, marker) {
+					b.filtered = true
+				}
+			}
+			if keep := 32; len(window) > keep {
+				window = window[len(window)-keep:]
+			}
+			b.tail = append(b.tail[:0], window...)
+		}
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *auditBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+// finish reports the response hash exactly once
+func (b *auditBody) finish() {
+	b.once.Do(func() {
+		b.done(hex.EncodeToString(b.hash.Sum(nil)), b.filtered)
+	})
+}
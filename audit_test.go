@@ -0,0 +1,85 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestAuditTrail(t *testing.T) {
+	var log bytes.Buffer
+	filtered := strings.Replace(chatCompletionJSON, `"finish_reason":"stop"`, `"finish_reason":"content_filter"`, 1)
+	responses := []string{chatCompletionJSON, filtered}
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, responses[0])
+		responses = responses[1:]
+	}, func(a *AzureAIFoundry) {
+		a.AuditSink = NewJSONLAuditSink(&log)
+	})
+
+	ctx := WithAttribution(context.Background(), Attribution{Team: "claims"})
+	for _, prompt := range []string{"first", "second"} {
+		if _, err := plugin.generateText(ctx, "gpt-4o", &ai.ModelRequest{
+			Messages: []*ai.Message{ai.NewUserTextMessage(prompt)},
+		}, nil); err != nil {
+			t.Fatalf("generateText() error = %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d audit records, want 2:\n%s", len(lines), log.String())
+	}
+	var records []AuditRecord
+	for _, line := range lines {
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if records[0].Model != "gpt-4o" || records[0].Operation != OperationChat || records[0].Attribution.Team != "claims" {
+		t.Fatalf("record = %+v", records[0])
+	}
+	if records[0].RequestHash == records[1].RequestHash || records[0].ResponseHash == "" {
+		t.Fatalf("unexpected hashes: %+v", records)
+	}
+	if records[0].ContentFiltered || !records[1].ContentFiltered {
+		t.Fatalf("ContentFiltered = %v, %v, want false, true", records[0].ContentFiltered, records[1].ContentFiltered)
+	}
+
+	if err := VerifyAuditLog(strings.NewReader(log.String())); err != nil {
+		t.Fatalf("VerifyAuditLog() error = %v", err)
+	}
+	tampered := strings.Replace(log.String(), `"team":"claims"`, `"team":"other"`, 1)
+	if err := VerifyAuditLog(strings.NewReader(tampered)); err == nil {
+		t.Fatal("VerifyAuditLog() accepted a modified record")
+	}
+	if err := VerifyAuditLog(strings.NewReader(lines[1] + "\n")); err == nil {
+		t.Fatal("VerifyAuditLog() accepted a log with a removed record")
+	}
+}
@@ -26,18 +26,23 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/core/logger"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/openai/openai-go/v3"
-	"github.com/openai/openai-go/v3/azure"
 	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared/constant"
 )
 
 const provider = "azureaifoundry"
@@ -59,22 +64,149 @@ type AzureAIFoundry struct {
 	APIKey     string                 // API key for authentication (required if not using DefaultAzureCredential)
 	APIVersion string                 // Azure OpenAI API version (e.g., "2024-12-01-preview", "2024-02-01"). Defaults to "2024-12-01-preview" if not specified
 	Credential azcore.TokenCredential // Optional: Use Azure DefaultAzureCredential instead of API key
+	ProviderID string                 // Optional: Provider name models are registered under, e.g. "azure-eastus". Defaults to "azureaifoundry". Give each plugin instance its own
 
-	mu      sync.Mutex // Mutex to control access
-	client  openai.Client
-	initted bool // Whether the plugin has been initialized
+	UseV1API         bool      // Optional: Send requests to the Azure OpenAI v1 API at "<Endpoint>/openai/v1/", which takes deployment names as models and needs no api-version. APIVersion is ignored
+	OpenAICompatible bool      // Optional: Treat Endpoint as an OpenAI-compatible base URL (e.g. vLLM at "http://vllm:8000/v1") instead of an Azure resource
+	AuthStyle        AuthStyle // Optional: How APIKey is sent. Defaults to the api-key header for Azure and a bearer token for OpenAI-compatible endpoints
+	AuthHeader       string    // Optional: Header APIKey is sent in with AuthStyleHeader
+	TokenScopes      []string  // Optional: Scopes of Credential tokens, e.g. "https://ml.azure.com/.default" for Azure ML. Defaults to Azure Cognitive Services
+
+	PathPrefix  string      // Optional: Path a gateway serves the API under, e.g. "/aoai" for "https://my-apim.azure-api.net/aoai/openai/deployments/...". Endpoint is then the gateway's origin
+	BaseURLMode BaseURLMode // Optional: URL layout of the gateway. Defaults to Azure's deployment-based layout
+
+	Headers map[string]string // Optional: Headers sent with every request, e.g. the "Ocp-Apim-Subscription-Key" or routing headers of an Azure API Management front door. They replace headers the plugin sets
+
+	FailoverEndpoints   []Endpoint    // Optional: Secondary endpoints in priority order, used when the ones before them return 408, 429 or 5xx or are unreachable
+	HealthProbeInterval time.Duration // Optional: How often endpoints that failed are probed so traffic returns to them. Defaults to 30 seconds
+
+	MaxRetries        int                             // Optional: Times failed chat, embedding, image and audio requests are retried. Defaults to 2; set -1 to disable retries
+	RetryBackoff      func(attempt int) time.Duration // Optional: Delay before the given retry, counting from 1, when Azure sent no Retry-After. Defaults to 0.5s doubling up to 8s
+	RetryableStatuses []int                           // Optional: HTTP statuses that are retried, as well as connection errors. Defaults to 408, 409, 429 and 5xx
+
+	RateLimits map[string]RateLimit // Optional: Client-side request and token limits per deployment name, shared by its models and embedders. ModelDefinition.RateLimit takes precedence
+
+	RateLimitRetries int           // Optional: Times a request throttled with 429 is resent after waiting for its Retry-After, before the client's own retries. Defaults to 0
+	MaxRetryAfter    time.Duration // Optional: Longest Retry-After RateLimitRetries waits for; longer waits fail the request at once. Defaults to 1 minute
+
+	StandbyProbeInterval time.Duration // Optional: How often the standby deployments of failover groups with ProbeStandby are probed. Defaults to 1 minute
+
+	Registry *RegistryConfig // Optional: Models, embedders and failover groups registered at Init, e.g. from LoadModelsFromFile
+
+	Subscribers []EventSubscriber // Optional: Receive typed lifecycle events (request started, first token, retries, ...)
+	AuditSink   AuditSink         // Optional: Record a tamper-evident audit trail of every request
+
+	Region          string          // Optional: Azure region of the endpoint (e.g. "westeurope"), checked against ResidencyPolicy
+	ResidencyPolicy ResidencyPolicy // Optional: Regions each data classification may be sent to
+	Classifier      DataClassifier  // Optional: Classifies model requests without a data classification by the data categories found in them, recorded in the "dataCategories" custom value
+
+	Fallback       FallbackGenerator                                // Optional: Serves chat requests when Azure is unreachable, e.g. an on-premises OpenAI-compatible endpoint
+	FallbackRegion string                                           // Optional: Region of the fallback, checked against ResidencyPolicy before classified requests are sent to it
+	FallbackPolicy func(ctx context.Context, modelName string) bool // Optional: Whether a request may be served by Fallback, on top of ResidencyPolicy. Defaults to allowing all
+
+	DataHandling DataHandlingPolicy // Optional: Data-handling defaults applied to every request, such as disabling stored completions
+
+	Guardrails map[string]GuardrailProfile // Optional: Named guardrail profiles attached to models with ModelDefinition.Guardrail and to requests with the "guardrail" config option
+
+	ErrorRedaction RedactionMode // Optional: How credentials are scrubbed from returned, logged and emitted errors. Defaults to RedactCredentials
+
+	EndUser func(ctx context.Context) string // Optional: Returns the end-user ID sent in the "user" field of chat, image and embedding requests for abuse monitoring, when the "user" config option is not set
+
+	TokenBudget *TokenBudget // Optional: Cap the estimated tokens of chat requests in flight, queuing or rejecting new ones with *BusyError
+
+	ModelLimits map[string]ModelLimits // Optional: Token limits by model name, overriding or extending ModelCatalog
+
+	Tokenizers map[string]Tokenizer // Optional: Tokenizer per deployment name for token counting. Defaults to ApproximateTokenizer
+
+	SystemPrompts map[string]string // Optional: Named system prompt templates (Go text/template) attached to models with ModelDefinition.SystemPrompt
+
+	Voices           map[string]VoiceProfile  // Optional: Text-to-speech voice per language code, used when the "voice" config option is "auto"
+	LanguageDetector func(text string) string // Optional: Language detector for automatic voice selection and LanguageEnforcement. Defaults to DetectLanguage
+
+	LanguageEnforcement *LanguageEnforcement // Optional: How chat answers not in the language of the "responseLanguage" config option are corrected. Defaults to re-prompting once
+
+	Pricing map[string]ModelPricing // Optional: Price per deployment name, used to report cost in streamed usage progress
+
+	StreamBufferSize     int                // Optional: Chunks buffered between Azure and a slow stream callback. 0 calls the callback synchronously
+	StreamBackpressure   BackpressurePolicy // Optional: What to do when the stream buffer is full. Defaults to BackpressureBlock
+	StreamResumeAttempts int                // Optional: Times a stream broken by a network error is replayed with the text generated so far and an instruction to continue it. Best-effort; off by default
+
+	FirstTokenTimeout time.Duration // Optional: How long a streamed request waits for its first chunk before failing with *FirstTokenTimeoutError, which fails over to the next deployment
+	GenerationTimeout time.Duration // Optional: Maximum time of a request, including the whole stream, before failing with *GenerationTimeoutError, which is not retried
+
+	DisableMediaValidation bool                // Optional: Send media parts without checking their bytes and content type
+	MediaTranscoder        MediaTranscoder     // Optional: Converts inline media in unsupported formats
+	AudioTranscoder        AudioTranscoder     // Optional: Converts inline audio in unsupported formats, e.g. FFmpegTranscoder. Takes precedence over MediaTranscoder for audio
+	AudioFormats           map[string][]string // Optional: Audio content types accepted per transcription deployment, overriding the defaults
+
+	TrimSilence          *VADOptions       // Optional: Cut silence from 16-bit PCM WAV audio before every transcription
+	TranscriptConfidence *ConfidencePolicy // Optional: Flag, re-transcribe or review transcripts with low-confidence segments
+	Vocabulary           VocabularyStore   // Optional: Terms learned with LearnVocabulary per tenant, added to the prompt of every transcription
+	VocabularyTerms      int               // Optional: Most corrected terms added to a transcription prompt. Defaults to 50
+
+	StrictConfig bool // Optional: Reject requests with unknown, unsupported or malformed config options instead of ignoring them
+
+	CapabilityProbe *CapabilityProbe // Optional: Check at Init which api-versions and chat request features the endpoint accepts, see Capabilities
+
+	PromptCompression *PromptCompression // Optional: Experimental. Drop low-information words from long parts of chat prompts to save tokens. The "promptCompression" config option sets the rate per request
+
+	StablePromptPrefix bool // Optional: Serialize the tools and leading system messages of chat requests deterministically so repeated prompts hit Azure's prompt cache
+
+	Retirements             map[string]ModelRetirement // Optional: Retirement dates by model version, overriding ModelRetirements
+	RetirementWarningWindow time.Duration              // Optional: How long before retirement to start warning. Defaults to 90 days
+
+	mu       sync.Mutex // Mutex to control access
+	eventsMu sync.RWMutex
+	audit    auditChain
+
+	retirementMu         sync.Mutex
+	vocabularyMu         sync.Mutex
+	retirementWarned     map[string]time.Time             // Last retirement warning per deployment
+	registry             registryState                    // Models registered from Registry and reloads
+	endpoints            endpointState                    // Health of the primary and failover endpoints
+	standby              standbyState                     // Probed health of standby deployments
+	deploymentLimits     sync.Map                         // ModelLimits of defined deployments
+	deploymentLimiters   sync.Map                         // Limiters of RateLimits by deployment
+	runtimeSettings      atomic.Pointer[RuntimeSettings]  // Settings applied with UpdateSettings
+	capabilities         atomic.Pointer[CapabilityReport] // Result of the last capability probe
+	budget               *tokenBudget                     // Enforces TokenBudget
+	lifecycle            lifecycleState                   // Requests in flight, drained by Close
+	cacheStatsMu         sync.Mutex
+	cacheStats           map[string]PromptCacheStats // Prompt cache usage per deployment
+	scopes               map[string]*Scope           // Scopes by name, for usage snapshots
+	restoredUsage        map[string]map[string]Usage // Usage restored for scopes created later
+	defaultModelMu       sync.Mutex
+	defaultModelDeclared bool // Whether the default model alias is registered
+	client               openai.Client
+	initted              bool // Whether the plugin has been initialized
 }
 
 // ModelDefinition represents a model with its name and type.
 type ModelDefinition struct {
 	Name          string // Model deployment name in Azure AI Foundry
 	Type          string // Type: "chat", "text"
-	MaxTokens     int32  // Maximum tokens the model can handle (optional)
+	MaxTokens     int32  // Context window in tokens, overriding ModelCatalog (optional)
 	SupportsMedia bool   // Whether the model supports media (images, audio) (optional)
+	SystemRole    string // Role system messages are sent with: "system" or "developer". Defaults to "developer" for o-series and GPT-5 models (optional)
+	Reasoning     bool   // The deployment serves a reasoning model (o-series, GPT-5) not in ModelCatalog, e.g. a custom name without ModelVersion (optional)
+	ServiceTier   string // Processing tier of requests that do not set the "serviceTier" config option: "auto", "default", "flex" or "priority" (optional)
+	EmulateTools  bool   // Emulate tool calling with JSON prompting for models without native tool support (optional)
+
+	ModelVersion     string         // Underlying model and version, e.g. "gpt-4o-2024-08-06", checked for retirement. Defaults to Name (optional)
+	SystemPrompt     string         // Name of a SystemPrompts template prepended to every request (optional)
+	SystemPromptVars map[string]any // Variables for the system prompt template (optional)
+	DefaultConfig    map[string]any // Config applied to every request; request config takes precedence (optional)
+	RateLimit        *RateLimit     // Client-side request and token limits for the deployment (optional)
+	Guardrail        string         // Name of a guardrail profile enforced on every request (optional)
+
+	StreamTransformers []StreamTransformerFactory // Rewrite the answer text, applied in order (optional)
 }
 
-// Name returns the provider name.
+// Name returns the provider name, ProviderID when set.
 func (a *AzureAIFoundry) Name() string {
+	if a.ProviderID != "" {
+		return a.ProviderID
+	}
 	return provider
 }
 
@@ -92,39 +224,81 @@ func (a *AzureAIFoundry) Init(ctx context.Context) []api.Action {
 		panic("azureaifoundry: Endpoint is required")
 	}
 
-	// Set default API version if not specified
-	apiVersion := a.APIVersion
-	if apiVersion == "" {
-		apiVersion = "2025-03-01-preview"
+	// Point the client at the endpoint and authenticate requests
+	opts, err := a.connectionOptions()
+	if err != nil {
+		panic(fmt.Sprintf("azureaifoundry: %v", err))
 	}
 
-	// Create client options using Azure-specific configuration
-	var opts []option.RequestOption
-
-	// Use azure.WithEndpoint which properly handles Azure OpenAI deployment-based URLs
-	opts = append(opts, azure.WithEndpoint(a.Endpoint, apiVersion))
-
-	if a.APIKey != "" {
-		// Use API key authentication
-		opts = append(opts, azure.WithAPIKey(a.APIKey))
-	} else if a.Credential != nil {
-		// Use token credential
-		opts = append(opts, azure.WithTokenCredential(a.Credential))
-	} else {
-		// Try default Azure credential
-		cred, err := azidentity.NewDefaultAzureCredential(nil)
-		if err != nil {
-			panic(fmt.Sprintf("azureaifoundry: failed to create default credential: %v", err))
+	// A transport of its own lets Close release the plugin's connections
+	a.lifecycle.transport = http.DefaultTransport.(*http.Transport).Clone()
+	opts = append(opts, option.WithHTTPClient(&http.Client{Transport: a.lifecycle.transport}))
+	opts = append(opts, option.WithMiddleware(a.requestModelMiddleware(), a.retryEventMiddleware()))
+	opts = append(opts, a.retryOptions()...)
+	opts = append(opts, option.WithMiddleware(a.rateLimitMiddleware()))
+	if !a.DataHandling.IsZero() {
+		opts = append(opts, option.WithMiddleware(a.dataHandlingMiddleware()))
+	}
+	if a.AuditSink != nil {
+		opts = append(opts, option.WithMiddleware(a.auditMiddleware()))
+	}
+	if len(a.FailoverEndpoints) > 0 {
+		// Innermost, so every endpoint attempt is part of the same audited request
+		if err := a.initEndpoints(); err != nil {
+			panic(fmt.Sprintf("azureaifoundry: %v", err))
 		}
-		opts = append(opts, azure.WithTokenCredential(cred))
+		opts = append(opts, option.WithMiddleware(a.endpointFailoverMiddleware()))
+	}
+	if a.usesGatewayPaths() {
+		// After failover, so every middleware sees Azure's paths
+		opts = append(opts, option.WithMiddleware(a.gatewayPathMiddleware()))
 	}
 
+	if a.TokenBudget != nil {
+		a.budget = newTokenBudget(*a.TokenBudget)
+	}
 	a.client = openai.NewClient(opts...)
 	a.initted = true
 
+	if a.CapabilityProbe != nil {
+		if _, err := a.ProbeCapabilities(ctx, *a.CapabilityProbe); err != nil {
+			logger.FromContext(ctx).Warn("azureaifoundry: capability probe failed", "err", a.redactError(err))
+		}
+	}
+
+	if a.Registry != nil {
+		actions, _, err := a.applyRegistry(a.Registry)
+		if err != nil {
+			panic(err)
+		}
+		return actions
+	}
 	return []api.Action{}
 }
 
+// modelMeta returns the metadata a model is registered with
+func (a *AzureAIFoundry) modelMeta(model ModelDefinition, info *ai.ModelInfo) *ai.ModelOptions {
+	// Auto-detect model capabilities if not provided
+	if info == nil {
+		info = a.inferModelCapabilities(model.Name, model.SupportsMedia)
+	}
+	meta := &ai.ModelOptions{
+		Label:    a.Name() + "-" + model.Name,
+		Supports: info.Supports,
+		Versions: info.Versions,
+	}
+	if model.EmulateTools {
+		supports := ai.ModelSupports{}
+		if info.Supports != nil {
+			supports = *info.Supports
+		}
+		supports.Tools = true
+		meta.Supports = &supports
+		meta.Label += emulatedToolsLabel
+	}
+	return meta
+}
+
 // DefineModel defines a model in the registry.
 func (a *AzureAIFoundry) DefineModel(g *genkit.Genkit, model ModelDefinition, info *ai.ModelInfo) ai.Model {
 	a.mu.Lock()
@@ -134,26 +308,83 @@ func (a *AzureAIFoundry) DefineModel(g *genkit.Genkit, model ModelDefinition, in
 		panic("azureaifoundry: Init not called")
 	}
 
-	// Auto-detect model capabilities if not provided
-	if info == nil {
-		info = a.inferModelCapabilities(model.Name, model.SupportsMedia)
+	meta, fn := a.modelAction(model, info)
+	return genkit.DefineModel(g, api.NewName(a.Name(), model.Name), meta, fn)
+}
+
+// modelAction returns the metadata and model function for a model definition
+func (a *AzureAIFoundry) modelAction(model ModelDefinition, info *ai.ModelInfo) (*ai.ModelOptions, ai.ModelFunc) {
+	meta := a.modelMeta(model, info)
+	systemPrompt := a.systemPromptTemplate(model)
+	a.warnRetirement(context.Background(), model)
+	a.defineLimits(model)
+
+	limiter := a.deploymentLimiter(model.Name)
+	if model.RateLimit != nil {
+		limiter = newRateLimiter(*model.RateLimit)
+	}
+	defaults := model.DefaultConfig
+	if _, ok := defaults["serviceTier"]; !ok && model.ServiceTier != "" {
+		defaults = maps.Clone(defaults)
+		if defaults == nil {
+			defaults = map[string]any{}
+		}
+		defaults["serviceTier"] = model.ServiceTier
 	}
 
-	// Create model metadata
-	meta := &ai.ModelOptions{
-		Label:    provider + "-" + model.Name,
-		Supports: info.Supports,
-		Versions: info.Versions,
+	generate := a.generateText
+	if model.EmulateTools {
+		generate = a.generateWithEmulatedTools
 	}
 
 	// Create the model function
-	return genkit.DefineModel(g, api.NewName(provider, model.Name), meta, func(
+	fn := func(
 		ctx context.Context,
 		input *ai.ModelRequest,
 		cb func(context.Context, *ai.ModelResponseChunk) error,
 	) (*ai.ModelResponse, error) {
-		return a.generateText(ctx, model.Name, input, cb)
-	})
+		a.warnRetirement(ctx, model)
+		if systemPrompt != nil {
+			var err error
+			if input, err = withSystemPrompt(input, systemPrompt, model.SystemPromptVars); err != nil {
+				return nil, err
+			}
+		}
+		if len(defaults) > 0 {
+			input = withDefaultConfig(input, defaults)
+		}
+		var request *rateRequest
+		if limiter != nil {
+			var err error
+			if request, err = limiter.wait(ctx, a.estimatedTokens(model.Name, input)); err != nil {
+				return nil, fmt.Errorf("rate limit of %s: %w", model.Name, err)
+			}
+		}
+		deployment := a.experimentDeployment(model.Name)
+		resp, err := generate(ctx, deployment, input, cb)
+		if limiter != nil {
+			var usage *ai.GenerationUsage
+			if resp != nil {
+				usage = resp.Usage
+			}
+			limiter.finish(request, usage, err)
+		}
+		if resp != nil {
+			if deployment != model.Name {
+				resp.Custom = withCustomValue(resp.Custom, "experiment", deployment)
+			}
+		}
+		return resp, err
+	}
+	if a.Fallback != nil {
+		fn = a.withFallback(model.Name, fn)
+	}
+	fn = a.withResponseLanguage(fn)
+	fn = a.withGuardrails(model.Guardrail, fn)
+	if len(model.StreamTransformers) > 0 {
+		fn = withStreamTransformers(model.StreamTransformers, fn)
+	}
+	return meta, a.withRedaction(fn)
 }
 
 // DefineEmbedder defines an embedder in the registry.
@@ -165,12 +396,33 @@ func (a *AzureAIFoundry) DefineEmbedder(g *genkit.Genkit, modelName string) ai.E
 		panic("azureaifoundry: Init not called")
 	}
 
-	return genkit.DefineEmbedder(g, api.NewName(provider, modelName), nil, func(
+	return genkit.DefineEmbedder(g, api.NewName(a.Name(), modelName), nil, a.embedderFunc(modelName))
+}
+
+// embedderFunc returns the embedder function for a deployment
+func (a *AzureAIFoundry) embedderFunc(modelName string) ai.EmbedderFunc {
+	model := ModelDefinition{Name: modelName}
+	a.warnRetirement(context.Background(), model)
+	limiter := a.deploymentLimiter(modelName)
+
+	return func(
 		ctx context.Context,
 		req *ai.EmbedRequest,
 	) (*ai.EmbedResponse, error) {
-		return a.embed(ctx, modelName, req)
-	})
+		a.warnRetirement(ctx, model)
+		var request *rateRequest
+		if limiter != nil {
+			var err error
+			if request, err = limiter.wait(ctx, a.estimatedEmbedTokens(modelName, req)); err != nil {
+				return nil, fmt.Errorf("rate limit of %s: %w", modelName, err)
+			}
+		}
+		resp, err := a.embed(ctx, modelName, req)
+		if limiter != nil {
+			limiter.finish(request, nil, err)
+		}
+		return resp, a.redactError(err)
+	}
 }
 
 // ImageGenerationRequest represents a request to generate images
@@ -181,6 +433,7 @@ type ImageGenerationRequest struct {
 	Quality        string // Quality: "standard" or "hd" (DALL-E 3 only)
 	Style          string // Style: "vivid" or "natural" (DALL-E 3 only)
 	ResponseFormat string // Format: "url" or "b64_json"
+	User           string // End-user ID for abuse monitoring. Defaults to AzureAIFoundry.EndUser or the request attribution
 }
 
 // ImageGenerationResponse represents the response from image generation
@@ -227,6 +480,9 @@ func (a *AzureAIFoundry) generateImagesInternal(ctx context.Context, modelName s
 	if req.ResponseFormat != "" {
 		params.ResponseFormat = openai.ImageGenerateParamsResponseFormat(req.ResponseFormat)
 	}
+	if user := a.endUser(ctx, req.User); user != "" {
+		params.User = openai.String(user)
+	}
 
 	// Generate images
 	resp, err := client.Images.Generate(ctx, params)
@@ -251,6 +507,7 @@ func (a *AzureAIFoundry) generateImagesInternal(ctx context.Context, modelName s
 
 // TTSRequest represents a text-to-speech request
 type TTSRequest struct {
+	Model          string  // TTS deployment name, required by SynthesizeTo
 	Input          string  // The text to synthesize
 	Voice          string  // Voice: "alloy", "echo", "fable", "onyx", "nova", "shimmer"
 	ResponseFormat string  // Format: "mp3", "opus", "aac", "flac", "wav", "pcm"
@@ -262,8 +519,9 @@ type TTSResponse struct {
 	Audio []byte // The audio data
 }
 
-// generateSpeechInternal converts text to speech using TTS models
-func (a *AzureAIFoundry) generateSpeechInternal(ctx context.Context, modelName string, req *TTSRequest) (*TTSResponse, error) {
+// generateSpeechInternal converts text to speech using TTS models. When onAudio is set, it
+// receives the audio as it is read from the response body.
+func (a *AzureAIFoundry) generateSpeechInternal(ctx context.Context, modelName string, req *TTSRequest, onAudio func([]byte) error) (*TTSResponse, error) {
 	a.mu.Lock()
 	if !a.initted {
 		a.mu.Unlock()
@@ -272,30 +530,14 @@ func (a *AzureAIFoundry) generateSpeechInternal(ctx context.Context, modelName s
 	client := a.client
 	a.mu.Unlock()
 
-	// Build TTS parameters
-	params := openai.AudioSpeechNewParams{
-		Model: openai.SpeechModel(modelName),
-		Input: req.Input,
-		Voice: openai.AudioSpeechNewParamsVoiceUnion{
-			OfString: openai.String(req.Voice),
-		},
-	}
-
-	if req.ResponseFormat != "" {
-		params.ResponseFormat = openai.AudioSpeechNewParamsResponseFormat(req.ResponseFormat)
-	}
-	if req.Speed > 0 {
-		params.Speed = openai.Float(req.Speed)
-	}
-
 	// Generate speech
-	resp, err := client.Audio.Speech.New(ctx, params)
+	resp, err := client.Audio.Speech.New(ctx, speechParams(modelName, req))
 	if err != nil {
 		return nil, fmt.Errorf("speech generation failed: %w", err)
 	}
 
 	// Read all audio data from the response body
-	audioData, err := io.ReadAll(resp.Body)
+	audioData, err := readSpeech(resp.Body, onAudio)
 	if closeErr := resp.Body.Close(); closeErr != nil {
 		return nil, fmt.Errorf("failed to close response body: %w", closeErr)
 	}
@@ -308,21 +550,60 @@ func (a *AzureAIFoundry) generateSpeechInternal(ctx context.Context, modelName s
 	}, nil
 }
 
+// speechParams builds the TTS parameters of a request
+func speechParams(modelName string, req *TTSRequest) openai.AudioSpeechNewParams {
+	params := openai.AudioSpeechNewParams{
+		Model: openai.SpeechModel(modelName),
+		Input: req.Input,
+		Voice: openai.AudioSpeechNewParamsVoiceUnion{
+			OfString: openai.String(req.Voice),
+		},
+	}
+
+	if req.ResponseFormat != "" {
+		params.ResponseFormat = openai.AudioSpeechNewParamsResponseFormat(req.ResponseFormat)
+	}
+	if req.Speed > 0 {
+		params.Speed = openai.Float(req.Speed)
+	}
+	return params
+}
+
 // STTRequest represents a speech-to-text request
 type STTRequest struct {
 	Audio          []byte  // The audio file content
 	Filename       string  // Filename with extension (e.g., "audio.mp3", "audio.wav") - required for format detection
 	Language       string  // Language code (e.g., "en", "es")
 	Prompt         string  // Optional text to guide the model's style
-	ResponseFormat string  // Format: "json", "text", "srt", "verbose_json", "vtt"
+	ResponseFormat string  // Format: "json", "text", "srt", "verbose_json", "vtt", "diarized_json"
 	Temperature    float64 // Temperature (0 to 1)
+	AutoChunking   bool    // Let the service split long audio with voice activity detection (required for diarization over 30s)
+
+	TrimSilence *VADOptions // Optional: Cut silence from WAV audio before upload, overriding AzureAIFoundry.TrimSilence
+	KeepSilence bool        // Send the audio untrimmed even when AzureAIFoundry.TrimSilence is set
 }
 
 // STTResponse represents the speech-to-text response
 type STTResponse struct {
-	Text     string  // Transcribed text
-	Language string  // Detected language
-	Duration float64 // Duration in seconds
+	Text        string              // Transcribed text
+	Language    string              // Detected language
+	Duration    float64             // Duration in seconds
+	Segments    []TranscriptSegment // Timed segments ("verbose_json" and "diarized_json" formats)
+	Model       string              // Deployment that produced the transcript, the fallback model after re-transcription
+	NeedsReview bool                // Low-confidence segments were sent for review (LowConfidenceReview)
+}
+
+// TranscriptSegment is a timed part of a transcription.
+type TranscriptSegment struct {
+	Start   float64 `json:"start"`             // Start time in seconds
+	End     float64 `json:"end"`               // End time in seconds
+	Text    string  `json:"text"`              // Transcribed text
+	Speaker string  `json:"speaker,omitempty"` // Speaker label ("diarized_json" format)
+
+	AvgLogprob       float64 `json:"avg_logprob,omitempty"`       // Average token log probability ("verbose_json" format)
+	NoSpeechProb     float64 `json:"no_speech_prob,omitempty"`    // Probability that the segment is silence ("verbose_json" format)
+	CompressionRatio float64 `json:"compression_ratio,omitempty"` // Text compression ratio; high values indicate repetition ("verbose_json" format)
+	LowConfidence    bool    `json:"lowConfidence,omitempty"`     // Set when the segment falls outside the ConfidencePolicy thresholds
 }
 
 // transcribeAudioInternal transcribes audio to text using Whisper models
@@ -341,11 +622,21 @@ func (a *AzureAIFoundry) transcribeAudioInternal(ctx context.Context, modelName
 		filename = "audio.mp3" // Default to mp3 if not specified
 	}
 
+	// Cut silence locally so long, mostly silent recordings are cheaper and faster to transcribe
+	audio := req.Audio
+	trimmed := a.trimSilence(ctx, req)
+	if trimmed != nil {
+		if len(trimmed.Regions) == 0 {
+			return &STTResponse{Language: req.Language, Duration: trimmed.Duration}, nil
+		}
+		audio = trimmed.Audio
+	}
+
 	// Create a named reader for the file upload
 	// The openai SDK expects an io.Reader, and the filename is inferred from the field name
 	// We need to use a file-like reader that can provide metadata
 	file := &fileReader{
-		Reader: bytes.NewReader(req.Audio),
+		Reader: bytes.NewReader(audio),
 		name:   filename,
 	}
 
@@ -358,8 +649,8 @@ func (a *AzureAIFoundry) transcribeAudioInternal(ctx context.Context, modelName
 	if req.Language != "" {
 		params.Language = openai.String(req.Language)
 	}
-	if req.Prompt != "" {
-		params.Prompt = openai.String(req.Prompt)
+	if prompt := a.vocabularyPrompt(ctx, modelName, req.Prompt); prompt != "" {
+		params.Prompt = openai.String(prompt)
 	}
 	if req.ResponseFormat != "" {
 		params.ResponseFormat = openai.AudioResponseFormat(req.ResponseFormat)
@@ -367,6 +658,11 @@ func (a *AzureAIFoundry) transcribeAudioInternal(ctx context.Context, modelName
 	if req.Temperature > 0 {
 		params.Temperature = openai.Float(req.Temperature)
 	}
+	if req.AutoChunking {
+		params.ChunkingStrategy = openai.AudioTranscriptionNewParamsChunkingStrategyUnion{
+			OfAuto: constant.ValueOf[constant.Auto](),
+		}
+	}
 
 	// Transcribe audio
 	resp, err := client.Audio.Transcriptions.New(ctx, params)
@@ -374,24 +670,48 @@ func (a *AzureAIFoundry) transcribeAudioInternal(ctx context.Context, modelName
 		return nil, fmt.Errorf("audio transcription failed: %w", err)
 	}
 
-	return &STTResponse{
+	// Segments are decoded from the raw JSON because diarized segments carry speaker labels
+	var segments struct {
+		Segments []TranscriptSegment `json:"segments"`
+	}
+	if raw := resp.RawJSON(); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &segments)
+	}
+
+	result := &STTResponse{
 		Text:     resp.Text,
 		Language: resp.Language,
 		Duration: resp.Duration,
-	}, nil
+		Segments: segments.Segments,
+		Model:    modelName,
+	}
+	if trimmed != nil {
+		// Report timestamps against the original recording
+		result.Duration = trimmed.Duration
+		for i := range result.Segments {
+			result.Segments[i].Start = trimmed.OriginalTime(result.Segments[i].Start)
+			result.Segments[i].End = trimmed.OriginalTime(result.Segments[i].End)
+		}
+	}
+	return a.applyConfidencePolicy(ctx, modelName, req, result)
 }
 
 // inferModelCapabilities infers model capabilities based on model info.
 func (a *AzureAIFoundry) inferModelCapabilities(modelName string, supportsMedia bool) *ai.ModelInfo {
 	// Detect tool support based on model name
 	supportsTools := supportsToolCalling(modelName)
+	constrained := ai.ConstrainedSupportNone
+	if supportsTools && supportsStructuredOutputs(modelName) {
+		constrained = ai.ConstrainedSupportAll
+	}
 	return &ai.ModelInfo{
 		Label: modelName,
 		Supports: &ai.ModelSupports{
-			Multiturn:  true,
-			Tools:      supportsTools,
-			SystemRole: true,
-			Media:      supportsMedia,
+			Multiturn:   true,
+			Tools:       supportsTools,
+			SystemRole:  true,
+			Media:       supportsMedia,
+			Constrained: constrained,
 		},
 	}
 }
@@ -409,33 +729,148 @@ func supportsToolCalling(modelName string) bool {
 }
 
 // generateText handles text generation using Azure OpenAI
-func (a *AzureAIFoundry) generateText(ctx context.Context, modelName string, input *ai.ModelRequest, cb func(context.Context, *ai.ModelResponseChunk) error) (*ai.ModelResponse, error) {
-	modelLower := strings.ToLower(modelName)
+func (a *AzureAIFoundry) generateText(ctx context.Context, modelName string, input *ai.ModelRequest, cb func(context.Context, *ai.ModelResponseChunk) error) (resp *ai.ModelResponse, err error) {
+	eventCtx := ctx
+	ctx, cb, timedOut := a.withTimeouts(ctx, modelName, cb)
+	started := Event{
+		Type:      EventRequestStarted,
+		Time:      time.Now(),
+		Model:     modelName,
+		Operation: operationForModel(modelName),
+		Streaming: cb != nil,
+	}
+	a.emit(ctx, started)
+	defer func() {
+		err = a.explainError(rateLimitError(flowBudgetError(eventCtx, timedOut(err))))
+		var usage *ai.GenerationUsage
+		if resp != nil {
+			usage = resp.Usage
+		}
+		recordFlowBudget(eventCtx, usage)
+		if started.Operation == OperationChat {
+			a.recordPromptCache(modelName, usage)
+		}
+		a.emitCompleted(eventCtx, started, resp, usage, err)
+	}()
 
-	// Handle image generation models (DALL-E)
-	if strings.Contains(modelLower, "dall-e") || strings.Contains(modelLower, "gpt-image") {
-		return a.generateImages(ctx, modelName, input)
+	var categories []string
+	if ctx, categories, err = a.classifyRequest(ctx, input); err != nil {
+		return nil, err
 	}
-
-	// Handle text-to-speech models
-	if strings.Contains(modelLower, "tts-") || strings.Contains(modelLower, "tts") {
-		return a.generateSpeech(ctx, modelName, input)
+	if categories != nil {
+		defer func() {
+			if resp != nil {
+				resp.Custom = withCustomValue(resp.Custom, "dataCategories", categories)
+			}
+		}()
+	}
+	if err := a.checkResidency(ctx); err != nil {
+		return nil, err
+	}
+	var end func()
+	if ctx, end, err = a.lifecycle.track(ctx); err != nil {
+		return nil, err
+	}
+	defer end()
+	if err := chargeFlowBudget(ctx); err != nil {
+		return nil, err
+	}
+	if a.StrictConfig {
+		if err := validateConfig(modelName, started.Operation, input.Config); err != nil {
+			return nil, err
+		}
+	}
+	if !a.DisableMediaValidation {
+		if input, err = a.validateMedia(ctx, modelName, started.Operation, input); err != nil {
+			return nil, err
+		}
 	}
 
-	// Handle speech-to-text models (Whisper, transcribe)
-	if strings.Contains(modelLower, "whisper") || strings.Contains(modelLower, "transcribe") {
+	switch started.Operation {
+	case OperationImage:
+		// Handle image generation models (DALL-E)
+		return a.generateImages(ctx, modelName, input)
+	case OperationSpeech:
+		// Handle text-to-speech models
+		return a.generateSpeech(ctx, modelName, input, cb)
+	case OperationTranscription:
+		// Handle speech-to-text models (Whisper, transcribe)
 		return a.transcribeAudioFromRequest(ctx, modelName, input)
 	}
 
 	// Default: standard chat completion
+	if _, ignored := normalizeConfig(input.Config); len(ignored) > 0 {
+		logger.FromContext(ctx).Warn("azureaifoundry: ignoring config options not supported by Azure OpenAI",
+			"model", modelName, "options", ignored)
+	}
+	if config := a.extractConfigFromRequest(input); config.reasoningEffort != nil && !a.acceptsReasoningEffort(modelName) {
+		logger.FromContext(ctx).Warn("azureaifoundry: ignoring reasoningEffort for a model without reasoning", "model", modelName)
+	} else if (config.temperature != nil || config.topP != nil) && a.isReasoningModel(modelName) {
+		logger.FromContext(ctx).Warn("azureaifoundry: ignoring temperature and topP for a reasoning model", "model", modelName)
+	}
+	// The response keeps the original request, so compressed turns do not enter the history
+	original := input
+	compressed, compression := a.compressPrompt(modelName, input)
+	if compressed != nil {
+		input = compressed
+	}
+	if err := a.checkTokenLimits(modelName, input, a.extractConfigFromRequest(input).maxTokens); err != nil {
+		return nil, err
+	}
+	if a.budget != nil {
+		tokens := a.reservedTokens(a.CountTokens(modelName, input.Messages), a.extractConfigFromRequest(input).maxTokens)
+		release, err := a.budget.acquire(ctx, tokens, PriorityFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	// Build chat completion parameters
 	params := a.buildChatCompletionParams(input, modelName)
+	a.applyEndUser(ctx, &params)
+	a.applyAttribution(ctx, &params)
 
 	// Handle streaming vs non-streaming
-	if cb != nil {
-		return a.generateTextStream(ctx, params, input, cb)
+	ctx, rateLimit := withRateLimitRecorder(ctx)
+	call := func(cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		if cb != nil && a.StreamResumeAttempts > 0 {
+			return a.generateTextStreamResumable(ctx, params, input, cb)
+		}
+		if cb != nil {
+			return a.generateTextStream(ctx, params, input, cb)
+		}
+		return a.generateTextSync(ctx, params, input)
+	}
+	if a.extractConfigFromRequest(input).footnotes {
+		footnotes := NewCitationFootnotes(input.Docs)
+		resp, err = transformResponse(ctx, footnotes, cb, call)
+		if resp != nil {
+			resp.Custom = withCustomValue(resp.Custom, "footnotes", footnotes.Footnotes())
+		}
+	} else {
+		resp, err = call(cb)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = original
+	if compression != nil {
+		resp.Custom = withCustomValue(resp.Custom, "promptCompression", compression)
 	}
-	return a.generateTextSync(ctx, params, input)
+	if status := rateLimit.last(); status != nil {
+		resp.Custom = withCustomValue(resp.Custom, "rateLimit", status)
+	}
+
+	// Validate cited answers against the supplied documents
+	if a.extractConfigFromRequest(input).citations {
+		if err := applyCitations(resp, input.Docs); err != nil {
+			return nil, err
+		}
+	}
+	a.annotateChatParts(resp, modelName)
+
+	return resp, nil
 }
 
 // generateImages handles image generation through Genkit's Generate interface
@@ -459,12 +894,18 @@ func (a *AzureAIFoundry) generateImages(ctx context.Context, modelName string, i
 		Style:          "vivid",
 		ResponseFormat: "url",
 	}
+	if strings.Contains(strings.ToLower(modelName), "gpt-image") {
+		// gpt-image models always return base64 data and have no style option
+		req.Quality = "auto"
+		req.Style = ""
+		req.ResponseFormat = ""
+	}
 
 	// Apply config from input if available
 	if input.Config != nil {
 		if configMap, ok := input.Config.(map[string]interface{}); ok {
-			if n, ok := configMap["n"].(int); ok {
-				req.N = n
+			if n, ok := configInt(configMap["n"]); ok {
+				req.N = int(n)
 			}
 			if size, ok := configMap["size"].(string); ok {
 				req.Size = size
@@ -478,6 +919,9 @@ func (a *AzureAIFoundry) generateImages(ctx context.Context, modelName string, i
 			if format, ok := configMap["response_format"].(string); ok {
 				req.ResponseFormat = format
 			}
+			if user, ok := configMap["user"].(string); ok {
+				req.User = user
+			}
 		}
 	}
 
@@ -490,11 +934,22 @@ func (a *AzureAIFoundry) generateImages(ctx context.Context, modelName string, i
 	// Convert to ModelResponse
 	var content []*ai.Part
 	for _, img := range resp.Images {
+		meta := a.partMetadata(modelName)
+		meta.MIME, meta.ImageSize, meta.RevisedPrompt = "image/png", req.Size, img.RevisedPrompt
+		if meta.RevisedPrompt == "" {
+			meta.RevisedPrompt = resp.RevisedPrompt
+		}
+		var part *ai.Part
 		if img.URL != "" {
-			content = append(content, ai.NewTextPart(img.URL))
+			part = ai.NewTextPart(img.URL)
 		} else if img.B64JSON != "" {
-			content = append(content, ai.NewTextPart(img.B64JSON))
+			part = ai.NewTextPart(img.B64JSON)
+			meta.ByteSize = decodedSize(img.B64JSON)
+		} else {
+			continue
 		}
+		meta.apply(part)
+		content = append(content, part)
 	}
 
 	return &ai.ModelResponse{
@@ -506,8 +961,9 @@ func (a *AzureAIFoundry) generateImages(ctx context.Context, modelName string, i
 	}, nil
 }
 
-// generateSpeech handles text-to-speech through Genkit's Generate interface
-func (a *AzureAIFoundry) generateSpeech(ctx context.Context, modelName string, input *ai.ModelRequest) (*ai.ModelResponse, error) {
+// generateSpeech handles text-to-speech through Genkit's Generate interface. Streaming
+// requests receive the audio as media parts while it is read.
+func (a *AzureAIFoundry) generateSpeech(ctx context.Context, modelName string, input *ai.ModelRequest, cb func(context.Context, *ai.ModelResponseChunk) error) (*ai.ModelResponse, error) {
 	// Extract text from messages
 	var text string
 	for _, msg := range input.Messages {
@@ -535,25 +991,45 @@ func (a *AzureAIFoundry) generateSpeech(ctx context.Context, modelName string, i
 			if format, ok := configMap["response_format"].(string); ok {
 				req.ResponseFormat = format
 			}
-			if speed, ok := configMap["speed"].(float64); ok {
+			if speed, ok := configFloat(configMap["speed"]); ok {
 				req.Speed = speed
 			}
+			// Pick a voice for the text's language from the plugin's Voices table
+			if req.Voice == autoVoice {
+				language, _ := configMap["language"].(string)
+				profile := a.selectVoice(text, language)
+				req.Voice = profile.Voice
+				if _, ok := configFloat(configMap["speed"]); !ok && profile.Speed > 0 {
+					req.Speed = profile.Speed
+				}
+			}
 		}
 	}
 
 	// Generate speech
-	resp, err := a.generateSpeechInternal(ctx, modelName, req)
+	var onAudio func([]byte) error
+	if cb != nil {
+		onAudio = func(audio []byte) error {
+			return cb(ctx, &ai.ModelResponseChunk{Role: ai.RoleModel, Content: []*ai.Part{speechChunkPart(req.ResponseFormat, audio)}})
+		}
+	}
+	resp, err := a.generateSpeechInternal(ctx, modelName, req, onAudio)
 	if err != nil {
 		return nil, err
 	}
 
 	// Return audio as base64-encoded text (following Genkit pattern)
 	audioBase64 := base64.StdEncoding.EncodeToString(resp.Audio)
+	part := ai.NewTextPart(audioBase64)
+	meta := a.partMetadata(modelName)
+	meta.MIME, meta.ByteSize, meta.Voice = speechMIMETypes[req.ResponseFormat], len(resp.Audio), req.Voice
+	meta.DurationSec = speechDuration(req.ResponseFormat, resp.Audio)
+	meta.apply(part)
 
 	return &ai.ModelResponse{
 		Message: &ai.Message{
 			Role:    ai.RoleModel,
-			Content: []*ai.Part{ai.NewTextPart(audioBase64)},
+			Content: []*ai.Part{part},
 		},
 		FinishReason: ai.FinishReasonStop,
 	}, nil
@@ -580,15 +1056,11 @@ func (a *AzureAIFoundry) transcribeAudioFromRequest(ctx context.Context, modelNa
 					}
 
 					// Extract format from media type
-					if strings.Contains(mediaText, "audio/mp3") || strings.Contains(mediaText, "audio/mpeg") {
-						filename = "audio.mp3"
-					} else if strings.Contains(mediaText, "audio/wav") {
-						filename = "audio.wav"
-					} else if strings.Contains(mediaText, "audio/opus") {
-						filename = "audio.opus"
-					} else {
-						filename = "audio.mp3" // default
+					contentType := part.ContentType
+					if contentType == "" {
+						contentType, _, _ = strings.Cut(strings.TrimPrefix(mediaText, "data:"), ";")
 					}
+					filename = audioFilename(contentType)
 				}
 			}
 		}
@@ -617,9 +1089,15 @@ func (a *AzureAIFoundry) transcribeAudioFromRequest(ctx context.Context, modelNa
 			if format, ok := configMap["response_format"].(string); ok {
 				req.ResponseFormat = format
 			}
-			if temp, ok := configMap["temperature"].(float64); ok {
+			if temp, ok := configFloat(configMap["temperature"]); ok {
 				req.Temperature = temp
 			}
+			if trim, ok := configMap["trimSilence"].(bool); ok {
+				req.KeepSilence = !trim
+				if trim && a.TrimSilence == nil {
+					req.TrimSilence = &VADOptions{}
+				}
+			}
 		}
 	}
 
@@ -629,10 +1107,21 @@ func (a *AzureAIFoundry) transcribeAudioFromRequest(ctx context.Context, modelNa
 		return nil, err
 	}
 
+	part := ai.NewTextPart(resp.Text)
+	meta := a.partMetadata(resp.Model)
+	if meta.Deployment == "" {
+		meta.Deployment = modelName
+	}
+	meta.MIME, meta.DurationSec = "text/plain", resp.Duration
+	if mime, ok := transcriptMIMETypes[req.ResponseFormat]; ok {
+		meta.MIME = mime
+	}
+	meta.apply(part)
+
 	return &ai.ModelResponse{
 		Message: &ai.Message{
 			Role:    ai.RoleModel,
-			Content: []*ai.Part{ai.NewTextPart(resp.Text)},
+			Content: []*ai.Part{part},
 		},
 		FinishReason: ai.FinishReasonStop,
 	}, nil
@@ -734,7 +1223,7 @@ func (a *AzureAIFoundry) convertMessagesToOpenAI(messages []*ai.Message) []opena
 					}
 					toolCalls = append(toolCalls, openai.ChatCompletionMessageToolCallUnionParam{
 						OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
-							ID:   fmt.Sprintf("call_%s", toolReq.Name),
+							ID:   toolCallID(toolReq.Name, toolReq.Ref),
 							Type: "function",
 							Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
 								Name:      toolReq.Name,
@@ -773,7 +1262,7 @@ func (a *AzureAIFoundry) convertMessagesToOpenAI(messages []*ai.Message) []opena
 							Content: openai.ChatCompletionToolMessageParamContentUnion{
 								OfString: openai.String(string(outputJSON)),
 							},
-							ToolCallID: fmt.Sprintf("call_%s", toolResp.Name),
+							ToolCallID: toolCallID(toolResp.Name, toolResp.Ref),
 						},
 					})
 				}
@@ -784,43 +1273,90 @@ func (a *AzureAIFoundry) convertMessagesToOpenAI(messages []*ai.Message) []opena
 	return openAIMessages
 }
 
+// toolCallID returns the tool call ID Azure assigned to a tool request, falling back to one
+// derived from the tool name for requests that did not come from Azure
+func toolCallID(name, ref string) string {
+	if ref != "" {
+		return ref
+	}
+	return fmt.Sprintf("call_%s", name)
+}
+
 // extractConfig extracts and validates configuration values from a ModelRequest
 type modelConfig struct {
 	maxTokens       *int64
 	temperature     *float64
 	topP            *float64
 	toolChoice      string
-	reasoningEffort *string // "none", "minimal", "low", "medium", "high", "xhigh"
+	reasoningEffort *string        // "none", "minimal", "low", "medium", "high", "xhigh"
+	citations       bool           // Return a CitedAnswer validated against the request documents
+	footnotes       bool           // Render "[docN]" citations as footnotes
+	responseFormat  string         // "text", "json_object" or "json_schema"
+	jsonSchema      map[string]any // Strict schema for structured outputs
+	stripReasoning  bool           // Drop the reasoning of reasoning models instead of returning it as a part
+	logprobs        bool           // Return the log probability of each token
+	topLogprobs     *int64         // Number of most likely alternatives returned per token
+	seed            *int64         // Best-effort deterministic sampling
+	serviceTier     string         // "auto", "default", "flex", "scale" or "priority"
+	user            string         // End-user ID for abuse monitoring
+	stop            []string
 }
 
 // extractConfigFromRequest safely extracts configuration values from request
 func (a *AzureAIFoundry) extractConfigFromRequest(input *ai.ModelRequest) *modelConfig {
 	config := &modelConfig{}
 
-	if input.Config == nil {
-		return config
-	}
-
-	configMap, ok := input.Config.(map[string]interface{})
-	if !ok {
-		return config
-	}
+	configMap, _ := normalizeConfig(input.Config)
 	if reasoningEffort, ok := configMap["reasoningEffort"].(string); ok {
 		config.reasoningEffort = &reasoningEffort
 	}
-	if maxTokens, ok := configMap["maxOutputTokens"].(int); ok {
-		val := int64(maxTokens)
-		config.maxTokens = &val
+	if maxTokens, ok := configInt(configMap["maxOutputTokens"]); ok {
+		config.maxTokens = &maxTokens
 	}
-	if temp, ok := configMap["temperature"].(float64); ok {
+	if temp, ok := configFloat(configMap["temperature"]); ok {
+		if limit := a.settings().MaxTemperature; limit > 0 && temp > limit {
+			temp = limit
+		}
 		config.temperature = &temp
 	}
-	if topP, ok := configMap["topP"].(float64); ok {
+	if topP, ok := configFloat(configMap["topP"]); ok {
 		config.topP = &topP
 	}
+	if stop := configStrings(configMap["stopSequences"]); len(stop) > 0 {
+		config.stop = stop
+	}
 	if toolChoice, ok := configMap["toolChoice"].(string); ok {
 		config.toolChoice = toolChoice
 	}
+	if citations, ok := configMap["citations"].(bool); ok {
+		config.citations = citations
+	}
+	if footnotes, ok := configMap["citationFootnotes"].(bool); ok {
+		config.footnotes = footnotes
+	}
+	if responseFormat, ok := configMap["responseFormat"].(string); ok {
+		config.responseFormat = responseFormat
+	}
+	config.jsonSchema = configSchema(configMap["jsonSchema"])
+	if stripReasoning, ok := configMap["stripReasoning"].(bool); ok {
+		config.stripReasoning = stripReasoning
+	}
+	if seed, ok := configInt(configMap["seed"]); ok {
+		config.seed = &seed
+	}
+	if logprobs, ok := configMap["logprobs"].(bool); ok {
+		config.logprobs = logprobs
+	}
+	if topLogprobs, ok := configInt(configMap["topLogprobs"]); ok {
+		config.topLogprobs = &topLogprobs
+		config.logprobs = true
+	}
+	if user, ok := configMap["user"].(string); ok {
+		config.user = user
+	}
+	if serviceTier, ok := configMap["serviceTier"].(string); ok {
+		config.serviceTier = serviceTier
+	}
 
 	return config
 }
@@ -837,15 +1373,39 @@ func (a *AzureAIFoundry) buildChatCompletionParams(input *ai.ModelRequest, model
 	// Apply configuration if provided
 	config := a.extractConfigFromRequest(input)
 	if config.maxTokens != nil {
-		params.MaxTokens = openai.Int(*config.maxTokens)
+		// Older api-versions only know max_tokens
+		if limits, _ := a.modelLimits(modelName); limits.MaxCompletionTokens && a.Capabilities().Supports(FeatureMaxCompletionTokens) {
+			params.MaxCompletionTokens = openai.Int(*config.maxTokens)
+		} else {
+			params.MaxTokens = openai.Int(*config.maxTokens)
+		}
 	}
-	if config.temperature != nil {
+	// Reasoning models reject sampling parameters
+	if config.temperature != nil && !a.isReasoningModel(modelName) {
 		params.Temperature = openai.Float(*config.temperature)
 	}
-	if config.topP != nil {
+	if config.topP != nil && !a.isReasoningModel(modelName) {
 		params.TopP = openai.Float(*config.topP)
 	}
-	if config.reasoningEffort != nil {
+	if config.seed != nil {
+		params.Seed = openai.Int(*config.seed)
+	}
+	if config.user != "" {
+		params.User = openai.String(config.user)
+	}
+	if config.serviceTier != "" && a.Capabilities().Supports(FeatureServiceTier) {
+		params.ServiceTier = openai.ChatCompletionNewParamsServiceTier(config.serviceTier)
+	}
+	if config.logprobs {
+		params.Logprobs = openai.Bool(true)
+	}
+	if config.topLogprobs != nil {
+		params.TopLogprobs = openai.Int(*config.topLogprobs)
+	}
+	if len(config.stop) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: config.stop}
+	}
+	if config.reasoningEffort != nil && a.acceptsReasoningEffort(modelName) {
 		// https://learn.microsoft.com/en-us/azure/ai-foundry/openai/how-to/reasoning?view=foundry-classic&tabs=REST%2Cgpt-5
 		reasoningEffortMap := map[string]openai.ReasoningEffort{
 			"low":     openai.ReasoningEffortLow,
@@ -860,6 +1420,18 @@ func (a *AzureAIFoundry) buildChatCompletionParams(input *ai.ModelRequest, model
 		}
 		// Invalid values are ignored, maintaining the default behavior.
 	}
+	applyResponseFormat(&params, config.responseFormat, config.jsonSchema, input)
+	if config.citations {
+		// Citations need their own schema, so they take precedence over responseFormat
+		params.Messages = append([]openai.ChatCompletionMessageParamUnion{{
+			OfSystem: &openai.ChatCompletionSystemMessageParam{
+				Content: openai.ChatCompletionSystemMessageParamContentUnion{
+					OfString: openai.String(citationsInstruction),
+				},
+			},
+		}}, params.Messages...)
+		params.ResponseFormat = citationsResponseFormat()
+	}
 	// Handle tools
 	if len(input.Tools) > 0 {
 		var tools []openai.ChatCompletionToolUnionParam
@@ -894,10 +1466,37 @@ func (a *AzureAIFoundry) buildChatCompletionParams(input *ai.ModelRequest, model
 			}
 		}
 	}
+	if a.usesDeveloperRole(modelName) {
+		params.Messages = withDeveloperRole(params.Messages)
+	}
+	if a.StablePromptPrefix {
+		stabilizePrefix(&params)
+	}
 
 	return params
 }
 
+// withDeveloperRole returns messages with system messages converted to developer messages,
+// which o-series models expect instead
+func withDeveloperRole(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	converted := make([]openai.ChatCompletionMessageParamUnion, len(messages))
+	for i, msg := range messages {
+		if system := msg.OfSystem; system != nil {
+			msg = openai.ChatCompletionMessageParamUnion{
+				OfDeveloper: &openai.ChatCompletionDeveloperMessageParam{
+					Content: openai.ChatCompletionDeveloperMessageParamContentUnion{
+						OfString:              system.Content.OfString,
+						OfArrayOfContentParts: system.Content.OfArrayOfContentParts,
+					},
+					Name: system.Name,
+				},
+			}
+		}
+		converted[i] = msg
+	}
+	return converted
+}
+
 // generateTextSync handles synchronous text generation
 func (a *AzureAIFoundry) generateTextSync(ctx context.Context, params openai.ChatCompletionNewParams, originalInput *ai.ModelRequest) (*ai.ModelResponse, error) {
 	resp, err := a.client.Chat.Completions.New(ctx, params)
@@ -913,10 +1512,46 @@ type toolCallAccumulator struct {
 	id        string
 	name      string
 	arguments strings.Builder
+	part      *ai.Part // Tool request, set once the call is complete
+	streamed  bool     // Whether the call was handed to the stream callback
+}
+
+// complete converts the call to a tool request part once its arguments have all arrived. A
+// call without a name has no part.
+func (t *toolCallAccumulator) complete() (*ai.Part, error) {
+	if t.part != nil || t.name == "" {
+		return t.part, nil
+	}
+	var args map[string]interface{}
+	if t.arguments.Len() > 0 {
+		if err := json.Unmarshal([]byte(t.arguments.String()), &args); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tool arguments for '%s': %w", t.name, err)
+		}
+	}
+	t.part = ai.NewToolRequestPart(&ai.ToolRequest{
+		Name:  t.name,
+		Ref:   t.id,
+		Input: args,
+	})
+	return t.part, nil
 }
 
 // generateTextStream handles streaming text generation
 func (a *AzureAIFoundry) generateTextStream(ctx context.Context, params openai.ChatCompletionNewParams, originalInput *ai.ModelRequest, cb func(context.Context, *ai.ModelResponseChunk) error) (*ai.ModelResponse, error) {
+	// Decouple reading from Azure from a slow callback
+	var buffer *streamBuffer
+	if a.StreamBufferSize > 0 {
+		buffer = newStreamBuffer(ctx, cb, a.StreamBufferSize, a.StreamBackpressure)
+		defer buffer.close()
+		cb = buffer.send
+	}
+
+	// Ask Azure for the final usage when the caller wants live usage progress
+	meter := a.newUsageMeter(string(params.Model), originalInput)
+	if meter != nil && a.Capabilities().Supports(FeatureStreamUsage) {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
+
 	// Note: Stream parameter is automatically set by NewStreaming
 	stream := a.client.Chat.Completions.NewStreaming(ctx, params)
 	defer func() {
@@ -926,27 +1561,84 @@ func (a *AzureAIFoundry) generateTextStream(ctx context.Context, params openai.C
 		}
 	}()
 
-	var fullText strings.Builder
+	var fullText, fullReasoning strings.Builder
+	var splitter reasoningSplitter
+	stripReasoning := a.extractConfigFromRequest(originalInput).stripReasoning
+	// emit records separated reasoning and answer text and streams them to the callback
+	emit := func(reasoning, text string) error {
+		fullReasoning.WriteString(reasoning)
+		fullText.WriteString(text)
+		parts := reasoningParts(reasoning, text, stripReasoning)
+		if cb == nil || len(parts) == 0 {
+			return nil
+		}
+		return cb(ctx, &ai.ModelResponseChunk{Content: parts})
+	}
 	toolCallsMap := make(map[int]*toolCallAccumulator)
+	// emitToolCalls streams the tool calls before index, which are complete once a later call
+	// or the finish reason arrives, so UIs can show them before the response ends
+	emitToolCalls := func(before int) error {
+		for _, idx := range slices.Sorted(maps.Keys(toolCallsMap)) {
+			call := toolCallsMap[idx]
+			if idx >= before || call.streamed {
+				continue
+			}
+			call.streamed = true
+			part, err := call.complete()
+			if err != nil {
+				return fmt.Errorf("failed to convert tool calls: %w", err)
+			}
+			if part == nil || cb == nil {
+				continue
+			}
+			if err := cb(ctx, &ai.ModelResponseChunk{Content: []*ai.Part{part}}); err != nil {
+				return fmt.Errorf("streaming callback error: %w", err)
+			}
+		}
+		return nil
+	}
+	var systemFingerprint string
+	var serviceTier string // Tier that processed the request
+	var finishReason string
+	var logprobs []openai.ChatCompletionTokenLogprob
+	var usage *ai.GenerationUsage
+	start := time.Now()
+	firstToken := true
 
 	for stream.Next() {
 		chunk := stream.Current()
+		if chunk.SystemFingerprint != "" {
+			systemFingerprint = chunk.SystemFingerprint
+		}
+		if chunk.ServiceTier != "" {
+			serviceTier = string(chunk.ServiceTier)
+		}
+		if chunk.Usage.TotalTokens > 0 {
+			usage = convertUsage(chunk.Usage)
+		}
 		if len(chunk.Choices) > 0 {
+			if reason := chunk.Choices[0].FinishReason; reason != "" {
+				finishReason = reason
+			}
 			delta := chunk.Choices[0].Delta
+			logprobs = append(logprobs, chunk.Choices[0].Logprobs.Content...)
+
+			if firstToken && (delta.Content != "" || len(delta.ToolCalls) > 0) {
+				firstToken = false
+				a.emit(ctx, Event{
+					Type:      EventFirstToken,
+					Model:     string(params.Model),
+					Operation: OperationChat,
+					Streaming: true,
+					Latency:   time.Since(start),
+				})
+			}
 
-			// Handle content streaming
-			if delta.Content != "" {
-				fullText.WriteString(delta.Content)
-
-				if cb != nil {
-					chunkResponse := &ai.ModelResponseChunk{
-						Content: []*ai.Part{
-							ai.NewTextPart(delta.Content),
-						},
-					}
-					if err := cb(ctx, chunkResponse); err != nil {
-						return nil, fmt.Errorf("streaming callback error: %w", err)
-					}
+			// Handle content streaming, separating the reasoning of reasoning models
+			if reasoningDelta := reasoningContent(delta.JSON.ExtraFields); delta.Content != "" || reasoningDelta != "" {
+				reasoning, text := splitter.split(delta.Content)
+				if err := emit(reasoningDelta+reasoning, text); err != nil {
+					return nil, fmt.Errorf("streaming callback error: %w", err)
 				}
 			}
 
@@ -955,6 +1647,9 @@ func (a *AzureAIFoundry) generateTextStream(ctx context.Context, params openai.C
 				idx := int(toolCallDelta.Index)
 
 				if toolCallsMap[idx] == nil {
+					if err := emitToolCalls(idx); err != nil {
+						return nil, err
+					}
 					toolCallsMap[idx] = &toolCallAccumulator{
 						id: toolCallDelta.ID,
 					}
@@ -968,18 +1663,49 @@ func (a *AzureAIFoundry) generateTextStream(ctx context.Context, params openai.C
 					toolCallsMap[idx].arguments.WriteString(toolCallDelta.Function.Arguments)
 				}
 			}
+
+			if meter != nil {
+				text := delta.Content
+				for _, toolCallDelta := range delta.ToolCalls {
+					text += toolCallDelta.Function.Arguments
+				}
+				if err := meter.observe(ctx, text, cb); err != nil {
+					return nil, fmt.Errorf("streaming callback error: %w", err)
+				}
+			}
+			if finishReason != "" {
+				if err := emitToolCalls(math.MaxInt); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
 	if err := stream.Err(); err != nil {
+		if len(toolCallsMap) > 0 || fullReasoning.Len() > 0 {
+			return nil, fmt.Errorf("stream error: %w", &partialStreamError{err: err})
+		}
 		return nil, fmt.Errorf("stream error: %w", err)
 	}
+	if err := emit(splitter.flush()); err != nil {
+		return nil, fmt.Errorf("streaming callback error: %w", err)
+	}
+	if err := emitToolCalls(math.MaxInt); err != nil {
+		return nil, err
+	}
+	if meter != nil {
+		if err := meter.finish(ctx, usage, cb); err != nil {
+			return nil, fmt.Errorf("streaming callback error: %w", err)
+		}
+	}
+	if buffer != nil {
+		if err := buffer.close(); err != nil {
+			return nil, fmt.Errorf("streaming callback error: %w", err)
+		}
+	}
 
 	// Build final message content
-	var content []*ai.Part
-	if fullText.Len() > 0 {
-		content = append(content, ai.NewTextPart(fullText.String()))
-	}
+	content := reasoningParts(fullReasoning.String(), fullText.String(), stripReasoning)
 
 	// Add tool calls to content
 	toolParts, err := a.convertToolCallsToParts(toolCallsMap)
@@ -988,35 +1714,41 @@ func (a *AzureAIFoundry) generateTextStream(ctx context.Context, params openai.C
 	}
 	content = append(content, toolParts...)
 
-	return &ai.ModelResponse{
+	response := &ai.ModelResponse{
 		Message: &ai.Message{
 			Role:    ai.RoleModel,
 			Content: content,
 		},
-		FinishReason: ai.FinishReasonStop,
-	}, nil
+		FinishReason: ai.FinishReasonStop, // Some OpenAI-compatible servers end streams without a finish reason
+		Usage:        usage,
+	}
+	if finishReason != "" {
+		response.FinishReason = a.convertFinishReason(finishReason)
+	}
+	if systemFingerprint != "" {
+		response.Custom = withCustomValue(response.Custom, "systemFingerprint", systemFingerprint)
+	}
+	if serviceTier != "" {
+		response.Custom = withCustomValue(response.Custom, "serviceTier", serviceTier)
+	}
+	if len(logprobs) > 0 {
+		response.Custom = withCustomValue(response.Custom, "logprobs", convertLogprobs(logprobs))
+	}
+	return response, nil
 }
 
 // convertToolCallsToParts converts accumulated tool calls to AI parts
 func (a *AzureAIFoundry) convertToolCallsToParts(toolCallsMap map[int]*toolCallAccumulator) ([]*ai.Part, error) {
 	var parts []*ai.Part
 
-	for _, toolCall := range toolCallsMap {
-		if toolCall.name == "" {
-			continue
+	for _, idx := range slices.Sorted(maps.Keys(toolCallsMap)) {
+		part, err := toolCallsMap[idx].complete()
+		if err != nil {
+			return nil, err
 		}
-
-		var args map[string]interface{}
-		if toolCall.arguments.Len() > 0 {
-			if err := json.Unmarshal([]byte(toolCall.arguments.String()), &args); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal tool arguments for '%s': %w", toolCall.name, err)
-			}
+		if part != nil {
+			parts = append(parts, part)
 		}
-
-		parts = append(parts, ai.NewToolRequestPart(&ai.ToolRequest{
-			Name:  toolCall.name,
-			Input: args,
-		}))
 	}
 
 	return parts, nil
@@ -1035,11 +1767,8 @@ func (a *AzureAIFoundry) convertResponse(resp *openai.ChatCompletion, originalIn
 	}
 
 	choice := resp.Choices[0]
-	var content []*ai.Part
-
-	if choice.Message.Content != "" {
-		content = append(content, ai.NewTextPart(choice.Message.Content))
-	}
+	reasoning, answer := splitReasoning(choice.Message.Content, choice.Message.JSON.ExtraFields)
+	content := reasoningParts(reasoning, answer, a.extractConfigFromRequest(originalInput).stripReasoning)
 
 	// Handle tool calls
 	if len(choice.Message.ToolCalls) > 0 {
@@ -1053,6 +1782,7 @@ func (a *AzureAIFoundry) convertResponse(resp *openai.ChatCompletion, originalIn
 				}
 				content = append(content, ai.NewToolRequestPart(&ai.ToolRequest{
 					Name:  functionToolCall.Function.Name,
+					Ref:   functionToolCall.ID,
 					Input: args,
 				}))
 			}
@@ -1063,12 +1793,10 @@ func (a *AzureAIFoundry) convertResponse(resp *openai.ChatCompletion, originalIn
 
 	usage := &ai.GenerationUsage{}
 	if resp.Usage.PromptTokens > 0 {
-		usage.InputTokens = int(resp.Usage.PromptTokens)
-		usage.OutputTokens = int(resp.Usage.CompletionTokens)
-		usage.TotalTokens = int(resp.Usage.TotalTokens)
+		usage = convertUsage(resp.Usage)
 	}
 
-	return &ai.ModelResponse{
+	response := &ai.ModelResponse{
 		Message: &ai.Message{
 			Role:    ai.RoleModel,
 			Content: content,
@@ -1076,6 +1804,54 @@ func (a *AzureAIFoundry) convertResponse(resp *openai.ChatCompletion, originalIn
 		FinishReason: finishReason,
 		Usage:        usage,
 	}
+	if resp.SystemFingerprint != "" {
+		response.Custom = withCustomValue(response.Custom, "systemFingerprint", resp.SystemFingerprint)
+	}
+	if resp.ServiceTier != "" {
+		response.Custom = withCustomValue(response.Custom, "serviceTier", string(resp.ServiceTier))
+	}
+	if len(choice.Logprobs.Content) > 0 {
+		response.Custom = withCustomValue(response.Custom, "logprobs", convertLogprobs(choice.Logprobs.Content))
+	}
+	return response
+}
+
+// convertUsage converts the token usage of a chat completion. Reasoning tokens, which are
+// billed as output but not returned, go to ThoughtsTokens and cached prompt tokens to
+// CachedContentTokens; audio and predicted output tokens are reported in Custom.
+func convertUsage(u openai.CompletionUsage) *ai.GenerationUsage {
+	usage := &ai.GenerationUsage{
+		InputTokens:         int(u.PromptTokens),
+		OutputTokens:        int(u.CompletionTokens),
+		TotalTokens:         int(u.TotalTokens),
+		ThoughtsTokens:      int(u.CompletionTokensDetails.ReasoningTokens),
+		CachedContentTokens: int(u.PromptTokensDetails.CachedTokens),
+	}
+	details := map[string]int64{
+		"inputAudioTokens":         u.PromptTokensDetails.AudioTokens,
+		"outputAudioTokens":        u.CompletionTokensDetails.AudioTokens,
+		"acceptedPredictionTokens": u.CompletionTokensDetails.AcceptedPredictionTokens,
+		"rejectedPredictionTokens": u.CompletionTokensDetails.RejectedPredictionTokens,
+	}
+	for key, tokens := range details {
+		if tokens > 0 {
+			if usage.Custom == nil {
+				usage.Custom = map[string]float64{}
+			}
+			usage.Custom[key] = float64(tokens)
+		}
+	}
+	return usage
+}
+
+// CacheHitRate returns the fraction of a response's input tokens that Azure served from the
+// prompt cache, reported in CachedContentTokens. Prompts share a cache entry when their first
+// 1,024 or more tokens are identical, so static instructions belong at the start.
+func CacheHitRate(usage *ai.GenerationUsage) float64 {
+	if usage == nil || usage.InputTokens == 0 {
+		return 0
+	}
+	return float64(usage.CachedContentTokens) / float64(usage.InputTokens)
 }
 
 // convertFinishReason converts OpenAI finish reason to Genkit format
@@ -1095,8 +1871,38 @@ func (a *AzureAIFoundry) convertFinishReason(reason string) ai.FinishReason {
 }
 
 // embed handles embedding generation using Azure OpenAI
-func (a *AzureAIFoundry) embed(ctx context.Context, modelName string, req *ai.EmbedRequest) (*ai.EmbedResponse, error) {
+func (a *AzureAIFoundry) embed(ctx context.Context, modelName string, req *ai.EmbedRequest) (_ *ai.EmbedResponse, err error) {
+	started := Event{
+		Type:      EventRequestStarted,
+		Time:      time.Now(),
+		Model:     modelName,
+		Operation: OperationEmbedding,
+	}
+	eventCtx := ctx
+	a.emit(ctx, started)
+	usage := &ai.GenerationUsage{}
+	defer func() {
+		err = flowBudgetError(eventCtx, err)
+		recordFlowBudget(eventCtx, usage)
+		a.emitCompleted(eventCtx, started, nil, usage, err)
+	}()
+
+	if err := a.checkResidency(ctx); err != nil {
+		return nil, err
+	}
+	var end func()
+	if ctx, end, err = a.lifecycle.track(ctx); err != nil {
+		return nil, err
+	}
+	defer end()
+	if err := chargeFlowBudget(ctx); err != nil {
+		return nil, err
+	}
+
 	var embeddings []*ai.Embedding
+	options, _ := req.Options.(map[string]interface{})
+	configuredUser, _ := options["user"].(string)
+	user := a.endUser(ctx, configuredUser)
 
 	// Process each document
 	for _, doc := range req.Input {
@@ -1113,15 +1919,21 @@ func (a *AzureAIFoundry) embed(ctx context.Context, modelName string, req *ai.Em
 		}
 
 		// Call Azure OpenAI embeddings API
-		resp, err := a.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		params := openai.EmbeddingNewParams{
 			Model: openai.EmbeddingModel(modelName),
 			Input: openai.EmbeddingNewParamsInputUnion{
 				OfString: openai.String(inputText),
 			},
-		})
+		}
+		if user != "" {
+			params.User = openai.String(user)
+		}
+		resp, err := a.client.Embeddings.New(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("embedding generation failed for model '%s': %w", modelName, err)
 		}
+		usage.InputTokens += int(resp.Usage.PromptTokens)
+		usage.TotalTokens += int(resp.Usage.TotalTokens)
 
 		// Extract embeddings from response
 		if len(resp.Data) > 0 {
@@ -1159,6 +1971,15 @@ func DefineCommonModels(a *AzureAIFoundry, g *genkit.Genkit) map[string]ai.Model
 		SupportsMedia: true,
 	}, nil)
 
+	// GPT-4.1 long-context models
+	for _, name := range []string{ModelGPT41, ModelGPT41Mini, ModelGPT41Nano} {
+		models[name] = a.DefineModel(g, ModelDefinition{
+			Name:          name,
+			Type:          "chat",
+			SupportsMedia: true,
+		}, nil)
+	}
+
 	// GPT-4o models
 	models["gpt-4o"] = a.DefineModel(g, ModelDefinition{
 		Name:          "gpt-4o",
@@ -1251,3 +2072,23 @@ func Embedder(g *genkit.Genkit, name string) ai.Embedder {
 func IsDefinedEmbedder(g *genkit.Genkit, name string) bool {
 	return genkit.LookupEmbedder(g, api.NewName(provider, name)) != nil
 }
+
+// Model returns the Model with the given name registered by this plugin instance.
+func (a *AzureAIFoundry) Model(g *genkit.Genkit, name string) ai.Model {
+	return genkit.LookupModel(g, api.NewName(a.Name(), name))
+}
+
+// IsDefinedModel reports whether this plugin instance defined a model.
+func (a *AzureAIFoundry) IsDefinedModel(g *genkit.Genkit, name string) bool {
+	return a.Model(g, name) != nil
+}
+
+// Embedder returns the Embedder with the given name registered by this plugin instance.
+func (a *AzureAIFoundry) Embedder(g *genkit.Genkit, name string) ai.Embedder {
+	return genkit.LookupEmbedder(g, api.NewName(a.Name(), name))
+}
+
+// IsDefinedEmbedder reports whether this plugin instance defined an embedder.
+func (a *AzureAIFoundry) IsDefinedEmbedder(g *genkit.Genkit, name string) bool {
+	return a.Embedder(g, name) != nil
+}
@@ -17,7 +17,52 @@
 
 package azureaifoundry
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// chatCompletionJSON is a minimal chat completion response body
+const chatCompletionJSON = `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4o",` +
+	`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}],` +
+	`"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`
+
+// newTestPlugin returns an initialized plugin whose requests are served by handler. The
+// configure functions can set plugin options before Init.
+func newTestPlugin(t *testing.T, handler http.HandlerFunc, configure ...func(*AzureAIFoundry)) *AzureAIFoundry {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+	for _, fn := range configure {
+		fn(plugin)
+	}
+	plugin.Init(context.Background())
+	return plugin
+}
+
+// captureRequests returns a handler that records JSON request bodies and replies with response
+func captureRequests(bodies *[]map[string]any, response string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		*bodies = append(*bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, response)
+	}
+}
 
 func TestInferModelCapabilitiesDetectsToolCallingModels(t *testing.T) {
 	plugin := &AzureAIFoundry{}
@@ -68,9 +113,235 @@ func TestInferModelCapabilitiesDetectsToolCallingModels(t *testing.T) {
 			if info.Supports.Tools != tt.wantTools {
 				t.Fatalf("Tools = %v, want %v", info.Supports.Tools, tt.wantTools)
 			}
+			wantConstrained := ai.ConstrainedSupportNone
+			if tt.wantTools && supportsStructuredOutputs(tt.modelName) {
+				wantConstrained = ai.ConstrainedSupportAll
+			}
+			if info.Supports.Constrained != wantConstrained {
+				t.Fatalf("Constrained = %q, want %q", info.Supports.Constrained, wantConstrained)
+			}
 			if info.Supports.Media != tt.wantMedia {
 				t.Fatalf("Media = %v, want %v", info.Supports.Media, tt.wantMedia)
 			}
 		})
 	}
 }
+
+func TestMultiplePluginInstances(t *testing.T) {
+	var eastBodies, westBodies []map[string]any
+	east := httptest.NewServer(captureRequests(&eastBodies, chatCompletionJSON))
+	defer east.Close()
+	west := httptest.NewServer(captureRequests(&westBodies, chatCompletionJSON))
+	defer west.Close()
+
+	ctx := context.Background()
+	eastPlugin := &AzureAIFoundry{Endpoint: east.URL + "/", APIKey: "test", ProviderID: "azure-eastus"}
+	westPlugin := &AzureAIFoundry{Endpoint: west.URL + "/", APIKey: "test", ProviderID: "azure-westeurope"}
+	g := genkit.Init(ctx, genkit.WithPlugins(eastPlugin, westPlugin))
+
+	eastPlugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	westPlugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	westPlugin.DefineEmbedder(g, "text-embedding-3-small")
+
+	if !eastPlugin.IsDefinedModel(g, "gpt-4o") || IsDefinedModel(g, "gpt-4o") {
+		t.Fatal("models should be registered under the instance provider only")
+	}
+	if !westPlugin.IsDefinedEmbedder(g, "text-embedding-3-small") || eastPlugin.IsDefinedEmbedder(g, "text-embedding-3-small") {
+		t.Fatal("embedder registered under the wrong provider")
+	}
+	if name := westPlugin.Model(g, "gpt-4o").Name(); name != "azure-westeurope/gpt-4o" {
+		t.Fatalf("Model().Name() = %q", name)
+	}
+
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(westPlugin.Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(westBodies) != 1 || len(eastBodies) != 0 {
+		t.Fatalf("requests: east %d, west %d; want the west endpoint only", len(eastBodies), len(westBodies))
+	}
+}
+
+func TestServiceTier(t *testing.T) {
+	const flexResponse = `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4o","service_tier":"flex",` +
+		`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`
+
+	tests := []struct {
+		name     string
+		model    ModelDefinition
+		config   map[string]any
+		wantTier any
+	}{
+		{name: "unset", model: ModelDefinition{Name: "gpt-4o"}, wantTier: nil},
+		{name: "per request", model: ModelDefinition{Name: "gpt-4o"}, config: map[string]any{"service_tier": "priority"}, wantTier: "priority"},
+		{name: "per model", model: ModelConfig{Name: "gpt-4o", ServiceTier: "flex"}.definition(), wantTier: "flex"},
+		{name: "request overrides model", model: ModelDefinition{Name: "gpt-4o", ServiceTier: "flex"}, config: map[string]any{"serviceTier": "priority"}, wantTier: "priority"},
+		{
+			name:     "model default config takes precedence",
+			model:    ModelDefinition{Name: "gpt-4o", ServiceTier: "flex", DefaultConfig: map[string]any{"serviceTier": "default"}},
+			wantTier: "default",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, flexResponse))
+			_, fn := plugin.modelAction(tt.model, nil)
+
+			resp, err := fn(context.Background(), &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+				Config:   tt.config,
+			}, nil)
+			if err != nil {
+				t.Fatalf("model function error = %v", err)
+			}
+			if got := bodies[0]["service_tier"]; got != tt.wantTier {
+				t.Fatalf("service_tier = %v, want %v", got, tt.wantTier)
+			}
+			if custom, _ := resp.Custom.(map[string]any); custom["serviceTier"] != "flex" {
+				t.Fatalf("custom = %v, want the tier Azure reported", resp.Custom)
+			}
+		})
+	}
+}
+
+func TestUsageDetails(t *testing.T) {
+	const usage = `{"prompt_tokens":120,"completion_tokens":900,"total_tokens":1020,` +
+		`"prompt_tokens_details":{"cached_tokens":64,"audio_tokens":0},` +
+		`"completion_tokens_details":{"reasoning_tokens":768,"audio_tokens":0,"accepted_prediction_tokens":12,"rejected_prediction_tokens":3}}`
+
+	tests := []struct {
+		name   string
+		stream bool
+		body   string
+	}{
+		{"sync", false, `{"id":"1","object":"chat.completion","model":"o3-mini","choices":[{"index":0,"finish_reason":"stop",` +
+			`"message":{"role":"assistant","content":"42"}}],"usage":` + usage + `}`},
+		{"stream", true, `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"42"}}]}` + "\n\n" +
+			`data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":` + usage + `}` + "\n\n" +
+			"data: [DONE]\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.stream {
+					w.Header().Set("Content-Type", "text/event-stream")
+				} else {
+					w.Header().Set("Content-Type", "application/json")
+				}
+				_, _ = io.WriteString(w, tt.body)
+			})
+			var cb ai.ModelStreamCallback
+			if tt.stream {
+				cb = func(context.Context, *ai.ModelResponseChunk) error { return nil }
+			}
+			resp, err := plugin.generateText(context.Background(), "o3-mini", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("What is 6 x 7?")},
+			}, cb)
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+
+			got := resp.Usage
+			if got.InputTokens != 120 || got.OutputTokens != 900 || got.ThoughtsTokens != 768 || got.CachedContentTokens != 64 {
+				t.Fatalf("Usage = %+v", got)
+			}
+			if rate := CacheHitRate(got); rate != 64.0/120 {
+				t.Fatalf("CacheHitRate() = %v, want %v", rate, 64.0/120)
+			}
+			want := map[string]float64{"acceptedPredictionTokens": 12, "rejectedPredictionTokens": 3}
+			if len(got.Custom) != len(want) || got.Custom["acceptedPredictionTokens"] != 12 || got.Custom["rejectedPredictionTokens"] != 3 {
+				t.Fatalf("Usage.Custom = %v, want %v", got.Custom, want)
+			}
+		})
+	}
+}
+
+func TestStreamFinishReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason string
+		want   ai.FinishReason
+	}{
+		{"stop", `"stop"`, ai.FinishReasonStop},
+		{"length", `"length"`, ai.FinishReasonLength},
+		{"content filter", `"content_filter"`, ai.FinishReasonBlocked},
+		{"tool calls", `"tool_calls"`, ai.FinishReasonStop},
+		{"missing", `null`, ai.FinishReasonStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"partial"}}]}`+"\n\n"+
+					`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":`+tt.reason+`}]}`+"\n\n"+
+					"data: [DONE]\n\n")
+			})
+			resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+			}, func(context.Context, *ai.ModelResponseChunk) error { return nil })
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if resp.FinishReason != tt.want {
+				t.Fatalf("FinishReason = %q, want %q", resp.FinishReason, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamToolCalls(t *testing.T) {
+	firstTool := make(chan struct{})
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		send := func(delta string) {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+			w.(http.Flusher).Flush()
+		}
+		send(`{"content":"Checking."}`)
+		send(`{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":"}}]}`)
+		send(`{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}`)
+		send(`{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"weather","arguments":""}}]}`)
+		// The first call is streamed before the second one completes
+		select {
+		case <-firstTool:
+		case <-time.After(5 * time.Second):
+			t.Error("first tool call was not streamed before the response ended")
+		}
+		send(`{"tool_calls":[{"index":1,"function":{"arguments":"{\"city\":\"Rome\"}"}}]}`)
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\ndata: [DONE]\n\n")
+	})
+
+	var streamed []string
+	resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("weather in Paris and Rome?")},
+		Tools:    []*ai.ToolDefinition{{Name: "weather"}},
+	}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		for _, part := range chunk.Content {
+			switch {
+			case part.IsToolRequest():
+				streamed = append(streamed, fmt.Sprintf("%s(%s) %v", part.ToolRequest.Name, part.ToolRequest.Ref, part.ToolRequest.Input))
+				if part.ToolRequest.Ref == "call_1" {
+					close(firstTool)
+				}
+			case part.IsText():
+				streamed = append(streamed, part.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+
+	want := []string{"Checking.", "weather(call_1) map[city:Paris]", "weather(call_2) map[city:Rome]"}
+	if !reflect.DeepEqual(streamed, want) {
+		t.Fatalf("streamed = %q, want %q", streamed, want)
+	}
+	if resp.FinishReason != ai.FinishReasonStop {
+		t.Fatalf("FinishReason = %q, want %q", resp.FinishReason, ai.FinishReasonStop)
+	}
+	requests := resp.ToolRequests()
+	if len(requests) != 2 || requests[0].ToolRequest.Ref != "call_1" || requests[1].ToolRequest.Ref != "call_2" {
+		t.Fatalf("tool requests = %+v", requests)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// BackpressurePolicy decides what happens when a stream callback falls behind and the
+// stream buffer is full.
+type BackpressurePolicy string
+
+const (
+	// BackpressureBlock pauses reading from Azure until the callback catches up.
+	BackpressureBlock BackpressurePolicy = "block"
+	// BackpressureCoalesce merges new text into the last buffered chunk, so a slow client
+	// receives fewer, larger chunks. Chunks that cannot be merged block.
+	BackpressureCoalesce BackpressurePolicy = "coalesce"
+	// BackpressureCancel fails the request with a *StreamBackpressureError.
+	BackpressureCancel BackpressurePolicy = "cancel"
+)
+
+// StreamBackpressureError is returned with BackpressureCancel when the stream callback
+// cannot keep up.
+type StreamBackpressureError struct {
+	Buffered int
+}
+
+// Error implements the error interface.
+func (e *StreamBackpressureError) Error() string {
+	return fmt.Sprintf("stream callback fell behind with %d chunks buffered", e.Buffered)
+}
+
+// streamBuffer delivers chunks to a stream callback from a bounded queue on its own
+// goroutine, so reading from Azure is decoupled from a slow consumer
+type streamBuffer struct {
+	cb     func(context.Context, *ai.ModelResponseChunk) error
+	size   int
+	policy BackpressurePolicy
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []*ai.ModelResponseChunk
+	closed bool
+	err    error // First callback or backpressure error
+	done   chan struct{}
+}
+
+// newStreamBuffer starts delivering buffered chunks to cb
+func newStreamBuffer(ctx context.Context, cb func(context.Context, *ai.ModelResponseChunk) error, size int, policy BackpressurePolicy) *streamBuffer {
+	b := &streamBuffer{cb: cb, size: size, policy: policy, done: make(chan struct{})}
+	b.cond = sync.NewCond(&b.mu)
+	go b.run(ctx)
+	return b
+}
+
+// send queues a chunk, applying the backpressure policy when the queue is full. It has the
+// signature of a stream callback.
+func (b *streamBuffer) send(_ context.Context, chunk *ai.ModelResponseChunk) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for {
+		if b.err != nil {
+			return b.err
+		}
+		if len(b.queue) < b.size {
+			b.queue = append(b.queue, chunk)
+			b.cond.Broadcast()
+			return nil
+		}
+		switch b.policy {
+		case BackpressureCoalesce:
+			if merged := coalesceChunks(b.queue[len(b.queue)-1], chunk); merged != nil {
+				b.queue[len(b.queue)-1] = merged
+				return nil
+			}
+		case BackpressureCancel:
+			b.err = &StreamBackpressureError{Buffered: len(b.queue)}
+			return b.err
+		}
+		b.cond.Wait()
+	}
+}
+
+// run delivers queued chunks until the buffer is closed and drained or a callback fails
+func (b *streamBuffer) run(ctx context.Context) {
+	defer close(b.done)
+	for {
+		b.mu.Lock()
+		for len(b.queue) == 0 && !b.closed && b.err == nil {
+			b.cond.Wait()
+		}
+		if b.err != nil || len(b.queue) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		chunk := b.queue[0]
+		b.queue = b.queue[1:]
+		b.cond.Broadcast()
+		b.mu.Unlock()
+
+		if err := b.cb(ctx, chunk); err != nil {
+			b.mu.Lock()
+			if b.err == nil {
+				b.err = err
+			}
+			b.queue = nil
+			b.cond.Broadcast()
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+// close waits until every queued chunk is delivered and returns the first error. Chunks are
+// discarded once an error occurred. It is safe to call more than once.
+func (b *streamBuffer) close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	<-b.done
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// coalesceChunks merges two text-only chunks into one, or returns nil if either carries
+// other content or custom metadata
+func coalesceChunks(first, second *ai.ModelResponseChunk) *ai.ModelResponseChunk {
+	if first.Custom != nil || second.Custom != nil || first.Index != second.Index {
+		return nil
+	}
+	var text string
+	for _, part := range append(append([]*ai.Part{}, first.Content...), second.Content...) {
+		if !part.IsText() {
+			return nil
+		}
+		text += part.Text
+	}
+	return &ai.ModelResponseChunk{Role: first.Role, Index: first.Index, Content: []*ai.Part{ai.NewTextPart(text)}}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func textChunk(text string) *ai.ModelResponseChunk {
+	return &ai.ModelResponseChunk{Content: []*ai.Part{ai.NewTextPart(text)}}
+}
+
+func TestStreamBufferPolicies(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     BackpressurePolicy
+		wantChunks []string
+		wantErr    bool
+	}{
+		{"block", BackpressureBlock, []string{"a", "b", "c", "d"}, false},
+		{"coalesce", BackpressureCoalesce, []string{"a", "bcd"}, false},
+		{"cancel", BackpressureCancel, []string{"a"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			started := make(chan struct{})
+			var delivered []string
+			buffer := newStreamBuffer(context.Background(), func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+				if len(delivered) == 0 {
+					close(started)
+					<-release // Hold the first chunk until the queue is full
+				}
+				delivered = append(delivered, chunk.Text())
+				return nil
+			}, 1, tt.policy)
+
+			if err := buffer.send(context.Background(), textChunk("a")); err != nil {
+				t.Fatal(err)
+			}
+			<-started
+			var sendErr error
+			sent := make(chan struct{})
+			go func() {
+				defer close(sent)
+				for _, text := range []string{"b", "c", "d"} {
+					if sendErr = buffer.send(context.Background(), textChunk(text)); sendErr != nil {
+						return
+					}
+				}
+			}()
+			if tt.policy != BackpressureBlock {
+				<-sent // Coalescing and canceling never wait for the callback
+			}
+			close(release)
+			<-sent
+
+			err := buffer.close()
+			if (err != nil) != tt.wantErr || (sendErr != nil) != tt.wantErr {
+				t.Fatalf("close() error = %v, send error = %v", err, sendErr)
+			}
+			var backpressureErr *StreamBackpressureError
+			if tt.wantErr && !errors.As(err, &backpressureErr) {
+				t.Fatalf("error = %v, want *StreamBackpressureError", err)
+			}
+			if strings.Join(delivered, ",") != strings.Join(tt.wantChunks, ",") {
+				t.Fatalf("delivered = %q, want %q", delivered, tt.wantChunks)
+			}
+		})
+	}
+}
+
+func TestStreamBufferCallbackError(t *testing.T) {
+	buffer := newStreamBuffer(context.Background(), func(context.Context, *ai.ModelResponseChunk) error {
+		return errors.New("client disconnected")
+	}, 4, BackpressureBlock)
+
+	_ = buffer.send(context.Background(), textChunk("a"))
+	if err := buffer.close(); err == nil || err.Error() != "client disconnected" {
+		t.Fatalf("close() error = %v", err)
+	}
+	if err := buffer.send(context.Background(), textChunk("b")); err == nil {
+		t.Fatal("send() after a callback error succeeded")
+	}
+}
+
+func TestBufferedStreaming(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, word := range []string{"one ", "two ", "three"} {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", word)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}, func(a *AzureAIFoundry) {
+		a.StreamBufferSize = 2
+		a.StreamBackpressure = BackpressureCoalesce
+	})
+
+	var streamed strings.Builder
+	resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("count")},
+	}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		streamed.WriteString(chunk.Text())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if streamed.String() != "one two three" || resp.Text() != "one two three" {
+		t.Fatalf("streamed = %q, response = %q", streamed.String(), resp.Text())
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// BatchEmbedOptions configures BatchEmbed.
+type BatchEmbedOptions struct {
+	Embedder  ai.Embedder // Embedder to use (required)
+	BatchSize int         // Documents per embedder call. Defaults to 16
+
+	Sink func(ctx context.Context, batch []EmbeddedDocument) error // Receives each batch of embeddings, e.g. to write them to a vector store (required)
+
+	Checkpoints CheckpointStore // Optional: Persists progress after every batch so an interrupted job resumes where it stopped
+	JobID       string          // Identifies the job in Checkpoints (required with Checkpoints)
+
+	OnProgress func(EmbedProgress) // Optional: Called after every batch
+	Tokenizer  Tokenizer           // Optional: Counts the tokens reported in progress. Defaults to ApproximateTokenizer
+}
+
+// EmbeddedDocument is a document with its embedding.
+type EmbeddedDocument struct {
+	Index     int // Position of the document in the input
+	Document  *ai.Document
+	Embedding []float32
+}
+
+// EmbedProgress reports the progress of a batch embedding job.
+type EmbedProgress struct {
+	Done    int           // Documents embedded, including those of earlier runs of the job
+	Total   int           // Documents in the job
+	Tokens  int           // Tokens embedded, as counted by the tokenizer
+	Elapsed time.Duration // Time spent in this run
+	ETA     time.Duration // Estimated time left, based on this run's rate
+}
+
+// EmbedCheckpoint is the saved progress of a batch embedding job.
+type EmbedCheckpoint struct {
+	Done      int       `json:"done"`   // Documents embedded and handed to the sink
+	Total     int       `json:"total"`  // Documents in the job, to detect a changed input
+	Tokens    int       `json:"tokens"` // Tokens embedded
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CheckpointStore persists the progress of batch embedding jobs.
+type CheckpointStore interface {
+	// Load returns the checkpoint of a job, or nil if it has none.
+	Load(ctx context.Context, jobID string) (*EmbedCheckpoint, error)
+	// Save stores the checkpoint of a job.
+	Save(ctx context.Context, jobID string, checkpoint EmbedCheckpoint) error
+}
+
+// FileCheckpointStore stores checkpoints as JSON files in a directory.
+type FileCheckpointStore struct {
+	Dir string
+}
+
+// Load implements CheckpointStore.
+func (s FileCheckpointStore) Load(ctx context.Context, jobID string) (*EmbedCheckpoint, error) {
+	data, err := os.ReadFile(s.path(jobID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var checkpoint EmbedCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// Save implements CheckpointStore. The file is replaced atomically, so a crash while saving
+// leaves the previous checkpoint.
+func (s FileCheckpointStore) Save(ctx context.Context, jobID string, checkpoint EmbedCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	if err := writeFileAtomic(s.path(jobID), data); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// path returns the checkpoint file of a job
+func (s FileCheckpointStore) path(jobID string) string {
+	return filepath.Join(s.Dir, jobID+".json")
+}
+
+// writeFileAtomic replaces a file, creating its directory, through a temporary file renamed
+// into place, so a crash while writing leaves the previous content
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// BatchEmbed embeds documents in batches and hands each batch to opts.Sink, for backfills
+// too large to embed in one call. With opts.Checkpoints, progress is saved after the sink
+// accepts a batch, and a later call with the same job ID and documents skips the documents
+// already embedded. Documents without text are skipped, as the embedder does not embed them.
+func BatchEmbed(ctx context.Context, g *genkit.Genkit, docs []*ai.Document, opts BatchEmbedOptions) error {
+	if opts.Embedder == nil || opts.Sink == nil {
+		return fmt.Errorf("batch embedding requires an embedder and a sink")
+	}
+	if opts.Checkpoints != nil && opts.JobID == "" {
+		return fmt.Errorf("batch embedding with checkpoints requires a job ID")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 16
+	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = ApproximateTokenizer
+	}
+
+	progress := EmbedProgress{Total: len(docs)}
+	if opts.Checkpoints != nil {
+		checkpoint, err := opts.Checkpoints.Load(ctx, opts.JobID)
+		if err != nil {
+			return err
+		}
+		if checkpoint != nil {
+			if checkpoint.Total != len(docs) {
+				return fmt.Errorf("checkpoint of job %q is for %d documents, got %d", opts.JobID, checkpoint.Total, len(docs))
+			}
+			progress.Done, progress.Tokens = checkpoint.Done, checkpoint.Tokens
+		}
+	}
+
+	start, resumedAt := time.Now(), progress.Done
+	for progress.Done < len(docs) {
+		end := min(progress.Done+opts.BatchSize, len(docs))
+		batch, tokens, err := embedBatch(ctx, g, opts, docs, progress.Done, end)
+		if err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			if err := opts.Sink(ctx, batch); err != nil {
+				return fmt.Errorf("batch embedding sink failed at document %d: %w", progress.Done, err)
+			}
+		}
+
+		progress.Done, progress.Tokens = end, progress.Tokens+tokens
+		if opts.Checkpoints != nil {
+			checkpoint := EmbedCheckpoint{Done: progress.Done, Total: len(docs), Tokens: progress.Tokens, UpdatedAt: time.Now()}
+			if err := opts.Checkpoints.Save(ctx, opts.JobID, checkpoint); err != nil {
+				return err
+			}
+		}
+		if opts.OnProgress != nil {
+			progress.Elapsed = time.Since(start)
+			rate := float64(progress.Elapsed) / float64(progress.Done-resumedAt)
+			progress.ETA = time.Duration(rate * float64(len(docs)-progress.Done))
+			opts.OnProgress(progress)
+		}
+	}
+	return nil
+}
+
+// embedBatch embeds the documents from start to end, returning their embeddings and tokens
+func embedBatch(ctx context.Context, g *genkit.Genkit, opts BatchEmbedOptions, docs []*ai.Document, start, end int) ([]EmbeddedDocument, int, error) {
+	var batch []EmbeddedDocument
+	var input []*ai.Document
+	tokens := 0
+	for i := start; i < end; i++ {
+		text := documentText(docs[i])
+		if text == "" {
+			continue
+		}
+		batch = append(batch, EmbeddedDocument{Index: i, Document: docs[i]})
+		input = append(input, docs[i])
+		tokens += opts.Tokenizer.CountTokens(text)
+	}
+	if len(input) == 0 {
+		return nil, 0, nil
+	}
+
+	resp, err := genkit.Embed(ctx, g, ai.WithEmbedder(opts.Embedder), ai.WithDocs(input...))
+	if err != nil {
+		return nil, 0, fmt.Errorf("batch embedding failed at document %d: %w", start, err)
+	}
+	if len(resp.Embeddings) != len(input) {
+		return nil, 0, fmt.Errorf("embedder returned %d embeddings for %d documents", len(resp.Embeddings), len(input))
+	}
+	for i, embedding := range resp.Embeddings {
+		batch[i].Embedding = embedding.Embedding
+	}
+	return batch, tokens, nil
+}
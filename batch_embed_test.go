@@ -0,0 +1,109 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestBatchEmbedResumes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"object":"list","model":"text-embedding-3-small","data":[{"object":"embedding","index":0,"embedding":[0.5,1]}],`+
+			`"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	embedder := plugin.DefineEmbedder(g, "text-embedding-3-small")
+
+	docs := []*ai.Document{
+		ai.DocumentFromText("one", nil),
+		ai.DocumentFromText("", nil), // Skipped
+		ai.DocumentFromText("three", nil),
+		ai.DocumentFromText("four", nil),
+		ai.DocumentFromText("five", nil),
+	}
+	store := FileCheckpointStore{Dir: t.TempDir()}
+	var stored []int
+	failed := false
+	var progress []EmbedProgress
+	opts := BatchEmbedOptions{
+		Embedder:    embedder,
+		BatchSize:   2,
+		Checkpoints: store,
+		JobID:       "backfill",
+		Sink: func(ctx context.Context, batch []EmbeddedDocument) error {
+			if batch[0].Index == 2 && !failed {
+				failed = true
+				return errors.New("vector store unavailable")
+			}
+			for _, doc := range batch {
+				if len(doc.Embedding) != 2 {
+					t.Fatalf("embedding = %v", doc.Embedding)
+				}
+				stored = append(stored, doc.Index)
+			}
+			return nil
+		},
+		OnProgress: func(p EmbedProgress) { progress = append(progress, p) },
+	}
+
+	// The first run stops when the sink fails on the second batch
+	err := BatchEmbed(ctx, g, docs, opts)
+	if err == nil || !strings.Contains(err.Error(), "vector store unavailable") {
+		t.Fatalf("BatchEmbed() error = %v", err)
+	}
+	checkpoint, err := store.Load(ctx, "backfill")
+	if err != nil || checkpoint == nil || checkpoint.Done != 2 || checkpoint.Total != 5 {
+		t.Fatalf("checkpoint = %+v, %v", checkpoint, err)
+	}
+
+	// The second run resumes after the first batch
+	if err := BatchEmbed(ctx, g, docs, opts); err != nil {
+		t.Fatalf("BatchEmbed() error = %v", err)
+	}
+	if len(stored) != 4 || stored[1] != 2 || stored[3] != 4 {
+		t.Fatalf("stored documents = %v", stored)
+	}
+	if requests != 6 {
+		t.Fatalf("got %d embedding requests, want 6", requests)
+	}
+	last := progress[len(progress)-1]
+	if len(progress) != 3 || last.Done != 5 || last.Total != 5 || last.Tokens == 0 || last.ETA != 0 {
+		t.Fatalf("progress = %+v", progress)
+	}
+
+	// A changed input does not resume from the old checkpoint
+	if err := BatchEmbed(ctx, g, docs[:4], opts); err == nil {
+		t.Fatal("BatchEmbed() resumed a checkpoint for different documents")
+	}
+}
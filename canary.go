@@ -0,0 +1,352 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Rollout defaults
+const (
+	defaultCanaryStepRequests    = 100
+	defaultCanaryMaxErrorRate    = 0.05
+	defaultCanaryMaxLatencyRatio = 1.5
+
+	// canaryMinSamples is how many canary requests a step needs before it can be rolled back
+	canaryMinSamples = 10
+)
+
+// defaultCanarySteps are the shares of traffic a rollout sends to the canary by default
+var defaultCanarySteps = []float64{0.01, 0.1, 1}
+
+// CanaryRollout registers a model that moves traffic from a stable deployment to a new one in
+// steps, e.g. 1% → 10% → 100%. Each step lasts until the canary served StepRequests requests;
+// when its error rate or latency regresses beyond the thresholds, all traffic goes back to the
+// stable deployment. Requests the canary fails with a 408, 429 or 5xx are retried on the stable
+// deployment. The rollout is controlled with CanaryStatus, PromoteCanary and RollbackCanary.
+type CanaryRollout struct {
+	Name            string    `json:"name"`                      // Name of the rollout model (required)
+	Stable          string    `json:"stable"`                    // Model or group serving traffic today (required)
+	Canary          string    `json:"canary"`                    // Model or group traffic is moved to (required)
+	Steps           []float64 `json:"steps,omitempty"`           // Increasing shares of traffic sent to Canary, up to 1. Defaults to 0.01, 0.1, 1
+	StepRequests    int       `json:"stepRequests,omitempty"`    // Canary requests a step serves before advancing. Defaults to 100
+	MaxErrorRate    float64   `json:"maxErrorRate,omitempty"`    // How much Canary's error rate may exceed Stable's. Defaults to 0.05
+	MaxLatencyRatio float64   `json:"maxLatencyRatio,omitempty"` // How many times Stable's average latency Canary's may reach. Defaults to 1.5
+	Manual          bool      `json:"manual,omitempty"`          // Advance only with PromoteCanary; regressions still roll back
+}
+
+// CanaryState is the phase of a canary rollout.
+type CanaryState string
+
+const (
+	// CanaryRamping means traffic is being moved to the canary.
+	CanaryRamping CanaryState = "ramping"
+	// CanaryComplete means the last step served its requests without regressing.
+	CanaryComplete CanaryState = "complete"
+	// CanaryRolledBack means all traffic went back to the stable deployment.
+	CanaryRolledBack CanaryState = "rolled_back"
+)
+
+// CanaryStatus is the progress of a canary rollout.
+type CanaryStatus struct {
+	Name   string
+	State  CanaryState
+	Step   int     // Index of the current step in Steps
+	Share  float64 // Share of traffic sent to the canary; 0 once rolled back
+	Reason string  // Why the rollout was rolled back
+
+	Canary CanaryStats // Canary requests in the current step
+	Stable CanaryStats // Stable requests since the rollout started
+}
+
+// CanaryStats counts the requests served by one side of a canary rollout.
+type CanaryStats struct {
+	Requests int
+	Errors   int
+	Latency  time.Duration // Average latency of successful requests
+}
+
+// ErrorRate returns the share of requests that failed.
+func (s CanaryStats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// canaryCounter accumulates CanaryStats
+type canaryCounter struct {
+	requests  int
+	errors    int
+	successes int
+	latency   time.Duration // Total latency of successful requests
+}
+
+func (c *canaryCounter) observe(elapsed time.Duration, failed bool) {
+	c.requests++
+	if failed {
+		c.errors++
+		return
+	}
+	c.successes++
+	c.latency += elapsed
+}
+
+func (c *canaryCounter) stats() CanaryStats {
+	stats := CanaryStats{Requests: c.requests, Errors: c.errors}
+	if c.successes > 0 {
+		stats.Latency = c.latency / time.Duration(c.successes)
+	}
+	return stats
+}
+
+// canary is the state of a running rollout
+type canary struct {
+	rollout CanaryRollout
+	random  func() float64
+
+	mu     sync.Mutex
+	state  CanaryState
+	step   int
+	reason string
+	canary canaryCounter // Reset on every step
+	stable canaryCounter
+}
+
+func newCanary(rollout CanaryRollout) *canary {
+	if len(rollout.Steps) == 0 {
+		rollout.Steps = defaultCanarySteps
+	}
+	if rollout.StepRequests <= 0 {
+		rollout.StepRequests = defaultCanaryStepRequests
+	}
+	if rollout.MaxErrorRate <= 0 {
+		rollout.MaxErrorRate = defaultCanaryMaxErrorRate
+	}
+	if rollout.MaxLatencyRatio <= 0 {
+		rollout.MaxLatencyRatio = defaultCanaryMaxLatencyRatio
+	}
+	return &canary{rollout: rollout, random: rand.Float64, state: CanaryRamping}
+}
+
+// validate checks that the rollout references declared names and ramps up
+func (r CanaryRollout) validate(declared map[string]bool) error {
+	for _, name := range []string{r.Stable, r.Canary} {
+		if !declared[name] {
+			return fmt.Errorf("model registry: canary %q references undeclared model %q", r.Name, name)
+		}
+	}
+	if r.Stable == r.Canary {
+		return fmt.Errorf("model registry: canary %q has the same stable and canary model", r.Name)
+	}
+	previous := 0.0
+	for _, share := range r.Steps {
+		if share <= previous || share > 1 {
+			return fmt.Errorf("model registry: canary %q steps must increase from above 0 up to 1", r.Name)
+		}
+		previous = share
+	}
+	if r.StepRequests < 0 || r.MaxErrorRate < 0 || r.MaxLatencyRatio < 0 {
+		return fmt.Errorf("model registry: canary %q has negative thresholds", r.Name)
+	}
+	return nil
+}
+
+// status returns the progress of the rollout
+func (c *canary) status() CanaryStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.statusLocked()
+}
+
+func (c *canary) statusLocked() CanaryStatus {
+	status := CanaryStatus{
+		Name:   c.rollout.Name,
+		State:  c.state,
+		Step:   c.step,
+		Reason: c.reason,
+		Canary: c.canary.stats(),
+		Stable: c.stable.stats(),
+	}
+	if c.state != CanaryRolledBack {
+		status.Share = c.rollout.Steps[c.step]
+	}
+	return status
+}
+
+// pick reports whether a request goes to the canary
+func (c *canary) pick() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state != CanaryRolledBack && c.random() < c.rollout.Steps[c.step]
+}
+
+// observe records a request outcome and returns the status when it advanced or rolled back
+// the rollout
+func (c *canary) observe(toCanary bool, elapsed time.Duration, failed bool) *CanaryStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !toCanary {
+		c.stable.observe(elapsed, failed)
+		return nil
+	}
+	if c.state == CanaryRolledBack {
+		return nil
+	}
+	c.canary.observe(elapsed, failed)
+
+	if c.canary.requests >= min(canaryMinSamples, c.rollout.StepRequests) {
+		if reason := c.regression(); reason != "" {
+			c.rollbackLocked(reason)
+			status := c.statusLocked()
+			return &status
+		}
+	}
+	if c.state == CanaryRamping && !c.rollout.Manual && c.canary.requests >= c.rollout.StepRequests {
+		c.advanceLocked()
+		status := c.statusLocked()
+		return &status
+	}
+	return nil
+}
+
+// regression describes how the canary regressed against the stable deployment, if it did
+func (c *canary) regression() string {
+	canary, stable := c.canary.stats(), c.stable.stats()
+	if canary.ErrorRate() > stable.ErrorRate()+c.rollout.MaxErrorRate {
+		return fmt.Sprintf("error rate %.1f%% against %.1f%% on %s", 100*canary.ErrorRate(), 100*stable.ErrorRate(), c.rollout.Stable)
+	}
+	if canary.Latency > 0 && stable.Latency > 0 && float64(canary.Latency) > c.rollout.MaxLatencyRatio*float64(stable.Latency) {
+		return fmt.Sprintf("average latency %s against %s on %s", canary.Latency, stable.Latency, c.rollout.Stable)
+	}
+	return ""
+}
+
+// advanceLocked moves to the next step, or completes the rollout after the last one.
+// A rolled back rollout starts over from the first step.
+func (c *canary) advanceLocked() {
+	switch {
+	case c.state == CanaryRolledBack:
+		c.state, c.step, c.reason = CanaryRamping, 0, ""
+	case c.step < len(c.rollout.Steps)-1:
+		c.step++
+	default:
+		c.state = CanaryComplete
+	}
+	c.canary = canaryCounter{}
+}
+
+func (c *canary) rollbackLocked(reason string) {
+	c.state, c.reason = CanaryRolledBack, reason
+	c.canary = canaryCounter{}
+}
+
+// canaryFunc returns a model function that splits requests between the stable and canary
+// models of a rollout
+func (a *AzureAIFoundry) canaryFunc(c *canary, member func(name string) ai.ModelFunc) ai.ModelFunc {
+	rollout := c.rollout
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		toCanary := c.pick()
+		timed := func(name string) ai.ModelFunc {
+			fn := member(name)
+			if fn == nil {
+				return nil
+			}
+			return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+				start := time.Now()
+				tracked, streamed := trackStream(cb)
+				resp, err := fn(ctx, input, tracked)
+				// Streams that broke after their first chunk are neither retried nor counted
+				if err == nil || (isFailoverError(ctx, err) && !streamed.Load()) {
+					if status := c.observe(name == rollout.Canary, time.Since(start), err != nil); status != nil {
+						a.emitCanary(ctx, status)
+					}
+				}
+				return resp, err
+			}
+		}
+		names := []string{rollout.Stable}
+		if toCanary {
+			names = []string{rollout.Canary, rollout.Stable}
+		}
+		return a.tryModels(ctx, rollout.Name, names, timed, input, cb)
+	}
+}
+
+// emitCanary reports that a rollout advanced or rolled back
+func (a *AzureAIFoundry) emitCanary(ctx context.Context, status *CanaryStatus) {
+	event := Event{Type: EventCanaryAdvanced, Model: status.Name, Canary: status}
+	if status.State == CanaryRolledBack {
+		event.Type = EventCanaryRolledBack
+	}
+	a.emit(ctx, event)
+}
+
+// canaryNamed returns the running rollout of a registry canary
+func (a *AzureAIFoundry) canaryNamed(name string) (*canary, error) {
+	a.registry.mu.RLock()
+	defer a.registry.mu.RUnlock()
+	if c := a.registry.canaries[name]; c != nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("canary %q is not in the model registry", name)
+}
+
+// CanaryStatus returns the progress of a canary rollout from the model registry.
+func (a *AzureAIFoundry) CanaryStatus(name string) (CanaryStatus, error) {
+	c, err := a.canaryNamed(name)
+	if err != nil {
+		return CanaryStatus{}, err
+	}
+	return c.status(), nil
+}
+
+// PromoteCanary moves a canary rollout to its next step without waiting for StepRequests,
+// or completes it from the last step. A rolled back rollout starts over from the first step.
+func (a *AzureAIFoundry) PromoteCanary(ctx context.Context, name string) (CanaryStatus, error) {
+	c, err := a.canaryNamed(name)
+	if err != nil {
+		return CanaryStatus{}, err
+	}
+	c.mu.Lock()
+	c.advanceLocked()
+	status := c.statusLocked()
+	c.mu.Unlock()
+	a.emitCanary(ctx, &status)
+	return status, nil
+}
+
+// RollbackCanary sends all traffic of a canary rollout back to its stable model.
+func (a *AzureAIFoundry) RollbackCanary(ctx context.Context, name string) (CanaryStatus, error) {
+	c, err := a.canaryNamed(name)
+	if err != nil {
+		return CanaryStatus{}, err
+	}
+	c.mu.Lock()
+	c.rollbackLocked("rolled back manually")
+	status := c.statusLocked()
+	c.mu.Unlock()
+	a.emitCanary(ctx, &status)
+	return status, nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestCanaryObserve(t *testing.T) {
+	type outcome struct {
+		canary  bool
+		latency time.Duration
+		failed  bool
+		times   int
+	}
+	tests := []struct {
+		name       string
+		rollout    CanaryRollout
+		outcomes   []outcome
+		wantState  CanaryState
+		wantStep   int
+		wantShare  float64
+		wantReason string
+	}{
+		{
+			name:     "advances after step requests",
+			rollout:  CanaryRollout{Steps: []float64{0.1, 0.5, 1}, StepRequests: 10},
+			outcomes: []outcome{{canary: false, latency: 100 * time.Millisecond, times: 20}, {canary: true, latency: 100 * time.Millisecond, times: 15}},
+			wantStep: 1, wantShare: 0.5, wantState: CanaryRamping,
+		},
+		{
+			name:     "completes after last step",
+			rollout:  CanaryRollout{Steps: []float64{0.5, 1}, StepRequests: 10},
+			outcomes: []outcome{{canary: true, latency: time.Millisecond, times: 20}},
+			wantStep: 1, wantShare: 1, wantState: CanaryComplete,
+		},
+		{
+			name:    "error rate regression rolls back",
+			rollout: CanaryRollout{StepRequests: 100},
+			outcomes: []outcome{
+				{canary: false, latency: 100 * time.Millisecond, times: 20},
+				{canary: true, latency: 100 * time.Millisecond, times: 8},
+				{canary: true, failed: true, times: 2},
+			},
+			wantState: CanaryRolledBack, wantReason: "error rate 20.0% against 0.0% on stable",
+		},
+		{
+			name:    "latency regression rolls back",
+			rollout: CanaryRollout{StepRequests: 100, MaxLatencyRatio: 2},
+			outcomes: []outcome{
+				{canary: false, latency: 100 * time.Millisecond, times: 20},
+				{canary: true, latency: 250 * time.Millisecond, times: 10},
+			},
+			wantState: CanaryRolledBack, wantReason: "average latency 250ms against 100ms on stable",
+		},
+		{
+			name:    "manual rollout waits",
+			rollout: CanaryRollout{Steps: []float64{0.1, 1}, StepRequests: 10, Manual: true},
+			outcomes: []outcome{
+				{canary: true, latency: time.Millisecond, times: 30},
+			},
+			wantStep: 0, wantShare: 0.1, wantState: CanaryRamping,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rollout.Name, tt.rollout.Stable, tt.rollout.Canary = "rollout", "stable", "canary"
+			c := newCanary(tt.rollout)
+			for _, o := range tt.outcomes {
+				for range o.times {
+					c.observe(o.canary, o.latency, o.failed)
+				}
+			}
+			status := c.status()
+			if status.State != tt.wantState || status.Step != tt.wantStep || status.Share != tt.wantShare || status.Reason != tt.wantReason {
+				t.Fatalf("status() = %+v, want state %s, step %d, share %v, reason %q",
+					status, tt.wantState, tt.wantStep, tt.wantShare, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCanaryRolloutValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		canary  string
+		wantErr string
+	}{
+		{name: "valid", canary: `{name: gpt-4o-rollout, stable: gpt-4o, canary: gpt-4o-v2, steps: [0.05, 0.5, 1]}`},
+		{name: "undeclared", canary: `{name: gpt-4o-rollout, stable: gpt-4o, canary: gpt-4o-v3}`, wantErr: `undeclared model "gpt-4o-v3"`},
+		{name: "same models", canary: `{name: gpt-4o-rollout, stable: gpt-4o, canary: gpt-4o}`, wantErr: "same stable and canary"},
+		{name: "decreasing steps", canary: `{name: gpt-4o-rollout, stable: gpt-4o, canary: gpt-4o-v2, steps: [0.5, 0.1]}`, wantErr: "steps must increase"},
+		{name: "name taken", canary: `{name: gpt-4o, stable: gpt-4o, canary: gpt-4o-v2}`, wantErr: "has the name of a model"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadModelsFromConfig([]byte("models: [{name: gpt-4o}, {name: gpt-4o-v2}]\ncanaries: [" + tt.canary + "]\n"))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("LoadModelsFromConfig() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("LoadModelsFromConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCanaryRollout(t *testing.T) {
+	var mu sync.Mutex
+	served := map[string]int{}
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		model, _ := body["model"].(string)
+		mu.Lock()
+		defer mu.Unlock()
+		served[model]++
+		if model == "gpt-4o-v2" && failing {
+			w.Header().Set("x-should-retry", "false")
+			http.Error(w, `{"error":{"code":"InternalServerError","message":"boom"}}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	defer server.Close()
+
+	registry, err := LoadModelsFromConfig([]byte(`
+models: [{name: gpt-4o}, {name: gpt-4o-v2}]
+canaries:
+  - {name: gpt-4o-rollout, stable: gpt-4o, canary: gpt-4o-v2, steps: [0.5, 1], stepRequests: 10, maxLatencyRatio: 1000}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Registry: registry}
+	ctx := context.Background()
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	var events []Event
+	plugin.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+		if event.Type == EventCanaryAdvanced || event.Type == EventCanaryRolledBack {
+			events = append(events, event)
+		}
+	}))
+	// Send every request the rollout decides on to the canary
+	c, err := plugin.canaryNamed("gpt-4o-rollout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.random = func() float64 { return 0 }
+
+	generate := func(times int) {
+		t.Helper()
+		for range times {
+			if _, err := genkit.Generate(ctx, g, ai.WithModelName("azureaifoundry/gpt-4o-rollout"), ai.WithPrompt("hi")); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+		}
+	}
+
+	// Failing canary requests are served by the stable model until the rollout rolls back
+	generate(canaryMinSamples + 5)
+	status, _ := plugin.CanaryStatus("gpt-4o-rollout")
+	if status.State != CanaryRolledBack || served["gpt-4o-v2"] != canaryMinSamples || served["gpt-4o"] != canaryMinSamples+5 {
+		t.Fatalf("status = %+v, served = %v, want rolled back after %d canary requests", status, served, canaryMinSamples)
+	}
+	if len(events) != 1 || events[0].Type != EventCanaryRolledBack || events[0].Canary.Reason == "" {
+		t.Fatalf("events = %+v, want one rollback", events)
+	}
+
+	// Promoting starts over; a healthy canary ramps up to all traffic
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	if status, err = plugin.PromoteCanary(ctx, "gpt-4o-rollout"); err != nil || status.State != CanaryRamping || status.Share != 0.5 {
+		t.Fatalf("PromoteCanary() = %+v, %v", status, err)
+	}
+	generate(20)
+	status, _ = plugin.CanaryStatus("gpt-4o-rollout")
+	if status.State != CanaryComplete || status.Share != 1 {
+		t.Fatalf("status = %+v, want complete", status)
+	}
+	if len(events) != 4 || events[2].Canary.Share != 1 || events[3].Canary.State != CanaryComplete {
+		t.Fatalf("events = %+v, want promotion, advance and completion", events)
+	}
+
+	if status, err = plugin.RollbackCanary(ctx, "gpt-4o-rollout"); err != nil || status.Share != 0 {
+		t.Fatalf("RollbackCanary() = %+v, %v", status, err)
+	}
+	if _, err := plugin.CanaryStatus("gpt-4o"); err == nil {
+		t.Fatal("CanaryStatus() of a model error = nil")
+	}
+}
+
+func TestCanaryStreaming(t *testing.T) {
+	tests := []struct {
+		name       string
+		broken     map[string]int
+		wantCalls  int // Requests sent to the stable model
+		wantErrors int // Canary errors counted toward rollback
+	}{
+		{"error before the first chunk", map[string]int{"gpt-4o-v2": 0}, 5, 5},
+		{"error after a chunk", map[string]int{"gpt-4o-v2": 1}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCanary(CanaryRollout{Name: "gpt-4o-rollout", Stable: "gpt-4o", Canary: "gpt-4o-v2", StepRequests: 100})
+			c.random = func() float64 { return 0 }
+			var calls []string
+			fn := (&AzureAIFoundry{}).canaryFunc(c, streamingMembers(&calls, tt.broken))
+
+			for range 5 {
+				var chunks []string
+				_, err := fn(t.Context(), &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}},
+					func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+						chunks = append(chunks, chunk.Text())
+						return nil
+					})
+				if (err != nil) != (tt.wantCalls == 0) || len(chunks) != 1 {
+					t.Fatalf("error = %v, chunks = %q", err, chunks)
+				}
+			}
+			stable := 0
+			for _, name := range calls {
+				if name == "gpt-4o" {
+					stable++
+				}
+			}
+			if status := c.status(); stable != tt.wantCalls || status.Canary.Errors != tt.wantErrors {
+				t.Fatalf("stable requests = %d, canary stats = %+v", stable, status.Canary)
+			}
+		})
+	}
+}
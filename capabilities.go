@@ -0,0 +1,199 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// Chat request features checked by capability probing
+const (
+	FeatureMaxCompletionTokens = "maxCompletionTokens" // max_completion_tokens instead of max_tokens
+	FeatureServiceTier         = "serviceTier"         // service_tier
+	FeatureStreamUsage         = "streamUsage"         // stream_options.include_usage
+)
+
+// capabilityProbeTimeout bounds a single capability probe request
+const capabilityProbeTimeout = 10 * time.Second
+
+// CapabilityProbe configures the probing of the endpoint at Init.
+type CapabilityProbe struct {
+	APIVersions []string // Additional api-versions to check, e.g. newer previews. The api-version requests use is always checked
+	Deployment  string   // Chat deployment features are checked with, e.g. "gpt-4o". Features are not checked without one
+}
+
+// CapabilityReport is what the endpoint accepted when it was probed.
+type CapabilityReport struct {
+	ProbedAt    time.Time
+	APIVersion  string          // The api-version requests use. Empty with UseV1API
+	APIVersions map[string]bool // Whether each checked api-version was accepted
+	Features    map[string]bool // Whether each checked feature was accepted with APIVersion
+}
+
+// Supports reports whether a feature may be sent. Features that were not checked, or a nil
+// report, are assumed to be supported.
+func (r *CapabilityReport) Supports(feature string) bool {
+	if r == nil {
+		return true
+	}
+	supported, checked := r.Features[feature]
+	return supported || !checked
+}
+
+// Capabilities returns the report of the last capability probe, or nil if the endpoint was
+// never probed.
+func (a *AzureAIFoundry) Capabilities() *CapabilityReport {
+	return a.capabilities.Load()
+}
+
+// ProbeCapabilities checks which api-versions and chat request features the endpoint
+// accepts, and makes the result the report requests are filtered with. The plugin must be
+// initialized. Init calls it when CapabilityProbe is set.
+func (a *AzureAIFoundry) ProbeCapabilities(ctx context.Context, probe CapabilityProbe) (*CapabilityReport, error) {
+	ctx, end, err := a.lifecycle.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	report := &CapabilityReport{
+		APIVersion:  a.apiVersion(),
+		APIVersions: make(map[string]bool),
+		Features:    make(map[string]bool),
+	}
+	versions := []string{report.APIVersion}
+	for _, version := range probe.APIVersions {
+		if !slices.Contains(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	if !a.OpenAICompatible && !a.UseV1API {
+		// OpenAI-compatible endpoints and the v1 API have no api-versions
+		for _, version := range versions {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(ctx, capabilityProbeTimeout)
+				defer cancel()
+				_, err := a.client.Models.List(ctx, option.WithQuery("api-version", version), option.WithMaxRetries(0))
+				mu.Lock()
+				report.APIVersions[version] = !isRejected(err)
+				mu.Unlock()
+			}()
+		}
+	}
+	if probe.Deployment != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			features := a.probeFeatures(ctx, probe.Deployment)
+			mu.Lock()
+			report.Features = features
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report.ProbedAt = time.Now()
+	a.capabilities.Store(report)
+	return report, nil
+}
+
+// probeFeatures sends a one-token chat request to the deployment without optional features,
+// then one per feature. A feature is unsupported when only its request is rejected.
+func (a *AzureAIFoundry) probeFeatures(ctx context.Context, deployment string) map[string]bool {
+	send := func(params openai.ChatCompletionNewParams, stream bool) error {
+		ctx, cancel := context.WithTimeout(ctx, capabilityProbeTimeout)
+		defer cancel()
+		params.Model = deployment
+		params.Messages = []openai.ChatCompletionMessageParamUnion{openai.UserMessage("ping")}
+		if !params.MaxCompletionTokens.Valid() {
+			params.MaxTokens = openai.Int(1)
+		}
+		if !stream {
+			_, err := a.client.Chat.Completions.New(ctx, params, option.WithMaxRetries(0))
+			return err
+		}
+		events := a.client.Chat.Completions.NewStreaming(ctx, params, option.WithMaxRetries(0))
+		defer events.Close()
+		for events.Next() {
+		}
+		return events.Err()
+	}
+
+	features := make(map[string]bool)
+	if err := send(openai.ChatCompletionNewParams{}, false); err != nil {
+		// Feature results would not be meaningful if the deployment itself fails
+		logger.FromContext(ctx).Warn("azureaifoundry: skipping feature probes, the probe deployment failed",
+			"model", deployment, "err", a.redactError(err))
+		return features
+	}
+	features[FeatureMaxCompletionTokens] = !isRejected(send(openai.ChatCompletionNewParams{MaxCompletionTokens: openai.Int(1)}, false))
+	features[FeatureServiceTier] = !isRejected(send(openai.ChatCompletionNewParams{ServiceTier: openai.ChatCompletionNewParamsServiceTierAuto}, false))
+	features[FeatureStreamUsage] = !isRejected(send(openai.ChatCompletionNewParams{
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)},
+	}, true))
+	return features
+}
+
+// isRejected reports whether a probe was refused as a bad request, e.g. an unknown parameter
+// or api-version, rather than failing for another reason
+func isRejected(err error) bool {
+	var apiErr *openai.Error
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusNotFound)
+}
+
+// explainError adds what the capability probe found to errors the api-version may cause
+func (a *AzureAIFoundry) explainError(err error) error {
+	report := a.Capabilities()
+	if report == nil || !isRejected(err) {
+		return err
+	}
+	if accepted, checked := report.APIVersions[report.APIVersion]; !checked || accepted {
+		return err
+	}
+	var working []string
+	for version, accepted := range report.APIVersions {
+		if accepted {
+			working = append(working, version)
+		}
+	}
+	slices.Sort(working)
+	if len(working) == 0 {
+		return fmt.Errorf("%w (api-version %s was rejected by the endpoint when probed)", err, report.APIVersion)
+	}
+	return fmt.Errorf("%w (api-version %s was rejected by the endpoint when probed; accepted: %s)",
+		err, report.APIVersion, strings.Join(working, ", "))
+}
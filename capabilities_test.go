@@ -0,0 +1,170 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// capabilityHandler fakes an endpoint that accepts the given api-versions and rejects chat
+// requests with the given parameters. Accepted chat requests are recorded in bodies
+func capabilityHandler(mu *sync.Mutex, bodies *[]map[string]any, versions []string, rejected []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-should-retry", "false")
+		if strings.HasSuffix(r.URL.Path, "/models") {
+			if !slices.Contains(versions, r.URL.Query().Get("api-version")) {
+				http.Error(w, `{"error":{"code":"404","message":"Resource not found"}}`, http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"object":"list","data":[]}`)
+			return
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		if strings.Contains(r.URL.Path, "/missing/") {
+			http.Error(w, `{"error":{"code":"DeploymentNotFound","message":"not found"}}`, http.StatusNotFound)
+			return
+		}
+		for _, param := range rejected {
+			if _, ok := body[param]; ok {
+				http.Error(w, `{"error":{"code":"BadRequest","message":"Unrecognized request argument supplied: `+param+`"}}`, http.StatusBadRequest)
+				return
+			}
+		}
+		mu.Lock()
+		*bodies = append(*bodies, body)
+		mu.Unlock()
+		if body["stream"] == true {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}
+}
+
+func TestCapabilityProbe(t *testing.T) {
+	tests := []struct {
+		name         string
+		versions     []string
+		rejected     []string
+		wantVersions map[string]bool
+		wantFeatures map[string]bool
+		wantBody     map[string]any
+		wantErr      string
+	}{
+		{
+			name:         "current endpoint",
+			versions:     []string{"2025-03-01-preview", "2024-02-01"},
+			wantVersions: map[string]bool{"2025-03-01-preview": true, "2024-02-01": true},
+			wantFeatures: map[string]bool{FeatureMaxCompletionTokens: true, FeatureServiceTier: true, FeatureStreamUsage: true},
+			wantBody:     map[string]any{"max_completion_tokens": float64(100), "service_tier": "flex"},
+			wantErr:      "not found",
+		},
+		{
+			name:         "older endpoint",
+			versions:     []string{"2024-02-01"},
+			rejected:     []string{"max_completion_tokens", "service_tier", "stream_options"},
+			wantVersions: map[string]bool{"2025-03-01-preview": false, "2024-02-01": true},
+			wantFeatures: map[string]bool{FeatureMaxCompletionTokens: false, FeatureServiceTier: false, FeatureStreamUsage: false},
+			wantBody:     map[string]any{"max_tokens": float64(100)},
+			wantErr:      "api-version 2025-03-01-preview was rejected by the endpoint when probed; accepted: 2024-02-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, capabilityHandler(&mu, &bodies, tt.versions, tt.rejected), func(a *AzureAIFoundry) {
+				a.CapabilityProbe = &CapabilityProbe{APIVersions: []string{"2024-02-01"}, Deployment: "gpt-4.1"}
+			})
+
+			report := plugin.Capabilities()
+			if report == nil {
+				t.Fatal("Capabilities() = nil after Init")
+			}
+			if !reflect.DeepEqual(report.APIVersions, tt.wantVersions) || !reflect.DeepEqual(report.Features, tt.wantFeatures) {
+				t.Fatalf("Capabilities() = %+v, want api-versions %v and features %v", report, tt.wantVersions, tt.wantFeatures)
+			}
+
+			// Unsupported parameters are left out of requests instead of failing them
+			ctx := context.Background()
+			g := genkit.Init(ctx)
+			model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4.1", Type: "chat"}, nil)
+			mu.Lock()
+			bodies = nil
+			mu.Unlock()
+			_, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("hi"),
+				ai.WithConfig(map[string]any{"maxOutputTokens": 100, "serviceTier": "flex"}))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			for key, want := range tt.wantBody {
+				if bodies[0][key] != want {
+					t.Errorf("request %s = %v, want %v", key, bodies[0][key], want)
+				}
+			}
+			if len(bodies[0]) != 2+len(tt.wantBody) {
+				t.Errorf("request = %v, want only model, messages and %v", bodies[0], tt.wantBody)
+			}
+
+			_, err = genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("hi"),
+				ai.WithConfig(map[string]any{"streamUsage": true}),
+				ai.WithStreaming(func(context.Context, *ai.ModelResponseChunk) error { return nil }))
+			if err != nil {
+				t.Fatalf("streaming Generate() error = %v", err)
+			}
+
+			missing := plugin.DefineModel(g, ModelDefinition{Name: "missing", Type: "chat"}, nil)
+			_, err = genkit.Generate(ctx, g, ai.WithModel(missing), ai.WithPrompt("hi"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCapabilityReportSupports(t *testing.T) {
+	var report *CapabilityReport
+	if !report.Supports(FeatureServiceTier) {
+		t.Error("nil report Supports() = false, want true")
+	}
+	report = &CapabilityReport{Features: map[string]bool{FeatureServiceTier: false}}
+	if report.Supports(FeatureServiceTier) {
+		t.Error("Supports() of rejected feature = true, want false")
+	}
+	if !report.Supports(FeatureStreamUsage) {
+		t.Error("Supports() of unchecked feature = false, want true")
+	}
+}
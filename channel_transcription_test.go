@@ -0,0 +1,156 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stereoWAV interleaves two mono test recordings of equal length into a stereo WAV file
+func stereoWAV(t *testing.T, left, right []byte) []byte {
+	t.Helper()
+	l, err := parseWAV(left)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := parseWAV(right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples []byte
+	for pos := 0; pos+2 <= len(l.data) && pos+2 <= len(r.data); pos += 2 {
+		samples = append(samples, l.data[pos:pos+2]...)
+		samples = append(samples, r.data[pos:pos+2]...)
+	}
+	return (&wavAudio{format: pcmFormat(2, l.sampleRate)}).encode(samples)
+}
+
+func TestWAVChannel(t *testing.T) {
+	samples := []int16{1, -1, 2, -2, 3, -3}
+	var data []byte
+	for _, s := range samples {
+		data = binary.LittleEndian.AppendUint16(data, uint16(s))
+	}
+	audio, err := parseWAV((&wavAudio{format: pcmFormat(2, 8000)}).encode(data))
+	if err != nil {
+		t.Fatalf("parseWAV() error = %v", err)
+	}
+
+	right, err := parseWAV(audio.channel(1))
+	if err != nil {
+		t.Fatalf("channel is not a WAV file: %v", err)
+	}
+	if right.channels != 1 || right.sampleRate != 8000 {
+		t.Fatalf("channel format = %d channels at %d Hz", right.channels, right.sampleRate)
+	}
+	var got []int16
+	for pos := 0; pos < len(right.data); pos += 2 {
+		got = append(got, int16(binary.LittleEndian.Uint16(right.data[pos:])))
+	}
+	if fmt.Sprint(got) != "[-1 -2 -3]" {
+		t.Fatalf("channel samples = %v", got)
+	}
+}
+
+func TestTranscribeChannels(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile() error = %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		// Answer according to when the uploaded channel starts speaking
+		trimmed, err := TrimSilence(data, VADOptions{})
+		if err != nil || len(trimmed.Regions) == 0 {
+			t.Errorf("uploaded channel has no speech: %v", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if trimmed.Regions[0].Start < 1 {
+			_, _ = io.WriteString(w, `{"text":"Thanks for calling. How can I help?","language":"english","duration":4,"segments":[
+				{"start":0.5,"end":1.5,"text":" Thanks for calling."},{"start":3,"end":3.8,"text":" How can I help?"}]}`)
+		} else {
+			_, _ = io.WriteString(w, `{"text":"My order is late.","language":"english","duration":4,"segments":[
+				{"start":1.8,"end":2.8,"text":" My order is late."}]}`)
+		}
+	})
+	ctx := context.Background()
+
+	agent := testWAV(0.5, 1, 1.5, 1)
+	customer := testWAV(1.8, 1, 1.2)
+	audio := stereoWAV(t, agent, customer)
+
+	transcript, err := plugin.TranscribeChannels(ctx, ChannelTranscriptionOptions{
+		Model:    "whisper",
+		Audio:    audio,
+		Speakers: []string{"agent", "customer"},
+	})
+	if err != nil {
+		t.Fatalf("TranscribeChannels() error = %v", err)
+	}
+	want := "agent: Thanks for calling.\ncustomer: My order is late.\nagent: How can I help?"
+	if transcript.Text != want {
+		t.Fatalf("Text = %q, want %q", transcript.Text, want)
+	}
+	if len(transcript.Segments) != 3 || transcript.Segments[1].Speaker != "customer" || transcript.Segments[1].Start != 1.8 {
+		t.Fatalf("Segments = %+v", transcript.Segments)
+	}
+	if transcript.Language != "english" || transcript.Duration != 4 {
+		t.Fatalf("Language = %q, Duration = %v", transcript.Language, transcript.Duration)
+	}
+
+	// Unlabelled channels are numbered
+	transcript, err = plugin.TranscribeChannels(ctx, ChannelTranscriptionOptions{Model: "whisper", Audio: audio})
+	if err != nil {
+		t.Fatalf("TranscribeChannels() error = %v", err)
+	}
+	if !strings.HasPrefix(transcript.Text, "channel 1: ") {
+		t.Fatalf("Text = %q", transcript.Text)
+	}
+}
+
+func TestTranscribeChannelsErrors(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+	stereo := stereoWAV(t, testWAV(1), testWAV(1))
+	tests := []struct {
+		name string
+		opts ChannelTranscriptionOptions
+		want string
+	}{
+		{"missing model", ChannelTranscriptionOptions{Audio: stereo}, "requires a transcription model"},
+		{"not WAV", ChannelTranscriptionOptions{Model: "whisper", Audio: []byte("ID3\x04\x00")}, "requires WAV audio"},
+		{"speaker count", ChannelTranscriptionOptions{Model: "whisper", Audio: stereo, Speakers: []string{"agent"}}, "got 1 speakers for 2 channels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := plugin.TranscribeChannels(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
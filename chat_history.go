@@ -0,0 +1,216 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// MarshalOpenAIMessages exports Genkit messages as an OpenAI chat completions "messages"
+// JSON array, including tool calls, tool results and image references. Message metadata,
+// such as Conversation branch IDs, is kept in a "metadata" field.
+func MarshalOpenAIMessages(messages []*ai.Message) ([]byte, error) {
+	converter := &AzureAIFoundry{}
+	var exported []json.RawMessage
+	for _, msg := range messages {
+		for _, converted := range converter.convertMessagesToOpenAI([]*ai.Message{msg}) {
+			data, err := json.Marshal(converted)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal messages: %w", err)
+			}
+			if len(msg.Metadata) > 0 {
+				if data, err = withMessageMetadata(data, msg.Metadata); err != nil {
+					return nil, fmt.Errorf("failed to marshal messages: %w", err)
+				}
+			}
+			exported = append(exported, data)
+		}
+	}
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal messages: %w", err)
+	}
+	return data, nil
+}
+
+// withMessageMetadata adds a "metadata" field to a marshaled message
+func withMessageMetadata(data []byte, metadata map[string]any) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	fields["metadata"] = encoded
+	return json.Marshal(fields)
+}
+
+// wireMessage is an OpenAI chat completions message in wire format
+type wireMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCallID string          `json:"tool_call_id"`
+	Metadata   map[string]any  `json:"metadata"`
+	ToolCalls  []struct {
+		ID       string `json:"id"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	} `json:"tool_calls"`
+}
+
+// wireContentPart is one element of an array-valued message content
+type wireContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+// UnmarshalOpenAIMessages imports an OpenAI chat completions "messages" JSON array, such as
+// a transcript recorded with the Azure SDK, as Genkit messages. Developer messages become
+// system messages, and tool results are matched to the tool calls that requested them.
+// A "metadata" field, as written by MarshalOpenAIMessages, becomes the message metadata.
+func UnmarshalOpenAIMessages(data []byte) ([]*ai.Message, error) {
+	var wire []wireMessage
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, fmt.Errorf("failed to parse messages: %w", err)
+	}
+
+	toolNames := make(map[string]string)
+	var messages []*ai.Message
+	for i, msg := range wire {
+		parts, err := wireContentParts(msg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+
+		switch msg.Role {
+		case "system", "developer":
+			messages = append(messages, ai.NewSystemMessage(parts...))
+		case "user":
+			messages = append(messages, ai.NewUserMessage(parts...))
+		case "assistant":
+			for _, call := range msg.ToolCalls {
+				var input map[string]any
+				if call.Function.Arguments != "" {
+					if err := json.Unmarshal([]byte(call.Function.Arguments), &input); err != nil {
+						return nil, fmt.Errorf("message %d: invalid arguments for tool %q: %w", i, call.Function.Name, err)
+					}
+				}
+				toolNames[call.ID] = call.Function.Name
+				parts = append(parts, ai.NewToolRequestPart(&ai.ToolRequest{
+					Name:  call.Function.Name,
+					Ref:   call.ID,
+					Input: input,
+				}))
+			}
+			messages = append(messages, ai.NewModelMessage(parts...))
+		case "tool":
+			var output any
+			text := partsText(parts)
+			if err := json.Unmarshal([]byte(text), &output); err != nil {
+				output = text
+			}
+			part := ai.NewToolResponsePart(&ai.ToolResponse{
+				Name:   toolNames[msg.ToolCallID],
+				Ref:    msg.ToolCallID,
+				Output: output,
+			})
+			// Tool results exported from one message share its metadata and are merged back
+			if last := len(messages) - 1; msg.Metadata != nil && last >= 0 && messages[last].Role == ai.RoleTool &&
+				reflect.DeepEqual(messages[last].Metadata, msg.Metadata) {
+				messages[last].Content = append(messages[last].Content, part)
+				continue
+			}
+			messages = append(messages, ai.NewMessage(ai.RoleTool, nil, part))
+		default:
+			return nil, fmt.Errorf("message %d: unsupported role %q", i, msg.Role)
+		}
+		messages[len(messages)-1].Metadata = msg.Metadata
+	}
+
+	return messages, nil
+}
+
+// wireContentParts converts string or array message content to Genkit parts
+func wireContentParts(content json.RawMessage) ([]*ai.Part, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		if text == "" {
+			return nil, nil
+		}
+		return []*ai.Part{ai.NewTextPart(text)}, nil
+	}
+
+	var wireParts []wireContentPart
+	if err := json.Unmarshal(content, &wireParts); err != nil {
+		return nil, fmt.Errorf("unsupported content: %w", err)
+	}
+	var parts []*ai.Part
+	for _, part := range wireParts {
+		switch part.Type {
+		case "text":
+			parts = append(parts, ai.NewTextPart(part.Text))
+		case "image_url":
+			parts = append(parts, ai.NewMediaPart(imageContentType(part.ImageURL.URL), part.ImageURL.URL))
+		default:
+			return nil, fmt.Errorf("unsupported content part type %q", part.Type)
+		}
+	}
+	return parts, nil
+}
+
+// imageContentType infers the MIME type of an image reference from its data URL or extension
+func imageContentType(url string) string {
+	if strings.HasPrefix(url, "data:") {
+		if end := strings.IndexAny(url, ";,"); end > len("data:") {
+			return url[len("data:"):end]
+		}
+	}
+	if contentType := mime.TypeByExtension(path.Ext(strings.SplitN(url, "?", 2)[0])); contentType != "" {
+		return contentType
+	}
+	return "image/*"
+}
+
+// partsText concatenates the text of the given parts
+func partsText(parts []*ai.Part) string {
+	var text strings.Builder
+	for _, part := range parts {
+		if part.IsText() {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestOpenAIMessagesRoundTrip(t *testing.T) {
+	messages := []*ai.Message{
+		ai.NewSystemTextMessage("You are a weather bot."),
+		ai.NewUserMessage(
+			ai.NewTextPart("What is the weather here?"),
+			ai.NewMediaPart("image/png", "https://example.com/photo.png"),
+		),
+		ai.NewModelMessage(ai.NewToolRequestPart(&ai.ToolRequest{
+			Name:  "getWeather",
+			Ref:   "call_abc",
+			Input: map[string]any{"city": "Madrid"},
+		})),
+		ai.NewMessage(ai.RoleTool, nil, ai.NewToolResponsePart(&ai.ToolResponse{
+			Name:   "getWeather",
+			Ref:    "call_abc",
+			Output: map[string]any{"tempC": float64(21)},
+		})),
+		ai.NewModelTextMessage("It is 21°C in Madrid."),
+	}
+
+	data, err := MarshalOpenAIMessages(messages)
+	if err != nil {
+		t.Fatalf("MarshalOpenAIMessages() error = %v", err)
+	}
+	got, err := UnmarshalOpenAIMessages(data)
+	if err != nil {
+		t.Fatalf("UnmarshalOpenAIMessages() error = %v", err)
+	}
+
+	if len(got) != len(messages) {
+		t.Fatalf("got %d messages, want %d:\n%s", len(got), len(messages), data)
+	}
+	for i := range messages {
+		if got[i].Role != messages[i].Role {
+			t.Fatalf("message %d role = %s, want %s", i, got[i].Role, messages[i].Role)
+		}
+	}
+	if media := got[1].Content[1]; !media.IsMedia() || media.Text != "https://example.com/photo.png" || media.ContentType != "image/png" {
+		t.Fatalf("media part = %+v", media)
+	}
+	if req := got[2].Content[0].ToolRequest; req == nil || req.Ref != "call_abc" || !reflect.DeepEqual(req.Input, map[string]any{"city": "Madrid"}) {
+		t.Fatalf("tool request = %+v", req)
+	}
+	if resp := got[3].Content[0].ToolResponse; resp == nil || resp.Name != "getWeather" || !reflect.DeepEqual(resp.Output, map[string]any{"tempC": float64(21)}) {
+		t.Fatalf("tool response = %+v", resp)
+	}
+	if got[4].Text() != "It is 21°C in Madrid." {
+		t.Fatalf("final answer = %q", got[4].Text())
+	}
+}
+
+func TestUnmarshalOpenAIMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantRole ai.Role
+		wantText string
+		wantErr  bool
+	}{
+		{name: "developer becomes system", data: `[{"role":"developer","content":"Be brief."}]`, wantRole: ai.RoleSystem, wantText: "Be brief."},
+		{name: "array content", data: `[{"role":"user","content":[{"type":"text","text":"Hi"}]}]`, wantRole: ai.RoleUser, wantText: "Hi"},
+		{name: "unsupported role", data: `[{"role":"function","content":"x"}]`, wantErr: true},
+		{name: "invalid json", data: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalOpenAIMessages([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("UnmarshalOpenAIMessages() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalOpenAIMessages() error = %v", err)
+			}
+			if got[0].Role != tt.wantRole || got[0].Text() != tt.wantText {
+				t.Fatalf("message = %s %q", got[0].Role, got[0].Text())
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// citationMarker matches "[docN]" citation markers, as written by On Your Data and for
+// documents packed with PackContext
+var citationMarker = regexp.MustCompile(`\[doc(\d+)\]`)
+
+// partialCitationMarker matches the start of a citation marker at the end of text
+var partialCitationMarker = regexp.MustCompile(`\[(d(o(c\d*)?)?)?$`)
+
+// Footnote is a source cited in an answer rendered by CitationFootnotes.
+type Footnote struct {
+	Number   int    // Footnote number, in order of first citation
+	SourceID string // ID in the citation marker, such as "doc1"
+	Label    string // Title, source or URL of the cited document; SourceID when it has none
+}
+
+// CitationFootnotes is a StreamTransformer that turns "[docN]" citation markers into
+// numbered Markdown footnotes as the answer streams. Each document gets one footnote number,
+// repeated markers next to each other are collapsed, and a block listing the cited sources is
+// emitted when the answer ends.
+type CitationFootnotes struct {
+	docs      []*ai.Document
+	footnotes []Footnote
+	numbers   map[string]int
+	pending   string
+	last      int // Number of the previous marker, while no text followed it
+}
+
+// NewCitationFootnotes returns a footnote renderer for an answer grounded in docs. Markers are
+// matched to documents by their "ref" or "id" metadata, falling back to their position.
+func NewCitationFootnotes(docs []*ai.Document) *CitationFootnotes {
+	return &CitationFootnotes{docs: docs, numbers: make(map[string]int)}
+}
+
+// Transform implements StreamTransformer.
+func (c *CitationFootnotes) Transform(text string) string {
+	buffer := c.pending + text
+	c.pending = ""
+
+	// Hold back a marker that may be completed by the next chunk
+	if loc := partialCitationMarker.FindStringIndex(buffer); loc != nil {
+		buffer, c.pending = buffer[:loc[0]], buffer[loc[0]:]
+	}
+
+	var out strings.Builder
+	start := 0
+	for _, match := range citationMarker.FindAllStringSubmatchIndex(buffer, -1) {
+		c.writeText(&out, buffer[start:match[0]])
+		number := c.footnoteNumber(buffer[match[2]:match[3]])
+		if number != c.last {
+			fmt.Fprintf(&out, "[^%d]", number)
+			c.last = number
+		}
+		start = match[1]
+	}
+	c.writeText(&out, buffer[start:])
+	return out.String()
+}
+
+// Flush implements StreamTransformer. It returns the text held back and the footnotes block.
+func (c *CitationFootnotes) Flush() string {
+	text := c.pending
+	c.pending = ""
+	if len(c.footnotes) == 0 {
+		return text
+	}
+
+	var out strings.Builder
+	out.WriteString(text)
+	out.WriteString("\n")
+	for _, footnote := range c.footnotes {
+		fmt.Fprintf(&out, "\n[^%d]: %s", footnote.Number, footnote.Label)
+	}
+	return out.String()
+}
+
+// Footnotes returns the sources cited so far, in footnote order.
+func (c *CitationFootnotes) Footnotes() []Footnote {
+	return append([]Footnote(nil), c.footnotes...)
+}
+
+// writeText writes answer text between markers
+func (c *CitationFootnotes) writeText(out *strings.Builder, text string) {
+	if text != "" {
+		out.WriteString(text)
+		c.last = 0
+	}
+}
+
+// footnoteNumber returns the footnote number of a cited document, adding a footnote the first
+// time it is cited
+func (c *CitationFootnotes) footnoteNumber(index string) int {
+	sourceID := "doc" + index
+	if number, ok := c.numbers[sourceID]; ok {
+		return number
+	}
+	number := len(c.footnotes) + 1
+	c.numbers[sourceID] = number
+	c.footnotes = append(c.footnotes, Footnote{Number: number, SourceID: sourceID, Label: c.label(sourceID, index)})
+	return number
+}
+
+// label describes the document cited by a marker
+func (c *CitationFootnotes) label(sourceID, index string) string {
+	var doc *ai.Document
+	for i, d := range c.docs {
+		if citationSourceID(d, i) == sourceID {
+			doc = d
+			break
+		}
+	}
+	if n, err := strconv.Atoi(index); doc == nil && err == nil && n >= 1 && n <= len(c.docs) {
+		doc = c.docs[n-1]
+	}
+	if doc == nil {
+		return sourceID
+	}
+	for _, key := range []string{"title", "source", "url"} {
+		if label, ok := doc.Metadata[key].(string); ok && label != "" {
+			return label
+		}
+	}
+	return sourceID
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestCitationFootnotes(t *testing.T) {
+	docs := []*ai.Document{
+		ai.DocumentFromText("Keys rotate every 90 days.", map[string]any{"ref": "doc1", "title": "Key rotation policy"}),
+		ai.DocumentFromText("Vaults are soft-deleted.", map[string]any{"source": "https://example.com/vaults"}),
+		ai.DocumentFromText("Untitled.", nil),
+	}
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{
+			name:   "numbered by first citation",
+			chunks: []string{"Vaults are soft-deleted [doc2]. Keys rotate [doc1] yearly [doc2]."},
+			want: "Vaults are soft-deleted [^1]. Keys rotate [^2] yearly [^1].\n\n" +
+				"[^1]: https://example.com/vaults\n[^2]: Key rotation policy",
+		},
+		{
+			name:   "markers split across chunks",
+			chunks: []string{"Keys rotate [d", "oc", "1", "] every 90 days [doc", "3]."},
+			want:   "Keys rotate [^1] every 90 days [^2].\n\n[^1]: Key rotation policy\n[^2]: doc3",
+		},
+		{
+			name:   "adjacent duplicates collapse",
+			chunks: []string{"Keys rotate.[doc1][doc1] [doc1]"},
+			want:   "Keys rotate.[^1] [^1]\n\n[^1]: Key rotation policy",
+		},
+		{
+			name:   "unknown documents keep their ID",
+			chunks: []string{"See [doc9]."},
+			want:   "See [^1].\n\n[^1]: doc9",
+		},
+		{
+			name:   "no citations",
+			chunks: []string{"Arrays are [", "0-indexed]"},
+			want:   "Arrays are [0-indexed]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			footnotes := NewCitationFootnotes(docs)
+			var got strings.Builder
+			for _, chunk := range tt.chunks {
+				got.WriteString(footnotes.Transform(chunk))
+			}
+			got.WriteString(footnotes.Flush())
+			if got.String() != tt.want {
+				t.Fatalf("rendered = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCitationFootnotesConfig(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"Keys rotate every 90 days [do", "c1][doc1]."} {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	var streamed strings.Builder
+	resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("How often do keys rotate?")},
+		Docs:     []*ai.Document{ai.DocumentFromText("Keys rotate every 90 days.", map[string]any{"title": "Key rotation policy"})},
+		Config:   map[string]any{"citationFootnotes": true},
+	}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		streamed.WriteString(chunk.Text())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	want := "Keys rotate every 90 days [^1].\n\n[^1]: Key rotation policy"
+	if streamed.String() != want || resp.Text() != want {
+		t.Fatalf("streamed = %q, text = %q, want %q", streamed.String(), resp.Text(), want)
+	}
+	footnotes, _ := resp.Custom.(map[string]any)["footnotes"].([]Footnote)
+	if len(footnotes) != 1 || footnotes[0].SourceID != "doc1" {
+		t.Fatalf("footnotes = %+v", resp.Custom)
+	}
+}
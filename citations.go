@@ -0,0 +1,135 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+// CitedAnswer is the structured response returned when the "citations" config option is enabled.
+type CitedAnswer struct {
+	Answer    string     `json:"answer"`
+	Citations []Citation `json:"citations"`
+}
+
+// Citation links part of an answer to one of the documents supplied with the request.
+type Citation struct {
+	SourceID string `json:"sourceId"` // The document's "ref" or "id" metadata, or its index
+	Quote    string `json:"quote"`    // Supporting quote from the source document
+}
+
+// CitationError is returned when the model cites sources that were not supplied with the request.
+type CitationError struct {
+	UnknownSources []string
+}
+
+// Error implements the error interface.
+func (e *CitationError) Error() string {
+	return fmt.Sprintf("response cites unknown sources: %s", strings.Join(e.UnknownSources, ", "))
+}
+
+// citationsInstruction is prepended as a system message in citation mode
+const citationsInstruction = "Answer using only the provided context documents. " +
+	"For every statement, add a citation whose sourceId is the identifier shown in brackets " +
+	"before the supporting document and whose quote is copied verbatim from that document."
+
+// citationsResponseFormat returns the strict JSON schema response format for cited answers
+func citationsResponseFormat() openai.ChatCompletionNewParamsResponseFormatUnion {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"answer": map[string]any{"type": "string"},
+			"citations": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"sourceId": map[string]any{"type": "string"},
+						"quote":    map[string]any{"type": "string"},
+					},
+					"required":             []string{"sourceId", "quote"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"answer", "citations"},
+		"additionalProperties": false,
+	}
+
+	return openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:   "cited_answer",
+				Strict: openai.Bool(true),
+				Schema: schema,
+			},
+		},
+	}
+}
+
+// citationSourceID returns the identifier Genkit renders for a context document
+func citationSourceID(doc *ai.Document, index int) string {
+	if ref, ok := doc.Metadata["ref"]; ok {
+		return fmt.Sprint(ref)
+	}
+	if id, ok := doc.Metadata["id"]; ok {
+		return fmt.Sprint(id)
+	}
+	return strconv.Itoa(index)
+}
+
+// applyCitations parses a cited answer from the response text, rejects citations to
+// unknown documents and stores the parsed answer in the response's Custom metadata.
+// Responses that request tool calls or did not finish normally carry no answer yet and
+// are left untouched.
+func applyCitations(resp *ai.ModelResponse, docs []*ai.Document) error {
+	if resp.FinishReason != ai.FinishReasonStop || len(resp.ToolRequests()) > 0 {
+		return nil
+	}
+
+	var cited CitedAnswer
+	if err := json.Unmarshal([]byte(resp.Text()), &cited); err != nil {
+		return fmt.Errorf("failed to parse cited answer: %w", err)
+	}
+
+	known := make(map[string]bool, len(docs))
+	for i, doc := range docs {
+		known[citationSourceID(doc, i)] = true
+	}
+
+	var unknown []string
+	for _, citation := range cited.Citations {
+		id := strings.Trim(citation.SourceID, "[] ")
+		if !known[id] {
+			unknown = append(unknown, citation.SourceID)
+		}
+	}
+	if len(unknown) > 0 {
+		return &CitationError{UnknownSources: unknown}
+	}
+
+	resp.Custom = withCustomValue(resp.Custom, "citations", &cited)
+	return nil
+}
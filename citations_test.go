@@ -0,0 +1,137 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestApplyCitations(t *testing.T) {
+	docs := []*ai.Document{
+		ai.DocumentFromText("Keys can be regenerated in the portal.", map[string]any{"id": "kb-7"}),
+		ai.DocumentFromText("Regenerate the secondary key first.", nil),
+	}
+
+	tests := []struct {
+		name        string
+		message     *ai.Message
+		finish      ai.FinishReason
+		text        string
+		wantUnknown []string
+		wantSkipped bool
+	}{
+		{
+			name: "known sources",
+			text: `{"answer":"Regenerate keys in the portal.","citations":[{"sourceId":"kb-7","quote":"Keys can be regenerated"},{"sourceId":"[1]","quote":"secondary key first"}]}`,
+		},
+		{
+			name:        "hallucinated source",
+			text:        `{"answer":"Keys rotate daily.","citations":[{"sourceId":"kb-9","quote":"rotate daily"}]}`,
+			wantUnknown: []string{"kb-9"},
+		},
+		{
+			name:        "tool call response",
+			message:     ai.NewModelMessage(ai.NewToolRequestPart(&ai.ToolRequest{Name: "searchDocs"})),
+			wantSkipped: true,
+		},
+		{
+			name:        "truncated response",
+			finish:      ai.FinishReasonLength,
+			text:        `{"answer":"Regenerate`,
+			wantSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &ai.ModelResponse{Message: tt.message, FinishReason: tt.finish}
+			if resp.Message == nil {
+				resp.Message = ai.NewModelTextMessage(tt.text)
+			}
+			if resp.FinishReason == "" {
+				resp.FinishReason = ai.FinishReasonStop
+			}
+			err := applyCitations(resp, docs)
+
+			if tt.wantSkipped {
+				if err != nil || resp.Custom != nil {
+					t.Fatalf("applyCitations() = %v, Custom = %v, want response left untouched", err, resp.Custom)
+				}
+				return
+			}
+
+			if tt.wantUnknown != nil {
+				var citationErr *CitationError
+				if !errors.As(err, &citationErr) {
+					t.Fatalf("applyCitations() error = %v, want *CitationError", err)
+				}
+				if strings.Join(citationErr.UnknownSources, ",") != strings.Join(tt.wantUnknown, ",") {
+					t.Fatalf("UnknownSources = %v, want %v", citationErr.UnknownSources, tt.wantUnknown)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyCitations() error = %v", err)
+			}
+			cited, ok := resp.Custom.(map[string]any)["citations"].(*CitedAnswer)
+			if !ok || len(cited.Citations) != 2 {
+				t.Fatalf("Custom citations = %#v", resp.Custom)
+			}
+		})
+	}
+}
+
+func TestBuildChatCompletionParamsCitationMode(t *testing.T) {
+	plugin := &AzureAIFoundry{}
+	params := plugin.buildChatCompletionParams(&ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("How do I rotate keys?")},
+		Config:   map[string]interface{}{"citations": true},
+	}, "gpt-4o")
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Messages []struct {
+			Role string `json:"role"`
+		} `json:"messages"`
+		ResponseFormat struct {
+			Type       string `json:"type"`
+			JSONSchema struct {
+				Name   string `json:"name"`
+				Strict bool   `json:"strict"`
+			} `json:"json_schema"`
+		} `json:"response_format"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ResponseFormat.Type != "json_schema" || !got.ResponseFormat.JSONSchema.Strict {
+		t.Fatalf("response_format = %+v, want strict json_schema", got.ResponseFormat)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" {
+		t.Fatalf("messages = %+v, want citation system message first", got.Messages)
+	}
+}
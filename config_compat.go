@@ -0,0 +1,168 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// configAliases maps config keys used by other Genkit provider plugins (Gemini, Anthropic,
+// OpenAI snake_case) to the keys understood by this plugin
+var configAliases = map[string]string{
+	"max_tokens":            "maxOutputTokens",
+	"maxTokens":             "maxOutputTokens",
+	"max_output_tokens":     "maxOutputTokens",
+	"max_completion_tokens": "maxOutputTokens",
+	"top_p":                 "topP",
+	"stop_sequences":        "stopSequences",
+	"stop":                  "stopSequences",
+	"reasoning_effort":      "reasoningEffort",
+	"tool_choice":           "toolChoice",
+	"response_format":       "responseFormat",
+	"json_schema":           "jsonSchema",
+	"top_logprobs":          "topLogprobs",
+	"service_tier":          "serviceTier",
+}
+
+// unsupportedConfigKeys lists config options of other providers that Azure OpenAI has no
+// equivalent for. They are dropped with a warning instead of failing the request.
+var unsupportedConfigKeys = map[string]bool{
+	"topK":               true,
+	"top_k":              true,
+	"safetySettings":     true,
+	"candidateCount":     true,
+	"thinkingConfig":     true,
+	"thinking":           true,
+	"responseModalities": true,
+	"version":            true,
+	"apiKey":             true,
+}
+
+// normalizeConfig converts a request config, either a map or a config struct such as
+// ai.GenerationCommonConfig, into a map keyed by this plugin's option names. It returns
+// the sorted keys of options that were dropped because Azure does not support them.
+func normalizeConfig(config any) (map[string]interface{}, []string) {
+	var raw map[string]interface{}
+	switch c := config.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		raw = c
+	default:
+		data, err := json.Marshal(c)
+		if err != nil || json.Unmarshal(data, &raw) != nil {
+			return map[string]interface{}{}, nil
+		}
+	}
+
+	normalized := make(map[string]interface{}, len(raw))
+	var ignored []string
+	for key, val := range raw {
+		if unsupportedConfigKeys[key] {
+			if !isZeroConfigValue(val) {
+				ignored = append(ignored, key)
+			}
+			continue
+		}
+		if alias, ok := configAliases[key]; ok {
+			if _, exists := raw[alias]; exists {
+				continue
+			}
+			key = alias
+		}
+		normalized[key] = val
+	}
+	sort.Strings(ignored)
+
+	return normalized, ignored
+}
+
+// isZeroConfigValue reports whether an ignored option was left at its zero value, as
+// struct configs serialize unset fields that way
+func isZeroConfigValue(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	}
+	return false
+}
+
+// configInt reads an integer config value, accepting any numeric type
+func configInt(val any) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), true
+		}
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// configFloat reads a floating point config value, accepting any numeric type
+func configFloat(val any) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// configStrings reads a list of strings, accepting a single string as a one-element list
+func configStrings(val any) []string {
+	switch v := val.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      any
+		wantMax     int64
+		wantTemp    float64
+		wantTopP    float64
+		wantStop    []string
+		wantIgnored []string
+	}{
+		{
+			name:     "plugin config",
+			config:   map[string]interface{}{"maxOutputTokens": 100, "temperature": 0.5, "topP": 0.9},
+			wantMax:  100,
+			wantTemp: 0.5,
+			wantTopP: 0.9,
+		},
+		{
+			name:     "genkit common config struct",
+			config:   &ai.GenerationCommonConfig{MaxOutputTokens: 200, Temperature: 0.2, StopSequences: []string{"END"}},
+			wantMax:  200,
+			wantTemp: 0.2,
+			wantStop: []string{"END"},
+		},
+		{
+			name: "gemini style config",
+			config: map[string]interface{}{
+				"maxOutputTokens": 300,
+				"topK":            40,
+				"safetySettings":  []interface{}{map[string]interface{}{"category": "HARM_CATEGORY_HATE_SPEECH"}},
+				"temperature":     1,
+			},
+			wantMax:     300,
+			wantTemp:    1,
+			wantIgnored: []string{"safetySettings", "topK"},
+		},
+		{
+			name:        "anthropic style config",
+			config:      map[string]interface{}{"max_tokens": 400, "top_p": 0.8, "stop_sequences": []interface{}{"\n\nHuman:"}, "top_k": 5},
+			wantMax:     400,
+			wantTopP:    0.8,
+			wantStop:    []string{"\n\nHuman:"},
+			wantIgnored: []string{"top_k"},
+		},
+	}
+
+	plugin := &AzureAIFoundry{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ignored := normalizeConfig(tt.config)
+			if !reflect.DeepEqual(ignored, tt.wantIgnored) {
+				t.Fatalf("ignored = %v, want %v", ignored, tt.wantIgnored)
+			}
+
+			config := plugin.extractConfigFromRequest(&ai.ModelRequest{Config: tt.config})
+			if config.maxTokens == nil || *config.maxTokens != tt.wantMax {
+				t.Fatalf("maxTokens = %v, want %d", config.maxTokens, tt.wantMax)
+			}
+			if tt.wantTemp != 0 && (config.temperature == nil || *config.temperature != tt.wantTemp) {
+				t.Fatalf("temperature = %v, want %v", config.temperature, tt.wantTemp)
+			}
+			if tt.wantTopP != 0 && (config.topP == nil || *config.topP != tt.wantTopP) {
+				t.Fatalf("topP = %v, want %v", config.topP, tt.wantTopP)
+			}
+			if !reflect.DeepEqual(config.stop, tt.wantStop) {
+				t.Fatalf("stop = %q, want %q", config.stop, tt.wantStop)
+			}
+		})
+	}
+}
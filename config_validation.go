@@ -0,0 +1,206 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// configKind describes the value type a config option accepts, as shown in errors
+type configKind string
+
+const (
+	configKindInt     configKind = "an integer"
+	configKindNumber  configKind = "a number"
+	configKindString  configKind = "a string"
+	configKindBool    configKind = "a boolean"
+	configKindStrings configKind = "a string or list of strings"
+	configKindObject  configKind = "an object"
+	configKindSchema  configKind = "a JSON schema object or Go struct"
+)
+
+// configOption describes an accepted config option, with its allowed values if they are fixed
+type configOption struct {
+	kind   configKind
+	values []string
+}
+
+// systemPromptOptions are accepted by every model because system prompts are applied before
+// the request is routed
+var systemPromptOptions = map[string]configOption{
+	"skipSystemPrompt": {kind: configKindBool},
+	"systemPromptVars": {kind: configKindObject},
+}
+
+// configOptions lists the config options each operation understands, after aliases are
+// resolved by normalizeConfig
+var configOptions = map[string]map[string]configOption{
+	OperationChat: {
+		"maxOutputTokens":     {kind: configKindInt},
+		"temperature":         {kind: configKindNumber},
+		"topP":                {kind: configKindNumber},
+		"stopSequences":       {kind: configKindStrings},
+		"reasoningEffort":     {kind: configKindString, values: []string{"none", "minimal", "low", "medium", "high", "xhigh"}},
+		"toolChoice":          {kind: configKindString, values: []string{"auto", "required", "none"}},
+		"citations":           {kind: configKindBool},
+		"citationFootnotes":   {kind: configKindBool},
+		"responseFormat":      {kind: configKindString, values: []string{ResponseFormatText, ResponseFormatJSONObject, ResponseFormatJSONSchema}},
+		"jsonSchema":          {kind: configKindSchema},
+		"stripReasoning":      {kind: configKindBool},
+		"seed":                {kind: configKindInt},
+		"logprobs":            {kind: configKindBool},
+		"topLogprobs":         {kind: configKindInt},
+		"streamUsage":         {kind: configKindBool},
+		"streamUsageInterval": {kind: configKindInt},
+		"user":                {kind: configKindString},
+		"serviceTier":         {kind: configKindString, values: []string{"auto", "default", "flex", "scale", "priority"}},
+		"promptCompression":   {kind: configKindNumber},
+		"responseLanguage":    {kind: configKindString},
+		"guardrail":           {kind: configKindString},
+	},
+	OperationImage: {
+		"n":               {kind: configKindInt},
+		"size":            {kind: configKindString},
+		"quality":         {kind: configKindString, values: []string{"standard", "hd", "low", "medium", "high", "auto"}},
+		"style":           {kind: configKindString, values: []string{"vivid", "natural"}},
+		"response_format": {kind: configKindString, values: []string{"url", "b64_json"}},
+		"user":            {kind: configKindString},
+	},
+	OperationSpeech: {
+		"voice":           {kind: configKindString},
+		"response_format": {kind: configKindString, values: []string{"mp3", "opus", "aac", "flac", "wav", "pcm"}},
+		"speed":           {kind: configKindNumber},
+		"language":        {kind: configKindString},
+	},
+	OperationTranscription: {
+		"language":        {kind: configKindString},
+		"prompt":          {kind: configKindString},
+		"response_format": {kind: configKindString, values: []string{"json", "text", "srt", "verbose_json", "vtt", "diarized_json"}},
+		"temperature":     {kind: configKindNumber},
+		"trimSilence":     {kind: configKindBool},
+	},
+}
+
+// ConfigError is returned in strict config mode when a request has unknown, unsupported or
+// malformed config options.
+type ConfigError struct {
+	Model     string
+	Operation string
+	Problems  []string // One entry per rejected option
+	Accepted  []string // Options accepted for the operation
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config for %s model %q: %s (accepted options: %s)",
+		e.Operation, e.Model, strings.Join(e.Problems, "; "), strings.Join(e.Accepted, ", "))
+}
+
+// validateConfig checks a request config against the options accepted for an operation
+func validateConfig(modelName, operation string, config any) error {
+	options := configOptions[operation]
+	normalized := map[string]interface{}{}
+	var problems []string
+
+	if operation == OperationChat {
+		var ignored []string
+		normalized, ignored = normalizeConfig(config)
+		for _, key := range ignored {
+			problems = append(problems, fmt.Sprintf("%q is not supported by Azure OpenAI", key))
+		}
+	} else if config != nil {
+		// Other operations only read map configs
+		configMap, ok := config.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("config must be a map, got %T", config))
+		}
+		normalized = configMap
+	}
+
+	keys := make([]string, 0, len(normalized))
+	for key := range normalized {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		option, ok := options[key]
+		if !ok {
+			option, ok = systemPromptOptions[key]
+		}
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown option %q", key))
+			continue
+		}
+		if problem := option.check(normalized[key]); problem != "" {
+			problems = append(problems, fmt.Sprintf("%q %s", key, problem))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	accepted := make([]string, 0, len(options)+len(systemPromptOptions))
+	for key := range options {
+		accepted = append(accepted, key)
+	}
+	for key := range systemPromptOptions {
+		accepted = append(accepted, key)
+	}
+	sort.Strings(accepted)
+	return &ConfigError{Model: modelName, Operation: operation, Problems: problems, Accepted: accepted}
+}
+
+// check returns why a value is not valid for the option, or "" if it is
+func (o configOption) check(val any) string {
+	if val == nil {
+		return ""
+	}
+	valid := false
+	switch o.kind {
+	case configKindInt:
+		_, valid = configInt(val)
+	case configKindNumber:
+		_, valid = configFloat(val)
+	case configKindString:
+		_, valid = val.(string)
+	case configKindBool:
+		_, valid = val.(bool)
+	case configKindStrings:
+		valid = configStrings(val) != nil
+	case configKindObject:
+		_, valid = val.(map[string]interface{})
+	case configKindSchema:
+		_, valid = val.(map[string]interface{})
+		valid = valid || isStructValue(val)
+	}
+	if !valid {
+		return fmt.Sprintf("must be %s, got %T", o.kind, val)
+	}
+
+	if len(o.values) > 0 {
+		for _, allowed := range o.values {
+			if val == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(o.values, ", "), val)
+	}
+	return ""
+}
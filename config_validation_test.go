@@ -0,0 +1,117 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		config    any
+		want      []string
+	}{
+		{"nil config", OperationChat, nil, nil},
+		{"valid chat config", OperationChat, map[string]any{"temperature": 0.2, "max_tokens": 100, "stop": "END", "seed": 7}, nil},
+		{"struct config", OperationChat, &ai.GenerationCommonConfig{Temperature: 0.5, MaxOutputTokens: 10}, nil},
+		{"system prompt options", OperationSpeech, map[string]any{"voice": "nova", "skipSystemPrompt": true}, nil},
+		{
+			name:      "typo",
+			operation: OperationChat,
+			config:    map[string]any{"temprature": 0.2},
+			want:      []string{`unknown option "temprature"`},
+		},
+		{
+			name:      "unsupported option",
+			operation: OperationChat,
+			config:    map[string]any{"topK": 40},
+			want:      []string{`"topK" is not supported by Azure OpenAI`},
+		},
+		{
+			name:      "malformed values",
+			operation: OperationChat,
+			config:    map[string]any{"maxOutputTokens": 1.5, "temperature": "hot", "reasoningEffort": "extreme"},
+			want: []string{
+				`"maxOutputTokens" must be an integer, got float64`,
+				`"reasoningEffort" must be one of none, minimal, low, medium, high, xhigh, got "extreme"`,
+				`"temperature" must be a number, got string`,
+			},
+		},
+		{
+			name:      "chat option on image model",
+			operation: OperationImage,
+			config:    map[string]any{"temperature": 0.2, "n": 2.0},
+			want:      []string{`unknown option "temperature"`},
+		},
+		{
+			name:      "struct config on speech model",
+			operation: OperationSpeech,
+			config:    &ai.GenerationCommonConfig{},
+			want:      []string{"config must be a map, got *ai.GenerationCommonConfig"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig("model", tt.operation, tt.config)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("validateConfig() error = %v", err)
+				}
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("validateConfig() error = %v, want *ConfigError", err)
+			}
+			if !reflect.DeepEqual(configErr.Problems, tt.want) {
+				t.Fatalf("Problems = %q, want %q", configErr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictConfig(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	request := &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+		Config:   map[string]any{"temprature": 0.2},
+	}
+
+	if _, err := plugin.generateText(context.Background(), "gpt-4o", request, nil); err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+
+	plugin.StrictConfig = true
+	_, err := plugin.generateText(context.Background(), "gpt-4o", request, nil)
+	if err == nil || !strings.Contains(err.Error(), "accepted options: citationFootnotes, citations, guardrail, jsonSchema, logprobs, maxOutputTokens") {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("got %d requests, want the rejected one not sent", len(bodies))
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// ContextPackOptions configures how retrieved documents are packed into a prompt context.
+type ContextPackOptions struct {
+	MaxTokens  int    // Token budget for the packed context. 0 means no budget
+	ScoreKey   string // Metadata key holding the relevance score. Defaults to "score", falling back to "rrfScore"
+	SourceKey  string // Metadata key identifying the document source. Defaults to "source"
+	Interleave bool   // Round-robin documents across sources instead of strict score order
+
+	Tokenizer Tokenizer // Counts tokens against MaxTokens. Defaults to ApproximateTokenizer
+}
+
+// PackedContext is the result of packing documents into a token budget.
+type PackedContext struct {
+	Documents []*ai.Document // Selected documents, each tagged with a "ref" metadata value
+	Text      string         // Rendered context with "[docN]" citation markers
+	Tokens    int            // Tokens used by Text, as counted by the tokenizer
+	Dropped   int            // Number of documents left out (duplicates or over budget)
+}
+
+// PackContext deduplicates documents, orders them by score, optionally interleaves sources and
+// greedily fills the token budget. Every selected document gets a stable citation ID ("doc1",
+// "doc2", ...) stored as its "ref" metadata and shown in the rendered text. Genkit renders
+// context documents by "ref" as well, so citation mode and groundedness checks see the same IDs.
+func PackContext(docs []*ai.Document, opts ContextPackOptions) *PackedContext {
+	if opts.SourceKey == "" {
+		opts.SourceKey = "source"
+	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = ApproximateTokenizer
+	}
+
+	unique := make([]*ai.Document, 0, len(docs))
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		if doc == nil || documentText(doc) == "" {
+			continue
+		}
+		key := documentKey(doc)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, doc)
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		return documentScore(unique[i], opts.ScoreKey) > documentScore(unique[j], opts.ScoreKey)
+	})
+	if opts.Interleave {
+		unique = interleaveBySource(unique, opts.SourceKey)
+	}
+
+	packed := &PackedContext{Dropped: len(docs) - len(unique)}
+	var text strings.Builder
+	for _, doc := range unique {
+		citationID := fmt.Sprintf("doc%d", len(packed.Documents)+1)
+		entry := fmt.Sprintf("[%s] %s\n\n", citationID, documentText(doc))
+		tokens := opts.Tokenizer.CountTokens(entry)
+		if opts.MaxTokens > 0 && packed.Tokens+tokens > opts.MaxTokens {
+			packed.Dropped++
+			continue
+		}
+
+		metadata := make(map[string]any, len(doc.Metadata)+1)
+		for key, val := range doc.Metadata {
+			metadata[key] = val
+		}
+		metadata["ref"] = citationID
+
+		packed.Documents = append(packed.Documents, &ai.Document{Content: doc.Content, Metadata: metadata})
+		packed.Tokens += tokens
+		text.WriteString(entry)
+	}
+	packed.Text = strings.TrimSpace(text.String())
+
+	return packed
+}
+
+// documentScore returns the numeric relevance score stored in document metadata
+func documentScore(doc *ai.Document, key string) float64 {
+	keys := []string{key}
+	if key == "" {
+		keys = []string{"score", "rrfScore"}
+	}
+	for _, k := range keys {
+		switch v := doc.Metadata[k].(type) {
+		case float64:
+			return v
+		case float32:
+			return float64(v)
+		case int:
+			return float64(v)
+		}
+	}
+	return 0
+}
+
+// interleaveBySource reorders score-sorted documents round-robin across their sources,
+// keeping score order within each source
+func interleaveBySource(docs []*ai.Document, sourceKey string) []*ai.Document {
+	var sources []string
+	groups := make(map[string][]*ai.Document)
+	for _, doc := range docs {
+		source := fmt.Sprint(doc.Metadata[sourceKey])
+		if _, ok := groups[source]; !ok {
+			sources = append(sources, source)
+		}
+		groups[source] = append(groups[source], doc)
+	}
+
+	result := make([]*ai.Document, 0, len(docs))
+	for len(result) < len(docs) {
+		for _, source := range sources {
+			if len(groups[source]) > 0 {
+				result = append(result, groups[source][0])
+				groups[source] = groups[source][1:]
+			}
+		}
+	}
+	return result
+}
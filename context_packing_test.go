@@ -0,0 +1,89 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestPackContext(t *testing.T) {
+	docs := []*ai.Document{
+		ai.DocumentFromText("wiki low", map[string]any{"source": "wiki", "score": 0.2}),
+		ai.DocumentFromText("wiki high", map[string]any{"source": "wiki", "score": 0.9}),
+		ai.DocumentFromText("wiki mid", map[string]any{"source": "wiki", "score": 0.8}),
+		ai.DocumentFromText("faq", map[string]any{"source": "faq", "score": 0.5}),
+		ai.DocumentFromText("wiki high", map[string]any{"source": "wiki", "score": 0.9}),
+	}
+
+	tests := []struct {
+		name        string
+		opts        ContextPackOptions
+		wantTexts   []string
+		wantDropped int
+	}{
+		{
+			name:        "orders by score and dedupes",
+			opts:        ContextPackOptions{},
+			wantTexts:   []string{"wiki high", "wiki mid", "faq", "wiki low"},
+			wantDropped: 1,
+		},
+		{
+			name:        "interleaves sources",
+			opts:        ContextPackOptions{Interleave: true},
+			wantTexts:   []string{"wiki high", "faq", "wiki mid", "wiki low"},
+			wantDropped: 1,
+		},
+		{
+			name:        "respects token budget",
+			opts:        ContextPackOptions{MaxTokens: 10},
+			wantTexts:   []string{"wiki high", "wiki mid"},
+			wantDropped: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed := PackContext(docs, tt.opts)
+
+			var texts []string
+			for i, doc := range packed.Documents {
+				texts = append(texts, documentText(doc))
+				wantID := "doc" + string(rune('1'+i))
+				if doc.Metadata["ref"] != wantID || citationSourceID(doc, i) != wantID {
+					t.Fatalf("ref = %v, want %s", doc.Metadata["ref"], wantID)
+				}
+				if !strings.Contains(packed.Text, "["+wantID+"] "+texts[i]) {
+					t.Fatalf("packed text %q missing citation for %q", packed.Text, texts[i])
+				}
+			}
+			if !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Fatalf("packed documents = %q, want %q", texts, tt.wantTexts)
+			}
+			if packed.Dropped != tt.wantDropped {
+				t.Fatalf("Dropped = %d, want %d", packed.Dropped, tt.wantDropped)
+			}
+			if tt.opts.MaxTokens > 0 && packed.Tokens > tt.opts.MaxTokens {
+				t.Fatalf("Tokens = %d exceeds budget %d", packed.Tokens, tt.opts.MaxTokens)
+			}
+		})
+	}
+}
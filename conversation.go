@@ -0,0 +1,258 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// Message metadata keys that link the messages of a Conversation into a tree of branches
+const (
+	MessageIDKey = "messageId"
+	ParentIDKey  = "parentId"
+)
+
+// Conversation is a chat history with alternative branches, such as regenerated answers and
+// edited user messages. Each message carries its ID and the ID of its parent as metadata, so
+// the branches survive MarshalOpenAIMessages and UnmarshalOpenAIMessages.
+type Conversation struct {
+	mu       sync.Mutex
+	messages []*ai.Message // Messages of all branches, in the order they were added
+	byID     map[string]*ai.Message
+	head     string // ID of the last message of the current branch
+	nextID   int
+}
+
+// NewConversation returns a conversation holding messages, such as those returned by Export.
+// Messages without branch metadata follow the message before them. The last message is the
+// end of the current branch.
+func NewConversation(messages ...*ai.Message) *Conversation {
+	c := &Conversation{byID: make(map[string]*ai.Message)}
+	for _, msg := range messages {
+		id, _ := msg.Metadata[MessageIDKey].(string)
+		parent, ok := msg.Metadata[ParentIDKey].(string)
+		if !ok {
+			parent = c.head
+		}
+		c.add(msg, id, parent)
+	}
+	return c
+}
+
+// Messages returns the messages of the current branch, from the first to the last.
+func (c *Conversation) Messages() []*ai.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.path(c.head)
+}
+
+// Export returns the messages of all branches with their branch metadata.
+func (c *Conversation) Export() []*ai.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*ai.Message(nil), c.messages...)
+}
+
+// Append adds messages to the end of the current branch and returns them with their IDs set.
+func (c *Conversation) Append(messages ...*ai.Message) []*ai.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	added := make([]*ai.Message, 0, len(messages))
+	for _, msg := range messages {
+		added = append(added, c.add(msg, "", c.head))
+	}
+	return added
+}
+
+// Edit adds msg as an alternative to the message with the given ID, such as an edited user
+// message, and makes it the current branch.
+func (c *Conversation) Edit(messageID string, msg *ai.Message) (*ai.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	original, ok := c.byID[messageID]
+	if !ok {
+		return nil, fmt.Errorf("conversation has no message %q", messageID)
+	}
+	parent, _ := original.Metadata[ParentIDKey].(string)
+	return c.add(msg, "", parent), nil
+}
+
+// Alternatives returns the message with the given ID and its alternatives, which share its
+// parent, in the order they were added.
+func (c *Conversation) Alternatives(messageID string) []*ai.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msg, ok := c.byID[messageID]
+	if !ok {
+		return nil
+	}
+	parent := msg.Metadata[ParentIDKey]
+	var alternatives []*ai.Message
+	for _, m := range c.messages {
+		if m.Metadata[ParentIDKey] == parent {
+			alternatives = append(alternatives, m)
+		}
+	}
+	return alternatives
+}
+
+// Checkout makes the branch through the message with the given ID current. The branch
+// continues to the most recently added message below it.
+func (c *Conversation) Checkout(messageID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.byID[messageID]; !ok {
+		return fmt.Errorf("conversation has no message %q", messageID)
+	}
+	c.head = messageID
+	for {
+		var next string
+		for _, m := range c.messages {
+			if m.Metadata[ParentIDKey] == c.head {
+				next = m.Metadata[MessageIDKey].(string)
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		c.head = next
+	}
+}
+
+// Generate generates a response to the current branch and appends the new messages to it,
+// including tool requests and responses. Options such as ai.WithModel and ai.WithConfig
+// apply to the request.
+func (c *Conversation) Generate(ctx context.Context, g *genkit.Genkit, opts ...ai.GenerateOption) (*ai.ModelResponse, error) {
+	c.mu.Lock()
+	parent := c.head
+	c.mu.Unlock()
+	return c.generate(ctx, g, parent, opts)
+}
+
+// Regenerate generates a new answer to the last user message of the current branch, such as
+// with a higher temperature or another deployment set through opts. The answer becomes an
+// alternative to the previous one and the current branch.
+func (c *Conversation) Regenerate(ctx context.Context, g *genkit.Genkit, opts ...ai.GenerateOption) (*ai.ModelResponse, error) {
+	c.mu.Lock()
+	var parent string
+	for id := c.head; id != ""; {
+		msg, ok := c.byID[id]
+		if !ok {
+			break
+		}
+		if msg.Role == ai.RoleUser {
+			parent = id
+			break
+		}
+		id, _ = msg.Metadata[ParentIDKey].(string)
+	}
+	c.mu.Unlock()
+
+	if parent == "" {
+		return nil, fmt.Errorf("conversation has no user message to regenerate an answer to")
+	}
+	return c.generate(ctx, g, parent, opts)
+}
+
+// generate generates a response to the branch ending at parent and adds the new messages
+// below it as the current branch
+func (c *Conversation) generate(ctx context.Context, g *genkit.Genkit, parent string, opts []ai.GenerateOption) (*ai.ModelResponse, error) {
+	c.mu.Lock()
+	history := c.path(parent)
+	c.mu.Unlock()
+
+	resp, err := genkit.Generate(ctx, g, append([]ai.GenerateOption{ai.WithMessages(history...)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The request of the final response includes the tool turns Genkit ran after the history
+	var added []*ai.Message
+	if resp.Request != nil && len(history) > 0 {
+		last := history[len(history)-1]
+		for i := len(history) - 1; i < len(resp.Request.Messages); i++ {
+			if msg := resp.Request.Messages[i]; msg.Role == last.Role && reflect.DeepEqual(msg.Content, last.Content) {
+				added = append(added, resp.Request.Messages[i+1:]...)
+				break
+			}
+		}
+	}
+	if resp.Message != nil {
+		added = append(added, resp.Message)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, msg := range added {
+		parent = c.add(msg, "", parent).Metadata[MessageIDKey].(string)
+	}
+	if len(added) > 0 {
+		resp.Message = c.byID[parent]
+	}
+	return resp, nil
+}
+
+// add stores a copy of msg below parent and makes it the end of the current branch. An ID is
+// assigned when id is empty or already taken.
+func (c *Conversation) add(msg *ai.Message, id, parent string) *ai.Message {
+	for id == "" || c.byID[id] != nil {
+		c.nextID++
+		id = fmt.Sprintf("msg-%d", c.nextID)
+	}
+
+	stored := *msg
+	stored.Metadata = make(map[string]any, len(msg.Metadata)+2)
+	for key, val := range msg.Metadata {
+		stored.Metadata[key] = val
+	}
+	stored.Metadata[MessageIDKey] = id
+	if parent != "" {
+		stored.Metadata[ParentIDKey] = parent
+	} else {
+		delete(stored.Metadata, ParentIDKey)
+	}
+
+	c.messages = append(c.messages, &stored)
+	c.byID[id] = &stored
+	c.head = id
+	return &stored
+}
+
+// path returns the messages from the first one to the message with the given ID
+func (c *Conversation) path(id string) []*ai.Message {
+	var path []*ai.Message
+	for id != "" {
+		msg, ok := c.byID[id]
+		if !ok {
+			break
+		}
+		path = append([]*ai.Message{msg}, path...)
+		id, _ = msg.Metadata[ParentIDKey].(string)
+	}
+	return path
+}
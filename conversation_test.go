@@ -0,0 +1,158 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// texts returns the text of each message
+func texts(messages []*ai.Message) []string {
+	result := make([]string, len(messages))
+	for i, msg := range messages {
+		result[i] = msg.Text()
+	}
+	return result
+}
+
+func TestConversationRegenerate(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",`+
+			`"message":{"role":"assistant","content":"answer %d from %s"}}]}`, len(bodies), body["model"])
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4.1", Type: "chat"}, nil)
+
+	conv := NewConversation(ai.NewSystemTextMessage("Be brief."))
+	question := conv.Append(ai.NewUserTextMessage("Hi"))[0]
+	first, err := conv.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	second, err := conv.Regenerate(ctx, g, ai.WithModel(Model(g, "gpt-4.1")), ai.WithConfig(map[string]any{"temperature": 1.2}))
+	if err != nil {
+		t.Fatalf("Regenerate() error = %v", err)
+	}
+
+	if got := texts(conv.Messages()); fmt.Sprint(got) != "[Be brief. Hi answer 2 from gpt-4.1]" {
+		t.Fatalf("Messages() = %q", got)
+	}
+	if len(bodies[1]["messages"].([]any)) != 2 || bodies[1]["temperature"] != 1.2 {
+		t.Fatalf("regenerate request = %v, want the history without the previous answer", bodies[1])
+	}
+	firstID := first.Message.Metadata[MessageIDKey].(string)
+	if second.Message.Metadata[ParentIDKey] != question.Metadata[MessageIDKey] {
+		t.Fatalf("regenerated answer metadata = %v", second.Message.Metadata)
+	}
+	if got := texts(conv.Alternatives(firstID)); fmt.Sprint(got) != "[answer 1 from gpt-4o answer 2 from gpt-4.1]" {
+		t.Fatalf("Alternatives() = %q", got)
+	}
+
+	// Branches survive an export through the OpenAI message format
+	data, err := MarshalOpenAIMessages(conv.Export())
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := UnmarshalOpenAIMessages(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewConversation(messages...)
+	if err := restored.Checkout(firstID); err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if got := texts(restored.Messages()); fmt.Sprint(got) != "[Be brief. Hi answer 1 from gpt-4o]" {
+		t.Fatalf("restored Messages() = %q", got)
+	}
+
+	edited, err := restored.Edit(question.Metadata[MessageIDKey].(string), ai.NewUserTextMessage("Hello"))
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if got := texts(restored.Messages()); fmt.Sprint(got) != "[Be brief. Hello]" {
+		t.Fatalf("edited Messages() = %q", got)
+	}
+	if got := restored.Alternatives(edited.Metadata[MessageIDKey].(string)); len(got) != 2 {
+		t.Fatalf("Alternatives() = %q", texts(got))
+	}
+	if err := restored.Checkout("missing"); err == nil {
+		t.Fatal("Checkout() accepted an unknown message")
+	}
+}
+
+func TestConversationRegenerateWithoutUserMessage(t *testing.T) {
+	conv := NewConversation(ai.NewSystemTextMessage("Be brief."))
+	if _, err := conv.Regenerate(context.Background(), nil); err == nil {
+		t.Fatal("Regenerate() error = nil, want an error")
+	}
+}
+
+func TestConversationKeepsToolTurns(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = io.WriteString(w, `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",`+
+				`"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"getWeather","arguments":"{}"}}]}}]}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	weather := genkit.DefineTool(g, "getWeather", "Returns the weather", func(ctx *ai.ToolContext, input struct{}) (string, error) {
+		return "sunny", nil
+	})
+
+	conv := NewConversation(ai.NewUserTextMessage("Weather?"))
+	if _, err := conv.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithTools(weather)); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var roles []ai.Role
+	for _, msg := range conv.Messages() {
+		roles = append(roles, msg.Role)
+	}
+	if fmt.Sprint(roles) != "[user model tool model]" {
+		t.Fatalf("roles = %v", roles)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestSortDataCategories(t *testing.T) {
+	got := sortDataCategories([]string{"pii", "financial", "secrets", "pii", "", "biometric"})
+	if want := []string{"secrets", "pii", "biometric", "financial"}; !slices.Equal(got, want) {
+		t.Fatalf("sortDataCategories() = %v, want %v", got, want)
+	}
+}
+
+func TestDataClassification(t *testing.T) {
+	tests := []struct {
+		name           string
+		categories     []string
+		classifyErr    error
+		classification string
+		wantClassified bool
+		wantCategories []string
+		wantErr        string
+	}{
+		{name: "nothing found", wantClassified: true, wantCategories: []string{}},
+		{name: "allowed category", categories: []string{"pii", "phi"}, wantClassified: true, wantCategories: []string{"phi", "pii"}},
+		{name: "category not allowed in region", categories: []string{"pii", "secrets"}, wantClassified: true, wantErr: `data classified "secrets" may not be sent to westeurope`},
+		{name: "explicit classification kept", categories: []string{"secrets"}, classification: "pii"},
+		{name: "classifier failure", classifyErr: errors.New("unavailable"), wantClassified: true, wantErr: "data classification failed: unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var classified []string
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+				a.Region = "westeurope"
+				a.ResidencyPolicy = ResidencyPolicy{"pii": {"westeurope"}, "phi": {"westeurope"}, "secrets": {"eastus"}}
+				a.Classifier = DataClassifierFunc(func(ctx context.Context, text string) ([]string, error) {
+					classified = append(classified, text)
+					return tt.categories, tt.classifyErr
+				})
+			})
+			ctx := context.Background()
+			g := genkit.Init(ctx)
+			model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+			if tt.classification != "" {
+				ctx = WithDataClassification(ctx, tt.classification)
+			}
+
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model),
+				ai.WithSystem("You are a clinic assistant."),
+				ai.WithPrompt("My patient John Smith has diabetes."))
+			if tt.wantClassified != (len(classified) == 1) {
+				t.Fatalf("classifier called with %q, want called %v", classified, tt.wantClassified)
+			}
+			if tt.wantClassified && (strings.Contains(classified[0], "clinic") || !strings.Contains(classified[0], "John Smith")) {
+				t.Fatalf("classified text = %q, want the user message only", classified[0])
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || len(bodies) != 0 {
+					t.Fatalf("Generate() error = %v, requests = %d, want %q before sending", err, len(bodies), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			custom, _ := resp.Custom.(map[string]any)
+			if got, ok := custom["dataCategories"]; ok != (tt.wantCategories != nil) || (ok && !reflect.DeepEqual(got, tt.wantCategories)) {
+				t.Fatalf("Custom[dataCategories] = %v, want %v", got, tt.wantCategories)
+			}
+		})
+	}
+}
+
+func TestModelClassifier(t *testing.T) {
+	var prompts []string
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		prompts = append(prompts, string(data))
+		response := chatCompletionJSON
+		if strings.Contains(string(data), "data protection officer") {
+			content, _ := json.Marshal(`{"categories":["PHI","weather"]}`)
+			response = strings.Replace(chatCompletionJSON, `"ok"`, string(content), 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, response)
+	})
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	classifierModel := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o-mini", Type: "chat"}, nil)
+	model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	// The classifier's own request to the plugin is not classified again
+	plugin.Classifier = NewModelClassifier(g, classifierModel)
+
+	resp, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("Summarize the discharge notes of patient 4711."))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	custom, _ := resp.Custom.(map[string]any)
+	if got := custom["dataCategories"]; !reflect.DeepEqual(got, []string{"phi"}) {
+		t.Fatalf("Custom[dataCategories] = %v, want [phi]", got)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "patient 4711") {
+		t.Fatalf("requests = %q, want the classification then the generation", prompts)
+	}
+}
+
+func TestLanguageClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Kind          string `json:"kind"`
+			AnalysisInput struct {
+				Documents []struct {
+					Text string `json:"text"`
+				} `json:"documents"`
+			} `json:"analysisInput"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/language/:analyze-text" || r.Header.Get("Ocp-Apim-Subscription-Key") != "key" || body.Kind != "PiiEntityRecognition" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var entities []string
+		if strings.Contains(body.AnalysisInput.Documents[0].Text, "jane@contoso.com") {
+			entities = append(entities, `{"category":"Email"}`, `{"category":"Person"}`)
+		}
+		if strings.Contains(body.AnalysisInput.Documents[0].Text, "AccountKey=") {
+			entities = append(entities, `{"category":"AzureStorageAccountKey"}`)
+		}
+		_, _ = io.WriteString(w, `{"kind":"PiiEntityRecognitionResults","results":{"documents":[{"id":"1","entities":[`+strings.Join(entities, ",")+`]}],"errors":[]}}`)
+	}))
+	defer server.Close()
+	classifier := &LanguageClassifier{Endpoint: server.URL + "/", APIKey: "key"}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "What is the weather?", want: nil},
+		{text: "Mail jane@contoso.com", want: []string{"pii"}},
+		{text: "Mail jane@contoso.com the AccountKey=abc", want: []string{"pii", "secrets"}},
+	}
+	for _, tt := range tests {
+		got, err := classifier.Classify(context.Background(), tt.text)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Classify(%q) = %v, %v, want %v", tt.text, got, err, tt.want)
+		}
+	}
+	if _, err := (&LanguageClassifier{Endpoint: server.URL}).Classify(context.Background(), "hi"); err == nil {
+		t.Error("Classify() without credentials error = nil")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// DataHandlingPolicy sets data-handling defaults that apply to every request the plugin
+// sends, including raw requests, whatever the request itself asks for.
+type DataHandlingPolicy struct {
+	DisableStorage bool // Send "store": false with chat requests so Azure does not keep them as stored completions
+	OmitEndUserIDs bool // Remove the "user" and "safety_identifier" fields, such as those set by EndUser
+	OmitMetadata   bool // Remove the request "metadata", which Azure keeps with stored completions
+}
+
+// IsZero reports whether the policy changes nothing.
+func (p DataHandlingPolicy) IsZero() bool {
+	return p == DataHandlingPolicy{}
+}
+
+// apply rewrites a JSON request body to follow the policy, reporting whether it changed
+func (p DataHandlingPolicy) apply(path string, body map[string]json.RawMessage) bool {
+	changed := false
+	remove := func(field string) {
+		if _, ok := body[field]; ok {
+			delete(body, field)
+			changed = true
+		}
+	}
+	if p.DisableStorage && strings.HasSuffix(path, "/chat/completions") && string(body["store"]) != "false" {
+		body["store"] = json.RawMessage("false")
+		changed = true
+	}
+	if p.OmitEndUserIDs {
+		remove("user")
+		remove("safety_identifier")
+	}
+	if p.OmitMetadata {
+		remove("metadata")
+	}
+	return changed
+}
+
+// dataHandlingMiddleware applies the plugin's DataHandlingPolicy to JSON request bodies. It
+// runs before the audit middleware so the audit trail records what was sent.
+func (a *AzureAIFoundry) dataHandlingMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if req.Body == nil || mediaType != "application/json" {
+			return next(req)
+		}
+
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		var body map[string]json.RawMessage
+		if json.Unmarshal(data, &body) == nil && a.DataHandling.apply(req.URL.Path, body) {
+			if data, err = json.Marshal(body); err != nil {
+				return nil, err
+			}
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		return next(req)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+)
+
+func TestDataHandlingPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DataHandlingPolicy
+		want   map[string]any // Expected values of the policy fields; nil means absent
+	}{
+		{"no policy", DataHandlingPolicy{}, map[string]any{"store": true, "user": nil, "metadata": map[string]any{"feature": "checkout", "team": "payments"}}},
+		// Azure rejects metadata without storage
+		{"storage disabled", DataHandlingPolicy{DisableStorage: true}, map[string]any{"store": false, "user": nil, "metadata": nil}},
+		{"all", DataHandlingPolicy{DisableStorage: true, OmitEndUserIDs: true, OmitMetadata: true}, map[string]any{"store": false, "user": nil, "metadata": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+				a.DataHandling = tt.policy
+			})
+			ctx := WithAttribution(context.Background(), Attribution{Team: "payments", Feature: "checkout"})
+
+			// Raw requests are covered too, even when they ask for storage
+			_, err := plugin.RawChatCompletion(ctx, openai.ChatCompletionNewParams{
+				Model:    "gpt-4o",
+				Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+				Store:    openai.Bool(true),
+			})
+			if err != nil {
+				t.Fatalf("RawChatCompletion() error = %v", err)
+			}
+			for field, want := range tt.want {
+				got, ok := bodies[0][field]
+				if want == nil && ok {
+					t.Fatalf("%s = %v, want it removed", field, got)
+				}
+				if want != nil && !reflect.DeepEqual(got, want) {
+					t.Fatalf("%s = %v, want %v", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDataHandlingPolicyGenerate(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.DataHandling = DataHandlingPolicy{DisableStorage: true, OmitEndUserIDs: true}
+	})
+	ctx := WithAttribution(context.Background(), Attribution{Team: "payments"})
+	resp, err := plugin.generateText(ctx, "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+	if err != nil || resp.Text() != "ok" {
+		t.Fatalf("generateText() = %v, %v", resp, err)
+	}
+	if bodies[0]["store"] != false || bodies[0]["user"] != nil || bodies[0]["messages"] == nil {
+		t.Fatalf("request = %v", bodies[0])
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// Endpoint is a secondary endpoint that requests fail over to. It must be of the same kind as
+// the primary endpoint, Azure or OpenAI-compatible, and serve the same deployment names.
+type Endpoint struct {
+	URL    string // Endpoint URL, e.g. "https://my-resource-westus.openai.azure.com" (required)
+	APIKey string // API key of the endpoint. Defaults to the plugin's APIKey
+	Region string // Azure region of the endpoint, checked against ResidencyPolicy
+}
+
+// EndpointStatus is the health of an endpoint.
+type EndpointStatus struct {
+	URL     string
+	Region  string
+	Healthy bool
+	Since   time.Time // When the endpoint last changed health
+}
+
+// endpointState tracks the health of the primary and secondary endpoints
+type endpointState struct {
+	mu        sync.Mutex
+	endpoints []Endpoint // Primary first, then FailoverEndpoints
+	urls      []*url.URL // Parsed URL of each endpoint
+	status    []EndpointStatus
+	probed    []time.Time // Last health probe per endpoint
+	probing   []bool
+}
+
+// EndpointHealth returns the health of the primary endpoint followed by the failover
+// endpoints, in priority order. It is empty when no FailoverEndpoints are configured.
+func (a *AzureAIFoundry) EndpointHealth() []EndpointStatus {
+	a.endpoints.mu.Lock()
+	defer a.endpoints.mu.Unlock()
+	return append([]EndpointStatus{}, a.endpoints.status...)
+}
+
+// initEndpoints sets up health tracking for the primary and failover endpoints
+func (a *AzureAIFoundry) initEndpoints() error {
+	s := &a.endpoints
+	s.endpoints = append([]Endpoint{{URL: a.Endpoint, APIKey: a.APIKey, Region: a.Region}}, a.FailoverEndpoints...)
+	now := time.Now()
+	for i := range s.endpoints {
+		s.endpoints[i].URL = strings.TrimRight(s.endpoints[i].URL, "/") + "/"
+		if a.UseV1API {
+			s.endpoints[i].URL = v1BaseURL(s.endpoints[i].URL)
+		}
+		parsed, err := url.Parse(s.endpoints[i].URL)
+		if err != nil || parsed.Host == "" {
+			return fmt.Errorf("invalid endpoint URL %q", s.endpoints[i].URL)
+		}
+		s.urls = append(s.urls, parsed)
+		s.status = append(s.status, EndpointStatus{URL: s.endpoints[i].URL, Region: s.endpoints[i].Region, Healthy: true, Since: now})
+	}
+	s.probed = make([]time.Time, len(s.endpoints))
+	s.probing = make([]bool, len(s.endpoints))
+	return nil
+}
+
+// endpointFailoverMiddleware sends requests to the first healthy endpoint allowed by the
+// residency policy and moves on to the next one when an endpoint returns 408, 429 or 5xx or
+// cannot be reached. Endpoints that failed are skipped until a health probe succeeds, and
+// are only tried again when every other endpoint failed too.
+func (a *AzureAIFoundry) endpointFailoverMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		a.probeEndpoints(req, next)
+
+		candidates := a.endpointCandidates(DataClassificationFromContext(req.Context()))
+		var body []byte
+		if req.Body != nil {
+			var err error
+			if body, err = io.ReadAll(req.Body); err != nil {
+				return nil, err
+			}
+			_ = req.Body.Close()
+		}
+
+		var resp *http.Response
+		var err error
+		for i, index := range candidates {
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+			attempt, buildErr := a.endpointRequest(req, index, body)
+			if buildErr != nil {
+				return nil, buildErr
+			}
+			resp, err = next(attempt)
+			if req.Context().Err() != nil {
+				return resp, err
+			}
+			if !isEndpointFailure(resp, err) {
+				a.setEndpointHealth(req.Context(), index, true)
+				return resp, err
+			}
+			a.setEndpointHealth(req.Context(), index, false)
+			if i < len(candidates)-1 {
+				a.emit(req.Context(), Event{
+					Type:      EventEndpointFailover,
+					Model:     requestModel(req),
+					Operation: operationFromPath(req.URL.Path),
+					Endpoint:  a.endpoints.endpoints[candidates[i+1]].URL,
+				})
+			}
+		}
+		return resp, err
+	}
+}
+
+// endpointCandidates returns the indexes of the endpoints to try in order: healthy ones
+// first, then unhealthy ones as a last resort. Secondary endpoints in regions the
+// classification does not allow are left out.
+func (a *AzureAIFoundry) endpointCandidates(classification string) []int {
+	s := &a.endpoints
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var healthy, unhealthy []int
+	for i, endpoint := range s.endpoints {
+		if i > 0 && a.ResidencyPolicy != nil && !a.ResidencyPolicy.Allows(classification, endpoint.Region) {
+			continue
+		}
+		if s.status[i].Healthy {
+			healthy = append(healthy, i)
+		} else {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// endpointRequest copies a request for the primary endpoint so it is sent to another one
+func (a *AzureAIFoundry) endpointRequest(req *http.Request, index int, body []byte) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	if body != nil {
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+		attempt.ContentLength = int64(len(body))
+	}
+	if index == 0 {
+		return attempt, nil
+	}
+
+	endpoint := a.endpoints.endpoints[index]
+	primary, secondary := a.endpoints.urls[0], a.endpoints.urls[index]
+	target := *req.URL
+	target.Scheme, target.Host = secondary.Scheme, secondary.Host
+	// The path after the primary endpoint's own path goes after the secondary's
+	if rest, ok := strings.CutPrefix(req.URL.EscapedPath(), primary.EscapedPath()); ok {
+		path, err := url.Parse(secondary.EscapedPath() + rest)
+		if err != nil {
+			return nil, err
+		}
+		target.Path, target.RawPath = path.Path, path.RawPath
+	}
+	attempt.URL = &target
+	attempt.Host = target.Host
+	if endpoint.APIKey != "" {
+		if name, value := a.apiKeyHeader(endpoint.APIKey); name != "" {
+			attempt.Header.Set(name, value)
+		}
+	}
+	return attempt, nil
+}
+
+// setEndpointHealth records the outcome of a request to an endpoint, emitting an event when
+// its health changes
+func (a *AzureAIFoundry) setEndpointHealth(ctx context.Context, index int, healthy bool) {
+	s := &a.endpoints
+	s.mu.Lock()
+	status := &s.status[index]
+	changed := status.Healthy != healthy
+	if changed {
+		status.Healthy = healthy
+		status.Since = time.Now()
+		s.probed[index] = status.Since
+	}
+	endpoint := status.URL
+	s.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	eventType := EventEndpointRecovered
+	if !healthy {
+		eventType = EventEndpointUnhealthy
+	}
+	a.emit(ctx, Event{Type: eventType, Endpoint: endpoint})
+}
+
+// probeEndpoints starts a health probe for every unhealthy endpoint that was not probed in
+// the last HealthProbeInterval. Probes reuse the headers of req and are sent with next.
+func (a *AzureAIFoundry) probeEndpoints(req *http.Request, next option.MiddlewareNext) {
+	interval := a.HealthProbeInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	s := &a.endpoints
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i, status := range s.status {
+		if status.Healthy || s.probing[i] || now.Sub(s.probed[i]) < interval {
+			continue
+		}
+		s.probing[i] = true
+		s.probed[i] = now
+		go a.probeEndpoint(req, next, i)
+	}
+}
+
+// probeEndpoint lists the models of an endpoint and marks it healthy when that succeeds
+func (a *AzureAIFoundry) probeEndpoint(req *http.Request, next option.MiddlewareNext, index int) {
+	ctx, end, err := a.lifecycle.track(context.WithoutCancel(req.Context()))
+	if err != nil {
+		a.endpoints.mu.Lock()
+		a.endpoints.probing[index] = false
+		a.endpoints.mu.Unlock()
+		return
+	}
+	defer end()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	// Close stops probes at once instead of waiting for them
+	defer context.AfterFunc(a.lifecycle.stop, cancel)()
+
+	healthy := false
+	probe, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		a.modelsURL(a.endpoints.endpoints[index].URL, url.QueryEscape(req.URL.Query().Get("api-version"))), nil)
+	if err == nil {
+		for name, value := range a.Headers {
+			probe.Header.Set(name, value)
+		}
+		for _, header := range []string{"Authorization", "Api-Key", a.AuthHeader} {
+			if value := req.Header.Get(header); header != "" && value != "" {
+				probe.Header.Set(header, value)
+			}
+		}
+		if key := a.endpoints.endpoints[index].APIKey; key != "" {
+			if name, value := a.apiKeyHeader(key); name != "" {
+				probe.Header.Set(name, value)
+			}
+		}
+		var resp *http.Response
+		if resp, err = next(probe); err == nil {
+			healthy = resp.StatusCode == http.StatusOK
+			_ = resp.Body.Close()
+		}
+	}
+
+	a.endpoints.mu.Lock()
+	a.endpoints.probing[index] = false
+	a.endpoints.mu.Unlock()
+	if healthy {
+		a.setEndpointHealth(ctx, index, true)
+	}
+}
+
+// isEndpointFailure reports whether a response means the endpoint is unavailable
+func isEndpointFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError
+}
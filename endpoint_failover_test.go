@@ -0,0 +1,199 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// endpointServer is a fake Azure endpoint that can be switched between healthy and failing
+type endpointServer struct {
+	*httptest.Server
+	failing  atomic.Bool
+	mu       sync.Mutex
+	requests []string // Paths and API keys of the requests received
+}
+
+func newEndpointServer(t *testing.T) *endpointServer {
+	t.Helper()
+	s := &endpointServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path+" "+r.Header.Get("Api-Key")+" "+string(body))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-should-retry", "false")
+		if s.failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":"503","message":"Service unavailable"}}`)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/models") {
+			_, _ = io.WriteString(w, `{"data":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// received returns the requests received so far and forgets them
+func (s *endpointServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func TestEndpointFailover(t *testing.T) {
+	primary, secondary := newEndpointServer(t), newEndpointServer(t)
+	events := make(chan Event, 16)
+	plugin := &AzureAIFoundry{
+		Endpoint:            primary.URL,
+		APIKey:              "primary-key",
+		FailoverEndpoints:   []Endpoint{{URL: secondary.URL + "/", APIKey: "secondary-key"}},
+		HealthProbeInterval: time.Millisecond,
+		Subscribers: []EventSubscriber{EventSubscriberFunc(func(ctx context.Context, event Event) {
+			events <- event
+		})},
+	}
+	plugin.Init(context.Background())
+	generate := func() {
+		t.Helper()
+		resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+			Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+		}, nil)
+		if err != nil {
+			t.Fatalf("generateText() error = %v", err)
+		}
+		if resp.Text() != "ok" {
+			t.Fatalf("Text() = %q", resp.Text())
+		}
+	}
+	waitEvent := func(eventType EventType) Event {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-events:
+				if event.Type == eventType {
+					return event
+				}
+			case <-timeout:
+				t.Fatalf("no %s event", eventType)
+			}
+		}
+	}
+
+	// The primary fails and the request is sent to the secondary with its key and the same body
+	primary.failing.Store(true)
+	generate()
+	failed, served := primary.received(), secondary.received()
+	if len(failed) != 1 || len(served) != 1 || failed[0] != strings.Replace(served[0], "secondary-key", "primary-key", 1) {
+		t.Fatalf("primary requests = %v, secondary requests = %v", failed, served)
+	}
+	if event := waitEvent(EventEndpointUnhealthy); event.Endpoint != primary.URL+"/" {
+		t.Fatalf("unhealthy event = %+v", event)
+	}
+	if event := waitEvent(EventEndpointFailover); event.Endpoint != secondary.URL+"/" || event.Model != "gpt-4o" {
+		t.Fatalf("failover event = %+v", event)
+	}
+	if health := plugin.EndpointHealth(); len(health) != 2 || health[0].Healthy || !health[1].Healthy {
+		t.Fatalf("EndpointHealth() = %+v", health)
+	}
+
+	// Probes keep failing while the primary is down; requests skip it
+	time.Sleep(5 * time.Millisecond)
+	generate()
+	if len(secondary.received()) != 1 {
+		t.Fatal("request was not served by the secondary")
+	}
+
+	// Once a probe succeeds, traffic returns to the primary
+	primary.failing.Store(false)
+	time.Sleep(5 * time.Millisecond)
+	generate()
+	if event := waitEvent(EventEndpointRecovered); event.Endpoint != primary.URL+"/" {
+		t.Fatalf("recovered event = %+v", event)
+	}
+	primary.received()
+	secondary.received()
+	generate()
+	if len(primary.received()) != 1 || len(secondary.received()) != 0 {
+		t.Fatal("request was not served by the recovered primary")
+	}
+}
+
+func TestEndpointFailoverResidency(t *testing.T) {
+	primary, secondary := newEndpointServer(t), newEndpointServer(t)
+	plugin := newTestPlugin(t, nil, func(a *AzureAIFoundry) {
+		a.Endpoint = primary.URL
+		a.Region = "westeurope"
+		a.ResidencyPolicy = ResidencyPolicy{"eu-customer": {"westeurope"}}
+		a.FailoverEndpoints = []Endpoint{{URL: secondary.URL, Region: "eastus"}}
+	})
+	primary.failing.Store(true)
+
+	ctx := WithDataClassification(context.Background(), "eu-customer")
+	_, err := plugin.generateText(ctx, "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+	if err == nil {
+		t.Fatal("generateText() succeeded, want the primary's error")
+	}
+	if len(secondary.received()) != 0 {
+		t.Fatal("classified request failed over to a region the policy does not allow")
+	}
+
+	// Unclassified requests may fail over anywhere
+	if _, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if len(secondary.received()) != 1 {
+		t.Fatal("unclassified request did not fail over")
+	}
+}
+
+func TestEndpointFailoverURL(t *testing.T) {
+	primary, secondary := newEndpointServer(t), newEndpointServer(t)
+	plugin := newTestPlugin(t, nil, func(a *AzureAIFoundry) {
+		// The request URL differs from the configured one in scheme case
+		a.Endpoint = strings.Replace(primary.URL, "http://", "HTTP://", 1) + "/"
+		a.FailoverEndpoints = []Endpoint{{URL: secondary.URL + "/"}}
+	})
+	primary.failing.Store(true)
+
+	if _, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	served := secondary.received()
+	if len(served) != 1 || !strings.HasPrefix(served[0], "/openai/deployments/gpt-4o/chat/completions ") {
+		t.Fatalf("secondary requests = %v", served)
+	}
+}
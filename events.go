@@ -0,0 +1,279 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// EventType identifies a lifecycle event emitted by the plugin.
+type EventType string
+
+const (
+	// EventRequestStarted is emitted before a request is sent to Azure.
+	EventRequestStarted EventType = "request_started"
+	// EventFirstToken is emitted when the first content or tool call delta of a stream arrives.
+	EventFirstToken EventType = "first_token"
+	// EventToolCallRequested is emitted for every tool call the model requests.
+	EventToolCallRequested EventType = "tool_call_requested"
+	// EventRetry is emitted before an HTTP request is retried.
+	EventRetry EventType = "retry"
+	// EventCompleted is emitted when a request finishes, successfully or not.
+	EventCompleted EventType = "completed"
+	// EventModelRetirement is emitted when a deployment's model is near or past retirement.
+	EventModelRetirement EventType = "model_retirement"
+	// EventRegistryReloaded is emitted when a model registry reload changes any definitions.
+	EventRegistryReloaded EventType = "registry_reloaded"
+	// EventSettingsChanged is emitted when the runtime settings change.
+	EventSettingsChanged EventType = "settings_changed"
+	// EventEndpointFailover is emitted when a request moves on to the next endpoint.
+	EventEndpointFailover EventType = "endpoint_failover"
+	// EventEndpointUnhealthy is emitted when an endpoint starts failing requests.
+	EventEndpointUnhealthy EventType = "endpoint_unhealthy"
+	// EventEndpointRecovered is emitted when an unhealthy endpoint serves requests again.
+	EventEndpointRecovered EventType = "endpoint_recovered"
+	// EventStandbyUnhealthy is emitted when a standby deployment of a failover group fails its health probe.
+	EventStandbyUnhealthy EventType = "standby_unhealthy"
+	// EventStandbyRecovered is emitted when a failing standby deployment passes its health probe again.
+	EventStandbyRecovered EventType = "standby_recovered"
+	// EventStreamResumed is emitted when a broken stream is replayed from the text generated so far.
+	EventStreamResumed EventType = "stream_resumed"
+	// EventFallback is emitted when a request Azure could not serve is sent to the fallback.
+	EventFallback EventType = "fallback"
+	// EventCanaryAdvanced is emitted when a canary rollout moves to its next step or completes.
+	EventCanaryAdvanced EventType = "canary_advanced"
+	// EventCanaryRolledBack is emitted when a canary rollout sends all traffic back to its stable model.
+	EventCanaryRolledBack EventType = "canary_rolled_back"
+)
+
+// Operations reported in Event.Operation
+const (
+	OperationChat          = "chat"
+	OperationEmbedding     = "embedding"
+	OperationImage         = "image"
+	OperationSpeech        = "speech"
+	OperationTranscription = "transcription"
+)
+
+// Event describes something that happened while serving a request.
+type Event struct {
+	Type         EventType
+	Time         time.Time
+	Model        string              // Deployment name
+	Operation    string              // One of the Operation* constants
+	Streaming    bool                // Whether the request is streamed
+	Attempt      int                 // Retry attempt number (EventRetry, EventStreamResumed)
+	ToolName     string              // Requested tool (EventToolCallRequested)
+	Latency      time.Duration       // Time since the request started (EventFirstToken, EventCompleted)
+	Usage        *ai.GenerationUsage // Token usage, when reported (EventCompleted)
+	FinishReason ai.FinishReason     // Finish reason (EventCompleted)
+	Err          error               // Error that ended the request, if any (EventCompleted)
+	Attribution  Attribution         // Labels attached to the request context with WithAttribution
+	Retirement   *RetirementNotice   // Retirement date and replacement (EventModelRetirement)
+	Registry     *RegistryChange     // Added, updated and removed names (EventRegistryReloaded)
+	Settings     *RuntimeSettings    // New runtime settings (EventSettingsChanged)
+	Endpoint     string              // Endpoint URL (EventEndpointFailover, EventEndpointUnhealthy, EventEndpointRecovered)
+	Fallback     string              // Name of the fallback generator (EventFallback)
+	Canary       *CanaryStatus       // Progress of the rollout (EventCanaryAdvanced, EventCanaryRolledBack)
+}
+
+// EventSubscriber receives lifecycle events. HandleEvent is called synchronously on the
+// request path, so implementations should return quickly.
+type EventSubscriber interface {
+	HandleEvent(ctx context.Context, event Event)
+}
+
+// EventSubscriberFunc adapts a function to the EventSubscriber interface.
+type EventSubscriberFunc func(ctx context.Context, event Event)
+
+// HandleEvent calls f(ctx, event).
+func (f EventSubscriberFunc) HandleEvent(ctx context.Context, event Event) {
+	f(ctx, event)
+}
+
+// Subscribe registers a subscriber for lifecycle events. It may be called before or after Init.
+func (a *AzureAIFoundry) Subscribe(subscriber EventSubscriber) {
+	a.eventsMu.Lock()
+	defer a.eventsMu.Unlock()
+	a.Subscribers = append(a.Subscribers, subscriber)
+}
+
+// emit delivers an event to all subscribers
+func (a *AzureAIFoundry) emit(ctx context.Context, event Event) {
+	a.eventsMu.RLock()
+	subscribers := a.Subscribers
+	a.eventsMu.RUnlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Attribution.IsZero() {
+		event.Attribution = AttributionFromContext(ctx)
+	}
+	event.Err = a.redactError(event.Err)
+	for _, subscriber := range subscribers {
+		subscriber.HandleEvent(ctx, event)
+	}
+}
+
+// emitCompleted emits the tool call and completion events for a request described by its
+// started event. The usage is reported separately because embeddings have no model response.
+func (a *AzureAIFoundry) emitCompleted(ctx context.Context, started Event, resp *ai.ModelResponse, usage *ai.GenerationUsage, err error) {
+	event := Event{
+		Type:      EventCompleted,
+		Model:     started.Model,
+		Operation: started.Operation,
+		Streaming: started.Streaming,
+		Latency:   time.Since(started.Time),
+		Usage:     usage,
+		Err:       err,
+	}
+	if resp != nil {
+		if resp.Message != nil {
+			for _, part := range resp.Message.Content {
+				if part.IsToolRequest() {
+					a.emit(ctx, Event{
+						Type:      EventToolCallRequested,
+						Model:     started.Model,
+						Operation: started.Operation,
+						Streaming: started.Streaming,
+						ToolName:  part.ToolRequest.Name,
+					})
+				}
+			}
+		}
+		event.FinishReason = resp.FinishReason
+	}
+	a.emit(ctx, event)
+}
+
+// retryEventMiddleware emits EventRetry for every retried HTTP attempt made by the client
+func (a *AzureAIFoundry) retryEventMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		if attempt, err := strconv.Atoi(req.Header.Get("X-Stainless-Retry-Count")); err == nil && attempt > 0 {
+			a.emit(req.Context(), Event{
+				Type:      EventRetry,
+				Model:     requestModel(req),
+				Operation: operationFromPath(req.URL.Path),
+				Attempt:   attempt,
+			})
+		}
+		return next(req)
+	}
+}
+
+// requestModelKey is the context key of the model named in a request's body
+type requestModelKey struct{}
+
+// requestModelMiddleware records the model field of JSON requests whose path names no
+// deployment, as with the v1 API, so the middlewares after it can report the model
+func (a *AzureAIFoundry) requestModelMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if req.Body == nil || mediaType != "application/json" || deploymentFromPath(req.URL.Path) != "" {
+			return next(req)
+		}
+
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		var body struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(data, &body) == nil && body.Model != "" {
+			req = req.WithContext(context.WithValue(req.Context(), requestModelKey{}, body.Model))
+		}
+		return next(req)
+	}
+}
+
+// requestModel returns the deployment or model a request to Azure is for
+func requestModel(req *http.Request) string {
+	if deployment := deploymentFromPath(req.URL.Path); deployment != "" {
+		return deployment
+	}
+	model, _ := req.Context().Value(requestModelKey{}).(string)
+	return model
+}
+
+// deploymentFromPath extracts the deployment name from an Azure OpenAI request path
+func deploymentFromPath(path string) string {
+	const marker = "/deployments/"
+	idx := strings.Index(path, marker)
+	if idx == -1 {
+		return ""
+	}
+	rest := path[idx+len(marker):]
+	if end := strings.Index(rest, "/"); end != -1 {
+		rest = rest[:end]
+	}
+	return rest
+}
+
+// operationFromPath classifies an Azure OpenAI request by its API route
+func operationFromPath(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/chat/completions"):
+		return OperationChat
+	case strings.HasSuffix(path, "/embeddings"):
+		return OperationEmbedding
+	case strings.Contains(path, "/images/"):
+		return OperationImage
+	case strings.HasSuffix(path, "/audio/speech"):
+		return OperationSpeech
+	case strings.Contains(path, "/audio/"):
+		return OperationTranscription
+	default:
+		return ""
+	}
+}
+
+// operationForModel classifies a deployment by the API it is served through
+func operationForModel(modelName string) string {
+	modelLower := strings.ToLower(modelName)
+	switch {
+	case strings.Contains(modelLower, "dall-e") || strings.Contains(modelLower, "gpt-image"):
+		return OperationImage
+	case strings.Contains(modelLower, "tts"):
+		return OperationSpeech
+	case strings.Contains(modelLower, "whisper") || strings.Contains(modelLower, "transcribe"):
+		return OperationTranscription
+	default:
+		return OperationChat
+	}
+}
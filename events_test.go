@@ -0,0 +1,145 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// recordEvents subscribes a recorder to the plugin and returns the recorded events
+func recordEvents(a *AzureAIFoundry) *[]Event {
+	var events []Event
+	a.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+		events = append(events, event)
+	}))
+	return &events
+}
+
+func TestSubscribeDeliversEventsInOrder(t *testing.T) {
+	a := &AzureAIFoundry{}
+	var calls []string
+	a.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+		calls = append(calls, "first:"+string(event.Type))
+	}))
+	a.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+		calls = append(calls, "second:"+string(event.Type))
+	}))
+
+	a.emit(context.Background(), Event{Type: EventRequestStarted})
+	a.emit(context.Background(), Event{Type: EventCompleted})
+
+	want := []string{
+		"first:request_started", "second:request_started",
+		"first:completed", "second:completed",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestEmitCompletedReportsToolCalls(t *testing.T) {
+	a := &AzureAIFoundry{}
+	events := recordEvents(a)
+
+	started := Event{Type: EventRequestStarted, Time: time.Now(), Model: "gpt-4o", Operation: OperationChat, Streaming: true}
+	resp := &ai.ModelResponse{
+		FinishReason: ai.FinishReasonStop,
+		Message: ai.NewModelMessage(
+			ai.NewTextPart("checking"),
+			ai.NewToolRequestPart(&ai.ToolRequest{Name: "getWeather"}),
+			ai.NewToolRequestPart(&ai.ToolRequest{Name: "getTime"}),
+		),
+	}
+	usage := &ai.GenerationUsage{InputTokens: 3, OutputTokens: 4}
+	a.emitCompleted(context.Background(), started, resp, usage, nil)
+
+	if len(*events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(*events), *events)
+	}
+	for i, name := range []string{"getWeather", "getTime"} {
+		event := (*events)[i]
+		if event.Type != EventToolCallRequested || event.ToolName != name {
+			t.Fatalf("event %d = %+v, want tool call %s", i, event, name)
+		}
+	}
+	completed := (*events)[2]
+	if completed.Type != EventCompleted || completed.FinishReason != ai.FinishReasonStop {
+		t.Fatalf("last event = %+v, want completion", completed)
+	}
+	if completed.Model != "gpt-4o" || completed.Operation != OperationChat || !completed.Streaming || completed.Usage != usage {
+		t.Fatalf("completion event did not carry request fields: %+v", completed)
+	}
+}
+
+func TestRetryEventMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		retryCount  string
+		path        string
+		wantEvent   bool
+		wantAttempt int
+		wantOp      string
+	}{
+		{name: "first attempt", retryCount: "0", path: "/openai/deployments/gpt-4o/chat/completions"},
+		{name: "missing header", path: "/openai/deployments/gpt-4o/chat/completions"},
+		{name: "chat retry", retryCount: "1", path: "/openai/deployments/gpt-4o/chat/completions", wantEvent: true, wantAttempt: 1, wantOp: OperationChat},
+		{name: "embedding retry", retryCount: "2", path: "/openai/deployments/text-embedding-3-small/embeddings", wantEvent: true, wantAttempt: 2, wantOp: OperationEmbedding},
+		{name: "transcription retry", retryCount: "1", path: "/openai/deployments/whisper/audio/transcriptions", wantEvent: true, wantAttempt: 1, wantOp: OperationTranscription},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &AzureAIFoundry{}
+			events := recordEvents(a)
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.openai.azure.com"+tt.path, nil)
+			if tt.retryCount != "" {
+				req.Header.Set("X-Stainless-Retry-Count", tt.retryCount)
+			}
+			called := false
+			_, _ = a.retryEventMiddleware()(req, func(*http.Request) (*http.Response, error) {
+				called = true
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+
+			if !called {
+				t.Fatal("middleware did not call next")
+			}
+			if !tt.wantEvent {
+				if len(*events) != 0 {
+					t.Fatalf("got events %+v, want none", *events)
+				}
+				return
+			}
+			if len(*events) != 1 {
+				t.Fatalf("got %d events, want 1", len(*events))
+			}
+			event := (*events)[0]
+			if event.Type != EventRetry || event.Attempt != tt.wantAttempt || event.Operation != tt.wantOp || event.Model != deploymentFromPath(tt.path) {
+				t.Fatalf("event = %+v", event)
+			}
+		})
+	}
+}
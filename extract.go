@@ -0,0 +1,129 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// defaultExtractRetries is the number of corrections requested after invalid output
+const defaultExtractRetries = 2
+
+// extractInstruction is the system message of extraction requests
+const extractInstruction = "Extract the information in the user's message into JSON that matches the response schema. " +
+	"Leave fields empty when the message does not state them; do not guess."
+
+// Validator is implemented by extraction targets that check their own values, such as
+// Invoice. A validation error is sent back to the model to correct its output.
+type Validator interface {
+	Validate() error
+}
+
+// ExtractOptions configures Extract.
+type ExtractOptions struct {
+	Model        ai.Model       // Chat model that supports structured outputs, e.g. gpt-4o (required)
+	Instructions string         // Optional: Additional guidance, e.g. "Dates are in European format"
+	Media        []*ai.Part     // Optional: Images of the source, e.g. a scanned invoice
+	Config       map[string]any // Optional: Additional config options, e.g. temperature
+	Retries      int            // Optional: Corrections requested after invalid output. Defaults to 2; negative for none
+}
+
+// ExtractionError is returned by Extract when the model output is still invalid after the
+// last retry.
+type ExtractionError struct {
+	Attempts int
+	Output   string // Output of the last attempt
+	Err      error  // Why the last output was rejected
+}
+
+// Error implements the error interface.
+func (e *ExtractionError) Error() string {
+	return fmt.Sprintf("extraction failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns why the last output was rejected.
+func (e *ExtractionError) Unwrap() error {
+	return e.Err
+}
+
+// Extract reads the fields of T from text, and from opts.Media, with strict structured
+// outputs generated from T's JSON schema:
+//
+//	invoice, err := azureaifoundry.Extract[azureaifoundry.Invoice](ctx, g, text, azureaifoundry.ExtractOptions{Model: gpt4o})
+//
+// Output that does not unmarshal into T, or that fails T's Validate method, is sent back to
+// the model with the error up to opts.Retries times.
+func Extract[T any](ctx context.Context, g *genkit.Genkit, text string, opts ExtractOptions) (*T, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("extraction requires a model")
+	}
+	retries := opts.Retries
+	if retries == 0 {
+		retries = defaultExtractRetries
+	}
+
+	config := maps.Clone(opts.Config)
+	if config == nil {
+		config = map[string]any{}
+	}
+	config["responseFormat"] = ResponseFormatJSONSchema
+	config["jsonSchema"] = core.InferSchemaMap(new(T))
+
+	instruction := extractInstruction
+	if opts.Instructions != "" {
+		instruction += "\n\n" + opts.Instructions
+	}
+	parts := append([]*ai.Part{ai.NewTextPart(text)}, opts.Media...)
+	messages := []*ai.Message{ai.NewSystemTextMessage(instruction), ai.NewUserMessage(parts...)}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := genkit.Generate(ctx, g,
+			ai.WithModel(opts.Model),
+			ai.WithMessages(messages...),
+			ai.WithConfig(config),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("extraction failed: %w", err)
+		}
+
+		output := strings.TrimSpace(resp.Text())
+		value := new(T)
+		problem := json.Unmarshal([]byte(output), value)
+		if problem == nil {
+			if validator, ok := any(value).(Validator); ok {
+				problem = validator.Validate()
+			}
+		}
+		if problem == nil {
+			return value, nil
+		}
+		if attempt > retries {
+			return nil, &ExtractionError{Attempts: attempt, Output: output, Err: problem}
+		}
+		messages = append(messages, resp.Message,
+			ai.NewUserTextMessage(fmt.Sprintf("The JSON is invalid: %v. Return the corrected JSON.", problem)))
+	}
+}
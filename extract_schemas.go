@@ -0,0 +1,228 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// The types below are ready-made extraction targets for Extract. Their Validate methods
+// catch inconsistent output, which is sent back to the model for correction.
+
+// invoiceTolerance is the rounding difference allowed between invoice amounts
+const invoiceTolerance = 0.01
+
+// Invoice is an invoice or receipt.
+type Invoice struct {
+	InvoiceNumber string            `json:"invoiceNumber"`
+	IssueDate     string            `json:"issueDate"` // YYYY-MM-DD
+	DueDate       string            `json:"dueDate"`   // YYYY-MM-DD
+	Currency      string            `json:"currency"`  // ISO 4217 code, e.g. "EUR"
+	Vendor        InvoiceParty      `json:"vendor"`
+	Customer      InvoiceParty      `json:"customer"`
+	LineItems     []InvoiceLineItem `json:"lineItems"`
+	Subtotal      float64           `json:"subtotal"`
+	Tax           float64           `json:"tax"`
+	Total         float64           `json:"total"`
+}
+
+// InvoiceParty is the vendor or customer of an invoice.
+type InvoiceParty struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	TaxID   string `json:"taxId"`
+}
+
+// InvoiceLineItem is a billed product or service.
+type InvoiceLineItem struct {
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   float64 `json:"unitPrice"`
+	Amount      float64 `json:"amount"`
+}
+
+// Validate checks that the invoice has a number and a total and that its amounts add up.
+func (inv *Invoice) Validate() error {
+	var problems []error
+	if inv.InvoiceNumber == "" {
+		problems = append(problems, errors.New("invoiceNumber is empty"))
+	}
+	if inv.Total == 0 {
+		problems = append(problems, errors.New("total is empty"))
+	}
+	problems = append(problems, checkDate("issueDate", inv.IssueDate), checkDate("dueDate", inv.DueDate))
+	if len(inv.LineItems) > 0 && inv.Subtotal != 0 {
+		var sum float64
+		for _, item := range inv.LineItems {
+			sum += item.Amount
+		}
+		if math.Abs(sum-inv.Subtotal) > invoiceTolerance {
+			problems = append(problems, fmt.Errorf("line item amounts add up to %.2f, not the subtotal %.2f", sum, inv.Subtotal))
+		}
+	}
+	if inv.Subtotal != 0 && math.Abs(inv.Subtotal+inv.Tax-inv.Total) > invoiceTolerance {
+		problems = append(problems, fmt.Errorf("subtotal %.2f plus tax %.2f is not the total %.2f", inv.Subtotal, inv.Tax, inv.Total))
+	}
+	return errors.Join(problems...)
+}
+
+// Resume is a CV or resume.
+type Resume struct {
+	Name       string             `json:"name"`
+	Email      string             `json:"email"`
+	Phone      string             `json:"phone"`
+	Location   string             `json:"location"`
+	Summary    string             `json:"summary"`
+	Skills     []string           `json:"skills"`
+	Experience []ResumeExperience `json:"experience"`
+	Education  []ResumeEducation  `json:"education"`
+	Languages  []string           `json:"languages"`
+}
+
+// ResumeExperience is a position held.
+type ResumeExperience struct {
+	Title       string `json:"title"`
+	Company     string `json:"company"`
+	StartDate   string `json:"startDate"` // YYYY-MM
+	EndDate     string `json:"endDate"`   // YYYY-MM, empty for the current position
+	Description string `json:"description"`
+}
+
+// ResumeEducation is a degree or qualification.
+type ResumeEducation struct {
+	Degree      string `json:"degree"`
+	Institution string `json:"institution"`
+	Year        int    `json:"year"` // Year of graduation
+}
+
+// Validate checks that the resume names the candidate and that its contact details and
+// dates are well-formed.
+func (r *Resume) Validate() error {
+	var problems []error
+	if r.Name == "" {
+		problems = append(problems, errors.New("name is empty"))
+	}
+	problems = append(problems, checkEmail("email", r.Email))
+	for i, exp := range r.Experience {
+		problems = append(problems,
+			checkMonth(fmt.Sprintf("experience[%d].startDate", i), exp.StartDate),
+			checkMonth(fmt.Sprintf("experience[%d].endDate", i), exp.EndDate))
+		if exp.StartDate != "" && exp.EndDate != "" && exp.EndDate < exp.StartDate {
+			problems = append(problems, fmt.Errorf("experience[%d] ends before it starts", i))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Contact is a person's contact details, e.g. from an email signature or business card.
+type Contact struct {
+	Name         string   `json:"name"`
+	Title        string   `json:"title"`
+	Organization string   `json:"organization"`
+	Emails       []string `json:"emails"`
+	Phones       []string `json:"phones"`
+	Address      string   `json:"address"`
+	Website      string   `json:"website"`
+}
+
+// Validate checks that the contact has a name or an email address and that the email
+// addresses are well-formed.
+func (c *Contact) Validate() error {
+	var problems []error
+	if c.Name == "" && len(c.Emails) == 0 {
+		problems = append(problems, errors.New("name and emails are both empty"))
+	}
+	for i, email := range c.Emails {
+		problems = append(problems, checkEmail(fmt.Sprintf("emails[%d]", i), email))
+	}
+	return errors.Join(problems...)
+}
+
+// Meeting is a meeting invitation or scheduling request.
+type Meeting struct {
+	Title     string   `json:"title"`
+	Date      string   `json:"date"`      // YYYY-MM-DD
+	StartTime string   `json:"startTime"` // HH:MM, 24-hour
+	EndTime   string   `json:"endTime"`   // HH:MM, 24-hour
+	TimeZone  string   `json:"timeZone"`  // IANA name, e.g. "Europe/Madrid"
+	Location  string   `json:"location"`  // Room, address or meeting link
+	Organizer string   `json:"organizer"`
+	Attendees []string `json:"attendees"`
+	Agenda    []string `json:"agenda"`
+}
+
+// Validate checks that the meeting has a title and that its date, times and time zone are
+// well-formed.
+func (m *Meeting) Validate() error {
+	var problems []error
+	if m.Title == "" {
+		problems = append(problems, errors.New("title is empty"))
+	}
+	problems = append(problems, checkDate("date", m.Date), checkClock("startTime", m.StartTime), checkClock("endTime", m.EndTime))
+	if m.StartTime != "" && m.EndTime != "" && m.EndTime < m.StartTime {
+		problems = append(problems, errors.New("endTime is before startTime"))
+	}
+	if m.TimeZone != "" {
+		if _, err := time.LoadLocation(m.TimeZone); err != nil {
+			problems = append(problems, fmt.Errorf("timeZone %q is not an IANA time zone", m.TimeZone))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// checkDate reports a non-empty field that is not a YYYY-MM-DD date
+func checkDate(field, value string) error {
+	return checkLayout(field, value, time.DateOnly, "YYYY-MM-DD")
+}
+
+// checkMonth reports a non-empty field that is not a YYYY-MM month
+func checkMonth(field, value string) error {
+	return checkLayout(field, value, "2006-01", "YYYY-MM")
+}
+
+// checkClock reports a non-empty field that is not an HH:MM time
+func checkClock(field, value string) error {
+	return checkLayout(field, value, "15:04", "HH:MM")
+}
+
+// checkLayout reports a non-empty field that does not match a time layout
+func checkLayout(field, value, layout, format string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse(layout, value); err != nil {
+		return fmt.Errorf("%s %q is not in %s format", field, value, format)
+	}
+	return nil
+}
+
+// checkEmail reports a non-empty field that is not an email address
+func checkEmail(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	local, domain, ok := strings.Cut(value, "@")
+	if !ok || local == "" || !strings.Contains(domain, ".") || strings.ContainsAny(value, " \t") {
+		return fmt.Errorf("%s %q is not an email address", field, value)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// chatCompletionWith returns a chat completion whose answer is content
+func chatCompletionWith(content string) string {
+	data, _ := json.Marshal(content)
+	return `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":` + string(data) + `}}]}`
+}
+
+func TestExtract(t *testing.T) {
+	const (
+		unbalanced = `{"invoiceNumber":"INV-7","issueDate":"2026-03-01","dueDate":"","currency":"EUR","vendor":{"name":"Acme","address":"","taxId":""},"customer":{"name":"","address":"","taxId":""},"lineItems":[{"description":"Widgets","quantity":2,"unitPrice":50,"amount":100}],"subtotal":100,"tax":21,"total":100}`
+		balanced   = `{"invoiceNumber":"INV-7","issueDate":"2026-03-01","dueDate":"","currency":"EUR","vendor":{"name":"Acme","address":"","taxId":""},"customer":{"name":"","address":"","taxId":""},"lineItems":[{"description":"Widgets","quantity":2,"unitPrice":50,"amount":100}],"subtotal":100,"tax":21,"total":121}`
+	)
+	tests := []struct {
+		name         string
+		replies      []string
+		retries      int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "valid", replies: []string{balanced}, wantRequests: 1},
+		{name: "corrected", replies: []string{"not json", unbalanced, balanced}, wantRequests: 3},
+		{name: "still invalid", replies: []string{unbalanced, unbalanced}, retries: 1, wantRequests: 2, wantErr: true},
+		{name: "no retries", replies: []string{unbalanced}, retries: -1, wantRequests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				var body map[string]any
+				_ = json.Unmarshal(data, &body)
+				bodies = append(bodies, body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, chatCompletionWith(tt.replies[len(bodies)-1]))
+			}))
+			defer server.Close()
+
+			ctx := context.Background()
+			plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+			g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+			model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+
+			invoice, err := Extract[Invoice](ctx, g, "Invoice INV-7 from Acme: 2 widgets at 50 EUR, VAT 21 EUR.",
+				ExtractOptions{Model: model, Retries: tt.retries})
+			if len(bodies) != tt.wantRequests {
+				t.Fatalf("got %d requests, want %d", len(bodies), tt.wantRequests)
+			}
+			if tt.wantErr {
+				var extractErr *ExtractionError
+				if !errors.As(err, &extractErr) || extractErr.Attempts != tt.wantRequests || extractErr.Output != unbalanced {
+					t.Fatalf("Extract() error = %v, want *ExtractionError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if invoice.Total != 121 || invoice.Vendor.Name != "Acme" || len(invoice.LineItems) != 1 {
+				t.Fatalf("invoice = %+v", invoice)
+			}
+
+			format, _ := bodies[0]["response_format"].(map[string]any)
+			schema, _ := format["json_schema"].(map[string]any)
+			if format["type"] != "json_schema" || schema["strict"] != true {
+				t.Fatalf("response_format = %v, want a strict JSON schema", bodies[0]["response_format"])
+			}
+			if last := fmt.Sprint(bodies[len(bodies)-1]["messages"]); tt.wantRequests > 1 && !strings.Contains(last, "The JSON is invalid") {
+				t.Fatalf("correction request messages = %s, want the validation error", last)
+			}
+		})
+	}
+}
+
+func TestExtractionSchemaValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   Validator
+		wantErr string
+	}{
+		{
+			name:  "valid invoice",
+			value: &Invoice{InvoiceNumber: "1", IssueDate: "2026-01-31", LineItems: []InvoiceLineItem{{Amount: 10}, {Amount: 5.5}}, Subtotal: 15.5, Tax: 1.55, Total: 17.05},
+		},
+		{
+			name:    "invoice line items",
+			value:   &Invoice{InvoiceNumber: "1", LineItems: []InvoiceLineItem{{Amount: 10}}, Subtotal: 12, Total: 12},
+			wantErr: "line item amounts add up to 10.00, not the subtotal 12.00",
+		},
+		{
+			name:    "invoice date",
+			value:   &Invoice{InvoiceNumber: "1", IssueDate: "31/01/2026", Total: 5},
+			wantErr: `issueDate "31/01/2026" is not in YYYY-MM-DD format`,
+		},
+		{name: "invoice without number", value: &Invoice{Total: 5}, wantErr: "invoiceNumber is empty"},
+		{
+			name:  "valid resume",
+			value: &Resume{Name: "Ada", Email: "ada@example.com", Experience: []ResumeExperience{{StartDate: "2020-01", EndDate: "2024-06"}, {StartDate: "2024-07"}}},
+		},
+		{
+			name:    "resume dates",
+			value:   &Resume{Name: "Ada", Experience: []ResumeExperience{{StartDate: "2024-01", EndDate: "2020-01"}}},
+			wantErr: "experience[0] ends before it starts",
+		},
+		{name: "resume email", value: &Resume{Name: "Ada", Email: "ada at example"}, wantErr: `email "ada at example" is not an email address`},
+		{name: "valid contact", value: &Contact{Emails: []string{"sam@example.org"}}},
+		{name: "empty contact", value: &Contact{Phones: []string{"555"}}, wantErr: "name and emails are both empty"},
+		{
+			name:  "valid meeting",
+			value: &Meeting{Title: "Sync", Date: "2026-05-04", StartTime: "09:30", EndTime: "10:00", TimeZone: "Europe/Madrid"},
+		},
+		{
+			name:    "meeting times",
+			value:   &Meeting{Title: "Sync", StartTime: "9:30am", TimeZone: "Madrid"},
+			wantErr: `startTime "9:30am" is not in HH:MM format` + "\n" + `timeZone "Madrid" is not an IANA time zone`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.value.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
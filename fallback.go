@@ -0,0 +1,140 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/openai/openai-go/v3"
+)
+
+// FallbackGenerator serves chat requests when Azure is unreachable, such as a model on an
+// on-premises OpenAI-compatible endpoint kept for business continuity.
+type FallbackGenerator interface {
+	// Name identifies the fallback in response metadata and events.
+	Name() string
+	// Generate answers a request that was meant for the named deployment. cb is nil unless
+	// the request is streamed.
+	Generate(ctx context.Context, modelName string, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error)
+}
+
+// FallbackGeneratorFunc adapts a function to the FallbackGenerator interface.
+type FallbackGeneratorFunc struct {
+	FallbackName string
+	Func         func(ctx context.Context, modelName string, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error)
+}
+
+// Name returns f.FallbackName.
+func (f FallbackGeneratorFunc) Name() string {
+	return f.FallbackName
+}
+
+// Generate calls f.Func.
+func (f FallbackGeneratorFunc) Generate(ctx context.Context, modelName string, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+	return f.Func(ctx, modelName, input, cb)
+}
+
+// noFallbackKey is the context key that defers the fallback, e.g. while a failover group has
+// deployments left to try
+type noFallbackKey struct{}
+
+// withoutFallback returns a context whose requests fail instead of using the fallback
+func withoutFallback(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noFallbackKey{}, true)
+}
+
+// withFallback returns a model function that sends requests Azure could not be reached for to
+// the plugin's Fallback when the fallback policy allows it. Responses it serves are tagged
+// with its name in the "fallback" custom field.
+func (a *AzureAIFoundry) withFallback(modelName string, fn ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		streamed := false
+		var tracked ai.ModelStreamCallback
+		if cb != nil {
+			tracked = func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+				streamed = true
+				return cb(ctx, chunk)
+			}
+		}
+		resp, err := fn(ctx, input, tracked)
+		// Chunks already sent cannot be taken back, so a broken stream is not replayed
+		if err == nil || streamed || !a.fallbackAllowed(ctx, modelName, err) {
+			return resp, err
+		}
+		return a.generateFallback(ctx, modelName, input, cb, err)
+	}
+}
+
+// generateFallback serves a request Azure failed with azureErr from the plugin's Fallback
+func (a *AzureAIFoundry) generateFallback(ctx context.Context, modelName string, input *ai.ModelRequest, cb ai.ModelStreamCallback, azureErr error) (*ai.ModelResponse, error) {
+	name := a.Fallback.Name()
+	logger.FromContext(ctx).Warn("azureaifoundry: Azure is unreachable, using the fallback",
+		"model", modelName, "fallback", name, "err", azureErr)
+	a.emit(ctx, Event{
+		Type:      EventFallback,
+		Model:     modelName,
+		Operation: OperationChat,
+		Streaming: cb != nil,
+		Fallback:  name,
+		Err:       azureErr,
+	})
+
+	resp, err := a.Fallback.Generate(ctx, modelName, input, cb)
+	if err != nil {
+		return nil, errors.Join(azureErr, err)
+	}
+	if resp != nil {
+		resp.Custom = withCustomValue(resp.Custom, "fallback", name)
+	}
+	return resp, nil
+}
+
+// fallbackAllowed reports whether a request that failed with err may be served by the
+// plugin's Fallback
+func (a *AzureAIFoundry) fallbackAllowed(ctx context.Context, modelName string, err error) bool {
+	if a.Fallback == nil || ctx.Value(noFallbackKey{}) != nil || !isUnreachableError(ctx, err) {
+		return false
+	}
+	if a.ResidencyPolicy != nil && !a.ResidencyPolicy.Allows(DataClassificationFromContext(ctx), a.FallbackRegion) {
+		return false
+	}
+	return a.FallbackPolicy == nil || a.FallbackPolicy(ctx, modelName)
+}
+
+// isUnreachableError reports whether err means Azure could not serve the request at all, as
+// opposed to rejecting it or throttling the caller
+func isUnreachableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var firstTokenErr *FirstTokenTimeoutError
+	if errors.As(err, &firstTokenErr) {
+		return true
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
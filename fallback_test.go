@@ -0,0 +1,149 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// staticFallback answers every request with "from fallback" and records the models asked for
+func staticFallback(models *[]string) FallbackGenerator {
+	return FallbackGeneratorFunc{
+		FallbackName: "on-prem",
+		Func: func(ctx context.Context, modelName string, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			*models = append(*models, modelName)
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage("from fallback"), FinishReason: ai.FinishReasonStop}, nil
+		},
+	}
+}
+
+// failingHandler replies to every request with status and no retries
+func failingHandler(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-should-retry", "false")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"error":{"code":"error","message":"failed"}}`)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		classification string
+		policy         func(ctx context.Context, modelName string) bool
+		want           string // Expected response text; empty means the Azure error is returned
+	}{
+		{"azure succeeds", captureRequests(new([]map[string]any), chatCompletionJSON), "", nil, "ok"},
+		{"azure unavailable", failingHandler(http.StatusServiceUnavailable), "", nil, "from fallback"},
+		{"throttled", failingHandler(http.StatusTooManyRequests), "", nil, ""},
+		{"bad request", failingHandler(http.StatusBadRequest), "", nil, ""},
+		{"residency allows fallback region", failingHandler(http.StatusBadGateway), "internal", nil, "from fallback"},
+		{"residency forbids fallback region", failingHandler(http.StatusBadGateway), "eu-customer", nil, ""},
+		{"policy forbids", failingHandler(http.StatusServiceUnavailable), "", func(context.Context, string) bool { return false }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var models []string
+			var events []Event
+			plugin := newTestPlugin(t, tt.handler, func(a *AzureAIFoundry) {
+				a.Region = "westeurope"
+				a.ResidencyPolicy = ResidencyPolicy{"internal": {"westeurope", "onprem"}, "eu-customer": {"westeurope"}}
+				a.Fallback = staticFallback(&models)
+				a.FallbackRegion = "onprem"
+				a.FallbackPolicy = tt.policy
+				a.Subscribers = []EventSubscriber{EventSubscriberFunc(func(_ context.Context, event Event) {
+					if event.Type == EventFallback {
+						events = append(events, event)
+					}
+				})}
+			})
+			_, fn := plugin.modelAction(ModelDefinition{Name: "gpt-4o"}, nil)
+			ctx := context.Background()
+			if tt.classification != "" {
+				ctx = WithDataClassification(ctx, tt.classification)
+			}
+
+			resp, err := fn(ctx, &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+			if tt.want == "" {
+				if err == nil || len(models) != 0 {
+					t.Fatalf("model error = %v, fallback models = %v, want the Azure error", err, models)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("model error = %v", err)
+			}
+			if resp.Text() != tt.want {
+				t.Fatalf("Text() = %q, want %q", resp.Text(), tt.want)
+			}
+			if tt.want != "from fallback" {
+				if resp.Custom != nil && resp.Custom.(map[string]any)["fallback"] != nil {
+					t.Fatalf("Custom = %v, want no fallback tag", resp.Custom)
+				}
+				return
+			}
+			if resp.Custom.(map[string]any)["fallback"] != "on-prem" {
+				t.Fatalf("Custom = %v, want the fallback tag", resp.Custom)
+			}
+			if len(models) != 1 || models[0] != "gpt-4o" {
+				t.Fatalf("fallback models = %v", models)
+			}
+			if len(events) != 1 || events[0].Fallback != "on-prem" || events[0].Model != "gpt-4o" || events[0].Err == nil {
+				t.Fatalf("events = %+v", events)
+			}
+		})
+	}
+}
+
+func TestFallbackAfterFailoverGroup(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		failingHandler(http.StatusServiceUnavailable)(w, r)
+	})
+	var models []string
+	plugin.Fallback = staticFallback(&models)
+
+	member := func(name string) ai.ModelFunc {
+		_, fn := plugin.modelAction(ModelDefinition{Name: name}, nil)
+		return fn
+	}
+	request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}
+	resp, err := plugin.tryModels(context.Background(), "gpt-4o", []string{"gpt-4o-eastus", "gpt-4o-westeurope"}, member, request, nil)
+	if err != nil {
+		t.Fatalf("tryModels() error = %v", err)
+	}
+	// Every deployment is tried before the fallback
+	if len(bodies) != 2 || bodies[1]["model"] != "gpt-4o-westeurope" {
+		t.Fatalf("requests = %v, want both deployments tried", bodies)
+	}
+	if resp.Text() != "from fallback" || len(models) != 1 || models[0] != "gpt-4o-westeurope" {
+		t.Fatalf("Text() = %q, fallback models = %v", resp.Text(), models)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+// SystemFingerprint returns the backend configuration fingerprint Azure reported for a
+// response, or "" if none was reported.
+func SystemFingerprint(resp *ai.ModelResponse) string {
+	if resp == nil {
+		return ""
+	}
+	custom, _ := resp.Custom.(map[string]any)
+	fingerprint, _ := custom["systemFingerprint"].(string)
+	return fingerprint
+}
+
+// FingerprintChange describes a system fingerprint that differs from the one previously
+// recorded for the same key.
+type FingerprintChange struct {
+	Key      string
+	Previous string
+	Current  string
+}
+
+// FingerprintTracker remembers the system fingerprint of seeded generations so that
+// non-reproducible output can be attributed to a backend change rather than an app change.
+// Persist Fingerprints between runs and pass them to NewFingerprintTracker to compare runs.
+type FingerprintTracker struct {
+	mu           sync.Mutex
+	fingerprints map[string]string
+}
+
+// NewFingerprintTracker returns a tracker seeded with fingerprints recorded by a previous run.
+func NewFingerprintTracker(previous map[string]string) *FingerprintTracker {
+	fingerprints := make(map[string]string, len(previous))
+	for key, fingerprint := range previous {
+		fingerprints[key] = fingerprint
+	}
+	return &FingerprintTracker{fingerprints: fingerprints}
+}
+
+// Check records the response's fingerprint under key, typically a test or prompt name used
+// with a fixed seed. If a different fingerprint was recorded before, a warning is logged and
+// the change is returned.
+func (t *FingerprintTracker) Check(ctx context.Context, key string, resp *ai.ModelResponse) *FingerprintChange {
+	current := SystemFingerprint(resp)
+	if current == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	previous := t.fingerprints[key]
+	t.fingerprints[key] = current
+	t.mu.Unlock()
+
+	if previous == "" || previous == current {
+		return nil
+	}
+	logger.FromContext(ctx).Warn("azureaifoundry: system fingerprint changed, seeded output may differ",
+		"key", key, "previous", previous, "current", current)
+	return &FingerprintChange{Key: key, Previous: previous, Current: current}
+}
+
+// Fingerprints returns a copy of the recorded fingerprints, for persisting between runs.
+func (t *FingerprintTracker) Fingerprints() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fingerprints := make(map[string]string, len(t.fingerprints))
+	for key, fingerprint := range t.fingerprints {
+		fingerprints[key] = fingerprint
+	}
+	return fingerprints
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestSeedAndSystemFingerprint(t *testing.T) {
+	var bodies []map[string]any
+	response := strings.Replace(chatCompletionJSON, `"model":"gpt-4o",`, `"model":"gpt-4o","system_fingerprint":"fp_2",`, 1)
+	plugin := newTestPlugin(t, captureRequests(&bodies, response))
+
+	resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+		Config:   map[string]interface{}{"seed": 42},
+	}, nil)
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if bodies[0]["seed"] != float64(42) {
+		t.Fatalf("seed = %v, want 42", bodies[0]["seed"])
+	}
+	if got := SystemFingerprint(resp); got != "fp_2" {
+		t.Fatalf("SystemFingerprint() = %q, want fp_2", got)
+	}
+
+	tracker := NewFingerprintTracker(map[string]string{"summary": "fp_1", "other": "fp_2"})
+	change := tracker.Check(context.Background(), "summary", resp)
+	if change == nil || change.Previous != "fp_1" || change.Current != "fp_2" {
+		t.Fatalf("Check() = %+v, want change from fp_1 to fp_2", change)
+	}
+	if change := tracker.Check(context.Background(), "other", resp); change != nil {
+		t.Fatalf("Check() = %+v for unchanged fingerprint", change)
+	}
+	if got := tracker.Fingerprints()["summary"]; got != "fp_2" {
+		t.Fatalf("recorded fingerprint = %q, want fp_2", got)
+	}
+}
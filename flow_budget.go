@@ -0,0 +1,194 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// FlowBudget limits the requests the plugin makes on behalf of one flow invocation, as a
+// safety net for agentic loops. Zero fields are not limited.
+type FlowBudget struct {
+	MaxTotalTokens int           // Tokens reported by the models and embedders called
+	MaxModelCalls  int           // Requests to models and embedders, including raw requests
+	MaxDuration    time.Duration // Wall time from the start of the invocation
+}
+
+// BudgetLimit names the FlowBudget limit a flow ran into.
+type BudgetLimit string
+
+const (
+	BudgetLimitTokens     BudgetLimit = "tokens"
+	BudgetLimitModelCalls BudgetLimit = "modelCalls"
+	BudgetLimitDuration   BudgetLimit = "duration"
+)
+
+// FlowBudgetUsage is what a flow invocation has used of its budget.
+type FlowBudgetUsage struct {
+	TotalTokens int
+	ModelCalls  int
+	Elapsed     time.Duration
+}
+
+// BudgetExceededError is returned when a request would exceed the FlowBudget of its flow
+// invocation, and by WithinBudget when the invocation ran out of time.
+type BudgetExceededError struct {
+	Limit  BudgetLimit
+	Budget FlowBudget
+	Usage  FlowBudgetUsage // Usage when the limit was hit
+}
+
+// Error implements the error interface.
+func (e *BudgetExceededError) Error() string {
+	switch e.Limit {
+	case BudgetLimitTokens:
+		return fmt.Sprintf("flow budget exceeded: %d tokens used of %d", e.Usage.TotalTokens, e.Budget.MaxTotalTokens)
+	case BudgetLimitModelCalls:
+		return fmt.Sprintf("flow budget exceeded: %d model calls made of %d", e.Usage.ModelCalls, e.Budget.MaxModelCalls)
+	default:
+		return fmt.Sprintf("flow budget exceeded: ran for %s of %s", e.Usage.Elapsed.Round(time.Millisecond), e.Budget.MaxDuration)
+	}
+}
+
+// flowBudgetKey is the context key for the budget of a flow invocation
+type flowBudgetKey struct{}
+
+// flowBudgetState tracks the usage of a flow invocation against its budget
+type flowBudgetState struct {
+	budget  FlowBudget
+	started time.Time
+	parent  *flowBudgetState // Budget of an enclosing invocation, which also applies
+
+	mu     sync.Mutex
+	tokens int
+	calls  int
+}
+
+// WithFlowBudget returns a context whose requests through the plugin share the budget. A
+// budget nested in another one counts against both. MaxDuration cancels the context with a
+// *BudgetExceededError as its cause; call cancel when the invocation ends.
+func WithFlowBudget(ctx context.Context, budget FlowBudget) (context.Context, context.CancelFunc) {
+	parent, _ := ctx.Value(flowBudgetKey{}).(*flowBudgetState)
+	state := &flowBudgetState{budget: budget, started: time.Now(), parent: parent}
+	ctx = context.WithValue(ctx, flowBudgetKey{}, state)
+	if budget.MaxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	cause := &BudgetExceededError{
+		Limit:  BudgetLimitDuration,
+		Budget: budget,
+		Usage:  FlowBudgetUsage{Elapsed: budget.MaxDuration},
+	}
+	return context.WithDeadlineCause(ctx, state.started.Add(budget.MaxDuration), cause)
+}
+
+// FlowBudgetUsageFromContext returns the usage of the innermost flow budget of ctx.
+func FlowBudgetUsageFromContext(ctx context.Context) (FlowBudgetUsage, bool) {
+	state, ok := ctx.Value(flowBudgetKey{}).(*flowBudgetState)
+	if !ok {
+		return FlowBudgetUsage{}, false
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.usage(), true
+}
+
+// WithinBudget wraps a flow function so that each invocation runs under the budget, e.g.
+//
+//	genkit.DefineFlow(g, "agent", azureaifoundry.WithinBudget(budget, runAgent))
+//
+// An invocation that runs out of time returns a *BudgetExceededError instead of the
+// cancellation error of whatever call was interrupted.
+func WithinBudget[In, Out any](budget FlowBudget, fn func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, input In) (Out, error) {
+		ctx, cancel := WithFlowBudget(ctx, budget)
+		defer cancel()
+		output, err := fn(ctx, input)
+		return output, flowBudgetError(ctx, err)
+	}
+}
+
+// chargeFlowBudget counts a request against the flow budgets of ctx, refusing it when a
+// budget is spent
+func chargeFlowBudget(ctx context.Context) error {
+	if err := flowBudgetError(ctx, ctx.Err()); err != nil {
+		return err
+	}
+	innermost, _ := ctx.Value(flowBudgetKey{}).(*flowBudgetState)
+	for state := innermost; state != nil; state = state.parent {
+		if err := state.check(); err != nil {
+			return err
+		}
+	}
+	for state := innermost; state != nil; state = state.parent {
+		state.mu.Lock()
+		state.calls++
+		state.mu.Unlock()
+	}
+	return nil
+}
+
+// recordFlowBudget adds the tokens of a finished request to the flow budgets of ctx
+func recordFlowBudget(ctx context.Context, usage *ai.GenerationUsage) {
+	if usage == nil {
+		return
+	}
+	state, _ := ctx.Value(flowBudgetKey{}).(*flowBudgetState)
+	for ; state != nil; state = state.parent {
+		state.mu.Lock()
+		state.tokens += usage.TotalTokens
+		state.mu.Unlock()
+	}
+}
+
+// flowBudgetError returns the *BudgetExceededError that cancelled ctx in place of err, or err
+func flowBudgetError(ctx context.Context, err error) error {
+	var budgetErr *BudgetExceededError
+	if err != nil && errors.As(context.Cause(ctx), &budgetErr) {
+		return budgetErr
+	}
+	return err
+}
+
+// check returns a *BudgetExceededError when the calls or tokens of the budget are spent
+func (s *flowBudgetState) check() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var limit BudgetLimit
+	switch {
+	case s.budget.MaxModelCalls > 0 && s.calls >= s.budget.MaxModelCalls:
+		limit = BudgetLimitModelCalls
+	case s.budget.MaxTotalTokens > 0 && s.tokens >= s.budget.MaxTotalTokens:
+		limit = BudgetLimitTokens
+	default:
+		return nil
+	}
+	return &BudgetExceededError{Limit: limit, Budget: s.budget, Usage: s.usage()}
+}
+
+// usage returns the usage so far; the caller holds s.mu
+func (s *flowBudgetState) usage() FlowBudgetUsage {
+	return FlowBudgetUsage{TotalTokens: s.tokens, ModelCalls: s.calls, Elapsed: time.Since(s.started)}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestFlowBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    FlowBudget
+		wantCalls int // Requests sent before the budget stops the loop
+		wantLimit BudgetLimit
+	}{
+		{name: "model calls", budget: FlowBudget{MaxModelCalls: 2}, wantCalls: 2, wantLimit: BudgetLimitModelCalls},
+		{name: "tokens", budget: FlowBudget{MaxTotalTokens: 10}, wantCalls: 2, wantLimit: BudgetLimitTokens}, // 6 tokens per call
+		{name: "unlimited", budget: FlowBudget{}, wantCalls: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+			ctx := context.Background()
+			g := genkit.Init(ctx)
+			model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+
+			var usage FlowBudgetUsage
+			agent := genkit.DefineFlow(g, "agent", WithinBudget(tt.budget, func(ctx context.Context, steps int) (string, error) {
+				defer func() { usage, _ = FlowBudgetUsageFromContext(ctx) }()
+				for i := 0; i < steps; i++ {
+					if _, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("next step")); err != nil {
+						return "", err
+					}
+				}
+				return "done", nil
+			}))
+
+			_, err := agent.Run(ctx, 5)
+			if len(bodies) != tt.wantCalls || usage.ModelCalls != tt.wantCalls || usage.TotalTokens != 6*tt.wantCalls {
+				t.Fatalf("sent %d requests, usage %+v, want %d calls", len(bodies), usage, tt.wantCalls)
+			}
+			if tt.wantLimit == "" {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				return
+			}
+			var budgetErr *BudgetExceededError
+			if !errors.As(err, &budgetErr) || budgetErr.Limit != tt.wantLimit {
+				t.Fatalf("Run() error = %v, want a %s BudgetExceededError", err, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestFlowBudgetDuration(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(400 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	})
+	run := WithinBudget(FlowBudget{MaxDuration: 50 * time.Millisecond}, func(ctx context.Context, prompt string) (*ai.ModelResponse, error) {
+		return plugin.generateText(ctx, "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage(prompt)}}, nil)
+	})
+
+	start := time.Now()
+	_, err := run(context.Background(), "hi")
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != BudgetLimitDuration {
+		t.Fatalf("error = %v, want a duration BudgetExceededError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("returned after %s, want the call aborted at the deadline", elapsed)
+	}
+}
+
+func TestNestedFlowBudget(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}
+
+	outer, cancel := WithFlowBudget(context.Background(), FlowBudget{MaxModelCalls: 2})
+	defer cancel()
+	if _, err := plugin.generateText(outer, "gpt-4o", request, nil); err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+
+	// A sub-agent with a larger budget of its own is still bound by the outer one
+	inner, cancelInner := WithFlowBudget(outer, FlowBudget{MaxModelCalls: 5})
+	defer cancelInner()
+	if _, err := plugin.generateText(inner, "gpt-4o", request, nil); err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	_, err := plugin.generateText(inner, "gpt-4o", request, nil)
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Budget.MaxModelCalls != 2 {
+		t.Fatalf("error = %v, want the outer budget exceeded", err)
+	}
+
+	innerUsage, _ := FlowBudgetUsageFromContext(inner)
+	outerUsage, _ := FlowBudgetUsageFromContext(outer)
+	if innerUsage.ModelCalls != 1 || outerUsage.ModelCalls != 2 || outerUsage.TotalTokens != 12 || len(bodies) != 2 {
+		t.Fatalf("inner usage %+v, outer usage %+v, %d requests", innerUsage, outerUsage, len(bodies))
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// BaseURLMode selects the URL layout of an API Management gateway or other front of the
+// Azure OpenAI API. Paths are shown without PathPrefix.
+type BaseURLMode string
+
+const (
+	// BaseURLModeAzure keeps Azure's layout, "/openai/deployments/<deployment>/chat/completions".
+	BaseURLModeAzure BaseURLMode = ""
+	// BaseURLModeDeployments drops the "/openai" segment,
+	// "/deployments/<deployment>/chat/completions", as gateways that add it back expect.
+	BaseURLModeDeployments BaseURLMode = "deployments"
+	// BaseURLModeModelInBody sends requests to "/chat/completions", for gateways that route
+	// by the deployment name in the body's model field.
+	BaseURLModeModelInBody BaseURLMode = "model-in-body"
+)
+
+// checkGateway reports gateway settings that cannot be used together
+func (a *AzureAIFoundry) checkGateway() error {
+	switch a.BaseURLMode {
+	case BaseURLModeAzure, BaseURLModeDeployments, BaseURLModeModelInBody:
+	default:
+		return fmt.Errorf("unknown BaseURLMode %q", a.BaseURLMode)
+	}
+	if a.OpenAICompatible && (a.BaseURLMode != BaseURLModeAzure || a.PathPrefix != "") {
+		return fmt.Errorf("BaseURLMode and PathPrefix do not apply to OpenAICompatible endpoints; include the path in Endpoint")
+	}
+	if a.UseV1API && a.BaseURLMode != BaseURLModeAzure {
+		return fmt.Errorf("BaseURLMode cannot be combined with UseV1API")
+	}
+	return nil
+}
+
+// usesGatewayPaths reports whether request paths differ from Azure's layout
+func (a *AzureAIFoundry) usesGatewayPaths() bool {
+	return a.PathPrefix != "" || a.BaseURLMode != BaseURLModeAzure
+}
+
+// gatewayPath maps the escaped path of a request in Azure's layout to the gateway's
+func (a *AzureAIFoundry) gatewayPath(path string) string {
+	if a.BaseURLMode != BaseURLModeAzure {
+		path = strings.TrimPrefix(path, "/openai")
+	}
+	if a.BaseURLMode == BaseURLModeModelInBody {
+		if rest, ok := strings.CutPrefix(path, "/deployments/"); ok {
+			if end := strings.Index(rest, "/"); end != -1 {
+				path = rest[end:]
+			}
+		}
+	}
+	if prefix := strings.Trim(a.PathPrefix, "/"); prefix != "" {
+		path = "/" + prefix + path
+	}
+	return path
+}
+
+// gatewayPathMiddleware rewrites request paths from Azure's layout to the gateway's. It runs
+// innermost, so events, audit records and endpoint failover see Azure's paths.
+func (a *AzureAIFoundry) gatewayPathMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		parsed, err := url.Parse(a.gatewayPath(req.URL.EscapedPath()))
+		if err != nil {
+			return nil, err
+		}
+		// A copy, as the middlewares before this one keep and may resend req
+		rewritten := req.Clone(req.Context())
+		rewritten.URL.Path = parsed.Path
+		rewritten.URL.RawPath = parsed.RawPath
+		return next(rewritten)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestGatewayPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*AzureAIFoundry)
+		want      []string // Paths of the chat and embedding requests
+	}{
+		{
+			name:      "azure layout",
+			configure: func(a *AzureAIFoundry) {},
+			want:      []string{"/openai/deployments/gpt-4o/chat/completions", "/openai/deployments/text-embedding-3-small/embeddings"},
+		},
+		{
+			name:      "path prefix",
+			configure: func(a *AzureAIFoundry) { a.PathPrefix = "/aoai/" },
+			want:      []string{"/aoai/openai/deployments/gpt-4o/chat/completions", "/aoai/openai/deployments/text-embedding-3-small/embeddings"},
+		},
+		{
+			name:      "deployments",
+			configure: func(a *AzureAIFoundry) { a.PathPrefix = "gateway/v2"; a.BaseURLMode = BaseURLModeDeployments },
+			want:      []string{"/gateway/v2/deployments/gpt-4o/chat/completions", "/gateway/v2/deployments/text-embedding-3-small/embeddings"},
+		},
+		{
+			name:      "model in body",
+			configure: func(a *AzureAIFoundry) { a.BaseURLMode = BaseURLModeModelInBody },
+			want:      []string{"/chat/completions", "/embeddings"},
+		},
+		{
+			name:      "v1 API with prefix",
+			configure: func(a *AzureAIFoundry) { a.PathPrefix = "/aoai"; a.UseV1API = true },
+			want:      []string{"/aoai/openai/v1/chat/completions", "/aoai/openai/v1/embeddings"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*http.Request
+			var audit bytes.Buffer
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/embeddings") {
+					_, _ = io.WriteString(w, `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]}],"usage":{"prompt_tokens":1,"total_tokens":1}}`)
+					return
+				}
+				_, _ = io.WriteString(w, chatCompletionJSON)
+			}, func(a *AzureAIFoundry) {
+				a.AuditSink = NewJSONLAuditSink(&audit)
+				tt.configure(a)
+			})
+
+			if _, err := plugin.generateText(t.Context(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if _, err := plugin.embed(t.Context(), "text-embedding-3-small", &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText("hi", nil)}}); err != nil {
+				t.Fatalf("embed() error = %v", err)
+			}
+			for i, req := range requests {
+				if req.URL.Path != tt.want[i] {
+					t.Errorf("request %d path = %s, want %s", i, req.URL.Path, tt.want[i])
+				}
+				if plugin.UseV1API == (req.URL.Query().Get("api-version") != "") {
+					t.Errorf("request %d api-version = %q", i, req.URL.Query().Get("api-version"))
+				}
+			}
+			var record AuditRecord
+			if err := json.NewDecoder(&audit).Decode(&record); err != nil || record.Model != "gpt-4o" {
+				t.Errorf("audit record model = %q, error = %v", record.Model, err)
+			}
+		})
+	}
+}
+
+func TestGatewayEventModel(t *testing.T) {
+	for _, mode := range []BaseURLMode{BaseURLModeAzure, BaseURLModeDeployments, BaseURLModeModelInBody} {
+		t.Run(string(mode), func(t *testing.T) {
+			var paths []string
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				if len(paths) == 1 {
+					w.Header().Set("Retry-After-Ms", "1")
+					w.Header().Set("x-should-retry", "false")
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, _ = io.WriteString(w, `{"error":{"code":"429","message":"Too many requests"}}`)
+					return
+				}
+				_, _ = io.WriteString(w, chatCompletionJSON)
+			}, func(a *AzureAIFoundry) {
+				a.PathPrefix = "/aoai"
+				a.BaseURLMode = mode
+				a.RateLimitRetries = 1
+			})
+			events := recordEvents(plugin)
+
+			if _, err := plugin.generateText(t.Context(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if len(paths) != 2 || !strings.HasPrefix(paths[0], "/aoai/") {
+				t.Fatalf("paths = %v", paths)
+			}
+			var retried bool
+			for _, event := range *events {
+				if event.Model != "gpt-4o" {
+					t.Errorf("%s event model = %q", event.Type, event.Model)
+				}
+				retried = retried || event.Type == EventRetry
+			}
+			if !retried {
+				t.Fatalf("events = %+v, want a retry", *events)
+			}
+		})
+	}
+}
+
+func TestGatewayFailover(t *testing.T) {
+	primary := newTestPlugin(t, failingHandler(http.StatusServiceUnavailable))
+	var paths []string
+	secondary := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	})
+	plugin := &AzureAIFoundry{
+		Endpoint:          primary.Endpoint,
+		APIKey:            "primary-key",
+		PathPrefix:        "/aoai",
+		BaseURLMode:       BaseURLModeDeployments,
+		FailoverEndpoints: []Endpoint{{URL: secondary.Endpoint}},
+	}
+	plugin.Init(t.Context())
+
+	if _, err := plugin.generateText(t.Context(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/aoai/deployments/gpt-4o/chat/completions" {
+		t.Fatalf("secondary requests = %v", paths)
+	}
+}
+
+func TestCheckGateway(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugin  *AzureAIFoundry
+		wantErr bool
+	}{
+		{"azure with prefix", &AzureAIFoundry{PathPrefix: "/aoai", BaseURLMode: BaseURLModeModelInBody}, false},
+		{"unknown mode", &AzureAIFoundry{BaseURLMode: "flat"}, true},
+		{"openai-compatible with prefix", &AzureAIFoundry{OpenAICompatible: true, PathPrefix: "/v1"}, true},
+		{"v1 API with mode", &AzureAIFoundry{UseV1API: true, BaseURLMode: BaseURLModeDeployments}, true},
+		{"v1 API with prefix", &AzureAIFoundry{UseV1API: true, PathPrefix: "/aoai"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.plugin.checkGateway(); (err != nil) != tt.wantErr {
+				t.Fatalf("checkGateway() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
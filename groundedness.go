@@ -0,0 +1,181 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// GroundednessAction selects what happens when an answer contains unsupported claims.
+type GroundednessAction string
+
+const (
+	// GroundednessAnnotate records the claim scores in the response metadata.
+	GroundednessAnnotate GroundednessAction = "annotate"
+	// GroundednessBlock returns a *GroundednessError when any claim is unsupported.
+	GroundednessBlock GroundednessAction = "block"
+)
+
+// GroundednessOptions configures a groundedness verification pass.
+type GroundednessOptions struct {
+	Model     ai.Model           // Verifier model, typically a cheaper deployment (required)
+	Threshold float64            // Minimum score for a claim to count as supported. Values <= 0 use the default of 0.5
+	Action    GroundednessAction // Annotate or block. Defaults to GroundednessAnnotate
+}
+
+// ClaimScore is the verifier's assessment of a single claim in an answer.
+type ClaimScore struct {
+	Claim     string   `json:"claim"`
+	Score     float64  `json:"score"`
+	Supported bool     `json:"supported"`
+	Sources   []string `json:"sources,omitempty"` // IDs of the supporting documents ("ref" or "id" metadata, or index)
+}
+
+// GroundednessReport summarizes how well an answer is supported by its context.
+type GroundednessReport struct {
+	Claims   []ClaimScore `json:"claims"`
+	Score    float64      `json:"score"`    // Mean claim score
+	Grounded bool         `json:"grounded"` // Whether every claim met the threshold. False if a non-empty answer yields no claims
+}
+
+// GroundednessError is returned when blocking is enabled and the answer has unsupported claims.
+type GroundednessError struct {
+	Report *GroundednessReport
+}
+
+// Error implements the error interface.
+func (e *GroundednessError) Error() string {
+	if len(e.Report.Claims) == 0 {
+		return "verifier found no claims in the answer to check against the sources"
+	}
+	var unsupported []string
+	for _, claim := range e.Report.Claims {
+		if !claim.Supported {
+			unsupported = append(unsupported, fmt.Sprintf("%q", claim.Claim))
+		}
+	}
+	return fmt.Sprintf("answer contains %d unsupported claim(s): %s", len(unsupported), strings.Join(unsupported, ", "))
+}
+
+// verifierOutput is the structured output requested from the verifier model
+type verifierOutput struct {
+	Claims []struct {
+		Claim   string   `json:"claim"`
+		Score   float64  `json:"score"`
+		Sources []string `json:"sources"`
+	} `json:"claims"`
+}
+
+// VerifyGroundedness scores each claim of a generated answer against the documents it was
+// generated from, using a separate verifier model. The report is stored in the response's
+// Custom metadata under "groundedness". With GroundednessBlock, unsupported answers are
+// rejected with a *GroundednessError instead.
+func VerifyGroundedness(ctx context.Context, g *genkit.Genkit, resp *ai.ModelResponse, docs []*ai.Document, opts GroundednessOptions) (*ai.ModelResponse, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("groundedness verification requires a verifier model")
+	}
+	if resp == nil || resp.Message == nil {
+		return nil, fmt.Errorf("no response to verify")
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 0.5
+	}
+	if opts.Action == "" {
+		opts.Action = GroundednessAnnotate
+	}
+
+	answer := resp.Text()
+	if strings.TrimSpace(answer) == "" {
+		resp.Custom = withCustomValue(resp.Custom, "groundedness", &GroundednessReport{Grounded: true})
+		return resp, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Split the answer below into its individual factual claims. For each claim, give a score ")
+	prompt.WriteString("from 0 to 1 for how well it is supported by the sources (1 means fully supported, ")
+	prompt.WriteString("0 means unsupported or contradicted) and list the identifiers of the supporting sources, ")
+	prompt.WriteString("as shown in brackets.\n\nSources:\n")
+	for i, doc := range docs {
+		fmt.Fprintf(&prompt, "[%s] %s\n", citationSourceID(doc, i), documentText(doc))
+	}
+	prompt.WriteString("\nAnswer:\n")
+	prompt.WriteString(answer)
+
+	out, _, err := genkit.GenerateData[verifierOutput](ctx, g,
+		ai.WithModel(opts.Model),
+		ai.WithMessages(ai.NewUserTextMessage(prompt.String())),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("groundedness verification failed: %w", err)
+	}
+
+	report := buildGroundednessReport(out, opts.Threshold)
+	if opts.Action == GroundednessBlock && !report.Grounded {
+		return nil, &GroundednessError{Report: report}
+	}
+
+	resp.Custom = withCustomValue(resp.Custom, "groundedness", report)
+	return resp, nil
+}
+
+// buildGroundednessReport applies the threshold to the verifier output. An answer with no
+// claims to check is not considered grounded.
+func buildGroundednessReport(out *verifierOutput, threshold float64) *GroundednessReport {
+	report := &GroundednessReport{}
+	if out == nil || len(out.Claims) == 0 {
+		return report
+	}
+	report.Grounded = true
+
+	var total float64
+	for _, c := range out.Claims {
+		claim := ClaimScore{
+			Claim:     c.Claim,
+			Score:     c.Score,
+			Supported: c.Score >= threshold,
+			Sources:   c.Sources,
+		}
+		if !claim.Supported {
+			report.Grounded = false
+		}
+		total += c.Score
+		report.Claims = append(report.Claims, claim)
+	}
+	if len(report.Claims) > 0 {
+		report.Score = total / float64(len(report.Claims))
+	}
+
+	return report
+}
+
+// withCustomValue sets key in a response's Custom metadata, preserving existing map entries
+func withCustomValue(custom any, key string, value any) map[string]any {
+	result := map[string]any{}
+	if existing, ok := custom.(map[string]any); ok {
+		for k, v := range existing {
+			result[k] = v
+		}
+	}
+	result[key] = value
+	return result
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestBuildGroundednessReport(t *testing.T) {
+	var out verifierOutput
+	if err := json.Unmarshal([]byte(`{"claims":[
+		{"claim":"Keys rotate every 90 days","score":0.9,"sources":["doc1"]},
+		{"claim":"Rotation is free","score":0.2}
+	]}`), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	report := buildGroundednessReport(&out, 0.5)
+	if report.Grounded {
+		t.Fatalf("Grounded = true, want false")
+	}
+	if len(report.Claims) != 2 || !report.Claims[0].Supported || report.Claims[1].Supported {
+		t.Fatalf("unexpected claim support: %+v", report.Claims)
+	}
+	if report.Score < 0.549 || report.Score > 0.551 {
+		t.Fatalf("Score = %v, want 0.55", report.Score)
+	}
+
+	err := &GroundednessError{Report: report}
+	if !strings.Contains(err.Error(), `"Rotation is free"`) {
+		t.Fatalf("error %q does not name the unsupported claim", err.Error())
+	}
+}
+
+func TestBuildGroundednessReportWithoutClaims(t *testing.T) {
+	if report := buildGroundednessReport(&verifierOutput{}, 0.5); report.Grounded {
+		t.Fatalf("Grounded = true for an answer without claims")
+	}
+}
+
+func TestVerifyGroundedness(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+
+	var verifierPrompt string
+	verifier := genkit.DefineModel(g, "test/verifier", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			verifierPrompt = req.Messages[0].Text()
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(`{"claims":[
+				{"claim":"Keys rotate every 90 days","score":0.9,"sources":["doc1"]},
+				{"claim":"Rotation is free","score":0.1,"sources":[]}
+			]}`)}, nil
+		})
+	docs := []*ai.Document{ai.DocumentFromText("Keys rotate every 90 days.", map[string]any{"ref": "doc1"})}
+
+	tests := []struct {
+		name        string
+		opts        GroundednessOptions
+		wantErr     string
+		wantBlocked bool
+	}{
+		{
+			name:    "requires verifier model",
+			opts:    GroundednessOptions{},
+			wantErr: "groundedness verification requires a verifier model",
+		},
+		{
+			name: "annotate stores report",
+			opts: GroundednessOptions{Model: verifier, Action: GroundednessAnnotate},
+		},
+		{
+			name:        "block rejects unsupported claims",
+			opts:        GroundednessOptions{Model: verifier, Action: GroundednessBlock},
+			wantBlocked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer := &ai.ModelResponse{Message: ai.NewModelTextMessage("Keys rotate every 90 days and rotation is free.")}
+			resp, err := VerifyGroundedness(ctx, g, answer, docs, tt.opts)
+
+			switch {
+			case tt.wantErr != "":
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			case tt.wantBlocked:
+				var groundErr *GroundednessError
+				if !errors.As(err, &groundErr) {
+					t.Fatalf("error = %v, want *GroundednessError", err)
+				}
+				if groundErr.Report.Grounded || len(groundErr.Report.Claims) != 2 {
+					t.Fatalf("unexpected report: %+v", groundErr.Report)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VerifyGroundedness() error = %v", err)
+			}
+			if !strings.Contains(verifierPrompt, "[doc1] Keys rotate every 90 days.") {
+				t.Fatalf("verifier prompt does not show the source ref: %q", verifierPrompt)
+			}
+			report, ok := resp.Custom.(map[string]any)["groundedness"].(*GroundednessReport)
+			if !ok {
+				t.Fatalf("Custom = %v, want groundedness report", resp.Custom)
+			}
+			if report.Grounded || report.Claims[0].Sources[0] != "doc1" {
+				t.Fatalf("unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestWithCustomValuePreservesEntries(t *testing.T) {
+	custom := withCustomValue(map[string]any{"existing": 1}, "groundedness", "report")
+	if custom["existing"] != 1 || custom["groundedness"] != "report" {
+		t.Fatalf("withCustomValue() = %v", custom)
+	}
+	if got := withCustomValue(nil, "k", "v"); got["k"] != "v" {
+		t.Fatalf("withCustomValue(nil) = %v", got)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// GuardrailProfile bundles the safety settings of a model or request, so they are managed in
+// one place instead of across config options. Profiles are declared in
+// AzureAIFoundry.Guardrails or RegistryConfig.Guardrails and attached by name with
+// ModelDefinition.Guardrail, ModelConfig.Guardrail or the "guardrail" config option. When a
+// model and a request both name a profile, both apply.
+type GuardrailProfile struct {
+	Moderation      *GuardrailModeration `json:"moderation,omitempty"`      // Screens the last user message before the request is sent
+	BlockedTopics   []string             `json:"blockedTopics,omitempty"`   // Topics the model is told in a system message to decline
+	MaxOutputTokens int64                `json:"maxOutputTokens,omitempty"` // Upper bound of the maxOutputTokens config option, and its default
+	AllowedTools    []string             `json:"allowedTools,omitempty"`    // Names of the tools requests may offer the model. Empty allows all tools
+	Regions         []string             `json:"regions,omitempty"`         // Azure regions requests may be sent to, checked against the plugin's Region
+}
+
+// GuardrailModeration configures the moderation of a GuardrailProfile, which scores the last
+// user message of each request like ModerateContent.
+type GuardrailModeration struct {
+	Model       string                         `json:"model"`                 // Chat deployment of the plugin that scores messages (required)
+	Categories  []string                       `json:"categories,omitempty"`  // Categories to score. Defaults to DefaultModerationCategories
+	Thresholds  map[string]ModerationThreshold `json:"thresholds,omitempty"`  // Per-category thresholds. Defaults to review at 0.5 and block at 0.8
+	Policy      string                         `json:"policy,omitempty"`      // Community guidelines included in the prompt
+	BlockReview bool                           `json:"blockReview,omitempty"` // Also reject messages that reach a review threshold
+}
+
+// GuardrailError is returned when a request violates a guardrail profile.
+type GuardrailError struct {
+	Profile    string
+	Reason     string
+	Moderation *ModerationResult // Set when the request was rejected by moderation
+}
+
+// Error implements the error interface.
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("guardrail profile %q: %s", e.Profile, e.Reason)
+}
+
+// moderationJSONShape tells a scoring model called without a Genkit output schema how to reply
+const moderationJSONShape = `Reply with a JSON object like {"scores":[{"category":"hate","score":0.1}],"rationale":"..."}.`
+
+// guardrail returns the profile with the given name, from Guardrails or the registry
+func (a *AzureAIFoundry) guardrail(name string) (GuardrailProfile, bool) {
+	if profile, ok := a.Guardrails[name]; ok {
+		return profile, true
+	}
+	a.registry.mu.RLock()
+	defer a.registry.mu.RUnlock()
+	if a.registry.config == nil {
+		return GuardrailProfile{}, false
+	}
+	profile, ok := a.registry.config.Guardrails[name]
+	return profile, ok
+}
+
+// withGuardrails enforces the model's guardrail profile, and the profile named by the
+// "guardrail" config option of each request
+func (a *AzureAIFoundry) withGuardrails(modelProfile string, fn ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		config, _ := normalizeConfig(input.Config)
+		var names []string
+		if modelProfile != "" {
+			names = append(names, modelProfile)
+		}
+		if name, _ := config["guardrail"].(string); name != "" && name != modelProfile {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return fn(ctx, input, cb)
+		}
+
+		for _, name := range names {
+			profile, ok := a.guardrail(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown guardrail profile %q", name)
+			}
+			var err error
+			if input, err = a.applyGuardrail(ctx, name, profile, input); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := fn(ctx, input, cb)
+		if resp != nil {
+			resp.Custom = withCustomValue(resp.Custom, "guardrails", names)
+		}
+		return resp, err
+	}
+}
+
+// applyGuardrail checks a request against a profile and returns it with the profile's
+// system message and output limit applied
+func (a *AzureAIFoundry) applyGuardrail(ctx context.Context, name string, profile GuardrailProfile, input *ai.ModelRequest) (*ai.ModelRequest, error) {
+	if len(profile.Regions) > 0 && !slices.ContainsFunc(profile.Regions, func(region string) bool {
+		return a.Region != "" && normalizeRegion(region) == normalizeRegion(a.Region)
+	}) {
+		region := a.Region
+		if region == "" {
+			region = "an unknown region"
+		}
+		return nil, &GuardrailError{Profile: name, Reason: fmt.Sprintf("requests may not be sent to %s (allowed: %s)",
+			region, strings.Join(profile.Regions, ", "))}
+	}
+	if len(profile.AllowedTools) > 0 {
+		for _, tool := range input.Tools {
+			if !slices.Contains(profile.AllowedTools, tool.Name) {
+				return nil, &GuardrailError{Profile: name, Reason: fmt.Sprintf("tool %q is not allowed", tool.Name)}
+			}
+		}
+	}
+	if profile.Moderation != nil {
+		if err := a.moderateRequest(ctx, name, profile.Moderation, input); err != nil {
+			return nil, err
+		}
+	}
+
+	req := *input
+	if len(profile.BlockedTopics) > 0 {
+		instruction := fmt.Sprintf("Do not discuss the following topics: %s. If the user asks about them, politely decline.",
+			strings.Join(profile.BlockedTopics, "; "))
+		req.Messages = append([]*ai.Message{ai.NewSystemTextMessage(instruction)}, input.Messages...)
+	}
+	if profile.MaxOutputTokens > 0 {
+		config, _ := normalizeConfig(input.Config)
+		if maxTokens, ok := configInt(config["maxOutputTokens"]); !ok || maxTokens > profile.MaxOutputTokens {
+			capped := make(map[string]any, len(config)+1)
+			for key, val := range config {
+				capped[key] = val
+			}
+			capped["maxOutputTokens"] = profile.MaxOutputTokens
+			req.Config = capped
+		}
+	}
+	return &req, nil
+}
+
+// moderateRequest scores the last user message of a request with the profile's moderation
+// deployment, failing with *GuardrailError when it is blocked
+func (a *AzureAIFoundry) moderateRequest(ctx context.Context, name string, moderation *GuardrailModeration, input *ai.ModelRequest) error {
+	var content UserContent
+	for _, msg := range slices.Backward(input.Messages) {
+		if msg.Role != ai.RoleUser {
+			continue
+		}
+		for _, part := range msg.Content {
+			switch {
+			case part.IsText():
+				content.Text += part.Text
+			case part.IsMedia() && strings.HasPrefix(part.ContentType, "image/"):
+				content.Media = append(content.Media, part)
+			}
+		}
+		break
+	}
+	if strings.TrimSpace(content.Text) == "" && len(content.Media) == 0 {
+		return nil
+	}
+
+	result, err := moderate(content, moderation.Categories, moderation.Thresholds, moderation.Policy, func(parts []*ai.Part) (*ai.ModelResponse, error) {
+		parts = append([]*ai.Part{ai.NewTextPart(moderationJSONShape + "\n\n")}, parts...)
+		return a.generateText(ctx, moderation.Model, &ai.ModelRequest{
+			Messages: []*ai.Message{ai.NewUserMessage(parts...)},
+			Output:   &ai.ModelOutputConfig{Format: "json"},
+		}, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("guardrail profile %q: %w", name, err)
+	}
+	if result.Decision == ModerationBlock || (moderation.BlockReview && result.Decision == ModerationReview) {
+		reason := "message blocked by moderation"
+		if len(result.Flagged) > 0 {
+			reason += " (" + strings.Join(result.Flagged, ", ") + ")"
+		}
+		return &GuardrailError{Profile: name, Reason: reason, Moderation: result}
+	}
+	return nil
+}
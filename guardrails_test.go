@@ -0,0 +1,213 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// moderationCompletionJSON is a chat completion whose answer scores content as harassment
+func moderationCompletionJSON(harassment float64) string {
+	answer, _ := json.Marshal(map[string]any{
+		"scores":    []map[string]any{{"category": "harassment", "score": harassment}},
+		"rationale": "Scored.",
+	})
+	quoted, _ := json.Marshal(string(answer))
+	return strings.Replace(chatCompletionJSON, `"content":"ok"`, `"content":`+string(quoted), 1)
+}
+
+func TestGuardrails(t *testing.T) {
+	profiles := map[string]GuardrailProfile{
+		"support": {
+			BlockedTopics:   []string{"legal advice", "competitors"},
+			MaxOutputTokens: 200,
+			AllowedTools:    []string{"lookupOrder"},
+		},
+		"eu-only":   {Regions: []string{"West Europe", "swedencentral"}},
+		"moderated": {Moderation: &GuardrailModeration{Model: "gpt-4o-mini"}},
+		"strict":    {Moderation: &GuardrailModeration{Model: "gpt-4o-mini", BlockReview: true}},
+	}
+	tests := []struct {
+		name          string
+		modelProfile  string
+		config        map[string]any
+		tools         []string
+		region        string
+		harassment    float64
+		wantErr       string
+		wantBlocked   bool // Whether the error is a *GuardrailError
+		wantRequests  int
+		wantMaxTokens float64
+		wantSystem    string
+	}{
+		{
+			name: "model profile adds topics and caps tokens", modelProfile: "support",
+			config:       map[string]any{"maxOutputTokens": 4000},
+			wantRequests: 1, wantMaxTokens: 200, wantSystem: "legal advice; competitors",
+		},
+		{
+			name: "lower token limit is kept", modelProfile: "support",
+			config:       map[string]any{"maxOutputTokens": 50},
+			wantRequests: 1, wantMaxTokens: 50,
+		},
+		{
+			name: "allowed tool", modelProfile: "support", tools: []string{"lookupOrder"},
+			wantRequests: 1, wantMaxTokens: 200,
+		},
+		{
+			name: "tool not allowed", modelProfile: "support", tools: []string{"lookupOrder", "refund"},
+			wantErr: `tool "refund" is not allowed`, wantBlocked: true,
+		},
+		{
+			name: "request profile", config: map[string]any{"guardrail": "support"},
+			wantRequests: 1, wantMaxTokens: 200,
+		},
+		{
+			name: "allowed region", config: map[string]any{"guardrail": "eu-only"}, region: "westeurope",
+			wantRequests: 1,
+		},
+		{
+			name: "region not allowed", config: map[string]any{"guardrail": "eu-only"}, region: "eastus",
+			wantErr: "may not be sent to eastus", wantBlocked: true,
+		},
+		{
+			name: "model and request profiles both apply", modelProfile: "support", config: map[string]any{"guardrail": "eu-only"}, region: "eastus",
+			wantErr: "may not be sent to eastus", wantBlocked: true,
+		},
+		{
+			name: "moderation allows", config: map[string]any{"guardrail": "moderated"}, harassment: 0.1,
+			wantRequests: 2,
+		},
+		{
+			name: "moderation blocks", config: map[string]any{"guardrail": "moderated"}, harassment: 0.9,
+			wantErr: "blocked by moderation (harassment)", wantBlocked: true, wantRequests: 1,
+		},
+		{
+			name: "review allowed by default", config: map[string]any{"guardrail": "moderated"}, harassment: 0.6,
+			wantRequests: 2,
+		},
+		{
+			name: "review blocked", config: map[string]any{"guardrail": "strict"}, harassment: 0.6,
+			wantErr: "blocked by moderation", wantBlocked: true, wantRequests: 1,
+		},
+		{
+			name: "unknown profile", config: map[string]any{"guardrail": "missing"},
+			wantErr: `unknown guardrail profile "missing"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				var body map[string]any
+				_ = json.Unmarshal(data, &body)
+				bodies = append(bodies, body)
+				w.Header().Set("Content-Type", "application/json")
+				if strings.Contains(string(data), "trust and safety reviewer") {
+					_, _ = io.WriteString(w, moderationCompletionJSON(tt.harassment))
+					return
+				}
+				_, _ = io.WriteString(w, chatCompletionJSON)
+			}, func(a *AzureAIFoundry) {
+				a.Guardrails = profiles
+				a.Region = tt.region
+			})
+			_, fn := plugin.modelAction(ModelDefinition{Name: "gpt-4o", Guardrail: tt.modelProfile}, nil)
+
+			input := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("Where is my order?")}, Config: tt.config}
+			for _, tool := range tt.tools {
+				input.Tools = append(input.Tools, &ai.ToolDefinition{Name: tool, InputSchema: map[string]any{"type": "object"}})
+			}
+			resp, err := fn(t.Context(), input, nil)
+			if tt.wantErr != "" {
+				var guardErr *GuardrailError
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || errors.As(err, &guardErr) != tt.wantBlocked {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(bodies) != tt.wantRequests {
+					t.Fatalf("sent %d requests, want %d", len(bodies), tt.wantRequests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if len(bodies) != tt.wantRequests {
+				t.Fatalf("sent %d requests, want %d", len(bodies), tt.wantRequests)
+			}
+			if names, _ := resp.Custom.(map[string]any)["guardrails"].([]string); len(names) == 0 {
+				t.Errorf("guardrails custom value = %v", resp.Custom)
+			}
+
+			body := bodies[len(bodies)-1]
+			if tt.wantMaxTokens != 0 && body["max_tokens"] != tt.wantMaxTokens {
+				t.Errorf("max_tokens = %v, want %v", body["max_tokens"], tt.wantMaxTokens)
+			}
+			if tt.wantSystem != "" {
+				messages, _ := body["messages"].([]any)
+				first, _ := messages[0].(map[string]any)
+				if first["role"] != "system" || !strings.Contains(first["content"].(string), tt.wantSystem) {
+					t.Errorf("first message = %v, want a system message blocking %s", first, tt.wantSystem)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryGuardrails(t *testing.T) {
+	config, err := LoadModelsFromConfig([]byte(`
+guardrails:
+  support:
+    maxOutputTokens: 100
+    blockedTopics: [pricing]
+models:
+  - name: gpt-4o
+    guardrail: support
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.Registry = config
+	})
+	fn := plugin.registry.modelFunc("gpt-4o")
+	if _, err := fn(t.Context(), &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bodies[0]["max_tokens"] != float64(100) {
+		t.Fatalf("max_tokens = %v, want 100", bodies[0]["max_tokens"])
+	}
+
+	if _, err := LoadModelsFromConfig([]byte(`
+guardrails:
+  broken:
+    moderation: {policy: "be nice"}
+models: []
+`)); err == nil || !strings.Contains(err.Error(), "moderation without a model") {
+		t.Fatalf("LoadModelsFromConfig() error = %v, want moderation without a model", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// speechChunkSize is the most audio read from a speech response before it is streamed
+const speechChunkSize = 16 * 1024
+
+// SSEWriter streams model output to an HTTP client as server-sent events. Chunk is a stream
+// callback, so it can be passed to ai.WithStreaming or a flow's streaming callback:
+//
+//	sse := azureaifoundry.NewSSEWriter(w)
+//	resp, err := genkit.Generate(r.Context(), g, ai.WithPrompt(prompt), ai.WithStreaming(sse.Chunk))
+//	if err != nil {
+//		sse.Error(err)
+//		return
+//	}
+//	sse.Done(resp)
+//
+// Each part is sent as a "text", "reasoning", "audio" or "media" event with a JSON payload,
+// followed by one "done" or "error" event.
+type SSEWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+// NewSSEWriter returns an SSEWriter for w. The event stream headers are written with the
+// first event.
+func NewSSEWriter(w http.ResponseWriter) *SSEWriter {
+	return &SSEWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+// sseMedia is the payload of "audio" and "media" events
+type sseMedia struct {
+	ContentType string `json:"contentType"`
+	Data        string `json:"data"` // Base64 for inline media, otherwise the URL
+}
+
+// Chunk sends the parts of a streamed chunk as events and flushes them to the client. It
+// returns an error when the client is gone, which stops the generation.
+func (s *SSEWriter) Chunk(_ context.Context, chunk *ai.ModelResponseChunk) error {
+	for _, part := range chunk.Content {
+		var err error
+		switch {
+		case part.IsReasoning():
+			err = s.Event("reasoning", map[string]string{"text": part.Text})
+		case part.IsText():
+			err = s.Event("text", map[string]string{"text": part.Text})
+		case part.IsMedia():
+			event := "media"
+			if strings.HasPrefix(part.ContentType, "audio/") {
+				event = "audio"
+			}
+			data := part.Text
+			if _, b64, ok := strings.Cut(data, ";base64,"); ok && strings.HasPrefix(data, "data:") {
+				data = b64
+			}
+			err = s.Event(event, sseMedia{ContentType: part.ContentType, Data: data})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Done sends a "done" event with the finish reason and usage of the final response.
+func (s *SSEWriter) Done(resp *ai.ModelResponse) error {
+	done := struct {
+		FinishReason ai.FinishReason     `json:"finishReason,omitempty"`
+		Usage        *ai.GenerationUsage `json:"usage,omitempty"`
+	}{}
+	if resp != nil {
+		done.FinishReason, done.Usage = resp.FinishReason, resp.Usage
+	}
+	return s.Event("done", done)
+}
+
+// Error sends an "error" event with the error's message.
+func (s *SSEWriter) Error(err error) error {
+	return s.Event("error", map[string]string{"error": err.Error()})
+}
+
+// Event sends a named event with a JSON payload and flushes it to the client.
+func (s *SSEWriter) Event(name string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", name, err)
+	}
+	if !s.started {
+		header := s.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no") // Keep proxies such as nginx from buffering the stream
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	return flush(s.rc)
+}
+
+// AudioWriter streams generated speech to an HTTP client as a chunked audio response, for
+// players that start before the whole file has arrived. Chunk is a stream callback:
+//
+//	audio := azureaifoundry.NewAudioWriter(w)
+//	_, err := genkit.Generate(r.Context(), g,
+//		ai.WithModelName("azureaifoundry/tts-1"),
+//		ai.WithPrompt(text),
+//		ai.WithConfig(map[string]any{"response_format": "mp3"}),
+//		ai.WithStreaming(audio.Chunk))
+//
+// The Content-Type header is taken from the first audio part. Parts that are not inline
+// audio are skipped.
+type AudioWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+// NewAudioWriter returns an AudioWriter for w.
+func NewAudioWriter(w http.ResponseWriter) *AudioWriter {
+	return &AudioWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+// Chunk writes the audio in a streamed chunk and flushes it to the client. It returns an
+// error when the client is gone, which stops the generation.
+func (a *AudioWriter) Chunk(_ context.Context, chunk *ai.ModelResponseChunk) error {
+	for _, part := range chunk.Content {
+		if !part.IsMedia() || !strings.HasPrefix(part.ContentType, "audio/") {
+			continue
+		}
+		_, b64, ok := strings.Cut(part.Text, ";base64,")
+		if !ok {
+			continue
+		}
+		audio, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return fmt.Errorf("failed to decode audio chunk: %w", err)
+		}
+		if !a.started {
+			a.w.Header().Set("Content-Type", part.ContentType)
+			a.w.Header().Set("Cache-Control", "no-cache")
+			a.w.WriteHeader(http.StatusOK)
+			a.started = true
+		}
+		if _, err := a.w.Write(audio); err != nil {
+			return err
+		}
+		if err := flush(a.rc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Started reports whether audio was written, after which an error can no longer be sent
+// as an HTTP status.
+func (a *AudioWriter) Started() bool {
+	return a.started
+}
+
+// flush sends buffered output to the client, ignoring writers that cannot flush
+func flush(rc *http.ResponseController) error {
+	if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// readSpeech reads a speech response body, passing each piece to onAudio when it is set
+func readSpeech(body io.Reader, onAudio func([]byte) error) ([]byte, error) {
+	if onAudio == nil {
+		return io.ReadAll(body)
+	}
+	var audio []byte
+	buf := make([]byte, speechChunkSize)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			audio = append(audio, buf[:n]...)
+			if cbErr := onAudio(buf[:n]); cbErr != nil {
+				return nil, cbErr
+			}
+		}
+		if err == io.EOF {
+			return audio, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// speechChunkPart returns a streamed piece of speech as an inline media part
+func speechChunkPart(format string, audio []byte) *ai.Part {
+	contentType := speechMIMETypes[format]
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return ai.NewMediaPart(contentType, "data:"+contentType+";base64,"+base64.StdEncoding.EncodeToString(audio))
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestSSEWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	sse := NewSSEWriter(recorder)
+	chunks := []*ai.ModelResponseChunk{
+		{Content: []*ai.Part{ai.NewReasoningPart("thinking", nil)}},
+		{Content: []*ai.Part{ai.NewTextPart("Hello\nworld")}},
+		{Content: []*ai.Part{ai.NewMediaPart("audio/mpeg", "data:audio/mpeg;base64,AAEC")}},
+		{Content: []*ai.Part{ai.NewMediaPart("image/png", "https://example.com/cat.png")}},
+	}
+	for _, chunk := range chunks {
+		if err := sse.Chunk(context.Background(), chunk); err != nil {
+			t.Fatalf("Chunk() error = %v", err)
+		}
+	}
+	if err := sse.Done(&ai.ModelResponse{FinishReason: ai.FinishReasonStop}); err != nil {
+		t.Fatalf("Done() error = %v", err)
+	}
+	if err := sse.Error(errors.New("late failure")); err != nil {
+		t.Fatalf("Error() error = %v", err)
+	}
+
+	want := "event: reasoning\ndata: {\"text\":\"thinking\"}\n\n" +
+		"event: text\ndata: {\"text\":\"Hello\\nworld\"}\n\n" +
+		"event: audio\ndata: {\"contentType\":\"audio/mpeg\",\"data\":\"AAEC\"}\n\n" +
+		"event: media\ndata: {\"contentType\":\"image/png\",\"data\":\"https://example.com/cat.png\"}\n\n" +
+		"event: done\ndata: {\"finishReason\":\"stop\"}\n\n" +
+		"event: error\ndata: {\"error\":\"late failure\"}\n\n"
+	if got := recorder.Body.String(); got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+	if !recorder.Flushed {
+		t.Fatal("events were not flushed")
+	}
+}
+
+func TestAudioWriter(t *testing.T) {
+	speech := bytes.Repeat([]byte{1, 2, 3, 4, 5}, speechChunkSize)
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(speech)
+	})
+
+	tests := []struct {
+		format   string
+		wantType string
+	}{
+		{format: "mp3", wantType: "audio/mpeg"},
+		{format: "pcm", wantType: "audio/pcm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			audio := NewAudioWriter(recorder)
+			chunks := 0
+			resp, err := plugin.generateText(context.Background(), "tts-1", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("Hello")},
+				Config:   map[string]any{"response_format": tt.format},
+			}, func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+				chunks++
+				return audio.Chunk(ctx, chunk)
+			})
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if !bytes.Equal(recorder.Body.Bytes(), speech) {
+				t.Fatalf("streamed %d bytes, want %d", recorder.Body.Len(), len(speech))
+			}
+			if want := len(speech) / speechChunkSize; chunks < want {
+				t.Fatalf("got %d chunks, want at least %d", chunks, want)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if !audio.Started() || !recorder.Flushed {
+				t.Fatal("audio was not flushed")
+			}
+			if got := PartMetadataOf(resp.Message.Content[0]).ByteSize; got != len(speech) {
+				t.Fatalf("final response byteSize = %d, want %d", got, len(speech))
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+//go:build integration
+
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// The conformance suite runs the examples' scenarios against a real Azure resource:
+//
+//	AZURE_OPENAI_ENDPOINT=https://my-resource.openai.azure.com/ \
+//	AZURE_OPENAI_API_KEY=... \
+//	AZURE_FOUNDRY_DEPLOYMENTS=chat=gpt-4o,image=dall-e-3,tts=tts,stt=whisper,embedding=text-embedding-3-small \
+//	AZURE_FOUNDRY_CONFORMANCE_REPORT=conformance.md \
+//	go test -tags integration -run TestAzureConformance -v .
+//
+// Checks without a deployment are skipped. Streaming, tools and vision use the chat deployment
+// unless mapped themselves.
+
+// conformanceChecks are the checks of the suite, in the order they run and are reported
+var conformanceChecks = []string{"chat", "streaming", "tools", "vision", "image", "tts", "stt", "embedding"}
+
+// conformanceOperations are the operations deployments of non-chat checks must be recognized as
+var conformanceOperations = map[string]string{
+	"image": OperationImage,
+	"tts":   OperationSpeech,
+	"stt":   OperationTranscription,
+}
+
+// conformanceResult is the outcome of one check
+type conformanceResult struct {
+	check      string
+	deployment string
+	status     string
+	duration   time.Duration
+	detail     string
+}
+
+// conformanceReport collects the results of the suite
+type conformanceReport struct {
+	mu      sync.Mutex
+	results []conformanceResult
+}
+
+func (r *conformanceReport) add(result conformanceResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+}
+
+// markdown renders the report as a Markdown table
+func (r *conformanceReport) markdown(endpoint string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Azure AI Foundry conformance report\n\nEndpoint: %s\nRun: %s\n\n", endpoint, time.Now().UTC().Format(time.RFC3339))
+	b.WriteString("| Check | Deployment | Status | Duration | Detail |\n|---|---|---|---|---|\n")
+	for _, result := range r.results {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", result.check, result.deployment, result.status,
+			result.duration.Round(time.Millisecond), strings.NewReplacer("\n", " ", "|", "\\|").Replace(result.detail))
+	}
+	return b.String()
+}
+
+// parseDeployments parses "check=deployment" pairs separated by commas
+func parseDeployments(value string) (map[string]string, error) {
+	deployments := map[string]string{}
+	for pair := range strings.SplitSeq(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		check, deployment, ok := strings.Cut(pair, "=")
+		if !ok || deployment == "" {
+			return nil, fmt.Errorf("deployment mapping %q is not check=deployment", pair)
+		}
+		if !slices.Contains(conformanceChecks, check) {
+			return nil, fmt.Errorf("unknown check %q, want one of %s", check, strings.Join(conformanceChecks, ", "))
+		}
+		deployments[check] = deployment
+	}
+	for _, check := range []string{"streaming", "tools", "vision"} {
+		if _, ok := deployments[check]; !ok && deployments["chat"] != "" {
+			deployments[check] = deployments["chat"]
+		}
+	}
+	return deployments, nil
+}
+
+func TestAzureConformance(t *testing.T) {
+	endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+	apiKey := os.Getenv("AZURE_OPENAI_API_KEY")
+	if endpoint == "" || apiKey == "" {
+		t.Skip("AZURE_OPENAI_ENDPOINT and AZURE_OPENAI_API_KEY must be set")
+	}
+	deployments, err := parseDeployments(os.Getenv("AZURE_FOUNDRY_DEPLOYMENTS"))
+	if err != nil {
+		t.Fatalf("AZURE_FOUNDRY_DEPLOYMENTS: %v", err)
+	}
+
+	ctx := t.Context()
+	plugin := &AzureAIFoundry{Endpoint: endpoint, APIKey: apiKey, APIVersion: os.Getenv("AZURE_OPENAI_API_VERSION")}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	models := map[string]ai.Model{}
+	model := func(deployment string) ai.Model {
+		if models[deployment] == nil {
+			models[deployment] = plugin.DefineModel(g, ModelDefinition{Name: deployment, Type: "chat", SupportsMedia: true}, nil)
+		}
+		return models[deployment]
+	}
+
+	report := &conformanceReport{}
+	t.Cleanup(func() {
+		out := report.markdown(endpoint)
+		t.Log("\n" + out)
+		if path := os.Getenv("AZURE_FOUNDRY_CONFORMANCE_REPORT"); path != "" {
+			if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+				t.Errorf("write conformance report: %v", err)
+			}
+		}
+	})
+
+	// speech is the audio generated by the tts check, transcribed by the stt check
+	var speech []byte
+	checks := map[string]func(ctx context.Context, deployment string) (string, error){
+		"chat": func(ctx context.Context, deployment string) (string, error) {
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)),
+				ai.WithPrompt("Reply with the single word: pong"))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			if !strings.Contains(strings.ToLower(resp.Text()), "pong") {
+				return "", fmt.Errorf("answer %q does not contain pong", resp.Text())
+			}
+			if resp.Usage == nil || resp.Usage.TotalTokens == 0 {
+				return "", errors.New("no usage reported")
+			}
+			return fmt.Sprintf("%d tokens", resp.Usage.TotalTokens), nil
+		},
+		"streaming": func(ctx context.Context, deployment string) (string, error) {
+			var chunks int
+			var streamed strings.Builder
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)),
+				ai.WithPrompt("Count from one to ten in words, separated by spaces."),
+				ai.WithStreaming(func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+					chunks++
+					streamed.WriteString(chunk.Text())
+					return nil
+				}))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			if chunks < 2 {
+				return "", fmt.Errorf("received %d chunks, want the answer streamed", chunks)
+			}
+			if streamed.String() != resp.Text() {
+				return "", fmt.Errorf("streamed text %q differs from the answer %q", streamed.String(), resp.Text())
+			}
+			return fmt.Sprintf("%d chunks", chunks), nil
+		},
+		"tools": func(ctx context.Context, deployment string) (string, error) {
+			var calls int
+			weather := genkit.DefineTool(g, "conformance_weather", "Returns the current temperature of a city in Celsius",
+				func(ctx *ai.ToolContext, input struct {
+					City string `json:"city"`
+				}) (int, error) {
+					calls++
+					return 17, nil
+				})
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)), ai.WithTools(weather),
+				ai.WithPrompt("What is the temperature in Madrid right now? Use the tool."))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			if calls == 0 {
+				return "", errors.New("the tool was not called")
+			}
+			if !strings.Contains(resp.Text(), "17") {
+				return "", fmt.Errorf("answer %q does not use the tool result", resp.Text())
+			}
+			return fmt.Sprintf("%d tool calls", calls), nil
+		},
+		"vision": func(ctx context.Context, deployment string) (string, error) {
+			img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+			for x := range 64 {
+				for y := range 64 {
+					img.Set(x, y, color.RGBA{R: 255, A: 255})
+				}
+			}
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err != nil {
+				return "", err
+			}
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)),
+				ai.WithMessages(ai.NewUserMessage(
+					ai.NewTextPart("What color is this image? Answer with one word."),
+					ai.NewMediaPart("image/png", "data:image/png;base64,"+base64.StdEncoding.EncodeToString(buf.Bytes())),
+				)))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			if !strings.Contains(strings.ToLower(resp.Text()), "red") {
+				return "", fmt.Errorf("answer %q does not name the color red", resp.Text())
+			}
+			return "", nil
+		},
+		"image": func(ctx context.Context, deployment string) (string, error) {
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)),
+				ai.WithPrompt("A red apple on a white table"),
+				ai.WithConfig(map[string]any{"n": 1, "size": "1024x1024"}))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			if resp.Message == nil || len(resp.Message.Content) == 0 {
+				return "", errors.New("no image returned")
+			}
+			return fmt.Sprintf("%d parts", len(resp.Message.Content)), nil
+		},
+		"tts": func(ctx context.Context, deployment string) (string, error) {
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)),
+				ai.WithPrompt("The quick brown fox jumps over the lazy dog."),
+				ai.WithConfig(map[string]any{"voice": "alloy", "response_format": "mp3"}))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			audio, err := base64.StdEncoding.DecodeString(resp.Text())
+			if err != nil || len(audio) == 0 {
+				return "", fmt.Errorf("no base64 audio returned: %v", err)
+			}
+			speech = audio
+			return fmt.Sprintf("%d bytes", len(audio)), nil
+		},
+		"stt": func(ctx context.Context, deployment string) (string, error) {
+			audio, want := speech, "fox"
+			if audio == nil {
+				// Without the tts check, transcribe the text_to_speech example's output
+				var err error
+				if audio, err = os.ReadFile("examples/speech_to_text/output_alloy.mp3"); err != nil {
+					return "", err
+				}
+				want = ""
+			}
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model(deployment)),
+				ai.WithMessages(ai.NewUserMessage(
+					ai.NewMediaPart("audio/mp3", "data:audio/mp3;base64,"+base64.StdEncoding.EncodeToString(audio)),
+				)),
+				ai.WithConfig(map[string]any{"language": "en", "response_format": "json"}))
+			if err != nil {
+				return "", fmt.Errorf("generate: %w", err)
+			}
+			text := strings.ToLower(resp.Text())
+			if text == "" || !strings.Contains(text, want) {
+				return "", fmt.Errorf("transcript %q does not contain %q", resp.Text(), want)
+			}
+			return fmt.Sprintf("%q", resp.Text()), nil
+		},
+		"embedding": func(ctx context.Context, deployment string) (string, error) {
+			embedder := plugin.DefineEmbedder(g, deployment)
+			resp, err := embedder.Embed(ctx, &ai.EmbedRequest{Input: []*ai.Document{
+				ai.DocumentFromText("Azure AI Foundry is a cloud-based AI platform.", nil),
+				ai.DocumentFromText("The weather is sunny today.", nil),
+			}})
+			if err != nil {
+				return "", fmt.Errorf("embed: %w", err)
+			}
+			if len(resp.Embeddings) != 2 || len(resp.Embeddings[0].Embedding) == 0 ||
+				len(resp.Embeddings[0].Embedding) != len(resp.Embeddings[1].Embedding) {
+				return "", fmt.Errorf("got %d embeddings, want 2 of the same non-zero dimension", len(resp.Embeddings))
+			}
+			return fmt.Sprintf("%d dimensions", len(resp.Embeddings[0].Embedding)), nil
+		},
+	}
+
+	for _, check := range conformanceChecks {
+		t.Run(check, func(t *testing.T) {
+			deployment := deployments[check]
+			result := conformanceResult{check: check, deployment: deployment}
+			start := time.Now()
+			defer func() {
+				result.duration = time.Since(start)
+				switch {
+				case t.Failed():
+					result.status = "FAIL"
+				case t.Skipped():
+					result.status = "SKIP"
+				default:
+					result.status = "PASS"
+				}
+				report.add(result)
+			}()
+
+			if deployment == "" {
+				result.detail = "no deployment mapped"
+				t.Skipf("no deployment mapped for %s in AZURE_FOUNDRY_DEPLOYMENTS", check)
+			}
+			if want, ok := conformanceOperations[check]; ok && operationForModel(deployment) != want {
+				result.detail = fmt.Sprintf("deployment name is served as %s, not %s", operationForModel(deployment), want)
+				t.Fatalf("deployment %s: %s", deployment, result.detail)
+			}
+			ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+			defer cancel()
+			detail, err := checks[check](ctx, deployment)
+			if err != nil {
+				result.detail = err.Error()
+				t.Fatal(err)
+			}
+			result.detail = detail
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// ErrClosed is returned for requests made after Close.
+var ErrClosed = errors.New("azureaifoundry: plugin is closed")
+
+// lifecycleState tracks the requests in flight so Close can drain them
+type lifecycleState struct {
+	once sync.Once
+
+	mu     sync.Mutex
+	closed bool
+	active int
+	idle   chan struct{} // Closed once no request is in flight after Close
+
+	stop        context.Context // Cancelled by Close, stopping pollers and health probes
+	stopPollers context.CancelFunc
+	abort       context.Context // Cancelled when Close stops waiting, aborting the requests in flight
+	abortAll    context.CancelCauseFunc
+
+	transport *http.Transport // Transport of the plugin's client, whose idle connections Close releases
+}
+
+// init sets up the contexts, so a plugin that was never initialized can still be closed
+func (l *lifecycleState) init() {
+	l.once.Do(func() {
+		l.idle = make(chan struct{})
+		l.stop, l.stopPollers = context.WithCancel(context.Background())
+		l.abort, l.abortAll = context.WithCancelCause(context.Background())
+	})
+}
+
+// track registers a request in flight. It returns the request context, which is cancelled
+// when Close stops waiting, and the function that ends the request.
+func (l *lifecycleState) track(ctx context.Context) (context.Context, func(), error) {
+	l.init()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ctx, nil, ErrClosed
+	}
+	l.active++
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stopAbort := context.AfterFunc(l.abort, func() { cancel(ErrClosed) })
+	var once sync.Once
+	end := func() {
+		once.Do(func() {
+			stopAbort()
+			cancel(nil)
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.active--
+			if l.closed && l.active == 0 {
+				close(l.idle)
+			}
+		})
+	}
+	return ctx, end, nil
+}
+
+// detachRequest gives the request kept by an API error a context that is not cancelled, as
+// the tracked context of a request is when it ends and DumpRequest needs a live one
+func detachRequest(err error) {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.Request != nil {
+		apiErr.Request = apiErr.Request.WithContext(context.WithoutCancel(apiErr.Request.Context()))
+		if apiErr.Response != nil {
+			apiErr.Response.Request = apiErr.Request
+		}
+	}
+}
+
+// Close shuts the plugin down, e.g. before a rolling deployment replaces the process. New
+// requests fail with ErrClosed, WatchRegistry and WatchSettings return and health probes stop.
+// Close then waits for the requests in flight, including streams, to finish. If ctx ends
+// first, the remaining requests are cancelled and an error is returned. Idle connections of
+// the plugin's transport are released either way.
+func (a *AzureAIFoundry) Close(ctx context.Context) error {
+	l := &a.lifecycle
+	l.init()
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		l.stopPollers()
+		if l.active == 0 {
+			close(l.idle)
+		}
+	}
+	l.mu.Unlock()
+
+	var err error
+	select {
+	case <-l.idle:
+	case <-ctx.Done():
+		l.mu.Lock()
+		active := l.active
+		l.mu.Unlock()
+		l.abortAll(ErrClosed)
+		err = fmt.Errorf("azureaifoundry: cancelled %d requests still in flight at shutdown: %w", active, ctx.Err())
+	}
+	if l.transport != nil {
+		l.transport.CloseIdleConnections()
+	}
+	return err
+}
+
+// trackedBody ends a tracked request when its response body is closed
+type trackedBody struct {
+	io.ReadCloser
+	end func()
+}
+
+// Close closes the body and ends the request.
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end()
+	return err
+}
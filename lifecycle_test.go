@@ -0,0 +1,159 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+)
+
+func TestClose(t *testing.T) {
+	tests := []struct {
+		name    string
+		between time.Duration
+		timeout time.Duration
+		wantErr string
+	}{
+		{name: "drains streams in flight", between: 20 * time.Millisecond, timeout: 5 * time.Second},
+		{name: "cancels streams at the deadline", between: 5 * time.Second, timeout: 50 * time.Millisecond, wantErr: "cancelled 1 requests still in flight"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newTestPlugin(t, slowStreamHandler(0, tt.between))
+			started := make(chan struct{})
+			var once bool
+			cb := func(context.Context, *ai.ModelResponseChunk) error {
+				if !once {
+					once = true
+					close(started)
+				}
+				return nil
+			}
+			request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("count")}}
+
+			generated := make(chan error, 1)
+			go func() {
+				_, err := plugin.generateText(context.Background(), "gpt-4o", request, cb)
+				generated <- err
+			}()
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("stream did not start")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			start := time.Now()
+			err := plugin.Close(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Close() error = %v", err)
+				}
+				if elapsed := time.Since(start); elapsed < tt.between {
+					t.Fatalf("Close() returned after %v, before the stream finished", elapsed)
+				}
+				if err := <-generated; err != nil {
+					t.Fatalf("generateText() error = %v", err)
+				}
+			} else {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("Close() error = %v, want %q", err, tt.wantErr)
+				}
+				select {
+				case err := <-generated:
+					if err == nil {
+						t.Fatal("cancelled stream succeeded")
+					}
+				case <-time.After(time.Second):
+					t.Fatal("stream was not cancelled")
+				}
+			}
+
+			if _, err := plugin.generateText(context.Background(), "gpt-4o", request, nil); !errors.Is(err, ErrClosed) {
+				t.Fatalf("generateText() after Close error = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestCloseStopsPollers(t *testing.T) {
+	var bodies []map[string]any
+	plugin, g := newRegistryPlugin(t, &bodies, "models:\n  - {name: gpt-4o}\n")
+	source := func(ctx context.Context) (*RuntimeSettings, error) {
+		return &RuntimeSettings{}, nil
+	}
+
+	done := make(chan error)
+	go func() { done <- plugin.WatchSettings(context.Background(), g, source, 5*time.Millisecond) }()
+	if err := plugin.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("WatchSettings() error = %v, want ErrClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchSettings() did not return after Close")
+	}
+}
+
+func TestCloseWaitsForSpeechBody(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = io.WriteString(w, "audio")
+	})
+	resp, err := plugin.RawSpeech(context.Background(), openai.AudioSpeechNewParams{
+		Model: "tts",
+		Input: "hello",
+		Voice: openai.AudioSpeechNewParamsVoiceUnion{OfString: openai.String("alloy")},
+	})
+	if err != nil {
+		t.Fatalf("RawSpeech() error = %v", err)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- plugin.Close(context.Background()) }()
+	select {
+	case err := <-closed:
+		t.Fatalf("Close() = %v before the speech body was closed", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if data, _ := io.ReadAll(resp.Body); string(data) != "audio" {
+		t.Fatalf("body = %q", data)
+	}
+	resp.Body.Close()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return after the speech body was closed")
+	}
+}
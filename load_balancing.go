@@ -0,0 +1,147 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// BalanceStrategy selects how a LoadBalancedGroup spreads requests across its members.
+type BalanceStrategy string
+
+const (
+	// BalanceWeighted picks members at random in proportion to their weights.
+	BalanceWeighted BalanceStrategy = "weighted"
+	// BalanceLeastLatency picks the member with the lowest recent response time. Members
+	// without measurements are tried first.
+	BalanceLeastLatency BalanceStrategy = "least-latency"
+)
+
+// latencySmoothing is the weight of a new measurement in a member's average latency
+const latencySmoothing = 0.2
+
+// LoadBalancedGroup registers a model that spreads requests across equivalent deployments,
+// e.g. to combine their tokens-per-minute quotas. When a member is throttled (429) or failing
+// (5xx, timeouts, network errors), the request moves on to another member.
+type LoadBalancedGroup struct {
+	Name     string          `json:"name"`               // Name of the group model (required)
+	Strategy BalanceStrategy `json:"strategy,omitempty"` // Defaults to BalanceWeighted
+	Models   []WeightedModel `json:"models"`             // Member model names from Models (required)
+}
+
+// WeightedModel is a member of a LoadBalancedGroup.
+type WeightedModel struct {
+	Name   string  `json:"name"`             // Model name from Models (required)
+	Weight float64 `json:"weight,omitempty"` // Relative share of requests with BalanceWeighted. Defaults to 1
+}
+
+// balancer orders the members of a load-balanced group for each request
+type balancer struct {
+	group LoadBalancedGroup
+
+	mu      sync.Mutex
+	latency map[string]time.Duration // Smoothed latency per member
+}
+
+// order returns the members in the order a request tries them
+func (b *balancer) order() []string {
+	names := make([]string, len(b.group.Models))
+	if b.group.Strategy == BalanceLeastLatency {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, member := range b.group.Models {
+			names[i] = member.Name
+		}
+		sort.SliceStable(names, func(i, j int) bool { return b.latency[names[i]] < b.latency[names[j]] })
+		return names
+	}
+
+	// Weighted sampling without replacement
+	remaining := append([]WeightedModel{}, b.group.Models...)
+	for i := range names {
+		total := 0.0
+		for _, member := range remaining {
+			total += member.weight()
+		}
+		pick, target := len(remaining)-1, rand.Float64()*total
+		for j, member := range remaining {
+			if target -= member.weight(); target < 0 {
+				pick = j
+				break
+			}
+		}
+		names[i] = remaining[pick].Name
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return names
+}
+
+// observe updates a member's latency with a request outcome. Failures double the estimate, so
+// throttled members move back until they answer quickly again.
+func (b *balancer) observe(name string, elapsed time.Duration, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.latency == nil {
+		b.latency = make(map[string]time.Duration)
+	}
+	previous, measured := b.latency[name]
+	switch {
+	case failed:
+		b.latency[name] = max(2*previous, elapsed, time.Second)
+	case !measured:
+		b.latency[name] = elapsed
+	default:
+		b.latency[name] = time.Duration(latencySmoothing*float64(elapsed) + (1-latencySmoothing)*float64(previous))
+	}
+}
+
+// weight returns the member's weight, defaulting to 1
+func (m WeightedModel) weight() float64 {
+	if m.Weight <= 0 {
+		return 1
+	}
+	return m.Weight
+}
+
+// loadBalancedFunc returns a model function that spreads requests across the group members
+func (a *AzureAIFoundry) loadBalancedFunc(group LoadBalancedGroup, member func(name string) ai.ModelFunc) ai.ModelFunc {
+	b := &balancer{group: group}
+	timed := func(name string) ai.ModelFunc {
+		fn := member(name)
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			start := time.Now()
+			resp, err := fn(ctx, input, cb)
+			if err == nil || isFailoverError(ctx, err) {
+				b.observe(name, time.Since(start), err != nil)
+			}
+			return resp, err
+		}
+	}
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		return a.tryModels(ctx, group.Name, b.order(), timed, input, cb)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestBalancerWeighted(t *testing.T) {
+	b := &balancer{group: LoadBalancedGroup{Models: []WeightedModel{{Name: "a", Weight: 3}, {Name: "b"}}}}
+	first := map[string]int{}
+	for range 4000 {
+		order := b.order()
+		if len(order) != 2 || order[0] == order[1] {
+			t.Fatalf("order() = %v, want every member once", order)
+		}
+		first[order[0]]++
+	}
+	if share := float64(first["a"]) / 4000; share < 0.7 || share > 0.8 {
+		t.Fatalf("a was picked first %.0f%% of the time, want about 75%%", share*100)
+	}
+}
+
+func TestBalancerLeastLatency(t *testing.T) {
+	b := &balancer{group: LoadBalancedGroup{
+		Strategy: BalanceLeastLatency,
+		Models:   []WeightedModel{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}}
+	b.observe("a", 100*time.Millisecond, false)
+	b.observe("b", 10*time.Millisecond, false)
+	if order := b.order(); !slices.Equal(order, []string{"c", "b", "a"}) {
+		t.Fatalf("order() = %v, want unmeasured first, then fastest", order)
+	}
+
+	// A throttled member moves back
+	b.observe("b", 10*time.Millisecond, true)
+	b.observe("c", 50*time.Millisecond, false)
+	if order := b.order(); !slices.Equal(order, []string{"c", "a", "b"}) {
+		t.Fatalf("order() after a failure = %v", order)
+	}
+
+	// Measurements are smoothed
+	b.observe("a", 200*time.Millisecond, false)
+	if got := b.latency["a"]; got != 120*time.Millisecond {
+		t.Fatalf("latency = %v, want 120ms", got)
+	}
+}
+
+func TestLoadBalancedGroup(t *testing.T) {
+	var models []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		models = append(models, body["model"])
+		w.Header().Set("Content-Type", "application/json")
+		if body["model"] == "gpt-4o-westus" {
+			w.Header().Set("x-should-retry", "false")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error":{"code":"429","message":"Rate limit exceeded"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	defer server.Close()
+
+	registry, err := LoadModelsFromConfig([]byte(`
+models:
+  - {name: gpt-4o-eastus}
+  - {name: gpt-4o-westus}
+loadBalancedGroups:
+  - name: gpt-4o
+    strategy: least-latency
+    models: [{name: gpt-4o-westus}, {name: gpt-4o-eastus}]
+failoverGroups:
+  - {name: chat, models: [gpt-4o]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Registry: registry}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+
+	for range 3 {
+		if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	// The throttled member is tried once, then the other one is preferred
+	want := []any{"gpt-4o-westus", "gpt-4o-eastus", "gpt-4o-eastus", "gpt-4o-eastus"}
+	if !slices.Equal(models, want) {
+		t.Fatalf("requested models = %v, want %v", models, want)
+	}
+
+	// Failover groups can contain load-balanced groups
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "chat")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() with the failover group error = %v", err)
+	}
+}
+
+func TestLoadBalancedGroupStreaming(t *testing.T) {
+	tests := []struct {
+		name      string
+		broken    map[string]int
+		wantCalls []string
+		wantText  string
+		wantErr   bool
+	}{
+		{"moves on before the first chunk", map[string]int{"a": 0}, []string{"a", "b"}, "b ", false},
+		{"stops after a chunk", map[string]int{"a": 2}, []string{"a"}, "a a ", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			plugin := &AzureAIFoundry{}
+			fn := plugin.loadBalancedFunc(LoadBalancedGroup{
+				Name:     "gpt-4o",
+				Strategy: BalanceLeastLatency,
+				Models:   []WeightedModel{{Name: "a"}, {Name: "b"}},
+			}, streamingMembers(&calls, tt.broken))
+
+			var streamed strings.Builder
+			_, err := fn(t.Context(), &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}},
+				func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+					streamed.WriteString(chunk.Text())
+					return nil
+				})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(calls, tt.wantCalls) || streamed.String() != tt.wantText {
+				t.Fatalf("calls = %v, streamed = %q", calls, streamed.String())
+			}
+		})
+	}
+}
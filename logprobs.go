@@ -0,0 +1,60 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+)
+
+// TokenLogprob is the log probability of a generated token, returned when the "logprobs"
+// config option is set.
+type TokenLogprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	TopLogprobs []TopLogprob `json:"topLogprobs,omitempty"` // Most likely alternatives, when "topLogprobs" is set
+}
+
+// TopLogprob is the log probability of a likely alternative to a generated token.
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+}
+
+// Logprobs returns the per-token log probabilities of a response, or nil if none were
+// requested.
+func Logprobs(resp *ai.ModelResponse) []TokenLogprob {
+	if resp == nil {
+		return nil
+	}
+	custom, _ := resp.Custom.(map[string]any)
+	logprobs, _ := custom["logprobs"].([]TokenLogprob)
+	return logprobs
+}
+
+// convertLogprobs converts the token log probabilities of a completion
+func convertLogprobs(tokens []openai.ChatCompletionTokenLogprob) []TokenLogprob {
+	logprobs := make([]TokenLogprob, len(tokens))
+	for i, token := range tokens {
+		logprobs[i] = TokenLogprob{Token: token.Token, Logprob: token.Logprob}
+		for _, top := range token.TopLogprobs {
+			logprobs[i].TopLogprobs = append(logprobs[i].TopLogprobs, TopLogprob{Token: top.Token, Logprob: top.Logprob})
+		}
+	}
+	return logprobs
+}
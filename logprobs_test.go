@@ -0,0 +1,96 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+const logprobsJSON = `{"content":[{"token":"Yes","logprob":-0.01,"bytes":[89,101,115],` +
+	`"top_logprobs":[{"token":"Yes","logprob":-0.01,"bytes":null},{"token":"No","logprob":-4.6,"bytes":null}]}],"refusal":null}`
+
+func TestLogprobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  bool
+		handler func(w http.ResponseWriter)
+	}{
+		{"sync", false, func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",`+
+				`"message":{"role":"assistant","content":"Yes"},"logprobs":%s}]}`, logprobsJSON)
+		}},
+		{"stream", true, func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Yes\"},\"logprobs\":%s}]}\n\n", logprobsJSON)
+			_, _ = io.WriteString(w, "data: [DONE]\n\n")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				tt.handler(w)
+			})
+			var cb ai.ModelStreamCallback
+			if tt.stream {
+				cb = func(context.Context, *ai.ModelResponseChunk) error { return nil }
+			}
+			resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("Is the sky blue?")},
+				Config:   map[string]any{"top_logprobs": 2},
+			}, cb)
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+
+			if got := string(body); !strings.Contains(got, `"logprobs":true`) || !strings.Contains(got, `"top_logprobs":2`) {
+				t.Fatalf("request = %s", got)
+			}
+			logprobs := Logprobs(resp)
+			if len(logprobs) != 1 || logprobs[0].Token != "Yes" || logprobs[0].Logprob != -0.01 {
+				t.Fatalf("Logprobs() = %+v", logprobs)
+			}
+			if top := logprobs[0].TopLogprobs; len(top) != 2 || top[1] != (TopLogprob{Token: "No", Logprob: -4.6}) {
+				t.Fatalf("top logprobs = %+v", top)
+			}
+		})
+	}
+}
+
+func TestLogprobsNotRequested(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bodies[0]["logprobs"]; ok || Logprobs(resp) != nil {
+		t.Fatalf("request = %v, logprobs = %v", bodies[0], Logprobs(resp))
+	}
+}
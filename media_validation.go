@@ -0,0 +1,296 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// MediaTranscoder converts media to one of the supported content types, e.g. by running
+// ffmpeg. It returns the converted data and its content type.
+type MediaTranscoder func(ctx context.Context, data []byte, contentType string, supported []string) ([]byte, string, error)
+
+// supportedMedia lists the content types accepted by each operation that takes media input
+var supportedMedia = map[string][]string{
+	OperationChat:          {"image/png", "image/jpeg", "image/gif", "image/webp"},
+	OperationTranscription: {"audio/flac", "audio/mpeg", "audio/mp4", "video/mp4", "audio/ogg", "audio/wav", "audio/webm"},
+}
+
+// mediaAliases maps alternative content type names to the names used for validation
+var mediaAliases = map[string]string{
+	"image/jpg":       "image/jpeg",
+	"audio/mp3":       "audio/mpeg",
+	"audio/mpga":      "audio/mpeg",
+	"audio/x-wav":     "audio/wav",
+	"audio/wave":      "audio/wav",
+	"audio/vnd.wave":  "audio/wav",
+	"audio/x-flac":    "audio/flac",
+	"audio/m4a":       "audio/mp4",
+	"audio/x-m4a":     "audio/mp4",
+	"audio/opus":      "audio/ogg",
+	"video/webm":      "audio/webm",
+	"application/ogg": "audio/ogg",
+	"audio/x-aiff":    "audio/aiff",
+	"audio/x-aac":     "audio/aac",
+}
+
+// canonicalMediaType normalizes a content type for comparison, dropping parameters
+func canonicalMediaType(contentType string) string {
+	contentType, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(contentType)), ";")
+	if alias, ok := mediaAliases[contentType]; ok {
+		return alias
+	}
+	return contentType
+}
+
+// SniffMediaType detects the content type of media from its leading bytes. It returns ""
+// for unrecognized formats.
+func SniffMediaType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "image/gif"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "image/webp"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return "audio/wav"
+	case bytes.HasPrefix(data, []byte("BM")) && len(data) >= 14:
+		return "image/bmp"
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return "image/tiff"
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return "application/pdf"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "audio/webm"
+	case len(data) >= 2 && data[0] == 0xFF && data[1]&0xF6 == 0xF0:
+		return "audio/aac" // ADTS frame sync with MPEG layer 0
+	case bytes.HasPrefix(data, []byte("ID3")), len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return "audio/mpeg"
+	case len(data) >= 12 && string(data[:4]) == "FORM" && (string(data[8:12]) == "AIFF" || string(data[8:12]) == "AIFC"):
+		return "audio/aiff"
+	case bytes.HasPrefix(data, []byte("#!AMR")):
+		return "audio/amr"
+	case bytes.HasPrefix(data, []byte("caff")):
+		return "audio/x-caf"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		switch brand := string(data[8:12]); {
+		case brand == "M4A " || brand == "M4B ":
+			return "audio/mp4"
+		case strings.HasPrefix(brand, "hei") || brand == "mif1":
+			return "image/heic"
+		default:
+			return "video/mp4"
+		}
+	}
+	return ""
+}
+
+// MediaError is returned when a media part cannot be sent to an endpoint.
+type MediaError struct {
+	Operation string
+	Declared  string   // Content type declared on the part
+	Detected  string   // Content type detected from the bytes, if recognized
+	Supported []string // Content types the endpoint accepts
+	Reason    string
+}
+
+// Error implements the error interface.
+func (e *MediaError) Error() string {
+	return fmt.Sprintf("invalid media for %s: %s (supported: %s)", e.Operation, e.Reason, strings.Join(e.Supported, ", "))
+}
+
+// validateMedia checks the media parts of a request against the content types the
+// operation accepts. Inline data is sniffed and must match its declared type; unsupported
+// inline data is passed to the transcoder, if any. It returns a copy of the request when
+// parts were transcoded or their content type was filled in.
+func (a *AzureAIFoundry) validateMedia(ctx context.Context, modelName, operation string, input *ai.ModelRequest) (*ai.ModelRequest, error) {
+	supported, ok := supportedMedia[operation]
+	if !ok {
+		return input, nil
+	}
+	if formats, ok := a.AudioFormats[modelName]; ok && operation == OperationTranscription {
+		supported = formats
+	}
+
+	var messages []*ai.Message
+	for i, msg := range input.Messages {
+		var content []*ai.Part
+		for j, part := range msg.Content {
+			if !part.IsMedia() {
+				continue
+			}
+			checked, err := a.validateMediaPart(ctx, operation, supported, part)
+			if err != nil {
+				return nil, err
+			}
+			if checked == part {
+				continue
+			}
+			if content == nil {
+				content = append([]*ai.Part{}, msg.Content...)
+			}
+			content[j] = checked
+		}
+		if content == nil {
+			continue
+		}
+		if messages == nil {
+			messages = append([]*ai.Message{}, input.Messages...)
+		}
+		copied := *msg
+		copied.Content = content
+		messages[i] = &copied
+	}
+
+	if messages == nil {
+		return input, nil
+	}
+	req := *input
+	req.Messages = messages
+	return &req, nil
+}
+
+// validateMediaPart validates a single media part, returning a replacement part when it
+// was transcoded or its content type was filled in
+func (a *AzureAIFoundry) validateMediaPart(ctx context.Context, operation string, supported []string, part *ai.Part) (*ai.Part, error) {
+	declared := part.ContentType
+	header, encoded, isData := strings.Cut(strings.TrimPrefix(part.Text, "data:"), ",")
+	isData = isData && strings.HasPrefix(part.Text, "data:")
+	if isData && declared == "" {
+		declared, _, _ = strings.Cut(header, ";")
+	}
+	mediaErr := &MediaError{Operation: operation, Declared: declared, Supported: supported}
+
+	if !isData {
+		// Remote URLs are fetched by Azure; only the declared type can be checked
+		if declared != "" && !isSupportedMedia(supported, declared) {
+			mediaErr.Reason = fmt.Sprintf("content type %q is not supported", declared)
+			return nil, mediaErr
+		}
+		return part, nil
+	}
+	if !strings.HasSuffix(header, ";base64") {
+		mediaErr.Reason = "data URL is not base64-encoded"
+		return nil, mediaErr
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		mediaErr.Reason = fmt.Sprintf("data URL has invalid base64: %v", err)
+		return nil, mediaErr
+	}
+
+	detected := SniffMediaType(data)
+	mediaErr.Detected = detected
+	source := detected
+	switch {
+	case detected == "":
+		if declared == "" || isSupportedMedia(supported, declared) {
+			return part, nil
+		}
+		// Unrecognized bytes in an unsupported declared format may still be convertible
+		source = canonicalMediaType(declared)
+		if a.transcoderFor(source) == nil {
+			mediaErr.Reason = fmt.Sprintf("content type %q is not supported", declared)
+			return nil, mediaErr
+		}
+	case declared != "" && canonicalMediaType(declared) != canonicalMediaType(detected):
+		mediaErr.Reason = fmt.Sprintf("declared as %q but the data is %s; fix the content type or the file", declared, detected)
+		return nil, mediaErr
+	case isSupportedMedia(supported, detected):
+		if declared != "" {
+			return part, nil
+		}
+		return ai.NewMediaPart(detected, part.Text), nil
+	case a.transcoderFor(detected) == nil:
+		hook := "MediaTranscoder"
+		if isAudioMedia(detected) {
+			hook = "AudioTranscoder"
+		}
+		mediaErr.Reason = fmt.Sprintf("%s is not supported; convert it or configure %s", detected, hook)
+		return nil, mediaErr
+	}
+
+	converted, contentType, err := a.transcoderFor(source)(ctx, data, source, supported)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcode %s media: %w", source, err)
+	}
+	if !isSupportedMedia(supported, contentType) {
+		mediaErr.Reason = fmt.Sprintf("transcoder returned unsupported content type %q", contentType)
+		return nil, mediaErr
+	}
+	return ai.NewMediaPart(contentType, "data:"+contentType+";base64,"+base64.StdEncoding.EncodeToString(converted)), nil
+}
+
+// transcoderFor returns the converter for a content type: the AudioTranscoder for audio
+// and video, falling back to the MediaTranscoder. It returns nil when neither is set.
+func (a *AzureAIFoundry) transcoderFor(contentType string) MediaTranscoder {
+	if a.AudioTranscoder != nil && isAudioMedia(contentType) {
+		return a.AudioTranscoder.Transcode
+	}
+	return a.MediaTranscoder
+}
+
+// isAudioMedia reports whether a content type is audio or a video container with audio
+func isAudioMedia(contentType string) bool {
+	return strings.HasPrefix(contentType, "audio/") || strings.HasPrefix(contentType, "video/")
+}
+
+// isSupportedMedia reports whether a content type is in the supported list
+func isSupportedMedia(supported []string, contentType string) bool {
+	contentType = canonicalMediaType(contentType)
+	for _, s := range supported {
+		if s == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// audioFilename returns an upload filename whose extension matches an audio content type,
+// as the transcription endpoint detects the format from the extension
+func audioFilename(contentType string) string {
+	switch canonicalMediaType(contentType) {
+	case "audio/wav":
+		return "audio.wav"
+	case "audio/flac":
+		return "audio.flac"
+	case "audio/ogg":
+		return "audio.ogg"
+	case "audio/webm":
+		return "audio.webm"
+	case "audio/mp4":
+		return "audio.m4a"
+	case "video/mp4":
+		return "audio.mp4"
+	default:
+		return "audio.mp3"
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+var (
+	pngBytes  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpegBytes = []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'}
+	webpBytes = []byte("RIFF\x24\x00\x00\x00WEBPVP8 ")
+	wavBytes  = []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	bmpBytes  = []byte("BM\x36\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00")
+)
+
+func dataURL(contentType string, data []byte) string {
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func TestSniffMediaType(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{pngBytes, "image/png"},
+		{jpegBytes, "image/jpeg"},
+		{[]byte("GIF89a\x01\x00"), "image/gif"},
+		{webpBytes, "image/webp"},
+		{wavBytes, "audio/wav"},
+		{[]byte("ID3\x04\x00"), "audio/mpeg"},
+		{[]byte{0xFF, 0xFB, 0x90, 0x00}, "audio/mpeg"},
+		{[]byte("fLaC\x00"), "audio/flac"},
+		{[]byte("OggS\x00"), "audio/ogg"},
+		{[]byte("\x00\x00\x00\x20ftypM4A \x00"), "audio/mp4"},
+		{[]byte("\x00\x00\x00\x20ftypisom\x00"), "video/mp4"},
+		{[]byte("hello"), ""},
+	}
+	for _, tt := range tests {
+		if got := SniffMediaType(tt.data); got != tt.want {
+			t.Errorf("SniffMediaType(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMedia(t *testing.T) {
+	toPNG := func(_ context.Context, data []byte, contentType string, supported []string) ([]byte, string, error) {
+		return pngBytes, "image/png", nil
+	}
+
+	tests := []struct {
+		name            string
+		operation       string
+		part            *ai.Part
+		transcoder      MediaTranscoder
+		wantErr         string
+		wantContentType string
+	}{
+		{"matching image", OperationChat, ai.NewMediaPart("image/png", dataURL("image/png", pngBytes)), nil, "", "image/png"},
+		{"alias", OperationChat, ai.NewMediaPart("image/jpg", dataURL("image/jpg", jpegBytes)), nil, "", "image/jpg"},
+		{"type filled in", OperationChat, ai.NewMediaPart("", dataURL("", webpBytes)), nil, "", "image/webp"},
+		{"mismatch", OperationChat, ai.NewMediaPart("image/png", dataURL("image/png", jpegBytes)), nil, `declared as "image/png" but the data is image/jpeg`, ""},
+		{"webp to whisper", OperationTranscription, ai.NewMediaPart("image/webp", dataURL("image/webp", webpBytes)), nil, "image/webp is not supported", ""},
+		{"unsupported without transcoder", OperationChat, ai.NewMediaPart("image/bmp", dataURL("image/bmp", bmpBytes)), nil, "image/bmp is not supported; convert it or configure MediaTranscoder", ""},
+		{"transcoded", OperationChat, ai.NewMediaPart("image/bmp", dataURL("image/bmp", bmpBytes)), toPNG, "", "image/png"},
+		{"audio", OperationTranscription, ai.NewMediaPart("audio/x-wav", dataURL("audio/x-wav", wavBytes)), nil, "", "audio/x-wav"},
+		{"remote url", OperationChat, ai.NewMediaPart("image/png", "https://example.com/a.png"), nil, "", "image/png"},
+		{"remote unsupported url", OperationChat, ai.NewMediaPart("image/tiff", "https://example.com/a.tiff"), nil, `content type "image/tiff" is not supported`, ""},
+		{"unknown bytes", OperationChat, ai.NewMediaPart("image/png", dataURL("image/png", []byte("???"))), nil, "", "image/png"},
+		{"speech has no media input", OperationSpeech, ai.NewMediaPart("image/png", dataURL("image/png", jpegBytes)), nil, "", "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &AzureAIFoundry{MediaTranscoder: tt.transcoder}
+			input := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserMessage(ai.NewTextPart("look"), tt.part)}}
+
+			got, err := plugin.validateMedia(context.Background(), "model", tt.operation, input)
+			if tt.wantErr != "" {
+				var mediaErr *MediaError
+				if !errors.As(err, &mediaErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validateMedia() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateMedia() error = %v", err)
+			}
+			part := got.Messages[0].Content[1]
+			if part.ContentType != tt.wantContentType {
+				t.Fatalf("content type = %q, want %q", part.ContentType, tt.wantContentType)
+			}
+			if input.Messages[0].Content[1] != tt.part {
+				t.Fatal("validateMedia() modified the caller's request")
+			}
+		})
+	}
+}
+
+func TestTranscriptionFilenameFromContentType(t *testing.T) {
+	var filename string
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, header, err := r.FormFile("file"); err == nil {
+			filename = header.Filename
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"hi"}`))
+	})
+
+	_, err := plugin.generateText(context.Background(), "whisper", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserMessage(ai.NewMediaPart("audio/x-wav", dataURL("audio/x-wav", wavBytes)))},
+	}, nil)
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if filename != "audio.wav" {
+		t.Fatalf("uploaded filename = %q, want audio.wav", filename)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// MeetingMinutesOptions configures the meeting minutes flow.
+type MeetingMinutesOptions struct {
+	TranscriptionModel string   // Transcription deployment, ideally a diarization model such as "gpt-4o-transcribe-diarize" (required)
+	Summarizer         ai.Model // Chat model that extracts the minutes (required)
+	Language           string   // Optional language code of the meeting (e.g., "en")
+}
+
+// MeetingAudioChunk is one consecutive part of a meeting recording.
+type MeetingAudioChunk struct {
+	Audio    []byte  `json:"audio"`
+	Filename string  `json:"filename,omitempty"` // Filename with extension, used for format detection
+	Offset   float64 `json:"offset"`             // Start of the chunk within the meeting, in seconds
+}
+
+// MeetingMinutesInput is the input of the meeting minutes flow.
+type MeetingMinutesInput struct {
+	Title     string              `json:"title,omitempty"`
+	Attendees []string            `json:"attendees,omitempty"` // Helps the summarizer name action item owners
+	Chunks    []MeetingAudioChunk `json:"chunks"`
+}
+
+// MeetingProgress is streamed by the meeting minutes flow after each step.
+type MeetingProgress struct {
+	Stage    string              `json:"stage"` // "transcribed" after each chunk, then "summarizing"
+	Chunk    int                 `json:"chunk"` // 1-based number of the chunk just transcribed
+	Chunks   int                 `json:"chunks"`
+	Segments []TranscriptSegment `json:"segments,omitempty"` // Segments of the chunk just transcribed
+}
+
+// MeetingDecision is a decision recorded in the minutes.
+type MeetingDecision struct {
+	Decision  string  `json:"decision"`
+	Timestamp float64 `json:"timestamp"` // Seconds from the start of the meeting
+}
+
+// ActionItem is a task agreed during the meeting.
+type ActionItem struct {
+	Task      string  `json:"task"`
+	Owner     string  `json:"owner"`         // Person or speaker label responsible, "" if unassigned
+	Due       string  `json:"due,omitempty"` // Due date as stated in the meeting
+	Timestamp float64 `json:"timestamp"`     // Seconds from the start of the meeting
+}
+
+// MeetingMinutes is the output of the meeting minutes flow.
+type MeetingMinutes struct {
+	Summary     string              `json:"summary"`
+	Decisions   []MeetingDecision   `json:"decisions"`
+	ActionItems []ActionItem        `json:"actionItems"`
+	Transcript  []TranscriptSegment `json:"transcript"`
+}
+
+// minutesOutput is the structured output requested from the summarizer
+type minutesOutput struct {
+	Summary     string            `json:"summary"`
+	Decisions   []MeetingDecision `json:"decisions"`
+	ActionItems []ActionItem      `json:"actionItems"`
+}
+
+// DefineMeetingMinutesFlow defines a streaming flow that transcribes the chunks of a meeting
+// recording with diarization and extracts a summary, decisions and action items with owners
+// and timestamps. Progress is streamed after every transcribed chunk. Speaker labels come
+// from the diarization of each chunk and are not matched across chunks.
+func (a *AzureAIFoundry) DefineMeetingMinutesFlow(g *genkit.Genkit, name string, opts MeetingMinutesOptions) *core.Flow[*MeetingMinutesInput, *MeetingMinutes, *MeetingProgress] {
+	if !a.initted {
+		panic("azureaifoundry: Init not called")
+	}
+	if opts.TranscriptionModel == "" || opts.Summarizer == nil {
+		panic("azureaifoundry: meeting minutes flow requires a transcription model and a summarizer")
+	}
+
+	return genkit.DefineStreamingFlow(g, name, func(ctx context.Context, input *MeetingMinutesInput, cb core.StreamCallback[*MeetingProgress]) (*MeetingMinutes, error) {
+		return a.generateMeetingMinutes(ctx, g, opts, input, cb)
+	})
+}
+
+// generateMeetingMinutes transcribes every chunk and summarizes the combined transcript
+func (a *AzureAIFoundry) generateMeetingMinutes(ctx context.Context, g *genkit.Genkit, opts MeetingMinutesOptions, input *MeetingMinutesInput, cb core.StreamCallback[*MeetingProgress]) (*MeetingMinutes, error) {
+	if input == nil || len(input.Chunks) == 0 {
+		return nil, fmt.Errorf("meeting minutes require at least one audio chunk")
+	}
+
+	minutes := &MeetingMinutes{}
+	for i, chunk := range input.Chunks {
+		transcript, err := a.transcribeSegments(ctx, opts.TranscriptionModel, &STTRequest{
+			Audio:    chunk.Audio,
+			Filename: chunk.Filename,
+			Language: opts.Language,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to transcribe chunk %d: %w", i+1, err)
+		}
+
+		segments := make([]TranscriptSegment, len(transcript.Segments))
+		for j, segment := range transcript.Segments {
+			segment.Start += chunk.Offset
+			segment.End += chunk.Offset
+			segments[j] = segment
+		}
+		minutes.Transcript = append(minutes.Transcript, segments...)
+
+		if cb != nil {
+			if err := cb(ctx, &MeetingProgress{Stage: "transcribed", Chunk: i + 1, Chunks: len(input.Chunks), Segments: segments}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if cb != nil {
+		if err := cb(ctx, &MeetingProgress{Stage: "summarizing", Chunk: len(input.Chunks), Chunks: len(input.Chunks)}); err != nil {
+			return nil, err
+		}
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Write the minutes of the meeting transcribed below: a short summary, the decisions made and ")
+	prompt.WriteString("the action items with their owner and any due date mentioned. For every decision and action ")
+	prompt.WriteString("item, give the timestamp in seconds of the transcript line where it was agreed.\n\n")
+	if input.Title != "" {
+		fmt.Fprintf(&prompt, "Meeting: %s\n", input.Title)
+	}
+	if len(input.Attendees) > 0 {
+		fmt.Fprintf(&prompt, "Attendees: %s\n", strings.Join(input.Attendees, ", "))
+	}
+	prompt.WriteString("\nTranscript:\n")
+	for _, segment := range minutes.Transcript {
+		speaker := segment.Speaker
+		if speaker == "" {
+			speaker = "Speaker"
+		}
+		fmt.Fprintf(&prompt, "[%.1fs] %s: %s\n", segment.Start, speaker, strings.TrimSpace(segment.Text))
+	}
+
+	out, _, err := genkit.GenerateData[minutesOutput](ctx, g,
+		ai.WithModel(opts.Summarizer),
+		ai.WithMessages(ai.NewUserTextMessage(prompt.String())),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("meeting summarization failed: %w", err)
+	}
+
+	minutes.Summary = out.Summary
+	minutes.Decisions = out.Decisions
+	minutes.ActionItems = out.ActionItems
+	return minutes, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestMeetingMinutesFlow(t *testing.T) {
+	responses := []string{
+		`{"text":"Let's ship on Friday.","segments":[{"id":"seg_0","start":1,"end":3,"text":"Let's ship on Friday.","speaker":"A"}]}`,
+		`{"text":"I'll write the release notes.","segments":[{"id":"seg_0","start":4,"end":6,"text":"I'll write the release notes.","speaker":"B"}]}`,
+	}
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, responses[0])
+		responses = responses[1:]
+	})
+
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	var summarizerPrompt string
+	summarizer := genkit.DefineModel(g, "test/summarizer", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			summarizerPrompt = req.Messages[0].Text()
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(`{"summary":"Release planning.",
+				"decisions":[{"decision":"Ship on Friday","timestamp":1}],
+				"actionItems":[{"task":"Write the release notes","owner":"B","timestamp":604}]}`)}, nil
+		})
+
+	flow := plugin.DefineMeetingMinutesFlow(g, "meetingMinutes", MeetingMinutesOptions{
+		TranscriptionModel: "gpt-4o-transcribe-diarize",
+		Summarizer:         summarizer,
+	})
+
+	var progress []*MeetingProgress
+	var minutes *MeetingMinutes
+	for value, err := range flow.Stream(ctx, &MeetingMinutesInput{
+		Title: "Release sync",
+		Chunks: []MeetingAudioChunk{
+			{Audio: []byte("part1"), Filename: "part1.wav"},
+			{Audio: []byte("part2"), Filename: "part2.wav", Offset: 600},
+		},
+	}) {
+		if err != nil {
+			t.Fatalf("flow error = %v", err)
+		}
+		if value.Done {
+			minutes = value.Output
+		} else {
+			progress = append(progress, value.Stream)
+		}
+	}
+
+	if len(progress) != 3 || progress[0].Chunk != 1 || progress[1].Chunk != 2 || progress[2].Stage != "summarizing" {
+		t.Fatalf("progress = %+v", progress)
+	}
+	if len(minutes.Transcript) != 2 || minutes.Transcript[1].Start != 604 {
+		t.Fatalf("transcript = %+v", minutes.Transcript)
+	}
+	if !strings.Contains(summarizerPrompt, "[604.0s] B: I'll write the release notes.") {
+		t.Fatalf("summarizer prompt = %q", summarizerPrompt)
+	}
+	if len(minutes.ActionItems) != 1 || minutes.ActionItems[0].Owner != "B" || minutes.Decisions[0].Decision != "Ship on Friday" {
+		t.Fatalf("minutes = %+v", minutes)
+	}
+}
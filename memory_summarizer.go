@@ -0,0 +1,161 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// defaultMemoryBatchSize is the number of messages distilled per summarizer call
+const defaultMemoryBatchSize = 40
+
+// MemorySummarizerOptions configures the memory summarizer flow.
+type MemorySummarizerOptions struct {
+	Model        ai.Model // Chat model that distills the history, e.g. a small deployment (required)
+	Instructions string   // Optional: What is worth remembering, e.g. "Only keep facts about the user's travel plans"
+	BatchSize    int      // Optional: Messages distilled per model call; longer histories are folded in batches. Defaults to 40
+}
+
+// MemorySummaryInput is the input of the memory summarizer flow.
+type MemorySummaryInput[M any] struct {
+	Messages []*ai.Message `json:"messages"`         // History to distill, e.g. Conversation.Messages()
+	Memory   *M            `json:"memory,omitempty"` // Memory stored so far, updated with the new messages
+}
+
+// UserMemory is the default memory object: what the user said about themselves and how
+// they want to be answered.
+type UserMemory struct {
+	Summary     string             `json:"summary"`     // Short summary of the conversations so far
+	Facts       []MemoryFact       `json:"facts"`       // Facts about the user
+	Preferences []MemoryPreference `json:"preferences"` // Preferences the user stated or showed
+}
+
+// MemoryFact is a fact about the user.
+type MemoryFact struct {
+	Fact     string `json:"fact"`
+	Category string `json:"category,omitempty"` // e.g. "work", "family", "location"
+}
+
+// MemoryPreference is a preference of the user.
+type MemoryPreference struct {
+	Topic      string `json:"topic"`      // e.g. "answer style", "food"
+	Preference string `json:"preference"` // e.g. "short answers with code first"
+}
+
+// DefineMemorySummarizer defines a flow that distills a chat history into a persistent
+// memory object of type M, such as UserMemory or an application-specific struct whose JSON
+// schema is sent to the model. The memory in the input is updated rather than replaced:
+// facts that still hold are kept and contradicted ones are dropped. Store the output with
+// the user's session and pass it back in with the next history.
+func DefineMemorySummarizer[M any](g *genkit.Genkit, name string, opts MemorySummarizerOptions) *core.Flow[*MemorySummaryInput[M], *M, struct{}] {
+	if opts.Model == nil {
+		panic("azureaifoundry: memory summarizer requires a model")
+	}
+	return genkit.DefineFlow(g, name, func(ctx context.Context, input *MemorySummaryInput[M]) (*M, error) {
+		return SummarizeMemory(ctx, g, input, opts)
+	})
+}
+
+// SummarizeMemory distills a chat history into a memory object of type M without
+// registering a flow.
+func SummarizeMemory[M any](ctx context.Context, g *genkit.Genkit, input *MemorySummaryInput[M], opts MemorySummarizerOptions) (*M, error) {
+	if input == nil {
+		return nil, fmt.Errorf("memory summarizer requires an input")
+	}
+	if opts.Model == nil {
+		return nil, fmt.Errorf("memory summarizer requires a model")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultMemoryBatchSize
+	}
+
+	memory := input.Memory
+	for start := 0; start < len(input.Messages); start += batchSize {
+		end := min(start+batchSize, len(input.Messages))
+		transcript := memoryTranscript(input.Messages[start:end])
+		if transcript == "" {
+			continue
+		}
+
+		var prompt strings.Builder
+		prompt.WriteString("You maintain a long-term memory of a user from their conversations with an assistant. ")
+		prompt.WriteString("Update the memory with what the conversation below reveals about the user: lasting facts ")
+		prompt.WriteString("and preferences, not one-off requests. Keep existing entries that still hold, drop the ones ")
+		prompt.WriteString("the conversation contradicts and do not duplicate entries.\n")
+		if opts.Instructions != "" {
+			fmt.Fprintf(&prompt, "\n%s\n", opts.Instructions)
+		}
+		if memory != nil {
+			current, err := json.Marshal(memory)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode memory: %w", err)
+			}
+			fmt.Fprintf(&prompt, "\nCurrent memory:\n%s\n", current)
+		}
+		fmt.Fprintf(&prompt, "\nConversation:\n%s", transcript)
+
+		updated, _, err := genkit.GenerateData[M](ctx, g,
+			ai.WithModel(opts.Model),
+			ai.WithMessages(ai.NewUserTextMessage(prompt.String())),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("memory summarization failed: %w", err)
+		}
+		memory = updated
+	}
+
+	if memory == nil {
+		// Nothing to distill
+		memory = new(M)
+	}
+	return memory, nil
+}
+
+// memoryTranscript renders the text of user and model messages, one line per message
+func memoryTranscript(messages []*ai.Message) string {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		var text strings.Builder
+		for _, part := range msg.Content {
+			if part.IsText() {
+				text.WriteString(part.Text)
+			}
+		}
+		if strings.TrimSpace(text.String()) == "" {
+			continue
+		}
+		speaker := "Assistant"
+		switch msg.Role {
+		case ai.RoleUser:
+			speaker = "User"
+		case ai.RoleSystem, ai.RoleTool:
+			// Instructions and tool output are not about the user
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, strings.TrimSpace(text.String()))
+	}
+	return transcript.String()
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestMemorySummarizer(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	var prompts []string
+	replies := []string{
+		`{"summary":"Planning a trip.","facts":[{"fact":"Lives in Madrid","category":"location"}],"preferences":[]}`,
+		`{"summary":"Planning a trip to Japan.","facts":[{"fact":"Lives in Madrid","category":"location"}],"preferences":[{"topic":"answer style","preference":"short answers"}]}`,
+	}
+	model := genkit.DefineModel(g, "test/memory", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			prompts = append(prompts, req.Messages[0].Text())
+			reply := replies[0]
+			replies = replies[1:]
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(reply)}, nil
+		})
+
+	flow := DefineMemorySummarizer[UserMemory](g, "summarizeMemory", MemorySummarizerOptions{
+		Model:     model,
+		BatchSize: 2,
+	})
+	memory, err := flow.Run(ctx, &MemorySummaryInput[UserMemory]{
+		Messages: []*ai.Message{
+			ai.NewSystemTextMessage("You are a travel agent."),
+			ai.NewUserTextMessage("I live in Madrid."),
+			ai.NewModelTextMessage("Great, where would you like to go?"),
+			ai.NewUserTextMessage("Japan. Keep answers short."),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := &UserMemory{
+		Summary:     "Planning a trip to Japan.",
+		Facts:       []MemoryFact{{Fact: "Lives in Madrid", Category: "location"}},
+		Preferences: []MemoryPreference{{Topic: "answer style", Preference: "short answers"}},
+	}
+	if !reflect.DeepEqual(memory, want) {
+		t.Fatalf("memory = %+v, want %+v", memory, want)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("got %d summarizer calls, want one per batch", len(prompts))
+	}
+	if strings.Contains(prompts[0], "travel agent") || !strings.Contains(prompts[0], "User: I live in Madrid.") || strings.Contains(prompts[0], "Current memory") {
+		t.Fatalf("first prompt = %q", prompts[0])
+	}
+	if !strings.Contains(prompts[1], `"fact":"Lives in Madrid"`) || !strings.Contains(prompts[1], "User: Japan. Keep answers short.") {
+		t.Fatalf("second prompt = %q, want the memory of the first batch", prompts[1])
+	}
+}
+
+func TestMemorySummarizerCustomSchema(t *testing.T) {
+	type shoppingMemory struct {
+		Sizes  map[string]string `json:"sizes"`
+		Brands []string          `json:"brands"`
+	}
+
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	var prompt string
+	model := genkit.DefineModel(g, "test/memory", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			prompt = req.Messages[0].Text()
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(`{"sizes":{"shoes":"42"},"brands":["Acme"]}`)}, nil
+		})
+
+	memory, err := SummarizeMemory(ctx, g, &MemorySummaryInput[shoppingMemory]{
+		Messages: []*ai.Message{ai.NewUserTextMessage("I wear size 42 shoes.")},
+		Memory:   &shoppingMemory{Brands: []string{"Acme"}},
+	}, MemorySummarizerOptions{Model: model, Instructions: "Only keep sizes and brands."})
+	if err != nil {
+		t.Fatalf("SummarizeMemory() error = %v", err)
+	}
+	if memory.Sizes["shoes"] != "42" || !strings.Contains(prompt, "Only keep sizes and brands.") || !strings.Contains(prompt, `"brands":["Acme"]`) {
+		t.Fatalf("memory = %+v, prompt = %q", memory, prompt)
+	}
+
+	empty, err := SummarizeMemory(ctx, g, &MemorySummaryInput[shoppingMemory]{}, MemorySummarizerOptions{Model: model})
+	if err != nil || empty == nil {
+		t.Fatalf("SummarizeMemory() of no messages = %v, %v", empty, err)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// ModelLimits are the token limits of a model.
+type ModelLimits struct {
+	ContextWindow       int  // Maximum prompt and output tokens of a request
+	MaxOutputTokens     int  // Maximum tokens generated in a response
+	MaxCompletionTokens bool // The output limit is sent as max_completion_tokens instead of max_tokens
+	Reasoning           bool // The model is a reasoning model (o-series, GPT-5) that accepts reasoningEffort
+	DeveloperRole       bool // System messages are sent with the developer role, as o-series models require
+}
+
+// ModelCatalog lists the token limits of Azure OpenAI chat models, keyed by model name. Model
+// versions such as "gpt-4.1-mini-2025-04-14" use the entry of their longest matching prefix.
+// Use AzureAIFoundry.ModelLimits to override or extend it.
+var ModelCatalog = map[string]ModelLimits{
+	"gpt-4.1":      {ContextWindow: 1047576, MaxOutputTokens: 32768, MaxCompletionTokens: true},
+	"gpt-4.1-mini": {ContextWindow: 1047576, MaxOutputTokens: 32768, MaxCompletionTokens: true},
+	"gpt-4.1-nano": {ContextWindow: 1047576, MaxOutputTokens: 32768, MaxCompletionTokens: true},
+	"gpt-4o":       {ContextWindow: 128000, MaxOutputTokens: 16384},
+	"gpt-4o-mini":  {ContextWindow: 128000, MaxOutputTokens: 16384},
+	"gpt-5":        {ContextWindow: 400000, MaxOutputTokens: 128000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+	"gpt-5-mini":   {ContextWindow: 400000, MaxOutputTokens: 128000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+	"gpt-5-nano":   {ContextWindow: 400000, MaxOutputTokens: 128000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+	"gpt-5-chat":   {ContextWindow: 128000, MaxOutputTokens: 16384, MaxCompletionTokens: true},
+	"o1":           {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+	"o1-mini":      {ContextWindow: 128000, MaxOutputTokens: 65536, MaxCompletionTokens: true, Reasoning: true},
+	"o3":           {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+	"o3-mini":      {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+	"o4-mini":      {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true, Reasoning: true, DeveloperRole: true},
+}
+
+// Common model names for long-context chat
+const (
+	ModelGPT41     = "gpt-4.1"
+	ModelGPT41Mini = "gpt-4.1-mini"
+	ModelGPT41Nano = "gpt-4.1-nano"
+)
+
+// TokenLimitError is returned when a request does not fit the token limits of its model.
+type TokenLimitError struct {
+	Model           string
+	PromptTokens    int // Estimated with the deployment's tokenizer
+	MaxOutputTokens int // Requested output limit, 0 if not set
+	Limits          ModelLimits
+}
+
+// Error implements the error interface.
+func (e *TokenLimitError) Error() string {
+	if e.MaxOutputTokens > e.Limits.MaxOutputTokens && e.Limits.MaxOutputTokens > 0 {
+		return fmt.Sprintf("maxOutputTokens %d is more than %s can generate (%d)", e.MaxOutputTokens, e.Model, e.Limits.MaxOutputTokens)
+	}
+	return fmt.Sprintf("prompt of about %d tokens plus maxOutputTokens %d does not fit the %d-token context window of %s",
+		e.PromptTokens, e.MaxOutputTokens, e.Limits.ContextWindow, e.Model)
+}
+
+// catalogLimits returns the limits of the longest ModelCatalog or ModelLimits key that
+// prefixes the model name
+func (a *AzureAIFoundry) catalogLimits(name string) (ModelLimits, bool) {
+	name = strings.ToLower(name)
+	var limits ModelLimits
+	match := ""
+	for _, catalog := range []map[string]ModelLimits{ModelCatalog, a.ModelLimits} {
+		for key, entry := range catalog {
+			// Plugin overrides win over catalog entries of the same length
+			if strings.HasPrefix(name, strings.ToLower(key)) && len(key) >= len(match) {
+				match, limits = key, entry
+			}
+		}
+	}
+	return limits, match != ""
+}
+
+// defineLimits resolves the limits of a model definition and remembers them for its
+// deployment. ModelDefinition.MaxTokens overrides the context window, ModelDefinition.Reasoning
+// marks a reasoning model and ModelDefinition.SystemRole selects the role of system messages.
+func (a *AzureAIFoundry) defineLimits(model ModelDefinition) {
+	name := model.ModelVersion
+	if name == "" {
+		name = model.Name
+	}
+	limits, ok := a.catalogLimits(name)
+	if model.MaxTokens > 0 {
+		limits.ContextWindow = int(model.MaxTokens)
+		ok = true
+	}
+	if model.Reasoning {
+		limits.Reasoning, limits.MaxCompletionTokens, limits.DeveloperRole = true, true, true
+		ok = true
+	}
+	switch model.SystemRole {
+	case "developer":
+		limits.DeveloperRole, ok = true, true
+	case "system":
+		limits.DeveloperRole, ok = false, true
+	}
+	if ok {
+		a.deploymentLimits.Store(model.Name, limits)
+	}
+}
+
+// modelLimits returns the limits of a deployment, from its definition or the catalog
+func (a *AzureAIFoundry) modelLimits(deployment string) (ModelLimits, bool) {
+	if limits, ok := a.deploymentLimits.Load(deployment); ok {
+		return limits.(ModelLimits), true
+	}
+	return a.catalogLimits(deployment)
+}
+
+// acceptsReasoningEffort reports whether reasoningEffort is sent to a deployment. It is left
+// out for models known not to reason, which reject it; unknown models receive it.
+func (a *AzureAIFoundry) acceptsReasoningEffort(deployment string) bool {
+	limits, ok := a.modelLimits(deployment)
+	return !ok || limits.Reasoning
+}
+
+// isReasoningModel reports whether a deployment is known to serve a reasoning model, which
+// takes max_completion_tokens and rejects temperature and topP
+func (a *AzureAIFoundry) isReasoningModel(deployment string) bool {
+	limits, ok := a.modelLimits(deployment)
+	return ok && limits.Reasoning
+}
+
+// usesDeveloperRole reports whether system messages are sent to a deployment with the
+// developer role
+func (a *AzureAIFoundry) usesDeveloperRole(deployment string) bool {
+	limits, ok := a.modelLimits(deployment)
+	return ok && limits.DeveloperRole
+}
+
+// checkTokenLimits rejects chat requests that would exceed the output limit or context
+// window of their model, before they are sent
+func (a *AzureAIFoundry) checkTokenLimits(deployment string, input *ai.ModelRequest, maxOutputTokens *int64) error {
+	limits, ok := a.modelLimits(deployment)
+	if !ok {
+		return nil
+	}
+	err := &TokenLimitError{Model: deployment, Limits: limits}
+	if maxOutputTokens != nil {
+		err.MaxOutputTokens = int(*maxOutputTokens)
+	}
+	if limits.MaxOutputTokens > 0 && err.MaxOutputTokens > limits.MaxOutputTokens {
+		return err
+	}
+	if limits.ContextWindow > 0 {
+		err.PromptTokens = a.CountTokens(deployment, input.Messages)
+		if err.PromptTokens+err.MaxOutputTokens > limits.ContextWindow {
+			return err
+		}
+	}
+	return nil
+}
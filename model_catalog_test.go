@@ -0,0 +1,216 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestCatalogLimits(t *testing.T) {
+	plugin := &AzureAIFoundry{ModelLimits: map[string]ModelLimits{
+		"gpt-4o":   {ContextWindow: 64000, MaxOutputTokens: 4096},
+		"phi-4-mm": {ContextWindow: 128000},
+	}}
+	tests := []struct {
+		name   string
+		want   ModelLimits
+		wantOK bool
+	}{
+		{"gpt-4.1", ModelCatalog["gpt-4.1"], true},
+		{"GPT-4.1-mini-2025-04-14", ModelCatalog["gpt-4.1-mini"], true},
+		{"gpt-4.1-nano", ModelCatalog["gpt-4.1-nano"], true},
+		{"gpt-4o-mini", ModelCatalog["gpt-4o-mini"], true},
+		{"o4-mini-2025-04-16", ModelCatalog["o4-mini"], true},
+		{"gpt-5-chat-2025-08-07", ModelCatalog["gpt-5-chat"], true},
+		{"gpt-4o-2024-11-20", ModelLimits{ContextWindow: 64000, MaxOutputTokens: 4096}, true},
+		{"phi-4-mm-instruct", ModelLimits{ContextWindow: 128000}, true},
+		{"my-deployment", ModelLimits{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := plugin.catalogLimits(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("catalogLimits() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLongContextModels(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	models := DefineCommonModels(plugin, g)
+	plugin.DefineModel(g, ModelDefinition{Name: "nano-prod", Type: "chat", ModelVersion: "gpt-4.1-nano-2025-04-14", MaxTokens: 100}, nil)
+
+	generate := func(model ai.Model, prompt string, maxOutputTokens int) error {
+		_, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt(prompt),
+			ai.WithConfig(map[string]any{"maxOutputTokens": maxOutputTokens}))
+		return err
+	}
+
+	// gpt-4.1 takes max_completion_tokens, older models max_tokens
+	if err := generate(models[ModelGPT41], "hi", 1000); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, ok := bodies[0]["max_tokens"]; ok || bodies[0]["max_completion_tokens"] != float64(1000) {
+		t.Fatalf("gpt-4.1 request = %v", bodies[0])
+	}
+	if err := generate(models["gpt-4o"], "hi", 1000); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if bodies[1]["max_tokens"] != float64(1000) {
+		t.Fatalf("gpt-4o request = %v", bodies[1])
+	}
+
+	tests := []struct {
+		name            string
+		model           ai.Model
+		prompt          string
+		maxOutputTokens int
+		want            string
+	}{
+		{"output limit", models[ModelGPT41Mini], "hi", 40000, "maxOutputTokens 40000 is more than gpt-4.1-mini can generate (32768)"},
+		{"context window", plugin.Model(g, "nano-prod"), strings.Repeat("word ", 100), 10, "does not fit the 100-token context window of nano-prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := generate(tt.model, tt.prompt, tt.maxOutputTokens)
+			var limitErr *TokenLimitError
+			if !errors.As(err, &limitErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("sent %d requests, want requests over the limits rejected locally", len(bodies))
+	}
+}
+
+func TestReasoningEffort(t *testing.T) {
+	tests := []struct {
+		model string
+		want  any // Expected reasoning_effort; nil means left out
+	}{
+		{"o3-mini", "low"},
+		{"o4-mini", "low"},
+		{"gpt-5", "low"},
+		{"gpt-5-chat", nil},
+		{"gpt-4o", nil},
+		{"my-deployment", "low"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+			_, err := plugin.generateText(context.Background(), tt.model, &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+				Config:   map[string]any{"reasoningEffort": "low"},
+			}, nil)
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if got := bodies[0]["reasoning_effort"]; got != tt.want {
+				t.Fatalf("reasoning_effort = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReasoningModelParameters(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	plugin.DefineModel(g, ModelDefinition{Name: "planner", Type: "chat", Reasoning: true}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "o3-mini", Type: "chat"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+
+	tests := []struct {
+		model     string
+		reasoning bool
+	}{
+		{"planner", true},
+		{"o3-mini", true},
+		{"gpt-4o", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			bodies = nil
+			_, err := genkit.Generate(ctx, g, ai.WithModel(plugin.Model(g, tt.model)), ai.WithPrompt("hi"),
+				ai.WithConfig(map[string]any{"maxOutputTokens": 500, "temperature": 0.2, "topP": 0.9}))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			body := bodies[0]
+			_, hasTemperature := body["temperature"]
+			_, hasTopP := body["top_p"]
+			_, hasMaxTokens := body["max_tokens"]
+			if hasTemperature == tt.reasoning || hasTopP == tt.reasoning || hasMaxTokens == tt.reasoning {
+				t.Fatalf("request = %v", body)
+			}
+			if tt.reasoning && body["max_completion_tokens"] != float64(500) {
+				t.Fatalf("max_completion_tokens = %v, want 500", body["max_completion_tokens"])
+			}
+		})
+	}
+}
+
+func TestDeveloperRole(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	plugin.DefineModel(g, ModelDefinition{Name: "o3-mini", Type: "chat"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "planner", Type: "chat", Reasoning: true}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "custom", Type: "chat", SystemRole: "developer"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "legacy", Type: "chat", ModelVersion: "gpt-5", SystemRole: "system"}, nil)
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"o3-mini", "developer"},
+		{"gpt-4o", "system"},
+		{"planner", "developer"},
+		{"custom", "developer"},
+		{"legacy", "system"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			bodies = nil
+			_, err := genkit.Generate(ctx, g, ai.WithModel(plugin.Model(g, tt.model)),
+				ai.WithSystem("Answer briefly."), ai.WithPrompt("hi"))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			first := bodies[0]["messages"].([]any)[0].(map[string]any)
+			if first["role"] != tt.want || first["content"] != "Answer briefly." {
+				t.Fatalf("first message = %v, want role %q", first, tt.want)
+			}
+		})
+	}
+}
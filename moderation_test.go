@@ -0,0 +1,101 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestModerateContent(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+
+	model := genkit.DefineModel(g, "test/moderator", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, Media: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			text := req.Messages[0].Text()
+			switch {
+			case strings.Contains(text, "filtered"):
+				return nil, errors.New(`400 Bad Request {"code":"content_filter","message":"The response was filtered"}`)
+			case strings.Contains(text, "buy now"):
+				return &ai.ModelResponse{Message: ai.NewModelTextMessage(
+					`{"scores":[{"category":"spam","score":0.9},{"category":"harassment","score":0.6}],"rationale":"Unsolicited ad."}`)}, nil
+			default:
+				return &ai.ModelResponse{Message: ai.NewModelTextMessage(
+					`{"scores":[{"category":"harassment","score":0.6}],"rationale":"Mildly rude."}`)}, nil
+			}
+		})
+
+	tests := []struct {
+		name         string
+		text         string
+		thresholds   map[string]ModerationThreshold
+		wantDecision ModerationDecision
+		wantFlagged  []string
+		wantPlatform bool
+	}{
+		{
+			name:         "review threshold",
+			text:         "you are not very smart",
+			wantDecision: ModerationReview,
+			wantFlagged:  []string{"harassment"},
+		},
+		{
+			name:         "custom thresholds",
+			text:         "you are not very smart",
+			thresholds:   map[string]ModerationThreshold{"harassment": {Review: 0.7, Block: 0.9}},
+			wantDecision: ModerationAllow,
+		},
+		{
+			name:         "block wins over review",
+			text:         "buy now!!!",
+			wantDecision: ModerationBlock,
+			wantFlagged:  []string{"harassment", "spam"},
+		},
+		{
+			name:         "azure content filter",
+			text:         "filtered content",
+			wantDecision: ModerationBlock,
+			wantPlatform: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ModerateContent(ctx, g, UserContent{Text: tt.text}, ModerationOptions{Model: model, Thresholds: tt.thresholds})
+			if err != nil {
+				t.Fatalf("ModerateContent() error = %v", err)
+			}
+			if result.Decision != tt.wantDecision || result.BlockedByPlatform != tt.wantPlatform {
+				t.Fatalf("result = %+v, want %s", result, tt.wantDecision)
+			}
+			if !reflect.DeepEqual(result.Flagged, tt.wantFlagged) {
+				t.Fatalf("Flagged = %v, want %v", result.Flagged, tt.wantFlagged)
+			}
+			if !tt.wantPlatform && len(result.Scores) != len(DefaultModerationCategories) {
+				t.Fatalf("Scores = %v, want every default category", result.Scores)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/openai/openai-go/v3"
+)
+
+// azureMLScope is the token scope of Azure ML online endpoints
+const azureMLScope = "https://ml.azure.com/.default"
+
+// OnlineEndpoint is an Azure ML managed online endpoint served as a Genkit model, such as a
+// fine-tuned open-source model behind a custom scoring script.
+type OnlineEndpoint struct {
+	Name       string                 // Model name registered with Genkit (required)
+	ScoringURI string                 // Scoring URI, e.g. "https://my-endpoint.westeurope.inference.ml.azure.com/score" (required)
+	APIKey     string                 // Endpoint key. When empty, requests use an Azure AD token from Credential
+	Credential azcore.TokenCredential // Credential for Azure AD tokens. Defaults to the plugin's Credential, then DefaultAzureCredential
+	Deployment string                 // Deployment requests are routed to, bypassing the endpoint's traffic split (optional)
+	Adapter    OnlineEndpointAdapter  // Converts requests and responses. Defaults to the chat completions format (optional)
+	HTTPClient *http.Client           // Defaults to http.DefaultClient (optional)
+}
+
+// OnlineEndpointAdapter converts Genkit requests to the body a scoring script expects and
+// its responses back.
+type OnlineEndpointAdapter interface {
+	// EncodeRequest returns the JSON body of the scoring request.
+	EncodeRequest(input *ai.ModelRequest) ([]byte, error)
+	// DecodeResponse converts the body of a successful scoring response.
+	DecodeResponse(data []byte, input *ai.ModelRequest) (*ai.ModelResponse, error)
+}
+
+// OnlineEndpointError is returned when a scoring request fails with a non-2xx status.
+type OnlineEndpointError struct {
+	Endpoint   string
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface.
+func (e *OnlineEndpointError) Error() string {
+	return fmt.Sprintf("online endpoint %q returned %d: %s", e.Endpoint, e.StatusCode, e.Body)
+}
+
+// DefineOnlineEndpoint defines a model that sends requests to an Azure ML online endpoint.
+// When info is nil, the model is registered as a multi-turn text model.
+func (a *AzureAIFoundry) DefineOnlineEndpoint(g *genkit.Genkit, endpoint OnlineEndpoint, info *ai.ModelInfo) ai.Model {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.initted {
+		panic("azureaifoundry: Init not called")
+	}
+	if endpoint.Name == "" || endpoint.ScoringURI == "" {
+		panic("azureaifoundry: OnlineEndpoint Name and ScoringURI are required")
+	}
+	if info == nil {
+		info = &ai.ModelInfo{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true}}
+	}
+	meta := &ai.ModelOptions{
+		Label:    a.Name() + "-" + endpoint.Name,
+		Supports: info.Supports,
+		Versions: info.Versions,
+	}
+	return genkit.DefineModel(g, api.NewName(a.Name(), endpoint.Name), meta, func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		resp, err := a.scoreOnlineEndpoint(ctx, endpoint, input, cb)
+		return resp, a.redactError(err)
+	})
+}
+
+// scoreOnlineEndpoint sends a request to an online endpoint. Scoring scripts do not stream,
+// so a streamed request receives the whole answer as one chunk.
+func (a *AzureAIFoundry) scoreOnlineEndpoint(ctx context.Context, endpoint OnlineEndpoint, input *ai.ModelRequest, cb ai.ModelStreamCallback) (resp *ai.ModelResponse, err error) {
+	started := Event{
+		Type:      EventRequestStarted,
+		Time:      time.Now(),
+		Model:     endpoint.Name,
+		Operation: OperationChat,
+		Streaming: cb != nil,
+	}
+	eventCtx := ctx
+	a.emit(ctx, started)
+	defer func() {
+		err = flowBudgetError(eventCtx, err)
+		var usage *ai.GenerationUsage
+		if resp != nil {
+			usage = resp.Usage
+		}
+		recordFlowBudget(eventCtx, usage)
+		a.emitCompleted(eventCtx, started, resp, usage, err)
+	}()
+
+	var categories []string
+	if ctx, categories, err = a.classifyRequest(ctx, input); err != nil {
+		return nil, err
+	}
+	if categories != nil {
+		defer func() {
+			if resp != nil {
+				resp.Custom = withCustomValue(resp.Custom, "dataCategories", categories)
+			}
+		}()
+	}
+	if err := a.checkResidency(ctx); err != nil {
+		return nil, err
+	}
+	var end func()
+	if ctx, end, err = a.lifecycle.track(ctx); err != nil {
+		return nil, err
+	}
+	defer end()
+	if err := chargeFlowBudget(ctx); err != nil {
+		return nil, err
+	}
+	adapter := endpoint.Adapter
+	if adapter == nil {
+		adapter = chatCompletionsAdapter{a: a, model: endpoint.Name}
+	}
+	body, err := adapter.EncodeRequest(input)
+	if err != nil {
+		return nil, fmt.Errorf("encode request for online endpoint %q: %w", endpoint.Name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.ScoringURI, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if endpoint.Deployment != "" {
+		req.Header.Set("azureml-model-deployment", endpoint.Deployment)
+	}
+	if err := a.authorizeOnlineEndpoint(ctx, req, endpoint); err != nil {
+		return nil, err
+	}
+
+	client := endpoint.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("online endpoint %q: %w", endpoint.Name, err)
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("online endpoint %q: %w", endpoint.Name, err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, &OnlineEndpointError{Endpoint: endpoint.Name, StatusCode: httpResp.StatusCode, Body: string(data)}
+	}
+
+	if resp, err = adapter.DecodeResponse(data, input); err != nil {
+		return nil, fmt.Errorf("decode response of online endpoint %q: %w", endpoint.Name, err)
+	}
+	resp.Request = input
+	if cb != nil && resp.Message != nil {
+		if err := cb(ctx, &ai.ModelResponseChunk{Role: ai.RoleModel, Content: resp.Message.Content}); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// authorizeOnlineEndpoint adds the endpoint key, or an Azure AD token when there is none
+func (a *AzureAIFoundry) authorizeOnlineEndpoint(ctx context.Context, req *http.Request, endpoint OnlineEndpoint) error {
+	if endpoint.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+endpoint.APIKey)
+		return nil
+	}
+	credential := endpoint.Credential
+	if credential == nil {
+		credential = a.Credential
+	}
+	if credential == nil {
+		var err error
+		if credential, err = azidentity.NewDefaultAzureCredential(nil); err != nil {
+			return fmt.Errorf("failed to create default credential: %w", err)
+		}
+	}
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureMLScope}})
+	if err != nil {
+		return fmt.Errorf("get token for online endpoint %q: %w", endpoint.Name, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	return nil
+}
+
+// chatCompletionsAdapter sends requests in the chat completions format, converted the same
+// way as for Azure OpenAI deployments
+type chatCompletionsAdapter struct {
+	a     *AzureAIFoundry
+	model string
+}
+
+// EncodeRequest returns a chat completions request body.
+func (c chatCompletionsAdapter) EncodeRequest(input *ai.ModelRequest) ([]byte, error) {
+	return json.Marshal(c.a.buildChatCompletionParams(input, c.model))
+}
+
+// DecodeResponse converts a chat completions response body.
+func (c chatCompletionsAdapter) DecodeResponse(data []byte, input *ai.ModelRequest) (*ai.ModelResponse, error) {
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, err
+	}
+	return c.a.convertResponse(&completion, input), nil
+}
+
+// InputDataAdapter sends requests in the "input_data" format of Azure ML model catalog
+// deployments and custom scoring scripts following it:
+//
+//	{"input_data": {"input_string": [{"role": "user", "content": "..."}], "parameters": {...}}}
+//
+// The answer is read from an "output" field, or from the first item of a list response.
+type InputDataAdapter struct {
+	Parameters map[string]any // Parameters sent with every request, e.g. {"do_sample": true}; request config takes precedence
+}
+
+// EncodeRequest returns an input_data request body. The temperature, topP and
+// maxOutputTokens config options are sent as temperature, top_p and max_new_tokens.
+func (d InputDataAdapter) EncodeRequest(input *ai.ModelRequest) ([]byte, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	messages := make([]message, 0, len(input.Messages))
+	for _, msg := range input.Messages {
+		role := string(msg.Role)
+		if msg.Role == ai.RoleModel {
+			role = "assistant"
+		}
+		messages = append(messages, message{Role: role, Content: msg.Text()})
+	}
+
+	parameters := make(map[string]any, len(d.Parameters))
+	for key, val := range d.Parameters {
+		parameters[key] = val
+	}
+	config, _ := normalizeConfig(input.Config)
+	for option, parameter := range map[string]string{"temperature": "temperature", "topP": "top_p", "maxOutputTokens": "max_new_tokens"} {
+		if val, ok := config[option]; ok {
+			parameters[parameter] = val
+		}
+	}
+
+	return json.Marshal(map[string]any{
+		"input_data": map[string]any{
+			"input_string": messages,
+			"parameters":   parameters,
+		},
+	})
+}
+
+// DecodeResponse reads the answer from {"output": "..."}, ["..."] or [{"0": "..."}].
+func (d InputDataAdapter) DecodeResponse(data []byte, input *ai.ModelRequest) (*ai.ModelResponse, error) {
+	var text string
+	var object struct {
+		Output *string `json:"output"`
+	}
+	var list []json.RawMessage
+	switch {
+	case json.Unmarshal(data, &object) == nil && object.Output != nil:
+		text = *object.Output
+	case json.Unmarshal(data, &list) == nil && len(list) > 0:
+		var item map[string]string
+		if json.Unmarshal(list[0], &text) != nil {
+			if err := json.Unmarshal(list[0], &item); err != nil || item["0"] == "" {
+				return nil, fmt.Errorf("unexpected response item %s", list[0])
+			}
+			text = item["0"]
+		}
+	default:
+		return nil, fmt.Errorf("unexpected response %s", data)
+	}
+	return &ai.ModelResponse{
+		Message:      ai.NewModelTextMessage(text),
+		FinishReason: ai.FinishReasonStop,
+	}, nil
+}
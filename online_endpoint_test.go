@@ -0,0 +1,150 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestOnlineEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   OnlineEndpoint
+		response   string
+		wantAuth   string
+		wantBody   string // Expected JSON request body
+		wantText   string
+		wantScopes []string
+	}{
+		{
+			name:     "chat completions with key",
+			endpoint: OnlineEndpoint{Name: "llama-ft", APIKey: "endpoint-key", Deployment: "blue"},
+			response: chatCompletionJSON,
+			wantAuth: "Bearer endpoint-key",
+			wantBody: `{"messages":[{"content":"hi","role":"user"}],"model":"llama-ft"}`,
+			wantText: "ok",
+		},
+		{
+			name:       "input data with Azure AD",
+			endpoint:   OnlineEndpoint{Name: "phi-ft", Adapter: InputDataAdapter{Parameters: map[string]any{"do_sample": true}}},
+			response:   `{"output":"hello"}`,
+			wantAuth:   "Bearer token",
+			wantBody:   `{"input_data":{"input_string":[{"role":"user","content":"hi"}],"parameters":{"do_sample":true,"max_new_tokens":50}}}`,
+			wantText:   "hello",
+			wantScopes: []string{azureMLScope},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			credential := &staticCredential{}
+			plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Credential: credential}
+			ctx := context.Background()
+			g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+			endpoint := tt.endpoint
+			endpoint.ScoringURI = server.URL + "/score"
+			model := plugin.DefineOnlineEndpoint(g, endpoint, nil)
+
+			config := map[string]any(nil)
+			if endpoint.Adapter != nil {
+				config = map[string]any{"max_tokens": 50}
+			}
+			var streamed string
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("hi"), ai.WithConfig(config),
+				ai.WithStreaming(func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+					streamed += chunk.Text()
+					return nil
+				}))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if resp.Text() != tt.wantText || streamed != tt.wantText {
+				t.Fatalf("Text() = %q, streamed %q, want %q", resp.Text(), streamed, tt.wantText)
+			}
+			if got.URL.Path != "/score" || got.Header.Get("Authorization") != tt.wantAuth || got.Header.Get("azureml-model-deployment") != endpoint.Deployment {
+				t.Fatalf("request = %s %v", got.URL.Path, got.Header)
+			}
+			var gotBody, wantBody any
+			_ = json.Unmarshal(body, &gotBody)
+			_ = json.Unmarshal([]byte(tt.wantBody), &wantBody)
+			if !reflect.DeepEqual(gotBody, wantBody) {
+				t.Fatalf("body = %s, want %s", body, tt.wantBody)
+			}
+			if !reflect.DeepEqual(credential.scopes, tt.wantScopes) {
+				t.Fatalf("token scopes = %v, want %v", credential.scopes, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func TestOnlineEndpointError(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFailedDependency)
+		_, _ = io.WriteString(w, "model not ready")
+	})
+	_, err := plugin.scoreOnlineEndpoint(context.Background(), OnlineEndpoint{Name: "llama-ft", ScoringURI: plugin.Endpoint + "score", APIKey: "key"},
+		&ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+	var endpointErr *OnlineEndpointError
+	if !errors.As(err, &endpointErr) || endpointErr.StatusCode != http.StatusFailedDependency || endpointErr.Body != "model not ready" {
+		t.Fatalf("error = %v, want *OnlineEndpointError", err)
+	}
+}
+
+func TestInputDataAdapterDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"output field", `{"output":"hello"}`, "hello", false},
+		{"list of strings", `["hello"]`, "hello", false},
+		{"list of objects", `[{"0":"hello"}]`, "hello", false},
+		{"unexpected", `{"result":"hello"}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := InputDataAdapter{}.DecodeResponse([]byte(tt.body), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeResponse() error = %v", err)
+			}
+			if err == nil && resp.Text() != tt.want {
+				t.Fatalf("Text() = %q, want %q", resp.Text(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3/azure"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// AuthStyle selects how the API key is sent to the endpoint.
+type AuthStyle string
+
+const (
+	// AuthStyleDefault sends the key in the api-key header to Azure and as a bearer token to
+	// OpenAI-compatible endpoints.
+	AuthStyleDefault AuthStyle = ""
+	// AuthStyleAPIKey sends the key in the api-key header, as Azure OpenAI expects.
+	AuthStyleAPIKey AuthStyle = "api-key"
+	// AuthStyleBearer sends the key as "Authorization: Bearer <key>", as vLLM and Azure ML
+	// online endpoints expect.
+	AuthStyleBearer AuthStyle = "bearer"
+	// AuthStyleHeader sends the key as is in the header named by AuthHeader.
+	AuthStyleHeader AuthStyle = "header"
+	// AuthStyleNone sends no key, e.g. to a self-hosted endpoint on a private network.
+	AuthStyleNone AuthStyle = "none"
+)
+
+// authStyle returns the auth style in effect, resolving AuthStyleDefault
+func (a *AzureAIFoundry) authStyle() AuthStyle {
+	if a.AuthStyle != AuthStyleDefault {
+		return a.AuthStyle
+	}
+	if a.OpenAICompatible {
+		return AuthStyleBearer
+	}
+	return AuthStyleAPIKey
+}
+
+// apiKeyHeader returns the header that carries key in the plugin's auth style, or "" when
+// no key is sent
+func (a *AzureAIFoundry) apiKeyHeader(key string) (name, value string) {
+	switch a.authStyle() {
+	case AuthStyleAPIKey:
+		return "Api-Key", key
+	case AuthStyleBearer:
+		return "Authorization", "Bearer " + key
+	case AuthStyleHeader:
+		return a.AuthHeader, key
+	}
+	return "", ""
+}
+
+// apiVersion returns the Azure OpenAI API version requests use, or "" for the v1 API
+func (a *AzureAIFoundry) apiVersion() string {
+	if a.UseV1API {
+		return ""
+	}
+	if a.APIVersion == "" {
+		return "2025-03-01-preview"
+	}
+	return a.APIVersion
+}
+
+// v1BaseURL returns the base URL of the Azure OpenAI v1 API of an endpoint, which may
+// already end in "/openai/v1"
+func v1BaseURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/openai/v1") {
+		endpoint += "/openai/v1"
+	}
+	return endpoint + "/"
+}
+
+// connectionOptions returns the client options that point requests at the endpoint and
+// authenticate them. Azure endpoints route requests by deployment and API version;
+// OpenAI-compatible endpoints and the Azure v1 API take the model in the body.
+func (a *AzureAIFoundry) connectionOptions() ([]option.RequestOption, error) {
+	if err := a.checkGateway(); err != nil {
+		return nil, err
+	}
+	var opts []option.RequestOption
+	switch {
+	case a.UseV1API && a.OpenAICompatible:
+		return nil, fmt.Errorf("UseV1API and OpenAICompatible cannot be combined")
+	case a.OpenAICompatible:
+		opts = append(opts, option.WithBaseURL(a.Endpoint))
+	case a.UseV1API:
+		// The v1 API needs no api-version and takes the deployment name as the model
+		opts = append(opts, option.WithBaseURL(v1BaseURL(a.Endpoint)))
+	default:
+		// Use azure.WithEndpoint which properly handles Azure OpenAI deployment-based URLs
+		opts = append(opts, azure.WithEndpoint(a.Endpoint, a.apiVersion()))
+	}
+
+	style := a.authStyle()
+	if style == AuthStyleHeader && a.AuthHeader == "" {
+		return nil, fmt.Errorf("AuthHeader is required with AuthStyleHeader")
+	}
+	var tokenOpts []azure.TokenCredentialOption
+	if len(a.TokenScopes) > 0 {
+		tokenOpts = append(tokenOpts, azure.WithTokenCredentialScopes(a.TokenScopes))
+	}
+
+	switch {
+	case style == AuthStyleNone:
+	case a.APIKey != "":
+		// Use API key authentication
+		opts = append(opts, option.WithHeader(a.apiKeyHeader(a.APIKey)))
+	case a.Credential != nil:
+		// Use token credential
+		opts = append(opts, azure.WithTokenCredential(a.Credential, tokenOpts...))
+	case a.OpenAICompatible:
+		// Self-hosted endpoints often need no auth, so no Azure credential is looked up
+	default:
+		// Try default Azure credential
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create default credential: %w", err)
+		}
+		opts = append(opts, azure.WithTokenCredential(cred, tokenOpts...))
+	}
+	// Set last, so they can replace the authentication headers
+	for name, value := range a.Headers {
+		opts = append(opts, option.WithHeader(name, value))
+	}
+	return opts, nil
+}
+
+// modelsURL returns the URL that lists the models of an endpoint, used as a health probe
+func (a *AzureAIFoundry) modelsURL(endpoint, apiVersion string) string {
+	if a.OpenAICompatible {
+		return endpoint + "models"
+	}
+	if a.UseV1API {
+		return v1BaseURL(endpoint) + "models"
+	}
+	return endpoint + "openai/models?api-version=" + apiVersion
+}
+
+// Generate sends a chat request to the named model of the plugin's endpoint. It makes a
+// plugin instance usable as another one's Fallback, e.g. a self-hosted OpenAI-compatible
+// endpoint serving requests while Azure is unreachable.
+func (a *AzureAIFoundry) Generate(ctx context.Context, modelName string, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+	return a.generateText(ctx, modelName, input, cb)
+}
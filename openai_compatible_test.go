@@ -0,0 +1,316 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestOpenAICompatibleAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		compatible bool
+		style      AuthStyle
+		wantPath   string
+		wantHeader string // Header that must carry the key
+		wantValue  string
+	}{
+		{"azure default", false, AuthStyleDefault, "/openai/deployments/gpt-4o/chat/completions", "Api-Key", "secret"},
+		{"azure bearer", false, AuthStyleBearer, "/openai/deployments/gpt-4o/chat/completions", "Authorization", "Bearer secret"},
+		{"compatible default", true, AuthStyleDefault, "/v1/chat/completions", "Authorization", "Bearer secret"},
+		{"compatible api-key", true, AuthStyleAPIKey, "/v1/chat/completions", "Api-Key", "secret"},
+		{"compatible custom header", true, AuthStyleHeader, "/v1/chat/completions", "X-Gateway-Key", "secret"},
+		{"compatible no auth", true, AuthStyleNone, "/v1/chat/completions", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*http.Request
+			var bodies []map[string]any
+			capture := captureRequests(&bodies, chatCompletionJSON)
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				capture(w, r)
+			}, func(a *AzureAIFoundry) {
+				a.APIKey = "secret"
+				a.OpenAICompatible = tt.compatible
+				a.AuthStyle = tt.style
+				a.AuthHeader = "X-Gateway-Key"
+				if tt.compatible {
+					a.Endpoint += "v1/"
+				}
+			})
+
+			resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+			if err != nil || resp.Text() != "ok" {
+				t.Fatalf("generateText() = %v, %v", resp, err)
+			}
+			req := requests[0]
+			if req.URL.Path != tt.wantPath || bodies[0]["model"] != "gpt-4o" {
+				t.Fatalf("request = %s %v, want %s", req.URL.Path, bodies[0]["model"], tt.wantPath)
+			}
+			if got := req.URL.Query().Get("api-version"); (got != "") == tt.compatible {
+				t.Fatalf("api-version = %q", got)
+			}
+			for _, header := range []string{"Api-Key", "Authorization", "X-Gateway-Key"} {
+				want := ""
+				if header == tt.wantHeader {
+					want = tt.wantValue
+				}
+				if got := req.Header.Get(header); got != want {
+					t.Fatalf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "added to requests",
+			headers: map[string]string{"Ocp-Apim-Subscription-Key": "apim-key", "X-Route": "eu"},
+			want:    map[string]string{"Ocp-Apim-Subscription-Key": "apim-key", "X-Route": "eu", "Api-Key": "secret"},
+		},
+		{
+			name:    "replace plugin headers",
+			headers: map[string]string{"Api-Key": "gateway-key"},
+			want:    map[string]string{"Api-Key": "gateway-key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*http.Request
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/embeddings") {
+					_, _ = io.WriteString(w, `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]}],"usage":{"prompt_tokens":1,"total_tokens":1}}`)
+					return
+				}
+				_, _ = io.WriteString(w, chatCompletionJSON)
+			}, func(a *AzureAIFoundry) {
+				a.APIKey = "secret"
+				a.Headers = tt.headers
+			})
+
+			if _, err := plugin.generateText(t.Context(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if _, err := plugin.embed(t.Context(), "text-embedding-3-small", &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText("hi", nil)}}); err != nil {
+				t.Fatalf("embed() error = %v", err)
+			}
+			for _, req := range requests {
+				for header, want := range tt.want {
+					if got := req.Header.Get(header); got != want {
+						t.Errorf("%s %s = %q, want %q", req.URL.Path, header, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestV1API(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string // Appended to the test server URL
+	}{
+		{"resource endpoint", ""},
+		{"v1 endpoint", "openai/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*http.Request
+			var bodies []map[string]any
+			var audit bytes.Buffer
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				data, _ := io.ReadAll(r.Body)
+				var body map[string]any
+				_ = json.Unmarshal(data, &body)
+				bodies = append(bodies, body)
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/embeddings") {
+					_, _ = io.WriteString(w, `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]}],"usage":{"prompt_tokens":1,"total_tokens":1}}`)
+					return
+				}
+				_, _ = io.WriteString(w, chatCompletionJSON)
+			}, func(a *AzureAIFoundry) {
+				a.APIKey = "secret"
+				a.UseV1API = true
+				a.APIVersion = "2024-02-01"
+				a.Endpoint += tt.endpoint
+				a.AuditSink = NewJSONLAuditSink(&audit)
+			})
+
+			if _, err := plugin.generateText(t.Context(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil); err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if _, err := plugin.embed(t.Context(), "text-embedding-3-small", &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText("hi", nil)}}); err != nil {
+				t.Fatalf("embed() error = %v", err)
+			}
+			want := []string{"/openai/v1/chat/completions gpt-4o", "/openai/v1/embeddings text-embedding-3-small"}
+			for i, req := range requests {
+				if got := req.URL.Path + " " + fmt.Sprint(bodies[i]["model"]); got != want[i] {
+					t.Errorf("request %d = %s, want %s", i, got, want[i])
+				}
+				if req.URL.RawQuery != "" || req.Header.Get("Api-Key") != "secret" {
+					t.Errorf("request %d query = %q, api-key = %q", i, req.URL.RawQuery, req.Header.Get("Api-Key"))
+				}
+			}
+			// The paths name no deployment, so records take the model from the body
+			decoder := json.NewDecoder(&audit)
+			for _, want := range []string{"gpt-4o", "text-embedding-3-small"} {
+				var record AuditRecord
+				if err := decoder.Decode(&record); err != nil || record.Model != want {
+					t.Errorf("audit record model = %q, error = %v, want %s", record.Model, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestV1APIFailover(t *testing.T) {
+	primary := newTestPlugin(t, failingHandler(http.StatusServiceUnavailable))
+	var paths []string
+	secondary := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+" "+r.Header.Get("Api-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	})
+	plugin := &AzureAIFoundry{
+		Endpoint:          primary.Endpoint,
+		APIKey:            "primary-key",
+		UseV1API:          true,
+		FailoverEndpoints: []Endpoint{{URL: secondary.Endpoint, APIKey: "secondary-key"}},
+	}
+	plugin.Init(t.Context())
+
+	resp, err := plugin.generateText(t.Context(), "gpt-4o", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+	if err != nil || resp.Text() != "ok" {
+		t.Fatalf("generateText() = %v, %v", resp, err)
+	}
+	if len(paths) != 1 || paths[0] != "/openai/v1/chat/completions secondary-key" {
+		t.Fatalf("secondary requests = %v", paths)
+	}
+	if got := plugin.modelsURL(plugin.endpoints.endpoints[1].URL, ""); got != secondary.Endpoint+"openai/v1/models" {
+		t.Fatalf("modelsURL() = %q", got)
+	}
+}
+
+func TestV1APIWithOpenAICompatible(t *testing.T) {
+	a := &AzureAIFoundry{Endpoint: "http://localhost:8000/v1", UseV1API: true, OpenAICompatible: true}
+	if _, err := a.connectionOptions(); err == nil {
+		t.Fatal("connectionOptions() error = nil, want an error")
+	}
+}
+
+func TestOpenAICompatibleStreamingTools(t *testing.T) {
+	var paths []string
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":"}}]}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		} {
+			_, _ = io.WriteString(w, "data: "+chunk+"\n\n")
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}, func(a *AzureAIFoundry) {
+		a.OpenAICompatible = true
+		a.AuthStyle = AuthStyleNone
+	})
+
+	resp, err := plugin.generateText(context.Background(), "meta-llama/Llama-3.1-8B-Instruct", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("Weather in Paris?")},
+		Tools:    []*ai.ToolDefinition{{Name: "weather", InputSchema: map[string]any{"type": "object"}}},
+	}, func(context.Context, *ai.ModelResponseChunk) error { return nil })
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/chat/completions" {
+		t.Fatalf("paths = %v", paths)
+	}
+	parts := resp.Message.Content
+	if len(parts) != 1 || !parts[0].IsToolRequest() || parts[0].ToolRequest.Name != "weather" {
+		t.Fatalf("Content = %+v, want the weather tool request", parts)
+	}
+	if input, _ := parts[0].ToolRequest.Input.(map[string]any); input["city"] != "Paris" {
+		t.Fatalf("tool input = %v", parts[0].ToolRequest.Input)
+	}
+}
+
+func TestOpenAICompatibleFailover(t *testing.T) {
+	primary := newTestPlugin(t, failingHandler(http.StatusServiceUnavailable))
+	var auth []string
+	secondary := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.URL.Path+" "+r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	})
+	plugin := &AzureAIFoundry{
+		Endpoint:          primary.Endpoint,
+		APIKey:            "primary-key",
+		OpenAICompatible:  true,
+		FailoverEndpoints: []Endpoint{{URL: secondary.Endpoint, APIKey: "secondary-key"}},
+	}
+	plugin.Init(context.Background())
+
+	resp, err := plugin.generateText(context.Background(), "llama", &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+	if err != nil || resp.Text() != "ok" {
+		t.Fatalf("generateText() = %v, %v", resp, err)
+	}
+	if len(auth) != 1 || auth[0] != "/chat/completions Bearer secondary-key" {
+		t.Fatalf("secondary requests = %v", auth)
+	}
+}
+
+func TestOpenAICompatibleFallback(t *testing.T) {
+	var bodies []map[string]any
+	onPrem := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.ProviderID = "on-prem"
+		a.OpenAICompatible = true
+	})
+	plugin := newTestPlugin(t, failingHandler(http.StatusServiceUnavailable), func(a *AzureAIFoundry) {
+		a.Fallback = onPrem
+	})
+	_, fn := plugin.modelAction(ModelDefinition{Name: "gpt-4o"}, nil)
+
+	resp, err := fn(context.Background(), &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}, nil)
+	if err != nil || resp.Text() != "ok" {
+		t.Fatalf("model = %v, %v", resp, err)
+	}
+	if resp.Custom.(map[string]any)["fallback"] != "on-prem" || len(bodies) != 1 || bodies[0]["model"] != "gpt-4o" {
+		t.Fatalf("Custom = %v, on-prem requests = %v", resp.Custom, bodies)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/base64"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Metadata keys set on the parts of responses for every modality, so consumers and traces
+// can handle outputs generically
+const (
+	PartMetadataMIME          = "mime"          // Media type of the part content, e.g. "text/plain", "image/png" or "audio/mpeg"
+	PartMetadataByteSize      = "byteSize"      // Size of the decoded image or audio in bytes
+	PartMetadataDurationSec   = "durationSec"   // Length of the audio in seconds, for transcriptions and WAV or PCM speech
+	PartMetadataVoice         = "voice"         // Voice of generated speech
+	PartMetadataImageSize     = "imageSize"     // Requested image dimensions, e.g. "1024x1024"
+	PartMetadataRevisedPrompt = "revisedPrompt" // Prompt the image model actually used
+	PartMetadataDeployment    = "deployment"    // Deployment that produced the part
+	PartMetadataRegion        = "region"        // Azure region of the endpoint, when AzureAIFoundry.Region is set
+)
+
+// PartMetadata is the typed form of the metadata the plugin sets on returned parts. Zero
+// fields are not set.
+type PartMetadata struct {
+	MIME          string
+	ByteSize      int
+	DurationSec   float64
+	Voice         string
+	ImageSize     string
+	RevisedPrompt string
+	Deployment    string
+	Region        string
+}
+
+// PartMetadataOf reads the plugin's metadata from a part.
+func PartMetadataOf(part *ai.Part) PartMetadata {
+	var m PartMetadata
+	if part == nil {
+		return m
+	}
+	m.MIME, _ = part.Metadata[PartMetadataMIME].(string)
+	m.ByteSize, _ = part.Metadata[PartMetadataByteSize].(int)
+	m.DurationSec, _ = part.Metadata[PartMetadataDurationSec].(float64)
+	m.Voice, _ = part.Metadata[PartMetadataVoice].(string)
+	m.ImageSize, _ = part.Metadata[PartMetadataImageSize].(string)
+	m.RevisedPrompt, _ = part.Metadata[PartMetadataRevisedPrompt].(string)
+	m.Deployment, _ = part.Metadata[PartMetadataDeployment].(string)
+	m.Region, _ = part.Metadata[PartMetadataRegion].(string)
+	return m
+}
+
+// apply sets the non-zero fields on a part, keeping its other metadata
+func (m PartMetadata) apply(part *ai.Part) {
+	values := map[string]any{
+		PartMetadataMIME:          m.MIME,
+		PartMetadataByteSize:      m.ByteSize,
+		PartMetadataDurationSec:   m.DurationSec,
+		PartMetadataVoice:         m.Voice,
+		PartMetadataImageSize:     m.ImageSize,
+		PartMetadataRevisedPrompt: m.RevisedPrompt,
+		PartMetadataDeployment:    m.Deployment,
+		PartMetadataRegion:        m.Region,
+	}
+	for key, val := range values {
+		if val == "" || val == 0 || val == 0.0 {
+			continue
+		}
+		if part.Metadata == nil {
+			part.Metadata = make(map[string]any, len(values))
+		}
+		part.Metadata[key] = val
+	}
+}
+
+// partMetadata returns the metadata shared by all parts a deployment returns
+func (a *AzureAIFoundry) partMetadata(deployment string) PartMetadata {
+	return PartMetadata{Deployment: deployment, Region: a.Region}
+}
+
+// annotateChatParts sets the metadata of the parts of a chat response. Text and reasoning are
+// plain text; tool requests only carry the deployment and region.
+func (a *AzureAIFoundry) annotateChatParts(resp *ai.ModelResponse, deployment string) {
+	if resp == nil || resp.Message == nil {
+		return
+	}
+	for _, part := range resp.Message.Content {
+		m := a.partMetadata(deployment)
+		if part.IsText() || part.IsReasoning() {
+			m.MIME = "text/plain"
+		}
+		m.apply(part)
+	}
+}
+
+// speechMIMETypes maps text-to-speech response formats to media types
+var speechMIMETypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"opus": "audio/opus",
+	"aac":  "audio/aac",
+	"flac": "audio/flac",
+	"wav":  "audio/wav",
+	"pcm":  "audio/pcm",
+}
+
+// speechPCMRate is the sample rate of "pcm" speech: 24 kHz, 16-bit, mono
+const speechPCMRate = 24000
+
+// speechDuration returns the length of generated speech in seconds, for the uncompressed
+// formats it can be computed for
+func speechDuration(format string, audio []byte) float64 {
+	switch format {
+	case "pcm":
+		return float64(len(audio)) / (speechPCMRate * 2)
+	case "wav":
+		if wav, err := parseWAV(audio); err == nil {
+			return float64(len(wav.data)) / float64(wav.sampleRate*wav.channels*2)
+		}
+	}
+	return 0
+}
+
+// transcriptMIMETypes maps transcription response formats to the media type of the text
+var transcriptMIMETypes = map[string]string{
+	"srt": "application/x-subrip",
+	"vtt": "text/vtt",
+}
+
+// decodedSize returns the size of base64 data once decoded
+func decodedSize(b64 string) int {
+	return base64.StdEncoding.DecodedLen(len(b64)) - countPadding(b64)
+}
+
+// countPadding returns the number of "=" padding characters at the end of base64 data
+func countPadding(b64 string) int {
+	n := 0
+	for i := len(b64) - 1; i >= 0 && b64[i] == '='; i-- {
+		n++
+	}
+	return n
+}
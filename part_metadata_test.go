@@ -0,0 +1,105 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestPartMetadata(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG image"))
+	speech := make([]byte, speechPCMRate) // Half a second of 16-bit PCM
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/generations"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"created":1,"data":[{"b64_json":"`+png+`","revised_prompt":"A red fox in snow"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/audio/speech"):
+			w.Header().Set("Content-Type", "audio/pcm")
+			_, _ = w.Write(speech)
+		case strings.HasSuffix(r.URL.Path, "/audio/transcriptions"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"text":"Hello","duration":2.5,"language":"english"}`)
+		default:
+			captureRequests(new([]map[string]any), chatCompletionJSON)(w, r)
+		}
+	}, func(a *AzureAIFoundry) {
+		a.Region = "westeurope"
+		a.DisableMediaValidation = true
+	})
+
+	tests := []struct {
+		name  string
+		model string
+		input *ai.ModelRequest
+		want  PartMetadata
+	}{
+		{
+			name:  "chat",
+			model: "gpt-4o",
+			input: &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}},
+			want:  PartMetadata{MIME: "text/plain", Deployment: "gpt-4o", Region: "westeurope"},
+		},
+		{
+			name:  "image",
+			model: "dall-e-3",
+			input: &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("a fox")},
+				Config:   map[string]any{"size": "1792x1024", "response_format": "b64_json"},
+			},
+			want: PartMetadata{MIME: "image/png", ByteSize: 10, ImageSize: "1792x1024", RevisedPrompt: "A red fox in snow", Deployment: "dall-e-3", Region: "westeurope"},
+		},
+		{
+			name:  "speech",
+			model: "tts-1",
+			input: &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("Hello")},
+				Config:   map[string]any{"voice": "nova", "response_format": "pcm"},
+			},
+			want: PartMetadata{MIME: "audio/pcm", ByteSize: len(speech), DurationSec: 0.5, Voice: "nova", Deployment: "tts-1", Region: "westeurope"},
+		},
+		{
+			name:  "transcription",
+			model: "whisper",
+			input: &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserMessage(ai.NewMediaPart("audio/mpeg", "data:audio/mpeg;base64,"+base64.StdEncoding.EncodeToString([]byte("audio"))))},
+				Config:   map[string]any{"response_format": "verbose_json"},
+			},
+			want: PartMetadata{MIME: "text/plain", DurationSec: 2.5, Deployment: "whisper", Region: "westeurope"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := plugin.generateText(context.Background(), tt.model, tt.input, nil)
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if got := PartMetadataOf(resp.Message.Content[0]); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("PartMetadataOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+)
+
+// PromptCacheStats is the prompt cache usage of a deployment's chat requests.
+type PromptCacheStats struct {
+	Requests          int `json:"requests"` // Requests that reported usage
+	InputTokens       int `json:"inputTokens"`
+	CachedInputTokens int `json:"cachedInputTokens"` // Part of InputTokens served from the prompt cache
+}
+
+// CacheHitRate returns the fraction of input tokens served from the prompt cache.
+func (s PromptCacheStats) CacheHitRate() float64 {
+	return CacheHitRate(&ai.GenerationUsage{InputTokens: s.InputTokens, CachedContentTokens: s.CachedInputTokens})
+}
+
+// PromptCacheStats returns the prompt cache usage of chat requests per deployment name,
+// including those of scopes.
+func (a *AzureAIFoundry) PromptCacheStats() map[string]PromptCacheStats {
+	a.cacheStatsMu.Lock()
+	defer a.cacheStatsMu.Unlock()
+	return maps.Clone(a.cacheStats)
+}
+
+// recordPromptCache adds the usage of a chat request to the prompt cache stats of its deployment
+func (a *AzureAIFoundry) recordPromptCache(modelName string, usage *ai.GenerationUsage) {
+	if usage == nil {
+		return
+	}
+	a.cacheStatsMu.Lock()
+	defer a.cacheStatsMu.Unlock()
+	if a.cacheStats == nil {
+		a.cacheStats = make(map[string]PromptCacheStats)
+	}
+	stats := a.cacheStats[modelName]
+	stats.Requests++
+	stats.InputTokens += usage.InputTokens
+	stats.CachedInputTokens += usage.CachedContentTokens
+	a.cacheStats[modelName] = stats
+}
+
+// stabilizePrefix makes the part of a chat request Azure caches, the tools and the system
+// messages before the conversation, serialize to the same bytes for the same prompt. Tools
+// are sorted by name, schemas are re-encoded with sorted keys and the leading system messages
+// get normalized line endings and whitespace. The order of the messages is kept.
+func stabilizePrefix(params *openai.ChatCompletionNewParams) {
+	slices.SortStableFunc(params.Tools, func(a, b openai.ChatCompletionToolUnionParam) int {
+		return strings.Compare(toolName(a), toolName(b))
+	})
+	for _, tool := range params.Tools {
+		if fn := tool.OfFunction; fn != nil {
+			if fn.Function.Description.Valid() {
+				fn.Function.Description = openai.String(normalizePromptText(fn.Function.Description.Value))
+			}
+			if fn.Function.Parameters != nil {
+				if canonical, ok := canonicalJSON(fn.Function.Parameters).(map[string]any); ok {
+					fn.Function.Parameters = canonical
+				}
+			}
+		}
+	}
+	if schema := params.ResponseFormat.OfJSONSchema; schema != nil && schema.JSONSchema.Schema != nil {
+		schema.JSONSchema.Schema = canonicalJSON(schema.JSONSchema.Schema)
+	}
+
+	for _, msg := range params.Messages {
+		switch {
+		case msg.OfSystem != nil:
+			normalizeSystemContent(&msg.OfSystem.Content.OfString, msg.OfSystem.Content.OfArrayOfContentParts)
+		case msg.OfDeveloper != nil:
+			normalizeSystemContent(&msg.OfDeveloper.Content.OfString, msg.OfDeveloper.Content.OfArrayOfContentParts)
+		default:
+			return
+		}
+	}
+}
+
+// toolName returns the function name of a tool, or "" for custom tools
+func toolName(tool openai.ChatCompletionToolUnionParam) string {
+	if tool.OfFunction != nil {
+		return tool.OfFunction.Function.Name
+	}
+	return ""
+}
+
+// normalizeSystemContent normalizes the text of a system or developer message
+func normalizeSystemContent(text *param.Opt[string], parts []openai.ChatCompletionContentPartTextParam) {
+	if text.Valid() {
+		*text = openai.String(normalizePromptText(text.Value))
+	}
+	for i := range parts {
+		parts[i].Text = normalizePromptText(parts[i].Text)
+	}
+}
+
+// normalizePromptText converts line endings to "\n" and trims trailing whitespace from every
+// line and the whole text, which templates and config files often vary in
+func normalizePromptText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// canonicalJSON re-encodes a value, such as a schema holding structs or raw JSON, as plain
+// maps and slices, which encode with sorted keys. Values that do not encode are returned as is.
+func canonicalJSON(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var canonical any
+	if err := decoder.Decode(&canonical); err != nil {
+		return value
+	}
+	return canonical
+}
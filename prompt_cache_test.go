@@ -0,0 +1,152 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestStablePromptPrefix(t *testing.T) {
+	weather := &ai.ToolDefinition{
+		Name:        "weather",
+		Description: "Current weather.  \r\n",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+	}
+	search := &ai.ToolDefinition{
+		Name:        "search",
+		Description: "Search the web.",
+		InputSchema: map[string]any{"properties": json.RawMessage(`{"query": {"type": "string"}}`), "type": "object"},
+	}
+	requests := []*ai.ModelRequest{
+		{
+			Messages: []*ai.Message{
+				ai.NewSystemTextMessage("You are helpful.  \r\nBe brief.\n"),
+				ai.NewUserTextMessage("first"),
+				ai.NewModelTextMessage("answer"),
+				ai.NewUserTextMessage("second"),
+			},
+			Tools: []*ai.ToolDefinition{weather, search},
+		},
+		{
+			Messages: []*ai.Message{
+				ai.NewSystemTextMessage("You are helpful.\nBe brief."),
+				ai.NewUserTextMessage("first"),
+				ai.NewModelTextMessage("answer"),
+				ai.NewUserTextMessage("second"),
+			},
+			Tools: []*ai.ToolDefinition{search, weather},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		stable bool
+		want   bool // Whether both requests send the same body
+	}{
+		{"disabled", false, false},
+		{"enabled", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(data))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, chatCompletionJSON)
+			}, func(a *AzureAIFoundry) { a.StablePromptPrefix = tt.stable })
+
+			for _, request := range requests {
+				if _, err := plugin.generateText(context.Background(), "gpt-4o", request, nil); err != nil {
+					t.Fatalf("generateText() error = %v", err)
+				}
+			}
+			if got := bodies[0] == bodies[1]; got != tt.want {
+				t.Fatalf("identical bodies = %v, want %v:\n%s\n%s", got, tt.want, bodies[0], bodies[1])
+			}
+			if !tt.stable {
+				return
+			}
+
+			var body struct {
+				Messages []struct {
+					Role    string `json:"role"`
+					Content string `json:"content"`
+				} `json:"messages"`
+				Tools []struct {
+					Function struct {
+						Name        string `json:"name"`
+						Description string `json:"description"`
+					} `json:"function"`
+				} `json:"tools"`
+			}
+			if err := json.Unmarshal([]byte(bodies[0]), &body); err != nil {
+				t.Fatal(err)
+			}
+			var messages, tools []string
+			for _, msg := range body.Messages {
+				messages = append(messages, msg.Role+": "+msg.Content)
+			}
+			for _, tool := range body.Tools {
+				tools = append(tools, tool.Function.Name+": "+tool.Function.Description)
+			}
+			wantMessages := []string{"system: You are helpful.\nBe brief.", "user: first", "assistant: answer", "user: second"}
+			if !reflect.DeepEqual(messages, wantMessages) {
+				t.Fatalf("messages = %q, want %q", messages, wantMessages)
+			}
+			wantTools := []string{"search: Search the web.", "weather: Current weather."}
+			if !reflect.DeepEqual(tools, wantTools) {
+				t.Fatalf("tools = %q, want %q", tools, wantTools)
+			}
+		})
+	}
+}
+
+func TestPromptCacheStats(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop",`+
+			`"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":2000,"completion_tokens":1,"total_tokens":2001,`+
+			`"prompt_tokens_details":{"cached_tokens":1536}}}`)
+	})
+	request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}
+	for _, model := range []string{"gpt-4o", "gpt-4o", "gpt-4o-mini"} {
+		if _, err := plugin.generateText(context.Background(), model, request, nil); err != nil {
+			t.Fatalf("generateText() error = %v", err)
+		}
+	}
+
+	want := map[string]PromptCacheStats{
+		"gpt-4o":      {Requests: 2, InputTokens: 4000, CachedInputTokens: 3072},
+		"gpt-4o-mini": {Requests: 1, InputTokens: 2000, CachedInputTokens: 1536},
+	}
+	stats := plugin.PromptCacheStats()
+	if !reflect.DeepEqual(stats, want) {
+		t.Fatalf("PromptCacheStats() = %+v, want %+v", stats, want)
+	}
+	if rate := stats["gpt-4o"].CacheHitRate(); rate != 0.768 {
+		t.Fatalf("CacheHitRate() = %v, want 0.768", rate)
+	}
+}
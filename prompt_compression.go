@@ -0,0 +1,241 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Compression defaults
+const (
+	defaultCompressionRate      = 0.3
+	defaultCompressionMinTokens = 500
+)
+
+// PromptCompression configures the experimental compression of long prompts, which drops
+// low-information words from long message parts, such as retrieved documents, before they
+// are sent. System messages and fenced code blocks are never compressed, and words with
+// digits or capitals, which tend to carry names, IDs and figures, are kept.
+type PromptCompression struct {
+	Rate      float64 // Share of the words of a compressed part that are dropped, from 0 to 1. Defaults to 0.3
+	MinTokens int     // Parts shorter than this are sent as is. Defaults to 500
+}
+
+// PromptCompressionStats compares the prompt tokens of a request before and after
+// compression. It is recorded in the "promptCompression" custom value of responses.
+type PromptCompressionStats struct {
+	TokensBefore int `json:"tokensBefore"`
+	TokensAfter  int `json:"tokensAfter"`
+}
+
+// fillerWords carry little meaning on their own and are dropped first
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "is": true, "are": true, "was": true, "were": true, "be": true,
+	"been": true, "being": true, "am": true, "do": true, "does": true, "did": true, "of": true, "to": true,
+	"in": true, "on": true, "at": true, "by": true, "for": true, "with": true, "as": true, "that": true,
+	"this": true, "these": true, "those": true, "it": true, "its": true, "and": true, "or": true, "so": true,
+	"then": true, "there": true, "here": true, "which": true, "who": true, "whom": true, "very": true,
+	"really": true, "just": true, "also": true, "quite": true, "rather": true, "actually": true,
+	"basically": true, "simply": true, "about": true, "has": true, "have": true, "had": true,
+	"will": true, "would": true, "can": true, "could": true, "should": true, "may": true, "might": true,
+}
+
+// compressPrompt returns a copy of the request with its long non-system text parts
+// compressed, or nil when compression is disabled for it
+func (a *AzureAIFoundry) compressPrompt(modelName string, input *ai.ModelRequest) (*ai.ModelRequest, *PromptCompressionStats) {
+	settings := PromptCompression{}
+	if a.PromptCompression != nil {
+		settings = *a.PromptCompression
+	}
+	config, _ := normalizeConfig(input.Config)
+	if rate, ok := configFloat(config["promptCompression"]); ok {
+		if rate <= 0 {
+			return nil, nil
+		}
+		settings.Rate = rate
+	} else if a.PromptCompression == nil {
+		return nil, nil
+	}
+	if settings.Rate <= 0 {
+		settings.Rate = defaultCompressionRate
+	}
+	settings.Rate = min(settings.Rate, 1)
+	if settings.MinTokens <= 0 {
+		settings.MinTokens = defaultCompressionMinTokens
+	}
+
+	tokenizer := a.Tokenizer(modelName)
+	messages := make([]*ai.Message, len(input.Messages))
+	changed := false
+	for i, msg := range input.Messages {
+		messages[i] = msg
+		if msg.Role == ai.RoleSystem {
+			continue
+		}
+		var content []*ai.Part
+		for j, part := range msg.Content {
+			if !part.IsText() || tokenizer.CountTokens(part.Text) < settings.MinTokens {
+				continue
+			}
+			if content == nil {
+				content = append([]*ai.Part{}, msg.Content...)
+			}
+			compressed := *part
+			compressed.Text = compressText(part.Text, settings.Rate)
+			content[j] = &compressed
+		}
+		if content != nil {
+			copied := *msg
+			copied.Content = content
+			messages[i] = &copied
+			changed = true
+		}
+	}
+	if !changed {
+		return nil, nil
+	}
+
+	compressed := *input
+	compressed.Messages = messages
+	return &compressed, &PromptCompressionStats{
+		TokensBefore: a.CountTokens(modelName, input.Messages),
+		TokensAfter:  a.CountTokens(modelName, messages),
+	}
+}
+
+// compressText drops the given share of the words of text outside fenced code blocks,
+// lowest information first
+func compressText(text string, rate float64) string {
+	segments := strings.Split(text, "```")
+	// Even segments are prose, odd ones code blocks
+	var words []*compressWord
+	counts := make(map[string]int)
+	lines := make([][][]string, len(segments))
+	for i := 0; i < len(segments); i += 2 {
+		for _, line := range strings.Split(segments[i], "\n") {
+			fields := strings.Fields(line)
+			lines[i] = append(lines[i], fields)
+			for j, field := range fields {
+				key := strings.ToLower(strings.TrimFunc(field, isWordEdge))
+				counts[key]++
+				words = append(words, &compressWord{segment: i, line: len(lines[i]) - 1, index: j, text: field, key: key})
+			}
+		}
+	}
+
+	var candidates []*compressWord
+	for _, word := range words {
+		if score, ok := wordInformation(word, counts, len(words)); ok {
+			word.score = score
+			candidates = append(candidates, word)
+		}
+	}
+	drop := min(int(rate*float64(len(words))), len(candidates))
+	for _, word := range wordsToDrop(candidates, drop) {
+		lines[word.segment][word.line][word.index] = ""
+	}
+
+	var out strings.Builder
+	for i, segment := range segments {
+		if i > 0 {
+			out.WriteString("```")
+		}
+		if i%2 == 1 {
+			out.WriteString(segment)
+			continue
+		}
+		for j, fields := range lines[i] {
+			if j > 0 {
+				out.WriteString("\n")
+			}
+			kept := fields[:0:0]
+			for _, field := range fields {
+				if field != "" {
+					kept = append(kept, field)
+				}
+			}
+			out.WriteString(strings.Join(kept, " "))
+		}
+	}
+	return out.String()
+}
+
+// compressWord is a word of a compressed text and its position
+type compressWord struct {
+	segment, line, index int
+	text, key            string
+	score                float64
+}
+
+// wordsToDrop picks the n least informative candidates. Words scoring below the cutoff are
+// all dropped; those at the cutoff are picked evenly over the text, so that repeated
+// passages are shortened alike
+func wordsToDrop(candidates []*compressWord, n int) []*compressWord {
+	if n == 0 {
+		return nil
+	}
+	sorted := slices.Clone(candidates)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].score < sorted[j].score })
+	cutoff := sorted[n-1].score
+
+	var dropped, ties []*compressWord
+	for _, word := range candidates {
+		switch {
+		case word.score < cutoff:
+			dropped = append(dropped, word)
+		case word.score == cutoff:
+			ties = append(ties, word)
+		}
+	}
+	remaining := n - len(dropped)
+	for i, word := range ties {
+		if (i+1)*remaining/len(ties) > i*remaining/len(ties) {
+			dropped = append(dropped, word)
+		}
+	}
+	return dropped
+}
+
+// wordInformation estimates how much a word tells the model, reporting false for words that
+// are always kept: those with digits or capitals, sentence punctuation or no letters at all
+func wordInformation(word *compressWord, counts map[string]int, total int) (float64, bool) {
+	if word.key == "" || strings.ContainsAny(word.text[len(word.text)-1:], ".,;:!?") {
+		return 0, false
+	}
+	if fillerWords[word.key] {
+		return 0, true
+	}
+	for _, r := range word.text {
+		if unicode.IsDigit(r) || unicode.IsUpper(r) {
+			return 0, false
+		}
+	}
+	// Frequent words are predictable from the rest of the prompt
+	return -math.Log2(float64(counts[word.key]) / float64(total)), true
+}
+
+// isWordEdge reports whether r is trimmed from the edges of words before they are compared
+func isWordEdge(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestCompressText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		rate float64
+		want string
+	}{
+		{
+			name: "fillers first",
+			text: "The invoice is due soon and the customer has paid twice",
+			rate: 0.3,
+			want: "The invoice due soon and customer paid twice",
+		},
+		{
+			name: "repeated words before rare ones",
+			text: "refund refund refund requested quickly",
+			rate: 0.4,
+			want: "refund requested quickly",
+		},
+		{
+			name: "figures, names and punctuation kept",
+			text: "the total for Contoso was 4711 euros in the end.",
+			rate: 1,
+			want: "Contoso 4711 end.",
+		},
+		{
+			name: "code blocks and lines kept",
+			text: "run the script\n```\nthe = a + b\n```\nand then check the output",
+			rate: 0.5,
+			want: "run script\n```\nthe = a + b\n```\ncheck output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compressText(tt.text, tt.rate); got != tt.want {
+				t.Fatalf("compressText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptCompression(t *testing.T) {
+	system := "You are a support agent and the answer must be short."
+	context := strings.Repeat("The customer has said that the order was delayed and that the parcel was very damaged. ", 30)
+	tests := []struct {
+		name        string
+		config      map[string]any
+		plugin      *PromptCompression
+		wantCompact bool
+	}{
+		{name: "disabled"},
+		{name: "plugin option", plugin: &PromptCompression{MinTokens: 50}, wantCompact: true},
+		{name: "request option", config: map[string]any{"promptCompression": 0.5}, wantCompact: true},
+		{name: "disabled per request", plugin: &PromptCompression{MinTokens: 50}, config: map[string]any{"promptCompression": 0}},
+		{name: "short parts kept", plugin: &PromptCompression{MinTokens: 10000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+				a.PromptCompression = tt.plugin
+			})
+			ctx := t.Context()
+			g := genkit.Init(ctx)
+			model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithConfig(tt.config), ai.WithMessages(
+				ai.NewSystemTextMessage(system),
+				ai.NewUserTextMessage(context),
+			))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			messages := bodies[0]["messages"].([]any)
+			sentSystem := messages[0].(map[string]any)["content"]
+			sentUser := messages[1].(map[string]any)["content"].(string)
+			if sentSystem != system {
+				t.Fatalf("system message = %q, want it uncompressed", sentSystem)
+			}
+			custom, _ := resp.Custom.(map[string]any)
+			stats, _ := custom["promptCompression"].(*PromptCompressionStats)
+			if !tt.wantCompact {
+				if sentUser != context || stats != nil {
+					t.Fatalf("user message = %q, stats = %+v, want it uncompressed", sentUser, stats)
+				}
+				return
+			}
+			if len(sentUser) >= len(context)*9/10 || !strings.Contains(sentUser, "customer") {
+				t.Fatalf("user message = %q, want it compressed", sentUser)
+			}
+			if stats == nil || stats.TokensAfter >= stats.TokensBefore {
+				t.Fatalf("Custom[promptCompression] = %+v, want fewer tokens after", stats)
+			}
+			if resp.Request.Messages[1].Text() != context {
+				t.Fatal("response request was compressed, want the original messages kept for the history")
+			}
+		})
+	}
+}
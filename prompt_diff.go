@@ -0,0 +1,259 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// defaultMinSimilarity is the similarity below which a case regresses when an embedder is set
+const defaultMinSimilarity = 0.9
+
+// PromptCase is a request of a prompt regression suite.
+type PromptCase struct {
+	Name    string           // Identifier used in the report
+	Request *ai.ModelRequest // Request sent to both configurations
+}
+
+// DiffTarget is a configuration a prompt suite runs against. To compare api-versions,
+// register the deployment with two plugin instances that differ in ProviderID and APIVersion.
+type DiffTarget struct {
+	Model  string         // Registered model, e.g. "azureaifoundry/gpt-4o" (required)
+	Config map[string]any // Config options that replace those of each request
+}
+
+// PromptDiffOptions configures DiffPrompts.
+type PromptDiffOptions struct {
+	Embedder         ai.Embedder // Compares the meaning of the response texts. Similarity is not checked without one
+	MinSimilarity    float64     // Similarity below which a case regresses. Defaults to 0.9
+	ExactText        bool        // Any change of the response text is a regression
+	MaxTokenIncrease float64     // Relative increase of total tokens beyond which a case regresses, e.g. 0.2. Not checked when 0
+	Concurrency      int         // Maximum cases run at once. Defaults to 4
+}
+
+// PromptDiff compares the responses of both configurations to one case.
+type PromptDiff struct {
+	Case      string
+	Baseline  *ai.ModelResponse // nil if the request failed
+	Candidate *ai.ModelResponse // nil if the request failed
+
+	BaselineErr  error
+	CandidateErr error
+
+	TextDiff            string  // Line diff of the response text ("-" baseline, "+" candidate), empty when equal
+	Similarity          float64 // Cosine similarity of the embedded response texts, 1 when equal. 0 without an Embedder
+	FinishReasonChanged bool
+	ToolCallsChanged    bool // Requested tools or their inputs differ
+
+	UsageDelta   ai.GenerationUsage // Candidate tokens minus baseline tokens
+	LatencyDelta time.Duration      // Candidate latency minus baseline latency
+
+	Regressions []string // Why the case regressed, empty when it passed
+}
+
+// Regressed reports whether the candidate regressed on the case.
+func (d *PromptDiff) Regressed() bool {
+	return len(d.Regressions) > 0
+}
+
+// PromptDiffReport is the result of DiffPrompts.
+type PromptDiffReport struct {
+	Baseline   string
+	Candidate  string
+	Diffs      []*PromptDiff      // In case order
+	UsageDelta ai.GenerationUsage // Sum of the cases' usage deltas
+}
+
+// Passed reports whether no case regressed, e.g. to fail a CI job.
+func (r *PromptDiffReport) Passed() bool {
+	return len(r.Regressions()) == 0
+}
+
+// Regressions returns the cases that regressed.
+func (r *PromptDiffReport) Regressions() []*PromptDiff {
+	var regressed []*PromptDiff
+	for _, diff := range r.Diffs {
+		if diff.Regressed() {
+			regressed = append(regressed, diff)
+		}
+	}
+	return regressed
+}
+
+// Summary renders the report as Markdown, with the text diffs of the cases that regressed.
+func (r *PromptDiffReport) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Prompt diff: %s → %s\n\n", r.Baseline, r.Candidate)
+	fmt.Fprintf(&b, "%d of %d cases regressed. Token delta: %+d input, %+d output, %+d total.\n\n",
+		len(r.Regressions()), len(r.Diffs), r.UsageDelta.InputTokens, r.UsageDelta.OutputTokens, r.UsageDelta.TotalTokens)
+	b.WriteString("| Case | Result | Similarity | Token delta | Latency delta |\n|---|---|---|---|---|\n")
+	for _, diff := range r.Diffs {
+		result := "pass"
+		if diff.Regressed() {
+			result = strings.Join(diff.Regressions, "; ")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %.3f | %+d | %s |\n", diff.Case, result, diff.Similarity, diff.UsageDelta.TotalTokens, diff.LatencyDelta)
+	}
+	for _, diff := range r.Regressions() {
+		if diff.TextDiff != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n```diff\n%s```\n", diff.Case, diff.TextDiff)
+		}
+	}
+	return b.String()
+}
+
+// DiffPrompts runs a prompt suite against a baseline and a candidate configuration, e.g. two
+// deployments, api-versions or configs, and diffs their responses: text, meaning (with
+// opts.Embedder), finish reason, tool calls, token usage and latency. A case regresses when
+// the candidate fails, its finish reason or tool calls change, its similarity falls below
+// opts.MinSimilarity or its tokens grow beyond opts.MaxTokenIncrease. Requested tools are not
+// executed. Sampling makes outputs vary between runs, so set a seed and a low temperature.
+func DiffPrompts(ctx context.Context, g *genkit.Genkit, cases []PromptCase, baseline, candidate DiffTarget, opts PromptDiffOptions) (*PromptDiffReport, error) {
+	models := make([]ai.Model, 2)
+	for i, target := range []DiffTarget{baseline, candidate} {
+		if models[i] = genkit.LookupModel(g, target.Model); models[i] == nil {
+			return nil, fmt.Errorf("model %q is not registered", target.Model)
+		}
+	}
+	if opts.MinSimilarity <= 0 {
+		opts.MinSimilarity = defaultMinSimilarity
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	report := &PromptDiffReport{Baseline: baseline.Model, Candidate: candidate.Model, Diffs: make([]*PromptDiff, len(cases))}
+	semaphore := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			diff := &PromptDiff{Case: c.Name}
+			report.Diffs[i] = diff
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				diff.BaselineErr, diff.CandidateErr = ctx.Err(), ctx.Err()
+				diff.Regressions = []string{"candidate failed: " + ctx.Err().Error()}
+				return
+			}
+			diff.Baseline, diff.BaselineErr = models[0].Generate(ctx, targetRequest(c.Request, baseline.Config), nil)
+			diff.Candidate, diff.CandidateErr = models[1].Generate(ctx, targetRequest(c.Request, candidate.Config), nil)
+			compareResponses(ctx, diff, opts)
+		}()
+	}
+	wg.Wait()
+
+	for _, diff := range report.Diffs {
+		report.UsageDelta.InputTokens += diff.UsageDelta.InputTokens
+		report.UsageDelta.OutputTokens += diff.UsageDelta.OutputTokens
+		report.UsageDelta.TotalTokens += diff.UsageDelta.TotalTokens
+	}
+	return report, nil
+}
+
+// targetRequest returns a copy of the request with the target's config options applied
+func targetRequest(input *ai.ModelRequest, config map[string]any) *ai.ModelRequest {
+	req := withDefaultConfig(input, map[string]any{})
+	maps.Copy(req.Config.(map[string]any), config)
+	return req
+}
+
+// compareResponses fills in the differences between the responses of a diff and why the
+// candidate regressed
+func compareResponses(ctx context.Context, diff *PromptDiff, opts PromptDiffOptions) {
+	if diff.CandidateErr != nil {
+		diff.Regressions = append(diff.Regressions, "candidate failed: "+diff.CandidateErr.Error())
+	}
+	if diff.BaselineErr != nil || diff.CandidateErr != nil {
+		// Nothing to compare against
+		return
+	}
+
+	baseline, candidate := diff.Baseline, diff.Candidate
+	diff.TextDiff = lineDiff(baseline.Text(), candidate.Text())
+	diff.FinishReasonChanged = baseline.FinishReason != candidate.FinishReason
+	diff.ToolCallsChanged = toolRequestsKey(baseline.ToolRequests()) != toolRequestsKey(candidate.ToolRequests())
+	diff.LatencyDelta = time.Duration((candidate.LatencyMs - baseline.LatencyMs) * float64(time.Millisecond))
+	var before, after ai.GenerationUsage
+	if baseline.Usage != nil {
+		before = *baseline.Usage
+	}
+	if candidate.Usage != nil {
+		after = *candidate.Usage
+	}
+	diff.UsageDelta = ai.GenerationUsage{
+		InputTokens:  after.InputTokens - before.InputTokens,
+		OutputTokens: after.OutputTokens - before.OutputTokens,
+		TotalTokens:  after.TotalTokens - before.TotalTokens,
+	}
+
+	if opts.ExactText && diff.TextDiff != "" {
+		diff.Regressions = append(diff.Regressions, "text changed")
+	}
+	if diff.FinishReasonChanged {
+		diff.Regressions = append(diff.Regressions, fmt.Sprintf("finish reason changed from %q to %q", baseline.FinishReason, candidate.FinishReason))
+	}
+	if diff.ToolCallsChanged {
+		diff.Regressions = append(diff.Regressions, "tool calls changed")
+	}
+	if opts.MaxTokenIncrease > 0 && before.TotalTokens > 0 {
+		if increase := float64(diff.UsageDelta.TotalTokens) / float64(before.TotalTokens); increase > opts.MaxTokenIncrease {
+			diff.Regressions = append(diff.Regressions, fmt.Sprintf("tokens increased by %.0f%%", increase*100))
+		}
+	}
+	if opts.Embedder != nil {
+		similarity, err := textSimilarity(ctx, opts.Embedder, baseline.Text(), candidate.Text())
+		switch {
+		case err != nil:
+			diff.Regressions = append(diff.Regressions, "similarity check failed: "+err.Error())
+		case similarity < opts.MinSimilarity:
+			diff.Regressions = append(diff.Regressions, fmt.Sprintf("similarity %.3f is below %.3f", similarity, opts.MinSimilarity))
+		}
+		diff.Similarity = similarity
+	}
+}
+
+// textSimilarity returns the cosine similarity of the embeddings of two texts
+func textSimilarity(ctx context.Context, embedder ai.Embedder, a, b string) (float64, error) {
+	switch {
+	case a == b:
+		return 1, nil
+	case a == "" || b == "":
+		// Empty input cannot be embedded, and shares no meaning with text
+		return 0, nil
+	}
+	resp, err := embedder.Embed(ctx, &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText(a, nil), ai.DocumentFromText(b, nil)}})
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Embeddings) != 2 {
+		return 0, fmt.Errorf("embedder returned %d embeddings for 2 texts", len(resp.Embeddings))
+	}
+	return dotProduct(normalizeVector(resp.Embeddings[0].Embedding), normalizeVector(resp.Embeddings[1].Embedding)), nil
+}
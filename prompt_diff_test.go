@@ -0,0 +1,137 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestDiffPrompts(t *testing.T) {
+	// Answers per deployment and prompt: text, finish reason and completion tokens
+	answers := map[string]map[string][3]any{
+		"gpt-4o": {
+			"pets":  {"I like cats", "stop", 10},
+			"story": {"Once upon a time", "stop", 10},
+			"fail":  {"ok", "stop", 10},
+		},
+		"gpt-4o-canary": {
+			"pets":  {"I love cats", "stop", 11},
+			"story": {"A dog and a fish", "length", 20},
+		},
+	}
+	var temperatures []any
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model       string `json:"model"`
+			Temperature any    `json:"temperature"`
+			Messages    []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		if body.Model == "gpt-4o-canary" {
+			temperatures = append(temperatures, body.Temperature)
+		}
+		answer, ok := answers[body.Model][body.Messages[0].Content]
+		if !ok {
+			w.Header().Set("x-should-retry", "false")
+			http.Error(w, `{"error":{"message":"boom"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":1,"model":%q,`+
+			`"choices":[{"index":0,"finish_reason":%q,"message":{"role":"assistant","content":%q}}],`+
+			`"usage":{"prompt_tokens":5,"completion_tokens":%d,"total_tokens":%d}}`,
+			body.Model, answer[1], answer[0], answer[2], 5+answer[2].(int))
+	})
+	g := genkit.Init(context.Background())
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o-canary", Type: "chat"}, nil)
+
+	var cases []PromptCase
+	for _, name := range []string{"pets", "story", "fail"} {
+		cases = append(cases, PromptCase{Name: name, Request: &ai.ModelRequest{
+			Messages: []*ai.Message{ai.NewUserTextMessage(name)},
+			Config:   map[string]any{"temperature": 0.7},
+		}})
+	}
+
+	tests := []struct {
+		name string
+		opts PromptDiffOptions
+		want map[string]int // Regressions per case
+	}{
+		{"defaults", PromptDiffOptions{}, map[string]int{"pets": 0, "story": 1, "fail": 1}},
+		{"semantic", PromptDiffOptions{Embedder: keywordEmbedder(g)}, map[string]int{"pets": 0, "story": 2, "fail": 1}},
+		{"exact text and tokens", PromptDiffOptions{ExactText: true, MaxTokenIncrease: 0.2}, map[string]int{"pets": 1, "story": 3, "fail": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			temperatures = nil
+			report, err := DiffPrompts(t.Context(), g, cases,
+				DiffTarget{Model: "azureaifoundry/gpt-4o"},
+				DiffTarget{Model: "azureaifoundry/gpt-4o-canary", Config: map[string]any{"temperature": 0.0}}, tt.opts)
+			if err != nil {
+				t.Fatalf("DiffPrompts() error = %v", err)
+			}
+			regressed := 0
+			for _, diff := range report.Diffs {
+				if len(diff.Regressions) != tt.want[diff.Case] {
+					t.Errorf("%s regressions = %q, want %d", diff.Case, diff.Regressions, tt.want[diff.Case])
+				}
+				if tt.want[diff.Case] > 0 {
+					regressed++
+				}
+			}
+			if report.Passed() || len(report.Regressions()) != regressed {
+				t.Fatalf("Passed() = %v, Regressions() = %d", report.Passed(), len(report.Regressions()))
+			}
+			if report.UsageDelta.TotalTokens != 11 || report.Diffs[0].UsageDelta.OutputTokens != 1 {
+				t.Fatalf("UsageDelta = %+v, pets = %+v", report.UsageDelta, report.Diffs[0].UsageDelta)
+			}
+			if report.Diffs[0].TextDiff != "- I like cats\n+ I love cats\n" {
+				t.Fatalf("TextDiff = %q", report.Diffs[0].TextDiff)
+			}
+			if tt.opts.Embedder != nil && report.Diffs[0].Similarity != 1 {
+				t.Fatalf("pets similarity = %v, want 1", report.Diffs[0].Similarity)
+			}
+			for _, temperature := range temperatures {
+				if temperature != 0.0 {
+					t.Fatalf("candidate temperature = %v, want the target config", temperature)
+				}
+			}
+			if summary := report.Summary(); !strings.Contains(summary, fmt.Sprintf("%d of 3 cases regressed", regressed)) || !strings.Contains(summary, "## story") {
+				t.Fatalf("Summary() = %s", summary)
+			}
+		})
+	}
+
+	if _, err := DiffPrompts(t.Context(), g, cases, DiffTarget{Model: "azureaifoundry/missing"}, DiffTarget{Model: "azureaifoundry/gpt-4o"}, PromptDiffOptions{}); err == nil {
+		t.Fatal("DiffPrompts() with an unregistered model error = nil")
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// QueryExpansionMode selects how a query is expanded before retrieval.
+type QueryExpansionMode string
+
+const (
+	// QueryExpansionMultiQuery rewrites the query into several alternative phrasings.
+	QueryExpansionMultiQuery QueryExpansionMode = "multi-query"
+	// QueryExpansionHyDE generates a hypothetical answer document and retrieves with it.
+	QueryExpansionHyDE QueryExpansionMode = "hyde"
+)
+
+// defaultRRFK is the rank constant commonly used for reciprocal rank fusion.
+const defaultRRFK = 60
+
+// QueryExpansionOptions configures a query expansion retriever.
+type QueryExpansionOptions struct {
+	Mode       QueryExpansionMode // Expansion strategy. Defaults to QueryExpansionMultiQuery
+	Model      ai.Model           // Chat model used to rewrite the query (required)
+	NumQueries int                // Number of rewritten queries for multi-query mode. Defaults to 3
+	RRFK       int                // Reciprocal rank fusion constant. Defaults to 60
+	TopK       int                // Maximum number of fused documents to return (optional, 0 returns all)
+}
+
+// DefineQueryExpansionRetriever defines a retriever that expands each query with a chat
+// model, runs the base retriever once per expanded query and merges the results with
+// reciprocal rank fusion. The original query is always included in the fused results.
+func (a *AzureAIFoundry) DefineQueryExpansionRetriever(g *genkit.Genkit, name string, base ai.Retriever, opts QueryExpansionOptions) ai.Retriever {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.initted {
+		panic("azureaifoundry: Init not called")
+	}
+	if base == nil {
+		panic("azureaifoundry: base retriever is required")
+	}
+	if opts.Model == nil {
+		panic("azureaifoundry: query expansion model is required")
+	}
+
+	if opts.Mode == "" {
+		opts.Mode = QueryExpansionMultiQuery
+	}
+	if opts.NumQueries <= 0 {
+		opts.NumQueries = 3
+	}
+	if opts.RRFK <= 0 {
+		opts.RRFK = defaultRRFK
+	}
+
+	return genkit.DefineRetriever(g, api.NewName(a.Name(), name), &ai.RetrieverOptions{
+		Label: a.Name() + "-" + name,
+	}, func(ctx context.Context, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
+		return a.retrieveWithExpansion(ctx, g, base, opts, req)
+	})
+}
+
+// retrieveWithExpansion expands the request query and fuses the base retriever results
+func (a *AzureAIFoundry) retrieveWithExpansion(ctx context.Context, g *genkit.Genkit, base ai.Retriever, opts QueryExpansionOptions, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
+	query := documentText(req.Query)
+	if query == "" {
+		return base.Retrieve(ctx, req)
+	}
+
+	expanded, err := expandQuery(ctx, g, opts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := append([]string{query}, expanded...)
+	var rankings [][]*ai.Document
+	for _, q := range queries {
+		resp, err := base.Retrieve(ctx, &ai.RetrieverRequest{
+			Query:   ai.DocumentFromText(q, nil),
+			Options: req.Options,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("retrieval failed for expanded query: %w", err)
+		}
+		rankings = append(rankings, resp.Documents)
+	}
+
+	fused := fuseReciprocalRank(rankings, opts.RRFK)
+	if opts.TopK > 0 && len(fused) > opts.TopK {
+		fused = fused[:opts.TopK]
+	}
+
+	return &ai.RetrieverResponse{Documents: fused}, nil
+}
+
+// expandQuery asks the configured model for alternative queries or a hypothetical document
+func expandQuery(ctx context.Context, g *genkit.Genkit, opts QueryExpansionOptions, query string) ([]string, error) {
+	var instruction string
+	switch opts.Mode {
+	case QueryExpansionHyDE:
+		instruction = "Write a short passage that directly answers the question below, " +
+			"as it might appear in a reference document. Return only the passage.\n\nQuestion: " + query
+	case QueryExpansionMultiQuery:
+		instruction = fmt.Sprintf("Rewrite the search query below into %d alternative search queries "+
+			"that use different wording or perspectives. Return one query per line with no numbering "+
+			"or extra text.\n\nQuery: %s", opts.NumQueries, query)
+	default:
+		return nil, fmt.Errorf("unsupported query expansion mode: %s", opts.Mode)
+	}
+
+	resp, err := genkit.Generate(ctx, g,
+		ai.WithModel(opts.Model),
+		ai.WithMessages(ai.NewUserTextMessage(instruction)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query expansion failed: %w", err)
+	}
+
+	if opts.Mode == QueryExpansionHyDE {
+		if passage := strings.TrimSpace(resp.Text()); passage != "" {
+			return []string{passage}, nil
+		}
+		return nil, nil
+	}
+	return parseRewrittenQueries(resp.Text(), query, opts.NumQueries), nil
+}
+
+// listMarkerPattern matches a leading bullet or "1." / "1)" list marker
+var listMarkerPattern = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s+`)
+
+// parseRewrittenQueries splits model output into distinct queries, dropping list markers,
+// blank lines and repeats of the original query
+func parseRewrittenQueries(text, original string, limit int) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(original)): true}
+	var queries []string
+
+	for _, line := range strings.Split(text, "\n") {
+		q := listMarkerPattern.ReplaceAllString(line, "")
+		q = strings.Trim(strings.TrimSpace(q), "\"")
+		q = strings.TrimSpace(q)
+		if q == "" || seen[strings.ToLower(q)] {
+			continue
+		}
+		seen[strings.ToLower(q)] = true
+		queries = append(queries, q)
+		if limit > 0 && len(queries) == limit {
+			break
+		}
+	}
+
+	return queries
+}
+
+// fuseReciprocalRank merges ranked document lists, scoring each document by the sum of
+// 1/(k+rank) across the lists it appears in. The fused score is recorded in the
+// document metadata under "rrfScore".
+func fuseReciprocalRank(rankings [][]*ai.Document, k int) []*ai.Document {
+	type fusedDoc struct {
+		doc   *ai.Document
+		score float64
+		first int
+	}
+
+	byKey := make(map[string]*fusedDoc)
+	var order []*fusedDoc
+	for _, docs := range rankings {
+		for rank, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			key := documentKey(doc)
+			fd, ok := byKey[key]
+			if !ok {
+				fd = &fusedDoc{doc: doc, first: len(order)}
+				byKey[key] = fd
+				order = append(order, fd)
+			}
+			fd.score += 1.0 / float64(k+rank+1)
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].score != order[j].score {
+			return order[i].score > order[j].score
+		}
+		return order[i].first < order[j].first
+	})
+
+	result := make([]*ai.Document, 0, len(order))
+	for _, fd := range order {
+		metadata := make(map[string]any, len(fd.doc.Metadata)+1)
+		for key, val := range fd.doc.Metadata {
+			metadata[key] = val
+		}
+		metadata["rrfScore"] = fd.score
+		result = append(result, &ai.Document{Content: fd.doc.Content, Metadata: metadata})
+	}
+
+	return result
+}
+
+// documentKey identifies a document for deduplication, preferring an "id" metadata value
+func documentKey(doc *ai.Document) string {
+	if id, ok := doc.Metadata["id"]; ok {
+		return fmt.Sprintf("id:%v", id)
+	}
+	return "text:" + documentText(doc)
+}
+
+// documentText concatenates the text parts of a document
+func documentText(doc *ai.Document) string {
+	if doc == nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, part := range doc.Content {
+		if part.IsText() {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
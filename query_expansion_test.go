@@ -0,0 +1,233 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestParseRewrittenQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "plain lines",
+			text:  "azure pricing\nfoundry cost model",
+			limit: 3,
+			want:  []string{"azure pricing", "foundry cost model"},
+		},
+		{
+			name:  "numbered and bulleted lines",
+			text:  "1. azure pricing\n- \"foundry cost model\"\n\n* billing for ai foundry",
+			limit: 3,
+			want:  []string{"azure pricing", "foundry cost model", "billing for ai foundry"},
+		},
+		{
+			name:  "drops original and duplicates",
+			text:  "How much does Foundry cost?\nazure pricing\nAzure Pricing",
+			limit: 3,
+			want:  []string{"azure pricing"},
+		},
+		{
+			name:  "keeps leading digits and hyphens in queries",
+			text:  "1. 2024 tax brackets\n2) 3D printing cost\n- -5 degree weather\n2024 tax brackets",
+			limit: 3,
+			want:  []string{"2024 tax brackets", "3D printing cost", "-5 degree weather"},
+		},
+		{
+			name:  "respects limit",
+			text:  "a\nb\nc",
+			limit: 2,
+			want:  []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRewrittenQueries(tt.text, "how much does foundry cost?", tt.limit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseRewrittenQueries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuseReciprocalRank(t *testing.T) {
+	a := ai.DocumentFromText("a", nil)
+	b := ai.DocumentFromText("b", nil)
+	c := ai.DocumentFromText("c", nil)
+	bByID := ai.DocumentFromText("b again", map[string]any{"id": 2})
+	bByIDDup := ai.DocumentFromText("b copy", map[string]any{"id": 2})
+
+	fused := fuseReciprocalRank([][]*ai.Document{
+		{a, b, c},
+		{b, c},
+		{bByID, bByIDDup},
+	}, defaultRRFK)
+
+	var got []string
+	for _, doc := range fused {
+		got = append(got, documentText(doc))
+	}
+	want := []string{"b", "b again", "c", "a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("fused order = %q, want %q", got, want)
+	}
+
+	if _, ok := fused[0].Metadata["rrfScore"].(float64); !ok {
+		t.Fatalf("rrfScore metadata missing on fused document")
+	}
+	if b.Metadata != nil {
+		t.Fatalf("input document metadata was mutated")
+	}
+}
+
+func TestRetrieveWithExpansion(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+
+	var modelCalls int
+	model := genkit.DefineModel(g, "test/expander", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			modelCalls++
+			text := "keys rotation schedule\n1. credential lifetime"
+			if strings.Contains(req.Messages[0].Text(), "Write a short passage") {
+				text = "  API keys rotate every 90 days.  "
+			}
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(text)}, nil
+		})
+
+	results := map[string][]*ai.Document{
+		"how often do keys rotate?":      {ai.DocumentFromText("rotation policy", nil), ai.DocumentFromText("faq", nil)},
+		"keys rotation schedule":         {ai.DocumentFromText("rotation policy", nil), ai.DocumentFromText("schedule", nil)},
+		"credential lifetime":            {ai.DocumentFromText("lifetime", nil)},
+		"API keys rotate every 90 days.": {ai.DocumentFromText("schedule", nil)},
+	}
+	var retrieved []string
+	base := genkit.DefineRetriever(g, "test/base", nil,
+		func(ctx context.Context, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
+			q := documentText(req.Query)
+			retrieved = append(retrieved, q)
+			if q == "credential lifetime" {
+				if _, fail := req.Options.(bool); fail {
+					return nil, errors.New("index unavailable")
+				}
+			}
+			return &ai.RetrieverResponse{Documents: results[q]}, nil
+		})
+
+	tests := []struct {
+		name          string
+		mode          QueryExpansionMode
+		query         string
+		options       any
+		topK          int
+		wantModel     int
+		wantRetrieved []string
+		wantTexts     []string
+		wantErr       string
+	}{
+		{
+			name:          "empty query falls back to base retriever",
+			mode:          QueryExpansionMultiQuery,
+			query:         "",
+			wantRetrieved: []string{""},
+		},
+		{
+			name:          "multi-query fuses rewritten queries",
+			mode:          QueryExpansionMultiQuery,
+			query:         "how often do keys rotate?",
+			wantModel:     1,
+			wantRetrieved: []string{"how often do keys rotate?", "keys rotation schedule", "credential lifetime"},
+			wantTexts:     []string{"rotation policy", "lifetime", "faq", "schedule"},
+		},
+		{
+			name:          "hyde retrieves with hypothetical passage",
+			mode:          QueryExpansionHyDE,
+			query:         "how often do keys rotate?",
+			wantModel:     1,
+			wantRetrieved: []string{"how often do keys rotate?", "API keys rotate every 90 days."},
+			wantTexts:     []string{"rotation policy", "schedule", "faq"},
+		},
+		{
+			name:          "truncates to TopK",
+			mode:          QueryExpansionMultiQuery,
+			query:         "how often do keys rotate?",
+			topK:          2,
+			wantModel:     1,
+			wantRetrieved: []string{"how often do keys rotate?", "keys rotation schedule", "credential lifetime"},
+			wantTexts:     []string{"rotation policy", "lifetime"},
+		},
+		{
+			name:          "wraps base retrieval errors",
+			mode:          QueryExpansionMultiQuery,
+			query:         "how often do keys rotate?",
+			options:       true,
+			wantModel:     1,
+			wantRetrieved: []string{"how often do keys rotate?", "keys rotation schedule", "credential lifetime"},
+			wantErr:       "retrieval failed for expanded query: index unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelCalls = 0
+			retrieved = nil
+			opts := QueryExpansionOptions{Mode: tt.mode, Model: model, NumQueries: 3, RRFK: defaultRRFK, TopK: tt.topK}
+
+			a := &AzureAIFoundry{}
+			resp, err := a.retrieveWithExpansion(ctx, g, base, opts, &ai.RetrieverRequest{
+				Query:   ai.DocumentFromText(tt.query, nil),
+				Options: tt.options,
+			})
+
+			if modelCalls != tt.wantModel {
+				t.Fatalf("model called %d times, want %d", modelCalls, tt.wantModel)
+			}
+			if !reflect.DeepEqual(retrieved, tt.wantRetrieved) {
+				t.Fatalf("retrieved queries = %q, want %q", retrieved, tt.wantRetrieved)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("retrieveWithExpansion() error = %v", err)
+			}
+			var texts []string
+			for _, doc := range resp.Documents {
+				texts = append(texts, documentText(doc))
+			}
+			if !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Fatalf("documents = %q, want %q", texts, tt.wantTexts)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// RateLimit caps the requests and tokens the plugin sends to a deployment per minute, so a
+// busy service queues requests locally instead of receiving 429 responses. Zero fields are
+// unlimited.
+type RateLimit struct {
+	RequestsPerMinute int  `json:"requestsPerMinute,omitempty"`
+	TokensPerMinute   int  `json:"tokensPerMinute,omitempty"` // Counted from the estimated prompt plus maxOutputTokens of a request until it reports its usage
+	Reject            bool `json:"reject,omitempty"`          // Fail requests over the limit with *QuotaExceededError instead of queueing them
+}
+
+// QuotaExceededError is returned when a RateLimit with Reject set would be exceeded by a request.
+type QuotaExceededError struct {
+	Limit      RateLimit     // The limit that would be exceeded
+	Tokens     int           // Estimated tokens of the request
+	RetryAfter time.Duration // When the limit allows the request again, if nothing else is sent
+}
+
+// Error implements the error interface.
+func (e *QuotaExceededError) Error() string {
+	var quota []string
+	if e.Limit.RequestsPerMinute > 0 {
+		quota = append(quota, fmt.Sprintf("%d requests", e.Limit.RequestsPerMinute))
+	}
+	if e.Limit.TokensPerMinute > 0 {
+		quota = append(quota, fmt.Sprintf("%d tokens", e.Limit.TokensPerMinute))
+	}
+	return fmt.Sprintf("request of about %d tokens would exceed the quota of %s per minute, retry after %s",
+		e.Tokens, strings.Join(quota, " and "), e.RetryAfter)
+}
+
+// rateLimiter enforces a RateLimit over a sliding one-minute window
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu       sync.Mutex
+	requests []*rateRequest // Requests started within the window, oldest first
+}
+
+// rateRequest is a request counted against the limit
+type rateRequest struct {
+	at     time.Time
+	tokens int
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	return &rateLimiter{limit: limit, now: time.Now}
+}
+
+// wait blocks until the limit allows another request of about the given tokens, then counts
+// it. With Reject set it fails instead of blocking
+func (l *rateLimiter) wait(ctx context.Context, tokens int) (*rateRequest, error) {
+	for {
+		request, delay := l.reserve(tokens)
+		if request != nil {
+			return request, nil
+		}
+		if l.limit.Reject {
+			return nil, &QuotaExceededError{Limit: l.limit, Tokens: tokens, RetryAfter: delay}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve counts a new request of about the given tokens if the limit allows it, or returns how
+// long to wait before trying again. A request larger than the token limit is allowed once the
+// window is empty, so it is not queued forever
+func (l *rateLimiter) reserve(tokens int) (*rateRequest, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for len(l.requests) > 0 && now.Sub(l.requests[0].at) >= time.Minute {
+		l.requests = l.requests[1:]
+	}
+	used := 0
+	for _, request := range l.requests {
+		used += request.tokens
+	}
+	if (l.limit.RequestsPerMinute > 0 && len(l.requests) >= l.limit.RequestsPerMinute) ||
+		(l.limit.TokensPerMinute > 0 && len(l.requests) > 0 && used+tokens > l.limit.TokensPerMinute) {
+		// Capacity frees up when the oldest request leaves the window
+		return nil, max(l.requests[0].at.Add(time.Minute).Sub(now), time.Millisecond)
+	}
+	request := &rateRequest{at: now, tokens: tokens}
+	l.requests = append(l.requests, request)
+	return request, 0
+}
+
+// record sets the tokens a counted request used
+func (l *rateLimiter) record(request *rateRequest, tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	request.tokens = tokens
+}
+
+// finish replaces the estimated tokens of a counted request with the tokens it used, or frees
+// them when it failed without reporting usage
+func (l *rateLimiter) finish(request *rateRequest, usage *ai.GenerationUsage, err error) {
+	switch {
+	case request == nil:
+	case usage != nil:
+		l.record(request, usage.TotalTokens)
+	case err != nil:
+		l.record(request, 0)
+	}
+}
+
+// deploymentLimiter returns the limiter of the RateLimits entry of a deployment, shared by all
+// its models and embedders, or nil if it has none
+func (a *AzureAIFoundry) deploymentLimiter(name string) *rateLimiter {
+	limit, ok := a.RateLimits[name]
+	if !ok {
+		return nil
+	}
+	limiter, _ := a.deploymentLimiters.LoadOrStore(name, newRateLimiter(limit))
+	return limiter.(*rateLimiter)
+}
+
+// estimatedTokens estimates the tokens a chat request counts against a token limit: its prompt
+// plus the output it may generate
+func (a *AzureAIFoundry) estimatedTokens(modelName string, input *ai.ModelRequest) int {
+	tokens := a.CountTokens(modelName, input.Messages)
+	if maxTokens := a.extractConfigFromRequest(input).maxTokens; maxTokens != nil {
+		tokens += int(*maxTokens)
+	}
+	return tokens
+}
+
+// estimatedEmbedTokens estimates the tokens of the documents of an embedding request
+func (a *AzureAIFoundry) estimatedEmbedTokens(modelName string, req *ai.EmbedRequest) int {
+	tokenizer := a.Tokenizer(modelName)
+	tokens := 0
+	for _, doc := range req.Input {
+		for _, part := range doc.Content {
+			tokens += tokenizer.CountTokens(part.Text)
+		}
+	}
+	return tokens
+}
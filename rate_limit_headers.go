@@ -0,0 +1,190 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// defaultMaxRetryAfter is the longest Retry-After RateLimitRetries waits for by default
+const defaultMaxRetryAfter = time.Minute
+
+// RateLimitStatus is what Azure reported about a deployment's rate limits in the headers
+// of a response. Successful chat responses carry it in their Custom metadata under
+// "rateLimit"; throttled requests fail with a RateLimitError.
+type RateLimitStatus struct {
+	RetryAfter        time.Duration `json:"retryAfter,omitempty"` // How long Azure asked to wait before retrying. Zero when not reported
+	RemainingRequests int           `json:"remainingRequests"`    // Requests left in the current window. -1 when not reported
+	RemainingTokens   int           `json:"remainingTokens"`      // Tokens left in the current window. -1 when not reported
+}
+
+// RateLimitError is returned when a request fails with rate limit headers, typically a 429.
+// It unwraps to the Azure error.
+type RateLimitError struct {
+	Status RateLimitStatus
+	Err    error
+}
+
+// Error implements the error interface.
+func (e *RateLimitError) Error() string {
+	msg := e.Err.Error()
+	if e.Status.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.Status.RetryAfter)
+	}
+	if e.Status.RemainingRequests >= 0 || e.Status.RemainingTokens >= 0 {
+		msg += fmt.Sprintf(" (remaining requests %d, tokens %d)", e.Status.RemainingRequests, e.Status.RemainingTokens)
+	}
+	return msg
+}
+
+// Unwrap returns the Azure error.
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// parseRateLimitHeaders reads the rate limit headers of a response, reporting whether any
+// was present
+func parseRateLimitHeaders(header http.Header) (RateLimitStatus, bool) {
+	status := RateLimitStatus{RemainingRequests: -1, RemainingTokens: -1}
+	found := false
+	if v, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && v >= 0 {
+		status.RetryAfter, found = time.Duration(v*float64(time.Millisecond)), true
+	} else if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds >= 0 {
+			status.RetryAfter, found = time.Duration(seconds*float64(time.Second)), true
+		} else if at, err := http.ParseTime(v); err == nil {
+			status.RetryAfter, found = max(0, time.Until(at)), true
+		}
+	}
+	if v, err := strconv.Atoi(header.Get("X-Ratelimit-Remaining-Requests")); err == nil {
+		status.RemainingRequests, found = v, true
+	}
+	if v, err := strconv.Atoi(header.Get("X-Ratelimit-Remaining-Tokens")); err == nil {
+		status.RemainingTokens, found = v, true
+	}
+	return status, found
+}
+
+// rateLimitKey is the context key of a request's rateLimitRecorder
+type rateLimitKey struct{}
+
+// rateLimitRecorder keeps the rate limit status of the last response of a request
+type rateLimitRecorder struct {
+	mu     sync.Mutex
+	status *RateLimitStatus
+}
+
+// withRateLimitRecorder returns a context whose responses record their rate limit status
+// in the returned recorder
+func withRateLimitRecorder(ctx context.Context) (context.Context, *rateLimitRecorder) {
+	recorder := &rateLimitRecorder{}
+	return context.WithValue(ctx, rateLimitKey{}, recorder), recorder
+}
+
+// last returns the recorded status, or nil if no response reported one
+func (r *rateLimitRecorder) last() *RateLimitStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// rateLimitMiddleware records the rate limit headers of responses and, with
+// RateLimitRetries, resends requests throttled with a 429 once their Retry-After passed
+func (a *AzureAIFoundry) rateLimitMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		var body []byte
+		if a.RateLimitRetries > 0 && req.Body != nil {
+			var err error
+			if body, err = io.ReadAll(req.Body); err != nil {
+				return nil, err
+			}
+			_ = req.Body.Close()
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			if err != nil {
+				return resp, err
+			}
+			status, ok := parseRateLimitHeaders(resp.Header)
+			if ok {
+				if recorder, _ := req.Context().Value(rateLimitKey{}).(*rateLimitRecorder); recorder != nil {
+					recorder.mu.Lock()
+					recorder.status = &status
+					recorder.mu.Unlock()
+				}
+			}
+			if resp.StatusCode != http.StatusTooManyRequests || attempt > a.RateLimitRetries ||
+				status.RetryAfter <= 0 || status.RetryAfter > a.maxRetryAfter() {
+				return resp, err
+			}
+
+			_ = resp.Body.Close()
+			timer := time.NewTimer(status.RetryAfter)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+			a.emit(req.Context(), Event{
+				Type:      EventRetry,
+				Model:     requestModel(req),
+				Operation: operationFromPath(req.URL.Path),
+				Attempt:   attempt,
+			})
+			req = req.Clone(req.Context())
+			if body != nil {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+		}
+	}
+}
+
+// maxRetryAfter returns the longest Retry-After RateLimitRetries waits for
+func (a *AzureAIFoundry) maxRetryAfter() time.Duration {
+	if a.MaxRetryAfter > 0 {
+		return a.MaxRetryAfter
+	}
+	return defaultMaxRetryAfter
+}
+
+// rateLimitError wraps Azure errors whose response carried rate limit headers in a
+// RateLimitError
+func rateLimitError(err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) || apiErr.Response == nil {
+		return err
+	}
+	if status, ok := parseRateLimitHeaders(apiErr.Response.Header); ok {
+		return &RateLimitError{Status: status, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    RateLimitStatus
+		wantOK  bool
+	}{
+		{
+			name:   "none",
+			want:   RateLimitStatus{RemainingRequests: -1, RemainingTokens: -1},
+			wantOK: false,
+		},
+		{
+			name:    "seconds and remaining",
+			headers: map[string]string{"Retry-After": "5", "x-ratelimit-remaining-requests": "0", "x-ratelimit-remaining-tokens": "1200"},
+			want:    RateLimitStatus{RetryAfter: 5 * time.Second, RemainingRequests: 0, RemainingTokens: 1200},
+			wantOK:  true,
+		},
+		{
+			name:    "milliseconds preferred",
+			headers: map[string]string{"Retry-After": "5", "retry-after-ms": "250"},
+			want:    RateLimitStatus{RetryAfter: 250 * time.Millisecond, RemainingRequests: -1, RemainingTokens: -1},
+			wantOK:  true,
+		},
+		{
+			name:    "malformed",
+			headers: map[string]string{"Retry-After": "soon", "x-ratelimit-remaining-tokens": "many"},
+			want:    RateLimitStatus{RemainingRequests: -1, RemainingTokens: -1},
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.headers {
+				header.Set(k, v)
+			}
+			got, ok := parseRateLimitHeaders(header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("parseRateLimitHeaders() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// throttlingHandler answers the first throttled requests with a 429 asking to wait
+// retryAfterMs, then succeeds
+func throttlingHandler(calls *atomic.Int32, throttled int32, retryAfterMs string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-should-retry", "false")
+		w.Header().Set("x-ratelimit-remaining-tokens", "0")
+		if calls.Add(1) <= throttled {
+			w.Header().Set("retry-after-ms", retryAfterMs)
+			w.Header().Set("x-ratelimit-remaining-requests", "0")
+			http.Error(w, `{"error":{"code":"429","message":"Rate limit is exceeded"}}`, http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("x-ratelimit-remaining-requests", "9")
+		w.Header().Set("x-ratelimit-remaining-tokens", "900")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}
+}
+
+func TestRateLimitRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		retries       int
+		maxRetryAfter time.Duration
+		throttled     int32
+		retryAfterMs  string
+		wantCalls     int32
+		wantStatus    RateLimitStatus
+		wantErr       bool
+	}{
+		{
+			name:         "no retries",
+			throttled:    1,
+			retryAfterMs: "20",
+			wantCalls:    1,
+			wantStatus:   RateLimitStatus{RetryAfter: 20 * time.Millisecond, RemainingRequests: 0, RemainingTokens: 0},
+			wantErr:      true,
+		},
+		{
+			name:         "retried after waiting",
+			retries:      2,
+			throttled:    2,
+			retryAfterMs: "20",
+			wantCalls:    3,
+			wantStatus:   RateLimitStatus{RemainingRequests: 9, RemainingTokens: 900},
+		},
+		{
+			name:         "retries exhausted",
+			retries:      1,
+			throttled:    2,
+			retryAfterMs: "20",
+			wantCalls:    2,
+			wantStatus:   RateLimitStatus{RetryAfter: 20 * time.Millisecond, RemainingRequests: 0, RemainingTokens: 0},
+			wantErr:      true,
+		},
+		{
+			name:          "wait too long",
+			retries:       2,
+			maxRetryAfter: time.Second,
+			throttled:     1,
+			retryAfterMs:  "60000",
+			wantCalls:     1,
+			wantStatus:    RateLimitStatus{RetryAfter: time.Minute, RemainingRequests: 0, RemainingTokens: 0},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var retried atomic.Int32
+			plugin := newTestPlugin(t, throttlingHandler(&calls, tt.throttled, tt.retryAfterMs), func(a *AzureAIFoundry) {
+				a.RateLimitRetries = tt.retries
+				a.MaxRetryAfter = tt.maxRetryAfter
+			})
+			plugin.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+				if event.Type == EventRetry {
+					retried.Add(1)
+				}
+			}))
+			ctx := context.Background()
+			g := genkit.Init(ctx)
+			model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+
+			started := time.Now()
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("hi"))
+			if calls.Load() != tt.wantCalls || retried.Load() != tt.wantCalls-1 {
+				t.Fatalf("calls = %d, retry events = %d, want %d calls", calls.Load(), retried.Load(), tt.wantCalls)
+			}
+			if tt.wantErr {
+				var rateErr *RateLimitError
+				if !errors.As(err, &rateErr) {
+					t.Fatalf("Generate() error = %v, want RateLimitError", err)
+				}
+				if rateErr.Status != tt.wantStatus {
+					t.Fatalf("RateLimitError.Status = %+v, want %+v", rateErr.Status, tt.wantStatus)
+				}
+				if time.Since(started) > 5*time.Second {
+					t.Fatalf("Generate() took %s, want no wait", time.Since(started))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			custom, _ := resp.Custom.(map[string]any)
+			if status, _ := custom["rateLimit"].(*RateLimitStatus); status == nil || !reflect.DeepEqual(*status, tt.wantStatus) {
+				t.Fatalf("Custom[rateLimit] = %+v, want %+v", custom["rateLimit"], tt.wantStatus)
+			}
+			if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+				t.Fatalf("Generate() took %s, want at least two 20ms waits", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		limit     RateLimit
+		tokens    []int // Tokens used by each earlier request, one second apart
+		estimate  int   // Estimated tokens of the new request
+		wantDelay time.Duration
+	}{
+		{"under request limit", RateLimit{RequestsPerMinute: 3}, []int{0, 0}, 0, 0},
+		{"at request limit", RateLimit{RequestsPerMinute: 2}, []int{0, 0}, 0, 58 * time.Second},
+		{"under token limit", RateLimit{TokensPerMinute: 1000}, []int{400, 500}, 100, 0},
+		{"at token limit", RateLimit{TokensPerMinute: 1000}, []int{600, 500}, 0, 58 * time.Second},
+		{"estimate over token limit", RateLimit{TokensPerMinute: 1000}, []int{400, 500}, 101, 58 * time.Second},
+		{"oversized request in empty window", RateLimit{TokensPerMinute: 1000}, nil, 5000, 0},
+		{"unlimited", RateLimit{}, []int{1e6, 1e6}, 1e6, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter(tt.limit)
+			clock := now
+			limiter.now = func() time.Time { return clock }
+			for _, tokens := range tt.tokens {
+				request, delay := limiter.reserve(0)
+				if request == nil {
+					t.Fatalf("earlier request delayed by %v", delay)
+				}
+				limiter.record(request, tokens)
+				clock = clock.Add(time.Second)
+			}
+
+			request, delay := limiter.reserve(tt.estimate)
+			if delay != tt.wantDelay || (request == nil) != (tt.wantDelay > 0) {
+				t.Fatalf("reserve() = %v, %v; want delay %v", request, delay, tt.wantDelay)
+			}
+
+			// Requests leave the window after a minute
+			clock = clock.Add(time.Minute)
+			if request, _ := limiter.reserve(tt.estimate); request == nil {
+				t.Fatal("request delayed after the window passed")
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitHonorsContext(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{RequestsPerMinute: 1})
+	if _, err := limiter.wait(context.Background(), 0); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestRateLimiterFinish(t *testing.T) {
+	tests := []struct {
+		name       string
+		usage      *ai.GenerationUsage
+		err        error
+		wantTokens int
+	}{
+		{"usage replaces estimate", &ai.GenerationUsage{TotalTokens: 120}, nil, 120},
+		{"failure frees estimate", nil, errors.New("boom"), 0},
+		{"success without usage keeps estimate", nil, nil, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter(RateLimit{TokensPerMinute: 1000})
+			request, _ := limiter.reserve(500)
+			limiter.finish(request, tt.usage, tt.err)
+			if request.tokens != tt.wantTokens {
+				t.Fatalf("tokens = %d, want %d", request.tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestRateLimitsRejectOverQuota(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.RateLimits = map[string]RateLimit{"gpt-4o": {TokensPerMinute: 300, Reject: true}}
+	})
+	_, fn := plugin.modelAction(ModelDefinition{Name: "gpt-4o"}, nil)
+	// Models of the same deployment share its limiter
+	_, other := plugin.modelAction(ModelDefinition{Name: "gpt-4o"}, nil)
+
+	request := func(maxOutputTokens int) *ai.ModelRequest {
+		return &ai.ModelRequest{
+			Messages: []*ai.Message{ai.NewUserTextMessage("hello")},
+			Config:   map[string]any{"maxOutputTokens": maxOutputTokens},
+		}
+	}
+	if _, err := fn(t.Context(), request(200), nil); err != nil {
+		t.Fatalf("first request error = %v", err)
+	}
+	// The first request reported 6 tokens of usage, leaving room for 294
+	_, err := other(t.Context(), request(300), nil)
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("second request error = %v, want *QuotaExceededError", err)
+	}
+	if quotaErr.Tokens <= 300 || quotaErr.RetryAfter <= 0 {
+		t.Fatalf("QuotaExceededError = %+v", quotaErr)
+	}
+	if _, err := fn(t.Context(), request(250), nil); err != nil {
+		t.Fatalf("third request error = %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(bodies))
+	}
+}
+
+func TestRateLimitsQueueEmbeddings(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]}],"model":"text-embedding-3-small","usage":{"prompt_tokens":1,"total_tokens":1}}`)
+	}, func(a *AzureAIFoundry) {
+		a.RateLimits = map[string]RateLimit{"text-embedding-3-small": {RequestsPerMinute: 1}}
+	})
+	embed := plugin.embedderFunc("text-embedding-3-small")
+	req := &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText("hello", nil)}}
+	if _, err := embed(t.Context(), req); err != nil {
+		t.Fatalf("first embed error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := embed(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second embed error = %v, want it queued until the deadline", err)
+	}
+}
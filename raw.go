@@ -0,0 +1,176 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// The Raw* methods send requests through the plugin's configured client, reusing its
+// authentication, retries, attribution and lifecycle events, for SDK features the Genkit
+// mapping does not cover yet. Model names in the params are Azure deployment names.
+
+// RawChatCompletion sends a chat completion request as-is.
+func (a *AzureAIFoundry) RawChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams, opts ...option.RequestOption) (resp *openai.ChatCompletion, err error) {
+	ctx, client, end, err := a.rawClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+	a.applyEndUser(ctx, &params)
+	a.applyAttribution(ctx, &params)
+
+	done := a.emitRawStarted(ctx, string(params.Model), OperationChat)
+	defer func() {
+		err = a.redactError(err)
+		var usage *ai.GenerationUsage
+		if resp != nil {
+			usage = convertUsage(resp.Usage)
+		}
+		done(usage, err)
+	}()
+
+	return client.Chat.Completions.New(ctx, params, opts...)
+}
+
+// RawEmbeddings sends an embeddings request as-is.
+func (a *AzureAIFoundry) RawEmbeddings(ctx context.Context, params openai.EmbeddingNewParams, opts ...option.RequestOption) (resp *openai.CreateEmbeddingResponse, err error) {
+	ctx, client, end, err := a.rawClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	done := a.emitRawStarted(ctx, string(params.Model), OperationEmbedding)
+	defer func() {
+		err = a.redactError(err)
+		var usage *ai.GenerationUsage
+		if resp != nil {
+			usage = &ai.GenerationUsage{
+				InputTokens: int(resp.Usage.PromptTokens),
+				TotalTokens: int(resp.Usage.TotalTokens),
+			}
+		}
+		done(usage, err)
+	}()
+
+	return client.Embeddings.New(ctx, params, opts...)
+}
+
+// RawImageGeneration sends an image generation request as-is.
+func (a *AzureAIFoundry) RawImageGeneration(ctx context.Context, params openai.ImageGenerateParams, opts ...option.RequestOption) (resp *openai.ImagesResponse, err error) {
+	ctx, client, end, err := a.rawClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	done := a.emitRawStarted(ctx, string(params.Model), OperationImage)
+	defer func() {
+		err = a.redactError(err)
+		done(nil, err)
+	}()
+
+	return client.Images.Generate(ctx, params, opts...)
+}
+
+// RawSpeech sends a text-to-speech request as-is. The caller must close the response body.
+func (a *AzureAIFoundry) RawSpeech(ctx context.Context, params openai.AudioSpeechNewParams, opts ...option.RequestOption) (resp *http.Response, err error) {
+	ctx, client, end, err := a.rawClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := a.emitRawStarted(ctx, string(params.Model), OperationSpeech)
+	defer func() {
+		err = a.redactError(err)
+		done(nil, err)
+	}()
+
+	resp, err = client.Audio.Speech.New(ctx, params, opts...)
+	if err != nil {
+		end()
+		return nil, err
+	}
+	// The request stays in flight until the caller has read the audio
+	resp.Body = &trackedBody{ReadCloser: resp.Body, end: end}
+	return resp, nil
+}
+
+// RawTranscription sends a speech-to-text request as-is.
+func (a *AzureAIFoundry) RawTranscription(ctx context.Context, params openai.AudioTranscriptionNewParams, opts ...option.RequestOption) (resp *openai.AudioTranscriptionNewResponseUnion, err error) {
+	ctx, client, end, err := a.rawClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	done := a.emitRawStarted(ctx, string(params.Model), OperationTranscription)
+	defer func() {
+		err = a.redactError(err)
+		done(nil, err)
+	}()
+
+	return client.Audio.Transcriptions.New(ctx, params, opts...)
+}
+
+// rawClient returns the configured client, failing if the plugin was not initialized or
+// closed, or the residency policy or flow budget does not allow the request. The request is
+// in flight until end is called and must use the returned context.
+func (a *AzureAIFoundry) rawClient(ctx context.Context) (_ context.Context, _ openai.Client, end func(), _ error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.initted {
+		return ctx, openai.Client{}, nil, fmt.Errorf("azureaifoundry: client not initialized")
+	}
+	if err := a.checkResidency(ctx); err != nil {
+		return ctx, openai.Client{}, nil, err
+	}
+	ctx, end, err := a.lifecycle.track(ctx)
+	if err != nil {
+		return ctx, openai.Client{}, nil, err
+	}
+	if err := chargeFlowBudget(ctx); err != nil {
+		end()
+		return ctx, openai.Client{}, nil, err
+	}
+	return ctx, a.client, end, nil
+}
+
+// emitRawStarted emits EventRequestStarted for a raw request and returns a function that
+// emits its completion
+func (a *AzureAIFoundry) emitRawStarted(ctx context.Context, model, operation string) func(*ai.GenerationUsage, error) {
+	started := Event{
+		Type:      EventRequestStarted,
+		Time:      time.Now(),
+		Model:     model,
+		Operation: operation,
+	}
+	a.emit(ctx, started)
+	return func(usage *ai.GenerationUsage, err error) {
+		recordFlowBudget(ctx, usage)
+		a.emitCompleted(ctx, started, nil, usage, err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+)
+
+func TestRawChatCompletion(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	var events []Event
+	plugin.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+		events = append(events, event)
+	}))
+
+	ctx := WithAttribution(context.Background(), Attribution{Flow: "raw"})
+	resp, err := plugin.RawChatCompletion(ctx, openai.ChatCompletionNewParams{
+		Model:               "gpt-4o",
+		Messages:            []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+		ParallelToolCalls:   openai.Bool(false),
+		MaxCompletionTokens: openai.Int(10),
+	})
+	if err != nil {
+		t.Fatalf("RawChatCompletion() error = %v", err)
+	}
+	if resp.Choices[0].Message.Content != "ok" {
+		t.Fatalf("content = %q", resp.Choices[0].Message.Content)
+	}
+	if _, ok := bodies[0]["user"]; bodies[0]["parallel_tool_calls"] != false || ok {
+		t.Fatalf("request body = %v", bodies[0])
+	}
+	if len(events) != 2 || events[1].Type != EventCompleted || events[1].Usage.TotalTokens != 6 || events[1].Model != "gpt-4o" || events[1].Attribution.Flow != "raw" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestRawRequiresInit(t *testing.T) {
+	plugin := &AzureAIFoundry{}
+	if _, err := plugin.RawEmbeddings(context.Background(), openai.EmbeddingNewParams{}); err == nil {
+		t.Fatal("RawEmbeddings() error = nil before Init")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3/packages/respjson"
+)
+
+// Tags reasoning models such as DeepSeek-R1 wrap their reasoning in
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// reasoningSplitter separates the reasoning of a response from the answer. Reasoning is the
+// content of a <think> block at the start of the output; it may arrive split across chunks.
+type reasoningSplitter struct {
+	decided  bool // Whether the start of the output was seen
+	thinking bool // Inside the <think> block
+	trimming bool // Dropping whitespace between </think> and the answer
+	pending  string
+}
+
+// split returns the reasoning and answer text in the next piece of output. Text that might
+// be the start of a tag is held back until the next call or flush.
+func (s *reasoningSplitter) split(text string) (reasoning, answer string) {
+	buffer := s.pending + text
+	s.pending = ""
+
+	if !s.decided {
+		trimmed := strings.TrimLeft(buffer, " \t\r\n")
+		switch {
+		case strings.HasPrefix(trimmed, thinkOpenTag):
+			s.decided, s.thinking = true, true
+			buffer = trimmed[len(thinkOpenTag):]
+		case strings.HasPrefix(thinkOpenTag, trimmed):
+			s.pending = buffer
+			return "", ""
+		default:
+			s.decided = true
+		}
+	}
+
+	if s.thinking {
+		if end := strings.Index(buffer, thinkCloseTag); end != -1 {
+			reasoning, buffer = buffer[:end], buffer[end+len(thinkCloseTag):]
+			s.thinking, s.trimming = false, true
+		} else {
+			keep := partialTagLength(buffer, thinkCloseTag)
+			s.pending = buffer[len(buffer)-keep:]
+			return buffer[:len(buffer)-keep], ""
+		}
+	}
+	if s.trimming {
+		buffer = strings.TrimLeft(buffer, " \t\r\n")
+		s.trimming = buffer == ""
+	}
+	return reasoning, buffer
+}
+
+// flush returns the text held back at the end of the output
+func (s *reasoningSplitter) flush() (reasoning, answer string) {
+	pending := s.pending
+	s.pending = ""
+	if s.thinking {
+		// The output ended inside the <think> block, e.g. at the token limit
+		return pending, ""
+	}
+	return "", pending
+}
+
+// partialTagLength returns the length of the longest suffix of text that is a proper prefix
+// of tag
+func partialTagLength(text, tag string) int {
+	for n := min(len(text), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// reasoningContent returns the reasoning_content field some Foundry models, such as
+// DeepSeek-R1, return next to the message content
+func reasoningContent(fields map[string]respjson.Field) string {
+	// Extra fields are never reported as valid, so only the raw value is checked
+	raw := fields["reasoning_content"].Raw()
+	if raw == "" {
+		return ""
+	}
+	var text string
+	_ = json.Unmarshal([]byte(raw), &text)
+	return text
+}
+
+// splitReasoning separates the reasoning of a complete message from its answer
+func splitReasoning(content string, fields map[string]respjson.Field) (reasoning, answer string) {
+	var splitter reasoningSplitter
+	reasoning, answer = splitter.split(content)
+	flushedReasoning, flushedAnswer := splitter.flush()
+	return reasoningContent(fields) + reasoning + flushedReasoning, answer + flushedAnswer
+}
+
+// reasoningParts returns the parts for reasoning and answer text, dropping the reasoning
+// when the request set the "stripReasoning" config option
+func reasoningParts(reasoning, answer string, strip bool) []*ai.Part {
+	var parts []*ai.Part
+	if reasoning != "" && !strip {
+		parts = append(parts, ai.NewReasoningPart(reasoning, nil))
+	}
+	if answer != "" {
+		parts = append(parts, ai.NewTextPart(answer))
+	}
+	return parts
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestReasoningSplitter(t *testing.T) {
+	tests := []struct {
+		name          string
+		chunks        []string
+		wantReasoning string
+		wantAnswer    string
+	}{
+		{"split tags", []string{"<thi", "nk>plan", "ning</th", "ink>\n\nAnswer"}, "planning", "Answer"},
+		{"leading whitespace", []string{"\n", " <think>a</think>b"}, "a", "b"},
+		{"no reasoning", []string{"Hello ", "<think> is a tag"}, "", "Hello <think> is a tag"},
+		{"other tag", []string{"<", "b>bold"}, "", "<b>bold"},
+		{"unterminated", []string{"<think>abc</thi"}, "abc</thi", ""},
+		{"whitespace only", []string{"  "}, "", "  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var splitter reasoningSplitter
+			var reasoning, answer strings.Builder
+			for _, chunk := range append(tt.chunks, "") {
+				r, a := splitter.split(chunk)
+				reasoning.WriteString(r)
+				answer.WriteString(a)
+			}
+			r, a := splitter.flush()
+			reasoning.WriteString(r)
+			answer.WriteString(a)
+			if reasoning.String() != tt.wantReasoning || answer.String() != tt.wantAnswer {
+				t.Fatalf("reasoning = %q, answer = %q, want %q, %q", reasoning.String(), answer.String(), tt.wantReasoning, tt.wantAnswer)
+			}
+		})
+	}
+}
+
+func TestReasoningExtraction(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       map[string]any
+		config        map[string]any
+		wantReasoning string
+		wantText      string
+	}{
+		{"think tags", map[string]any{"content": "<think>6 times 7</think>\n\n42"}, nil, "6 times 7", "42"},
+		{"reasoning_content", map[string]any{"content": "42", "reasoning_content": "6 times 7"}, nil, "6 times 7", "42"},
+		{"stripped", map[string]any{"content": "<think>6 times 7</think>42"}, map[string]any{"stripReasoning": true}, "", "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := map[string]any{"role": "assistant"}
+			for k, v := range tt.message {
+				message[k] = v
+			}
+			body, _ := json.Marshal(map[string]any{
+				"id": "1", "object": "chat.completion", "created": 1, "model": "DeepSeek-R1",
+				"choices": []any{map[string]any{"index": 0, "finish_reason": "stop", "message": message}},
+			})
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(body)
+			})
+
+			resp, err := plugin.generateText(context.Background(), "DeepSeek-R1", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("6 times 7?")},
+				Config:   tt.config,
+			}, nil)
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if resp.Reasoning() != tt.wantReasoning || resp.Text() != tt.wantText {
+				t.Fatalf("reasoning = %q, text = %q", resp.Reasoning(), resp.Text())
+			}
+		})
+	}
+}
+
+func TestReasoningStreaming(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"<think>", "6 times ", "7</think>", "\n\n4", "2"} {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	var streamedReasoning, streamedText strings.Builder
+	resp, err := plugin.generateText(context.Background(), "DeepSeek-R1", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("6 times 7?")},
+	}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		for _, part := range chunk.Content {
+			if part.IsReasoning() {
+				streamedReasoning.WriteString(part.Text)
+			}
+		}
+		streamedText.WriteString(chunk.Text())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if streamedReasoning.String() != "6 times 7" || streamedText.String() != "42" {
+		t.Fatalf("streamed reasoning = %q, text = %q", streamedReasoning.String(), streamedText.String())
+	}
+	if resp.Reasoning() != "6 times 7" || resp.Text() != "42" {
+		t.Fatalf("reasoning = %q, text = %q", resp.Reasoning(), resp.Text())
+	}
+}
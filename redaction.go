@@ -0,0 +1,206 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/openai/openai-go/v3"
+)
+
+// RedactionMode controls how much of an error is scrubbed before the plugin returns, logs
+// or emits it.
+type RedactionMode string
+
+const (
+	// RedactCredentials removes API keys, bearer tokens, SAS signatures and sensitive headers.
+	// It is the default.
+	RedactCredentials RedactionMode = "credentials"
+	// RedactStrict also replaces endpoint hosts with a stable hash, e.g. "endpoint-3f2a9c1e",
+	// so errors can be correlated without revealing resource names.
+	RedactStrict RedactionMode = "strict"
+	// RedactNone leaves errors untouched, for local debugging.
+	RedactNone RedactionMode = "none"
+)
+
+// redacted replaces scrubbed values
+const redacted = "REDACTED"
+
+var (
+	// secretQueryPattern matches credentials passed as query parameters
+	secretQueryPattern = regexp.MustCompile(`(?i)([?&](?:api-key|api_key|apikey|key|subscription-key|code|sig|token|access_token)=)[^&\s"']+`)
+	// secretHeaderPattern matches credential headers in request dumps
+	secretHeaderPattern = regexp.MustCompile(`(?im)^((?:api-key|authorization|proxy-authorization|x-api-key|ocp-apim-subscription-key)\s*:\s*)\S[^\r\n]*`)
+	// bearerPattern matches bearer tokens anywhere in a message
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+	// endpointHostPattern matches the host of an http or https URL
+	endpointHostPattern = regexp.MustCompile(`(?i)(https?://)([^/\s"'?#]+)`)
+)
+
+// sensitiveHeaders are removed from requests kept in API errors
+var sensitiveHeaders = []string{"Api-Key", "Authorization", "Proxy-Authorization", "X-Api-Key", "Ocp-Apim-Subscription-Key"}
+
+// redactedError is an error whose message was scrubbed. It unwraps to the original error,
+// whose API request was scrubbed in place.
+type redactedError struct {
+	msg string
+	err error
+}
+
+// Error implements the error interface.
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the original error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError scrubs credentials, and endpoints in strict mode, from an error
+func (a *AzureAIFoundry) redactError(err error) error {
+	detachRequest(err)
+	mode := a.ErrorRedaction
+	if err == nil || mode == RedactNone {
+		return err
+	}
+	if _, ok := err.(*redactedError); ok {
+		return err
+	}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		apiErr.Request = a.redactRequest(apiErr.Request)
+		if apiErr.Response != nil {
+			apiErr.Response.Request = apiErr.Request
+		}
+	}
+	msg := err.Error()
+	if scrubbed := a.redactText(msg); scrubbed != msg {
+		return &redactedError{msg: scrubbed, err: err}
+	}
+	return err
+}
+
+// withRedaction wraps a model function so the errors it returns are scrubbed
+func (a *AzureAIFoundry) withRedaction(fn ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		resp, err := fn(ctx, input, cb)
+		return resp, a.redactError(err)
+	}
+}
+
+// redactText scrubs credentials, and endpoints in strict mode, from a message
+func (a *AzureAIFoundry) redactText(text string) string {
+	for _, secret := range a.secrets() {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	text = secretQueryPattern.ReplaceAllString(text, "${1}"+redacted)
+	text = secretHeaderPattern.ReplaceAllString(text, "${1}"+redacted)
+	text = bearerPattern.ReplaceAllString(text, "${1}"+redacted)
+	if a.AuthHeader != "" {
+		custom := regexp.MustCompile(`(?im)^(` + regexp.QuoteMeta(a.AuthHeader) + `\s*:\s*)\S[^\r\n]*`)
+		text = custom.ReplaceAllString(text, "${1}"+redacted)
+	}
+	if a.ErrorRedaction == RedactStrict {
+		text = endpointHostPattern.ReplaceAllStringFunc(text, func(match string) string {
+			groups := endpointHostPattern.FindStringSubmatch(match)
+			return groups[1] + hashHost(groups[2])
+		})
+	}
+	return text
+}
+
+// redactRequest returns a copy of an API request without credentials
+func (a *AzureAIFoundry) redactRequest(req *http.Request) *http.Request {
+	if req == nil {
+		return nil
+	}
+	clean := req.Clone(req.Context())
+	for _, header := range append(append(sensitiveHeaders, a.AuthHeader), a.credentialHeaders()...) {
+		if header != "" && clean.Header.Get(header) != "" {
+			clean.Header.Set(header, redacted)
+		}
+	}
+	if clean.URL != nil {
+		if parsed, err := url.Parse(a.redactText(clean.URL.String())); err == nil {
+			clean.URL = parsed
+		}
+	}
+	return clean
+}
+
+// minSecretLength is the length below which configured keys are not replaced verbatim,
+// as they would match unrelated text
+const minSecretLength = 8
+
+// credentialHeaderWords mark the names of custom headers that carry credentials
+var credentialHeaderWords = []string{"key", "token", "secret", "auth", "signature", "password"}
+
+// credentialHeaders returns the names of the custom headers that carry credentials
+func (a *AzureAIFoundry) credentialHeaders() []string {
+	var names []string
+	for name := range a.Headers {
+		lower := strings.ToLower(name)
+		if slices.ContainsFunc(credentialHeaderWords, func(word string) bool { return strings.Contains(lower, word) }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// secrets returns the configured API keys and the values of credential headers
+func (a *AzureAIFoundry) secrets() []string {
+	var secrets []string
+	for _, key := range []string{a.APIKey} {
+		if len(key) >= minSecretLength {
+			secrets = append(secrets, key)
+		}
+	}
+	for _, endpoint := range a.FailoverEndpoints {
+		if len(endpoint.APIKey) >= minSecretLength {
+			secrets = append(secrets, endpoint.APIKey)
+		}
+	}
+	for _, name := range a.credentialHeaders() {
+		value := a.Headers[name]
+		// The credential of an "Authorization: Bearer <token>" value may appear on its own
+		if _, credential, ok := strings.Cut(value, " "); ok && len(credential) >= minSecretLength {
+			secrets = append(secrets, credential)
+		}
+		if len(value) >= minSecretLength {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// hashHost returns a stable pseudonym for an endpoint host
+func hashHost(host string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(host)))
+	return "endpoint-" + hex.EncodeToString(sum[:4])
+}
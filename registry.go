@@ -0,0 +1,584 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/goccy/go-yaml"
+	"github.com/openai/openai-go/v3"
+)
+
+// RegistryConfig declares the models a plugin registers at Init, so a fleet of deployments
+// can be managed in a config file instead of code.
+type RegistryConfig struct {
+	Models         []ModelConfig   `json:"models"`
+	Embedders      []string        `json:"embedders,omitempty"`      // Embedding deployment names
+	FailoverGroups []FailoverGroup `json:"failoverGroups,omitempty"` // Models that try several deployments in turn
+
+	LoadBalancedGroups []LoadBalancedGroup `json:"loadBalancedGroups,omitempty"` // Models that spread requests across deployments
+	Routers            []TokenRouter       `json:"routers,omitempty"`            // Models that pick a deployment by prompt size
+	Canaries           []CanaryRollout     `json:"canaries,omitempty"`           // Models that move traffic to a new deployment in steps
+
+	Guardrails map[string]GuardrailProfile `json:"guardrails,omitempty"` // Guardrail profiles by name, attached to models with ModelConfig.Guardrail
+}
+
+// ModelConfig declares a deployment in a RegistryConfig.
+type ModelConfig struct {
+	Name             string            `json:"name"`                       // Deployment name (required)
+	Type             string            `json:"type,omitempty"`             // "chat" or "text"
+	ModelVersion     string            `json:"modelVersion,omitempty"`     // Underlying model and version, checked for retirement
+	MaxTokens        int32             `json:"maxTokens,omitempty"`        // Context window in tokens, overriding ModelCatalog
+	Reasoning        bool              `json:"reasoning,omitempty"`        // The deployment serves a reasoning model (o-series, GPT-5)
+	SystemRole       string            `json:"systemRole,omitempty"`       // Role system messages are sent with: "system" or "developer"
+	ServiceTier      string            `json:"serviceTier,omitempty"`      // Processing tier, e.g. "flex" or "priority"
+	EmulateTools     bool              `json:"emulateTools,omitempty"`     // Emulate tool calling for models without native tool support
+	Supports         *ai.ModelSupports `json:"supports,omitempty"`         // Capabilities. Inferred from the name when omitted
+	Config           map[string]any    `json:"config,omitempty"`           // Default request config
+	RateLimit        *RateLimit        `json:"rateLimit,omitempty"`        // Client-side request and token limits
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`     // Name of a SystemPrompts template
+	SystemPromptVars map[string]any    `json:"systemPromptVars,omitempty"` // Variables for the system prompt template
+	Guardrail        string            `json:"guardrail,omitempty"`        // Name of a guardrail profile enforced on every request
+}
+
+// FailoverGroup registers a model that sends each request to the first of its member
+// deployments and moves on to the next when a deployment is throttled (429) or failing
+// (5xx, timeouts, network errors).
+type FailoverGroup struct {
+	Name         string   `json:"name"`                   // Name of the group model (required)
+	Models       []string `json:"models"`                 // Member names from Models or LoadBalancedGroups, in priority order (required)
+	ProbeStandby bool     `json:"probeStandby,omitempty"` // Probe the members after the first periodically, so failover skips those found failing
+}
+
+// definition converts the config to a model definition
+func (c ModelConfig) definition() ModelDefinition {
+	return ModelDefinition{
+		Name:             c.Name,
+		Type:             c.Type,
+		MaxTokens:        c.MaxTokens,
+		Reasoning:        c.Reasoning,
+		SystemRole:       c.SystemRole,
+		ServiceTier:      c.ServiceTier,
+		EmulateTools:     c.EmulateTools,
+		SupportsMedia:    c.Supports != nil && c.Supports.Media,
+		ModelVersion:     c.ModelVersion,
+		SystemPrompt:     c.SystemPrompt,
+		SystemPromptVars: c.SystemPromptVars,
+		DefaultConfig:    c.Config,
+		RateLimit:        c.RateLimit,
+		Guardrail:        c.Guardrail,
+	}
+}
+
+// LoadModelsFromConfig parses a YAML or JSON model registry and validates it. Unknown fields
+// are rejected so typos surface at startup.
+func LoadModelsFromConfig(data []byte) (*RegistryConfig, error) {
+	// JSON is valid YAML, so both formats go through the same conversion
+	converted, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse model registry: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.DisallowUnknownFields()
+	var config RegistryConfig
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse model registry: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// LoadModelsFromFile reads a YAML or JSON model registry file. See LoadModelsFromConfig.
+func LoadModelsFromFile(path string) (*RegistryConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model registry: %w", err)
+	}
+	return LoadModelsFromConfig(data)
+}
+
+// validate checks that names are set and unique and that groups reference declared models
+func (c *RegistryConfig) validate() error {
+	for name, profile := range c.Guardrails {
+		if profile.Moderation != nil && profile.Moderation.Model == "" {
+			return fmt.Errorf("model registry: guardrail profile %q has moderation without a model", name)
+		}
+	}
+	models := make(map[string]bool, len(c.Models))
+	for i, model := range c.Models {
+		if model.Name == "" {
+			return fmt.Errorf("model registry: model %d has no name", i+1)
+		}
+		if models[model.Name] {
+			return fmt.Errorf("model registry: model %q is declared twice", model.Name)
+		}
+		models[model.Name] = true
+	}
+	declared := maps.Clone(models)
+	for i, group := range c.LoadBalancedGroups {
+		if group.Name == "" {
+			return fmt.Errorf("model registry: load-balanced group %d has no name", i+1)
+		}
+		if declared[group.Name] {
+			return fmt.Errorf("model registry: load-balanced group %q has the name of a model or group", group.Name)
+		}
+		if group.Strategy != "" && group.Strategy != BalanceWeighted && group.Strategy != BalanceLeastLatency {
+			return fmt.Errorf("model registry: load-balanced group %q has unknown strategy %q", group.Name, group.Strategy)
+		}
+		if len(group.Models) == 0 {
+			return fmt.Errorf("model registry: load-balanced group %q has no models", group.Name)
+		}
+		for _, member := range group.Models {
+			if !models[member.Name] {
+				return fmt.Errorf("model registry: load-balanced group %q references undeclared model %q", group.Name, member.Name)
+			}
+			if member.Weight < 0 {
+				return fmt.Errorf("model registry: load-balanced group %q has a negative weight for %q", group.Name, member.Name)
+			}
+		}
+		declared[group.Name] = true
+	}
+	models = declared
+	for i, group := range c.FailoverGroups {
+		if group.Name == "" {
+			return fmt.Errorf("model registry: failover group %d has no name", i+1)
+		}
+		if models[group.Name] {
+			return fmt.Errorf("model registry: failover group %q has the name of a model or group", group.Name)
+		}
+		if len(group.Models) == 0 {
+			return fmt.Errorf("model registry: failover group %q has no models", group.Name)
+		}
+		for _, member := range group.Models {
+			if !models[member] {
+				return fmt.Errorf("model registry: failover group %q references undeclared model %q", group.Name, member)
+			}
+		}
+		models[group.Name] = true
+	}
+	for i, router := range c.Routers {
+		if router.Name == "" {
+			return fmt.Errorf("model registry: router %d has no name", i+1)
+		}
+		if models[router.Name] {
+			return fmt.Errorf("model registry: router %q has the name of a model or group", router.Name)
+		}
+		if err := router.validate(models); err != nil {
+			return err
+		}
+		models[router.Name] = true
+	}
+	for i, rollout := range c.Canaries {
+		if rollout.Name == "" {
+			return fmt.Errorf("model registry: canary %d has no name", i+1)
+		}
+		if models[rollout.Name] {
+			return fmt.Errorf("model registry: canary %q has the name of a model or group", rollout.Name)
+		}
+		if err := rollout.validate(models); err != nil {
+			return err
+		}
+		models[rollout.Name] = true
+	}
+	return nil
+}
+
+// RegistryChange lists the names a registry reload added, updated or removed. Updated names
+// keep the capabilities and label they were registered with; reloads that would change them
+// fail instead.
+type RegistryChange struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// IsZero reports whether the reload changed nothing.
+func (c *RegistryChange) IsZero() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// registryState tracks the models and embedders registered from a RegistryConfig. Registered
+// actions dispatch to the current entry on every call, so definitions can be swapped without
+// re-registering, which Genkit does not allow.
+type registryState struct {
+	mu        sync.RWMutex
+	config    *RegistryConfig
+	models    map[string]*registryModel // Registered models and failover groups by name
+	embedders map[string]bool           // Registered embedders by name; false once removed
+	canaries  map[string]*canary        // Running canary rollouts by name
+}
+
+// registryModel is the current definition behind a registered model action
+type registryModel struct {
+	meta *ai.ModelOptions
+	fn   ai.ModelFunc // nil once removed from the registry
+}
+
+// modelFunc returns the current function of a registered model, nil if it was removed
+func (s *registryState) modelFunc(name string) ai.ModelFunc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if entry := s.models[name]; entry != nil {
+		return entry.fn
+	}
+	return nil
+}
+
+// registryMeta returns the metadata each model, group, router and rollout in config would be
+// registered with. Callers must hold s.mu
+func (a *AzureAIFoundry) registryMeta(config *RegistryConfig) map[string]*ai.ModelOptions {
+	metas := make(map[string]*ai.ModelOptions)
+	for _, model := range config.Models {
+		var info *ai.ModelInfo
+		if model.Supports != nil {
+			info = &ai.ModelInfo{Supports: model.Supports}
+		}
+		metas[model.Name] = a.modelMeta(model.definition(), info)
+	}
+	// Groups, routers and rollouts accept what their first member accepts
+	derive := func(name, member string) {
+		base := metas[member]
+		if entry := a.registry.models[member]; entry != nil {
+			base = entry.meta
+		}
+		if base == nil {
+			return
+		}
+		meta := *base
+		meta.Label = a.Name() + "-" + name
+		metas[name] = &meta
+	}
+	for _, group := range config.LoadBalancedGroups {
+		derive(group.Name, group.Models[0].Name)
+	}
+	for _, group := range config.FailoverGroups {
+		derive(group.Name, group.Models[0])
+	}
+	for _, router := range config.Routers {
+		derive(router.Name, router.Routes[0].Model)
+	}
+	for _, rollout := range config.Canaries {
+		derive(rollout.Name, rollout.Stable)
+	}
+	return metas
+}
+
+// applyRegistry makes config the current registry. It returns actions for names that were
+// never registered, which the caller must register, and what changed. Genkit validates requests
+// against the metadata an action was registered with, so a config that changes the
+// capabilities or label of a registered name is rejected and nothing is applied.
+func (a *AzureAIFoundry) applyRegistry(config *RegistryConfig) ([]api.Action, *RegistryChange, error) {
+	s := &a.registry
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.models == nil {
+		s.models = make(map[string]*registryModel)
+		s.embedders = make(map[string]bool)
+		s.canaries = make(map[string]*canary)
+	}
+
+	var changed []string
+	for name, meta := range a.registryMeta(config) {
+		if entry := s.models[name]; entry != nil && !reflect.DeepEqual(entry.meta, meta) {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return nil, nil, fmt.Errorf("azureaifoundry: registry changes the capabilities of %s, which requires a restart", strings.Join(changed, ", "))
+	}
+
+	previousModels := make(map[string]ModelConfig)
+	previousGroups := make(map[string]FailoverGroup)
+	previousBalanced := make(map[string]LoadBalancedGroup)
+	previousRouters := make(map[string]TokenRouter)
+	previousCanaries := make(map[string]CanaryRollout)
+	previousEmbedders := make(map[string]bool)
+	if s.config != nil {
+		for _, model := range s.config.Models {
+			previousModels[model.Name] = model
+		}
+		for _, group := range s.config.FailoverGroups {
+			previousGroups[group.Name] = group
+		}
+		for _, group := range s.config.LoadBalancedGroups {
+			previousBalanced[group.Name] = group
+		}
+		for _, router := range s.config.Routers {
+			previousRouters[router.Name] = router
+		}
+		for _, rollout := range s.config.Canaries {
+			previousCanaries[rollout.Name] = rollout
+		}
+		for _, name := range s.config.Embedders {
+			previousEmbedders[name] = true
+		}
+	}
+
+	change := &RegistryChange{}
+	var actions []api.Action
+	current := make(map[string]bool)
+	// set stores a model definition, registering an action for names seen for the first time
+	set := func(name string, existed bool, meta *ai.ModelOptions, fn ai.ModelFunc) {
+		current[name] = true
+		entry := s.models[name]
+		switch {
+		case entry == nil:
+			entry = &registryModel{meta: meta}
+			s.models[name] = entry
+			actions = append(actions, ai.NewModel(api.NewName(a.Name(), name), meta, func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+				fn := s.modelFunc(name)
+				if fn == nil {
+					return nil, fmt.Errorf("model %q was removed from the model registry", name)
+				}
+				return fn(ctx, input, cb)
+			}).(api.Action))
+			change.Added = append(change.Added, name)
+		case existed:
+			change.Updated = append(change.Updated, name)
+		default:
+			change.Added = append(change.Added, name)
+		}
+		entry.fn = fn
+	}
+
+	for _, model := range config.Models {
+		previous, existed := previousModels[model.Name]
+		if existed && reflect.DeepEqual(previous, model) {
+			current[model.Name] = true
+			continue
+		}
+		var info *ai.ModelInfo
+		if model.Supports != nil {
+			info = &ai.ModelInfo{Supports: model.Supports}
+		}
+		meta, fn := a.modelAction(model.definition(), info)
+		set(model.Name, existed, meta, fn)
+	}
+	for _, group := range config.LoadBalancedGroups {
+		previous, existed := previousBalanced[group.Name]
+		if existed && reflect.DeepEqual(previous, group) {
+			current[group.Name] = true
+			continue
+		}
+		// The group accepts what its first deployment accepts
+		meta := *s.models[group.Models[0].Name].meta
+		meta.Label = a.Name() + "-" + group.Name
+		set(group.Name, existed, &meta, a.loadBalancedFunc(group, s.modelFunc))
+	}
+	for _, group := range config.FailoverGroups {
+		previous, existed := previousGroups[group.Name]
+		if existed && reflect.DeepEqual(previous, group) {
+			current[group.Name] = true
+			continue
+		}
+		// The group accepts what its primary deployment accepts
+		meta := *s.models[group.Models[0]].meta
+		meta.Label = a.Name() + "-" + group.Name
+		set(group.Name, existed, &meta, a.failoverFunc(group, s.modelFunc))
+	}
+	if slices.ContainsFunc(config.FailoverGroups, func(group FailoverGroup) bool { return group.ProbeStandby }) {
+		a.startStandbyProbes()
+	}
+	for _, router := range config.Routers {
+		previous, existed := previousRouters[router.Name]
+		if existed && reflect.DeepEqual(previous, router) {
+			current[router.Name] = true
+			continue
+		}
+		// The router accepts what its first route accepts
+		meta := *s.models[router.Routes[0].Model].meta
+		meta.Label = a.Name() + "-" + router.Name
+		set(router.Name, existed, &meta, a.tokenRouterFunc(router, s.modelFunc))
+	}
+	for _, rollout := range config.Canaries {
+		previous, existed := previousCanaries[rollout.Name]
+		if existed && reflect.DeepEqual(previous, rollout) {
+			current[rollout.Name] = true
+			continue
+		}
+		// A changed rollout starts over. It accepts what its stable model accepts
+		c := newCanary(rollout)
+		s.canaries[rollout.Name] = c
+		meta := *s.models[rollout.Stable].meta
+		meta.Label = a.Name() + "-" + rollout.Name
+		set(rollout.Name, existed, &meta, a.canaryFunc(c, s.modelFunc))
+	}
+	for name, entry := range s.models {
+		if !current[name] && entry.fn != nil {
+			entry.fn = nil
+			delete(s.canaries, name)
+			change.Removed = append(change.Removed, name)
+		}
+	}
+
+	embedders := make(map[string]bool, len(config.Embedders))
+	for _, name := range config.Embedders {
+		embedders[name] = true
+		active, registered := s.embedders[name]
+		switch {
+		case !registered:
+			actions = append(actions, ai.NewEmbedder(api.NewName(a.Name(), name), nil, a.registryEmbedderFunc(name)).(api.Action))
+			change.Added = append(change.Added, name)
+		case !active:
+			change.Added = append(change.Added, name)
+		}
+		s.embedders[name] = true
+	}
+	for name, active := range s.embedders {
+		if active && !embedders[name] {
+			s.embedders[name] = false
+			change.Removed = append(change.Removed, name)
+		}
+	}
+
+	s.config = config
+	sort.Strings(change.Added)
+	sort.Strings(change.Updated)
+	sort.Strings(change.Removed)
+	return actions, change, nil
+}
+
+// registryEmbedderFunc returns an embedder function that fails once the embedder is removed
+// from the registry
+func (a *AzureAIFoundry) registryEmbedderFunc(name string) ai.EmbedderFunc {
+	embed := a.embedderFunc(name)
+	return func(ctx context.Context, req *ai.EmbedRequest) (*ai.EmbedResponse, error) {
+		a.registry.mu.RLock()
+		active := a.registry.embedders[name]
+		a.registry.mu.RUnlock()
+		if !active {
+			return nil, fmt.Errorf("embedder %q was removed from the model registry", name)
+		}
+		return embed(ctx, req)
+	}
+}
+
+// failoverFunc returns a model function that tries the group members in order
+func (a *AzureAIFoundry) failoverFunc(group FailoverGroup, member func(name string) ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		names := group.Models
+		if group.ProbeStandby {
+			a.registry.mu.RLock()
+			config := a.registry.config
+			a.registry.mu.RUnlock()
+			names = a.standbyOrder(config, names)
+		}
+		return a.tryModels(ctx, group.Name, names, member, input, cb)
+	}
+}
+
+// trackStream wraps a stream callback to record whether it was sent a chunk. A nil callback
+// stays nil.
+func trackStream(cb ai.ModelStreamCallback) (ai.ModelStreamCallback, *atomic.Bool) {
+	streamed := &atomic.Bool{}
+	if cb == nil {
+		return nil, streamed
+	}
+	return func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+		streamed.Store(true)
+		return cb(ctx, chunk)
+	}, streamed
+}
+
+// tryModels sends a request to the named models in turn until one succeeds or fails with an
+// error another deployment would not fix. Only the first is tried when failover is disabled
+// in the runtime settings, and none after one that already streamed chunks.
+func (a *AzureAIFoundry) tryModels(ctx context.Context, group string, names []string, member func(name string) ai.ModelFunc, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+	var err error
+	for i, name := range names {
+		fn := member(name)
+		if fn == nil {
+			err = fmt.Errorf("model %q was removed from the model registry", name)
+			continue
+		}
+		memberCtx := ctx
+		if i < len(names)-1 && !a.settings().DisableFailover {
+			// The fallback is for when no deployment of the group can be reached
+			memberCtx = withoutFallback(ctx)
+		}
+		memberCb, streamed := trackStream(cb)
+		var resp *ai.ModelResponse
+		if resp, err = fn(memberCtx, input, memberCb); err == nil {
+			return resp, nil
+		}
+		// Once chunks reached the caller, the next deployment would stream a second answer
+		if streamed.Load() || !isFailoverError(ctx, err) || i == len(names)-1 || a.settings().DisableFailover {
+			break
+		}
+		logger.FromContext(ctx).Warn("azureaifoundry: failing over to the next deployment",
+			"group", group, "model", name, "next", names[i+1], "err", a.redactError(err))
+	}
+	return nil, err
+}
+
+// isFailoverError reports whether another deployment might succeed where this one failed
+func isFailoverError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var firstTokenErr *FirstTokenTimeoutError
+	if errors.As(err, &firstTokenErr) {
+		return true
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// withDefaultConfig returns a copy of the request with default config options added where
+// the request does not set them
+func withDefaultConfig(input *ai.ModelRequest, defaults map[string]any) *ai.ModelRequest {
+	config := maps.Clone(defaults)
+	switch c := input.Config.(type) {
+	case nil:
+	case map[string]any:
+		maps.Copy(config, c)
+	default:
+		var requested map[string]any
+		if data, err := json.Marshal(c); err == nil && json.Unmarshal(data, &requested) == nil {
+			maps.Copy(config, requested)
+		}
+	}
+	req := *input
+	req.Config = config
+	return &req
+}
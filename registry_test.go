@@ -0,0 +1,218 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+const registryYAML = `
+models:
+  - name: gpt-4o-eastus
+    type: chat
+    modelVersion: gpt-4o-2024-11-20
+    supports: {multiturn: true, tools: true, systemRole: true, media: true}
+    config:
+      temperature: 0.2
+      maxOutputTokens: 500
+    rateLimit: {requestsPerMinute: 600, tokensPerMinute: 100000}
+  - name: gpt-4o-westeurope
+    type: chat
+embedders: [text-embedding-3-small]
+failoverGroups:
+  - name: gpt-4o
+    models: [gpt-4o-eastus, gpt-4o-westeurope]
+`
+
+func TestLoadModelsFromConfig(t *testing.T) {
+	config, err := LoadModelsFromConfig([]byte(registryYAML))
+	if err != nil {
+		t.Fatalf("LoadModelsFromConfig() error = %v", err)
+	}
+	if len(config.Models) != 2 || len(config.Embedders) != 1 || len(config.FailoverGroups) != 1 {
+		t.Fatalf("config = %+v", config)
+	}
+	first := config.Models[0]
+	if !first.Supports.Tools || first.Config["temperature"] != 0.2 || first.RateLimit.TokensPerMinute != 100000 {
+		t.Fatalf("first model = %+v", first)
+	}
+
+	// JSON is accepted too
+	data, _ := json.Marshal(config)
+	path := filepath.Join(t.TempDir(), "models.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := LoadModelsFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadModelsFromFile() error = %v", err)
+	}
+	if fromFile.FailoverGroups[0].Models[1] != "gpt-4o-westeurope" {
+		t.Fatalf("config from file = %+v", fromFile)
+	}
+}
+
+func TestLoadModelsFromConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"unknown field", "models:\n  - name: a\n    temprature: 1\n", `unknown field "temprature"`},
+		{"missing name", "models:\n  - type: chat\n", "model 1 has no name"},
+		{"duplicate model", "models:\n  - name: a\n  - name: a\n", `model "a" is declared twice`},
+		{"undeclared member", "models:\n  - name: a\nfailoverGroups:\n  - name: g\n    models: [a, b]\n", `references undeclared model "b"`},
+		{"group shadows model", "models:\n  - name: a\nfailoverGroups:\n  - name: a\n    models: [a]\n", "has the name of a model"},
+		{"empty group", "models:\n  - name: a\nfailoverGroups:\n  - name: g\n", `failover group "g" has no models`},
+		{"unknown strategy", "models:\n  - name: a\nloadBalancedGroups:\n  - {name: g, strategy: random, models: [{name: a}]}\n", `unknown strategy "random"`},
+		{"undeclared balanced member", "models:\n  - name: a\nloadBalancedGroups:\n  - {name: g, models: [{name: b}]}\n", `load-balanced group "g" references undeclared model "b"`},
+		{"negative weight", "models:\n  - name: a\nloadBalancedGroups:\n  - {name: g, models: [{name: a, weight: -1}]}\n", "negative weight"},
+		{"router without routes", "models:\n  - name: a\nrouters:\n  - name: r\n", `router "r" has no routes`},
+		{"unlimited route first", "models:\n  - name: a\n  - name: b\nrouters:\n  - {name: r, routes: [{model: a}, {model: b, maxPromptTokens: 10}]}\n", "only the last route may omit it"},
+		{"decreasing routes", "models:\n  - name: a\n  - name: b\nrouters:\n  - {name: r, routes: [{model: a, maxPromptTokens: 10}, {model: b, maxPromptTokens: 5}]}\n", "increasing maxPromptTokens"},
+		{"groups share a name", "models:\n  - name: a\nloadBalancedGroups:\n  - {name: g, models: [{name: a}]}\nfailoverGroups:\n  - {name: g, models: [a]}\n", `failover group "g" has the name of a model or group`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadModelsFromConfig([]byte(tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRegistersModelsAtInit(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		if body["model"] == "gpt-4o-eastus" {
+			w.Header().Set("x-should-retry", "false")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error":{"code":"429","message":"Rate limit exceeded"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	defer server.Close()
+
+	registry, err := LoadModelsFromConfig([]byte(registryYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Registry: registry}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+
+	for _, name := range []string{"gpt-4o-eastus", "gpt-4o-westeurope", "gpt-4o"} {
+		if !IsDefinedModel(g, name) {
+			t.Fatalf("model %q is not registered", name)
+		}
+	}
+	if !IsDefinedEmbedder(g, "text-embedding-3-small") {
+		t.Fatal("embedder is not registered")
+	}
+
+	resp, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi"),
+		ai.WithConfig(map[string]any{"maxOutputTokens": 100}))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Text() != "ok" {
+		t.Fatalf("Text() = %q", resp.Text())
+	}
+	if len(bodies) != 2 || bodies[0]["model"] != "gpt-4o-eastus" || bodies[1]["model"] != "gpt-4o-westeurope" {
+		t.Fatalf("requests = %v, want failover from eastus to westeurope", bodies)
+	}
+	// Defaults fill in options the request does not set
+	if bodies[0]["temperature"] != 0.2 || bodies[0]["max_tokens"] != float64(100) {
+		t.Fatalf("eastus request = %v", bodies[0])
+	}
+}
+
+// streamingMembers returns group members that stream an answer. Members listed in broken
+// stream that many chunks of a partial answer and then fail with a network error.
+func streamingMembers(calls *[]string, broken map[string]int) func(name string) ai.ModelFunc {
+	return func(name string) ai.ModelFunc {
+		return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			*calls = append(*calls, name)
+			chunks, failing := broken[name]
+			if !failing {
+				chunks = 1
+			}
+			for range chunks {
+				if err := cb(ctx, &ai.ModelResponseChunk{Content: []*ai.Part{ai.NewTextPart(name + " ")}}); err != nil {
+					return nil, err
+				}
+			}
+			if failing {
+				return nil, &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+			}
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(name)}, nil
+		}
+	}
+}
+
+func TestTryModelsStreaming(t *testing.T) {
+	tests := []struct {
+		name      string
+		broken    map[string]int
+		wantCalls []string
+		wantText  string // Streamed text
+		wantErr   bool
+	}{
+		{"fails over before the first chunk", map[string]int{"eastus": 0}, []string{"eastus", "westeurope"}, "westeurope ", false},
+		{"no failover after a chunk", map[string]int{"eastus": 1}, []string{"eastus"}, "eastus ", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &AzureAIFoundry{}
+			var calls []string
+			var streamed strings.Builder
+			cb := func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+				streamed.WriteString(chunk.Text())
+				return nil
+			}
+			request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}
+			_, err := plugin.tryModels(t.Context(), "gpt-4o", []string{"eastus", "westeurope"}, streamingMembers(&calls, tt.broken), request, cb)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tryModels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") || streamed.String() != tt.wantText {
+				t.Fatalf("calls = %v, streamed = %q", calls, streamed.String())
+			}
+		})
+	}
+}
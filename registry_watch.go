@@ -0,0 +1,129 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// defaultPollInterval is how often watched sources are reloaded by default
+const defaultPollInterval = 30 * time.Second
+
+// RegistrySource loads the current YAML or JSON model registry, e.g. from a file or an Azure
+// App Configuration key.
+type RegistrySource func(ctx context.Context) ([]byte, error)
+
+// RegistryFile returns a source that reads a model registry file.
+func RegistryFile(path string) RegistrySource {
+	return func(ctx context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+// ReloadRegistry makes config the plugin's model registry at runtime. New models, failover
+// groups and embedders are registered with g; changed ones take effect on their next request;
+// removed ones fail with an error, as Genkit cannot unregister actions. The capabilities a
+// model was first registered with cannot change: a config that changes them is rejected as a
+// whole. An EventRegistryReloaded event reports what changed.
+func (a *AzureAIFoundry) ReloadRegistry(ctx context.Context, g *genkit.Genkit, config *RegistryConfig) (*RegistryChange, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	if !a.initted {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("azureaifoundry: client not initialized")
+	}
+	actions, change, err := a.applyRegistry(config)
+	a.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, action := range actions {
+		genkit.RegisterAction(g, action)
+	}
+	if !change.IsZero() {
+		a.emit(ctx, Event{Type: EventRegistryReloaded, Registry: change})
+	}
+	return change, nil
+}
+
+// WatchRegistry polls source every interval (30 seconds by default) and reloads the model
+// registry when its content changes. Sources that fail to load or parse are logged and the
+// current registry is kept. It blocks until ctx is done or Close is called.
+func (a *AzureAIFoundry) WatchRegistry(ctx context.Context, g *genkit.Genkit, source RegistrySource, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	var last []byte
+	return a.poll(ctx, interval, func() {
+		data, err := source(ctx)
+		switch {
+		case err != nil:
+			logger.FromContext(ctx).Error("azureaifoundry: failed to load model registry", "err", err)
+		case !bytes.Equal(data, last):
+			last = data
+			if err := a.reloadRegistryData(ctx, g, data); err != nil {
+				logger.FromContext(ctx).Error("azureaifoundry: keeping current model registry", "err", err)
+			}
+		}
+	})
+}
+
+// poll calls fn immediately and then every interval until ctx is done or the plugin is
+// closed, which returns ErrClosed
+func (a *AzureAIFoundry) poll(ctx context.Context, interval time.Duration, fn func()) error {
+	a.lifecycle.init()
+	for {
+		fn()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-a.lifecycle.stop.Done():
+			return ErrClosed
+		case <-time.After(interval):
+		}
+	}
+}
+
+// reloadRegistryData parses a model registry and reloads it
+func (a *AzureAIFoundry) reloadRegistryData(ctx context.Context, g *genkit.Genkit, data []byte) error {
+	config, err := LoadModelsFromConfig(data)
+	if err != nil {
+		return err
+	}
+	change, err := a.ReloadRegistry(ctx, g, config)
+	if err != nil {
+		return err
+	}
+	if !change.IsZero() {
+		logger.FromContext(ctx).Info("azureaifoundry: reloaded model registry",
+			"added", change.Added, "updated", change.Updated, "removed", change.Removed)
+	}
+	return nil
+}
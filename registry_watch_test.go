@@ -0,0 +1,209 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// newRegistryPlugin returns a plugin initialized through Genkit with a model registry
+func newRegistryPlugin(t *testing.T, bodies *[]map[string]any, config string) (*AzureAIFoundry, *genkit.Genkit) {
+	t.Helper()
+	registry, err := LoadModelsFromConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(captureRequests(bodies, chatCompletionJSON))
+	t.Cleanup(server.Close)
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Registry: registry}
+	return plugin, genkit.Init(context.Background(), genkit.WithPlugins(plugin))
+}
+
+func TestReloadRegistry(t *testing.T) {
+	var bodies []map[string]any
+	plugin, g := newRegistryPlugin(t, &bodies, `
+models:
+  - {name: gpt-4o, config: {temperature: 0.2}}
+  - {name: gpt-4o-mini}
+embedders: [text-embedding-3-small]
+`)
+	var events []Event
+	plugin.Subscribe(EventSubscriberFunc(func(ctx context.Context, event Event) {
+		if event.Type == EventRegistryReloaded {
+			events = append(events, event)
+		}
+	}))
+
+	reloaded, err := LoadModelsFromConfig([]byte(`
+models:
+  - {name: gpt-4o, config: {temperature: 0.7}}
+  - {name: gpt-4.1}
+failoverGroups:
+  - {name: chat, models: [gpt-4o, gpt-4.1]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	change, err := plugin.ReloadRegistry(ctx, g, reloaded)
+	if err != nil {
+		t.Fatalf("ReloadRegistry() error = %v", err)
+	}
+	want := RegistryChange{Added: []string{"chat", "gpt-4.1"}, Updated: []string{"gpt-4o"}, Removed: []string{"gpt-4o-mini", "text-embedding-3-small"}}
+	if strings.Join(change.Added, ",") != strings.Join(want.Added, ",") ||
+		strings.Join(change.Updated, ",") != strings.Join(want.Updated, ",") ||
+		strings.Join(change.Removed, ",") != strings.Join(want.Removed, ",") {
+		t.Fatalf("change = %+v, want %+v", change, want)
+	}
+	if len(events) != 1 || events[0].Registry != change {
+		t.Fatalf("events = %+v", events)
+	}
+
+	// Updated definitions apply to the next request
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if bodies[0]["temperature"] != 0.7 {
+		t.Fatalf("request = %v, want the reloaded default config", bodies[0])
+	}
+	// Added models and groups are registered
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "chat")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() with the new group error = %v", err)
+	}
+	// Removed models stay registered but fail
+	_, err = genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o-mini")), ai.WithPrompt("hi"))
+	if err == nil || !strings.Contains(err.Error(), `model "gpt-4o-mini" was removed`) {
+		t.Fatalf("Generate() with a removed model error = %v", err)
+	}
+	if _, err := genkit.Embed(ctx, g, ai.WithEmbedder(Embedder(g, "text-embedding-3-small")), ai.WithTextDocs("hi")); err == nil {
+		t.Fatal("Embed() with a removed embedder succeeded")
+	}
+
+	// Reloading the same config changes nothing
+	events = nil
+	if change, err := plugin.ReloadRegistry(ctx, g, reloaded); err != nil || !change.IsZero() || len(events) != 0 {
+		t.Fatalf("repeated reload = %+v, %v; %d events", change, err, len(events))
+	}
+
+	// Models can come back after removal
+	restored, _ := LoadModelsFromConfig([]byte("models:\n  - {name: gpt-4o-mini}\n"))
+	if change, err := plugin.ReloadRegistry(ctx, g, restored); err != nil || strings.Join(change.Added, ",") != "gpt-4o-mini" {
+		t.Fatalf("restoring reload = %+v, %v", change, err)
+	}
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o-mini")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() with a restored model error = %v", err)
+	}
+}
+
+func TestReloadRegistryCapabilities(t *testing.T) {
+	var bodies []map[string]any
+	plugin, g := newRegistryPlugin(t, &bodies, `
+models:
+  - {name: gpt-4o, supports: {multiturn: true, tools: true}}
+failoverGroups:
+  - {name: chat, models: [gpt-4o]}
+`)
+	ctx := context.Background()
+
+	// Changing what a registered model accepts is rejected
+	changed, _ := LoadModelsFromConfig([]byte(`
+models:
+  - {name: gpt-4o, supports: {multiturn: true}, config: {temperature: 0.7}}
+failoverGroups:
+  - {name: chat, models: [gpt-4o]}
+`))
+	_, err := plugin.ReloadRegistry(ctx, g, changed)
+	if err == nil || !strings.Contains(err.Error(), "capabilities of gpt-4o,") {
+		t.Fatalf("ReloadRegistry() error = %v, want the changed names", err)
+	}
+	// Nothing was applied
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, ok := bodies[0]["temperature"]; ok {
+		t.Fatalf("request = %v, want the original config", bodies[0])
+	}
+
+	// Other changes still apply
+	updated, _ := LoadModelsFromConfig([]byte(`
+models:
+  - {name: gpt-4o, supports: {multiturn: true, tools: true}, config: {temperature: 0.7}}
+failoverGroups:
+  - {name: chat, models: [gpt-4o]}
+`))
+	if change, err := plugin.ReloadRegistry(ctx, g, updated); err != nil || strings.Join(change.Updated, ",") != "gpt-4o" {
+		t.Fatalf("ReloadRegistry() = %+v, %v", change, err)
+	}
+}
+
+func TestWatchRegistry(t *testing.T) {
+	var bodies []map[string]any
+	plugin, g := newRegistryPlugin(t, &bodies, "models:\n  - {name: gpt-4o}\n")
+	reloads := make(chan *RegistryChange, 4)
+	plugin.Subscribe(EventSubscriberFunc(func(ctx context.Context, event Event) {
+		if event.Type == EventRegistryReloaded {
+			reloads <- event.Registry
+		}
+	}))
+
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	write := func(config string) {
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("models:\n  - {name: gpt-4o}\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- plugin.WatchRegistry(ctx, g, RegistryFile(path), 5*time.Millisecond) }()
+
+	// An invalid file is ignored and a later valid one applied
+	write("models: [{name: gpt-4o, bogus: 1}]\n")
+	time.Sleep(20 * time.Millisecond)
+	write("models:\n  - {name: gpt-4o}\n  - {name: gpt-4.1}\n")
+	select {
+	case change := <-reloads:
+		if strings.Join(change.Added, ",") != "gpt-4.1" {
+			t.Fatalf("change = %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("registry was not reloaded")
+	}
+	if !IsDefinedModel(g, "gpt-4.1") {
+		t.Fatal("reloaded model is not registered")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("WatchRegistry() error = %v", err)
+	}
+	if len(reloads) != 0 {
+		t.Fatalf("unexpected reloads: %d", len(reloads))
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/tracing"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// RecordedGeneration is a model call made through this plugin, as captured in a trace.
+type RecordedGeneration struct {
+	TraceID  string
+	SpanID   string
+	Model    string            // Registered model name, e.g. "azureaifoundry/gpt-4o"
+	Request  *ai.ModelRequest  // Request as received by the model
+	Response *ai.ModelResponse // Recorded response, nil if the call failed
+}
+
+// RecordedGenerations extracts the model calls made through this plugin from a Genkit trace
+// export, as written by the Dev UI trace store. data holds one trace or a JSON array of
+// traces. Calls are returned in start order. providers lists the plugin instances to include
+// by ProviderID, defaulting to "azureaifoundry".
+func RecordedGenerations(data []byte, providers ...string) ([]*RecordedGeneration, error) {
+	if len(providers) == 0 {
+		providers = []string{provider}
+	}
+	var traces []*tracing.Data
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &traces); err != nil {
+			return nil, fmt.Errorf("failed to parse traces: %w", err)
+		}
+	} else {
+		var trace tracing.Data
+		if err := json.Unmarshal(trimmed, &trace); err != nil {
+			return nil, fmt.Errorf("failed to parse trace: %w", err)
+		}
+		traces = []*tracing.Data{&trace}
+	}
+
+	var spans []*tracing.SpanData
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			if span.TraceID == "" {
+				span.TraceID = trace.TraceID
+			}
+			spans = append(spans, span)
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartTime < spans[j].StartTime
+	})
+
+	var recorded []*RecordedGeneration
+	for _, span := range spans {
+		name, _ := span.Attributes["genkit:name"].(string)
+		if span.Attributes["genkit:metadata:subtype"] != "model" || !slices.Contains(providers, strings.SplitN(name, "/", 2)[0]) {
+			continue
+		}
+		input, _ := span.Attributes["genkit:input"].(string)
+		var req ai.ModelRequest
+		if err := json.Unmarshal([]byte(input), &req); err != nil {
+			return nil, fmt.Errorf("failed to parse request of span %s: %w", span.SpanID, err)
+		}
+
+		generation := &RecordedGeneration{TraceID: span.TraceID, SpanID: span.SpanID, Model: name, Request: &req}
+		if output, ok := span.Attributes["genkit:output"].(string); ok && output != "" {
+			var resp ai.ModelResponse
+			if err := json.Unmarshal([]byte(output), &resp); err != nil {
+				return nil, fmt.Errorf("failed to parse response of span %s: %w", span.SpanID, err)
+			}
+			generation.Response = &resp
+		}
+		recorded = append(recorded, generation)
+	}
+	return recorded, nil
+}
+
+// ReplayOptions configures ReplayGeneration.
+type ReplayOptions struct {
+	Model string // Registered model to replay against, e.g. "azureaifoundry/gpt-4o-canary". Defaults to the recorded model
+}
+
+// ReplayResult compares a replayed generation with its recording.
+type ReplayResult struct {
+	Recorded *RecordedGeneration
+	Model    string            // Model the request was replayed against
+	Response *ai.ModelResponse // Replayed response
+
+	TextDiff            string // Line diff of the response text ("-" recorded, "+" replayed), empty when equal
+	FinishReasonChanged bool
+	ToolCallsChanged    bool // Requested tools or their inputs differ
+}
+
+// Changed reports whether the replayed response differs from the recording.
+func (r *ReplayResult) Changed() bool {
+	return r.TextDiff != "" || r.FinishReasonChanged || r.ToolCallsChanged
+}
+
+// ReplayGeneration re-executes a recorded request, optionally against a different model,
+// and diffs the output with the recorded response. The request is sent to the model as
+// recorded: requested tools are not executed. Sampling makes outputs vary between runs,
+// so set a seed and a low temperature when comparing deployments.
+func ReplayGeneration(ctx context.Context, g *genkit.Genkit, recorded *RecordedGeneration, opts ReplayOptions) (*ReplayResult, error) {
+	name := opts.Model
+	if name == "" {
+		name = recorded.Model
+	}
+	model := genkit.LookupModel(g, name)
+	if model == nil {
+		return nil, fmt.Errorf("model %q is not registered", name)
+	}
+
+	resp, err := model.Generate(ctx, recorded.Request, nil)
+	if err != nil {
+		return nil, fmt.Errorf("replay of span %s failed: %w", recorded.SpanID, err)
+	}
+
+	result := &ReplayResult{Recorded: recorded, Model: name, Response: resp}
+	var recordedText, recordedTools string
+	var recordedFinish ai.FinishReason
+	if recorded.Response != nil {
+		recordedText = recorded.Response.Text()
+		recordedTools = toolRequestsKey(recorded.Response.ToolRequests())
+		recordedFinish = recorded.Response.FinishReason
+	}
+	result.TextDiff = lineDiff(recordedText, resp.Text())
+	result.FinishReasonChanged = recordedFinish != resp.FinishReason
+	result.ToolCallsChanged = recordedTools != toolRequestsKey(resp.ToolRequests())
+	return result, nil
+}
+
+// toolRequestsKey renders tool requests for comparison, ignoring call IDs
+func toolRequestsKey(parts []*ai.Part) string {
+	var key strings.Builder
+	for _, part := range parts {
+		key.WriteString(part.ToolRequest.Name)
+		key.WriteString(toJSONString(part.ToolRequest.Input))
+		key.WriteByte('\n')
+	}
+	return key.String()
+}
+
+// lineDiff returns a line-based diff of two texts, or "" when they are equal
+func lineDiff(before, after string) string {
+	if before == after {
+		return ""
+	}
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&diff, "  %s\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&diff, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&diff, "+ %s\n", b[j])
+			j++
+		}
+	}
+	return diff.String()
+}
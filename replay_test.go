@@ -0,0 +1,155 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// traceJSON builds a trace export with a flow span and model spans recording the given responses
+func traceJSON(t *testing.T, responses ...string) []byte {
+	t.Helper()
+	request, _ := json.Marshal(&ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}})
+	spans := map[string]any{
+		"root": map[string]any{
+			"spanId": "root", "startTime": 1,
+			"attributes": map[string]any{"genkit:name": "myFlow", "genkit:metadata:subtype": "flow", "genkit:input": `"hi"`},
+		},
+		"other": map[string]any{
+			"spanId": "other", "startTime": 2,
+			"attributes": map[string]any{"genkit:name": "googleai/gemini-2.5-flash", "genkit:metadata:subtype": "model", "genkit:input": string(request)},
+		},
+	}
+	for i, text := range responses {
+		response, _ := json.Marshal(&ai.ModelResponse{Message: ai.NewModelTextMessage(text), FinishReason: ai.FinishReasonStop})
+		id := string(rune('a' + i))
+		spans[id] = map[string]any{
+			"spanId": id, "startTime": 10 - i,
+			"attributes": map[string]any{
+				"genkit:name":             "azureaifoundry/gpt-4o",
+				"genkit:metadata:subtype": "model",
+				"genkit:input":            string(request),
+				"genkit:output":           string(response),
+			},
+		}
+	}
+	data, err := json.Marshal(map[string]any{"traceId": "trace-1", "spans": spans})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRecordedGenerations(t *testing.T) {
+	recorded, err := RecordedGenerations(traceJSON(t, "first", "second"))
+	if err != nil {
+		t.Fatalf("RecordedGenerations() error = %v", err)
+	}
+	if len(recorded) != 2 {
+		t.Fatalf("got %d generations, want 2", len(recorded))
+	}
+	// Ordered by start time, which is reversed relative to the input
+	if recorded[0].SpanID != "b" || recorded[0].Response.Text() != "second" || recorded[0].TraceID != "trace-1" {
+		t.Fatalf("first generation = %+v", recorded[0])
+	}
+	if recorded[1].Request.Messages[0].Text() != "hi" || recorded[1].Model != "azureaifoundry/gpt-4o" {
+		t.Fatalf("second generation = %+v", recorded[1])
+	}
+
+	array := append(append([]byte("["), traceJSON(t, "first")...), ']')
+	if recorded, err := RecordedGenerations(array); err != nil || len(recorded) != 1 {
+		t.Fatalf("RecordedGenerations(array) = %d generations, error = %v", len(recorded), err)
+	}
+}
+
+func TestReplayGeneration(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON))
+	g := genkit.Init(context.Background())
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o-canary", Type: "chat"}, nil)
+
+	recorded, err := RecordedGenerations(traceJSON(t, "ok", "Sure!\nok"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		recorded    *RecordedGeneration
+		model       string
+		wantModel   string
+		wantDiff    string
+		wantChanged bool
+	}{
+		{"unchanged", recorded[1], "", "gpt-4o", "", false},
+		{"changed on another deployment", recorded[0], "azureaifoundry/gpt-4o-canary", "gpt-4o-canary", "- Sure!\n  ok\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			result, err := ReplayGeneration(context.Background(), g, tt.recorded, ReplayOptions{Model: tt.model})
+			if err != nil {
+				t.Fatalf("ReplayGeneration() error = %v", err)
+			}
+			if bodies[0]["model"] != tt.wantModel {
+				t.Fatalf("replayed against %v, want %s", bodies[0]["model"], tt.wantModel)
+			}
+			if result.TextDiff != tt.wantDiff || result.Changed() != tt.wantChanged {
+				t.Fatalf("TextDiff = %q, Changed() = %v", result.TextDiff, result.Changed())
+			}
+		})
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		before, after, want string
+	}{
+		{"a\nb", "a\nb", ""},
+		{"a\nb\nc", "a\nx\nc", "  a\n- b\n+ x\n  c\n"},
+		{"", "new", "- \n+ new\n"},
+	}
+	for _, tt := range tests {
+		if got := lineDiff(tt.before, tt.after); got != tt.want {
+			t.Errorf("lineDiff(%q, %q) = %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestRecordedGenerationsProviders(t *testing.T) {
+	data := bytes.ReplaceAll(traceJSON(t, "first", "second"), []byte("azureaifoundry/"), []byte("azure-eastus/"))
+
+	if recorded, err := RecordedGenerations(data); err != nil || len(recorded) != 0 {
+		t.Fatalf("RecordedGenerations() = %d generations, error = %v; want none for the default provider", len(recorded), err)
+	}
+	recorded, err := RecordedGenerations(data, "azure-eastus", "azure-westeurope")
+	if err != nil || len(recorded) != 2 {
+		t.Fatalf("RecordedGenerations() = %d generations, error = %v", len(recorded), err)
+	}
+	if recorded[0].Model != "azure-eastus/gpt-4o" {
+		t.Fatalf("Model = %q", recorded[0].Model)
+	}
+}
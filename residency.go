@@ -0,0 +1,97 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ResidencyPolicy maps data classifications (e.g. "pii", "eu-customer") to the Azure regions
+// their data may be sent to.
+type ResidencyPolicy map[string][]string
+
+// DataResidencyError is returned when a request tagged with a data classification would be
+// sent to a region its classification does not allow.
+type DataResidencyError struct {
+	Classification string
+	Region         string
+	Allowed        []string
+}
+
+// Error implements the error interface.
+func (e *DataResidencyError) Error() string {
+	region := e.Region
+	if region == "" {
+		region = "an unknown region"
+	}
+	return fmt.Sprintf("data classified %q may not be sent to %s (allowed: %s)",
+		e.Classification, region, strings.Join(e.Allowed, ", "))
+}
+
+// classificationKey is the context key for data classifications
+type classificationKey struct{}
+
+// WithDataClassification tags the requests made with the returned context with a data
+// classification, which the plugin's ResidencyPolicy is enforced against.
+func WithDataClassification(ctx context.Context, classification string) context.Context {
+	return context.WithValue(ctx, classificationKey{}, classification)
+}
+
+// DataClassificationFromContext returns the data classification of ctx, if any.
+func DataClassificationFromContext(ctx context.Context) string {
+	classification, _ := ctx.Value(classificationKey{}).(string)
+	return classification
+}
+
+// Allows reports whether data with the classification may be sent to region. Unclassified
+// data is always allowed; classifications missing from the policy are never allowed.
+func (p ResidencyPolicy) Allows(classification, region string) bool {
+	if classification == "" {
+		return true
+	}
+	for _, allowed := range p[classification] {
+		if region != "" && normalizeRegion(allowed) == normalizeRegion(region) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkResidency refuses requests whose context classification is not allowed in the
+// plugin's region
+func (a *AzureAIFoundry) checkResidency(ctx context.Context) error {
+	if a.ResidencyPolicy == nil {
+		return nil
+	}
+	classification := DataClassificationFromContext(ctx)
+	if a.ResidencyPolicy.Allows(classification, a.Region) {
+		return nil
+	}
+	return &DataResidencyError{
+		Classification: classification,
+		Region:         a.Region,
+		Allowed:        a.ResidencyPolicy[classification],
+	}
+}
+
+// normalizeRegion makes "West Europe" and "westeurope" compare equal
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestResidencyPolicy(t *testing.T) {
+	policy := ResidencyPolicy{"pii": {"West Europe", "swedencentral"}}
+
+	tests := []struct {
+		name           string
+		classification string
+		region         string
+		want           bool
+	}{
+		{name: "unclassified", region: "eastus", want: true},
+		{name: "allowed region", classification: "pii", region: "westeurope", want: true},
+		{name: "other region", classification: "pii", region: "eastus"},
+		{name: "unknown region", classification: "pii"},
+		{name: "undeclared classification", classification: "phi", region: "westeurope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allows(tt.classification, tt.region); got != tt.want {
+				t.Fatalf("Allows(%q, %q) = %v, want %v", tt.classification, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResidencyEnforcedBeforeSending(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.Region = "eastus"
+		a.ResidencyPolicy = ResidencyPolicy{"pii": {"westeurope"}}
+	})
+	request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("hi")}}
+
+	ctx := WithDataClassification(context.Background(), "pii")
+	_, err := plugin.generateText(ctx, "gpt-4o", request, nil)
+	var residencyErr *DataResidencyError
+	if !errors.As(err, &residencyErr) || residencyErr.Region != "eastus" {
+		t.Fatalf("generateText() error = %v, want *DataResidencyError", err)
+	}
+	if _, err := plugin.embed(ctx, "text-embedding-3-small", &ai.EmbedRequest{Input: []*ai.Document{ai.DocumentFromText("x", nil)}}); !errors.As(err, &residencyErr) {
+		t.Fatalf("embed() error = %v, want *DataResidencyError", err)
+	}
+	if len(bodies) != 0 {
+		t.Fatalf("%d requests were sent despite the residency policy", len(bodies))
+	}
+
+	if _, err := plugin.generateText(context.Background(), "gpt-4o", request, nil); err != nil || len(bodies) != 1 {
+		t.Fatalf("unclassified request: err = %v, sent = %d", err, len(bodies))
+	}
+}
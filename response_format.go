@@ -0,0 +1,196 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+// Values of the "responseFormat" chat config option
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// jsonSchemaName names the schema sent with structured output requests
+const jsonSchemaName = "response"
+
+// jsonModeInstruction is prepended as a system message in JSON mode when no message mentions
+// JSON, which the API requires
+const jsonModeInstruction = "Respond with a valid JSON object."
+
+// applyResponseFormat sets the response format of a chat request from the "responseFormat"
+// config option. Structured outputs use the "jsonSchema" option, or the request output schema
+// when it is not set, and fall back to JSON mode when neither is available.
+func applyResponseFormat(params *openai.ChatCompletionNewParams, format string, schema map[string]any, input *ai.ModelRequest) {
+	if format == "" && schema != nil {
+		format = ResponseFormatJSONSchema
+	}
+	if format == "" {
+		format = outputResponseFormat(input.Output)
+	}
+	if format == ResponseFormatJSONSchema {
+		if schema == nil && input.Output != nil && input.Output.Schema != nil {
+			schema = strictSchema(input.Output.Schema)
+		}
+		if schema != nil {
+			params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+				OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+					JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+						Name:   jsonSchemaName,
+						Strict: openai.Bool(true),
+						Schema: schema,
+					},
+				},
+			}
+			return
+		}
+		format = ResponseFormatJSONObject
+	}
+
+	switch format {
+	case ResponseFormatText:
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfText: &shared.ResponseFormatTextParam{}}
+	case ResponseFormatJSONObject:
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &shared.ResponseFormatJSONObjectParam{}}
+		if !mentionsJSON(input.Messages) {
+			params.Messages = append([]openai.ChatCompletionMessageParamUnion{openai.SystemMessage(jsonModeInstruction)}, params.Messages...)
+		}
+	}
+}
+
+// outputResponseFormat maps the Genkit output config of a request, as set by ai.WithOutputType,
+// to a response format. Constrained JSON output with a schema uses structured outputs, other
+// JSON output uses JSON mode.
+func outputResponseFormat(output *ai.ModelOutputConfig) string {
+	if output == nil || (output.Format != "json" && output.ContentType != "application/json") {
+		return ""
+	}
+	if output.Constrained && output.Schema != nil {
+		return ResponseFormatJSONSchema
+	}
+	return ResponseFormatJSONObject
+}
+
+// structuredOutputFamilies lists the model families that support strict structured outputs
+var structuredOutputFamilies = []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"}
+
+// supportsStructuredOutputs reports whether a model supports strict structured outputs, which
+// lets Genkit constrain its output natively
+func supportsStructuredOutputs(modelName string) bool {
+	modelLower := strings.ToLower(modelName)
+	for _, family := range structuredOutputFamilies {
+		if strings.HasPrefix(modelLower, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsJSON reports whether any text part of the messages contains the word "json"
+func mentionsJSON(messages []*ai.Message) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Content {
+			if part.IsText() && strings.Contains(strings.ToLower(part.Text), "json") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configSchema returns the strict JSON schema of the "jsonSchema" config option, which is
+// either a schema map or a Go value whose type is reflected
+func configSchema(val any) map[string]any {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		return strictSchema(v)
+	}
+	if !isStructValue(val) {
+		return nil
+	}
+	return strictSchema(core.InferSchemaMap(val))
+}
+
+// isStructValue reports whether a value is a struct or a pointer to one
+func isStructValue(val any) bool {
+	t := reflect.TypeOf(val)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+// strictSchema copies a JSON schema and adapts it to strict structured outputs, which require
+// every object to list all of its properties as required and to disallow additional ones
+func strictSchema(schema map[string]any) map[string]any {
+	strict := make(map[string]any, len(schema))
+	for key, val := range schema {
+		switch key {
+		case "$schema", "$id":
+			continue
+		case "properties", "$defs", "definitions":
+			if props, ok := val.(map[string]any); ok {
+				copied := make(map[string]any, len(props))
+				for name, prop := range props {
+					copied[name] = strictValue(prop)
+				}
+				val = copied
+			}
+		default:
+			val = strictValue(val)
+		}
+		strict[key] = val
+	}
+
+	if props, ok := strict["properties"].(map[string]any); ok {
+		required := make([]string, 0, len(props))
+		for name := range props {
+			required = append(required, name)
+		}
+		sort.Strings(required)
+		strict["required"] = required
+		strict["additionalProperties"] = false
+	}
+	return strict
+}
+
+// strictValue applies strictSchema to nested schemas such as items and anyOf entries
+func strictValue(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		return strictSchema(v)
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = strictValue(item)
+		}
+		return copied
+	}
+	return val
+}
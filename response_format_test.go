@@ -0,0 +1,200 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestBuildChatCompletionParamsResponseFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		prompt       string
+		config       map[string]any
+		wantType     string
+		wantMessages int
+	}{
+		{"json mode adds an instruction", "List three colors", map[string]any{"responseFormat": "json_object"}, "json_object", 2},
+		{"json mode with a JSON prompt", "List three colors as JSON", map[string]any{"responseFormat": "json_object"}, "json_object", 1},
+		{"snake case alias", "Reply in json", map[string]any{"response_format": "json_object"}, "json_object", 1},
+		{"text", "Hi", map[string]any{"responseFormat": "text"}, "text", 1},
+		{"default", "Hi", nil, "", 1},
+		{"json schema", "Hi", map[string]any{"responseFormat": "json_schema", "jsonSchema": map[string]any{"type": "object"}}, "json_schema", 1},
+		{"schema implies json schema", "Hi", map[string]any{"jsonSchema": colorList{}}, "json_schema", 1},
+		{"json schema without a schema", "Hi", map[string]any{"responseFormat": "json_schema"}, "json_object", 2},
+		{"citations take precedence", "Hi", map[string]any{"responseFormat": "json_object", "citations": true}, "json_schema", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &AzureAIFoundry{}
+			params := plugin.buildChatCompletionParams(&ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage(tt.prompt)},
+				Config:   tt.config,
+			}, "gpt-4o")
+
+			body, err := json.Marshal(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Messages []struct {
+					Role    string `json:"role"`
+					Content any    `json:"content"`
+				} `json:"messages"`
+				ResponseFormat struct {
+					Type string `json:"type"`
+				} `json:"response_format"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.ResponseFormat.Type != tt.wantType {
+				t.Fatalf("response_format type = %q, want %q", got.ResponseFormat.Type, tt.wantType)
+			}
+			if len(got.Messages) != tt.wantMessages {
+				t.Fatalf("messages = %+v, want %d", got.Messages, tt.wantMessages)
+			}
+		})
+	}
+}
+
+type colorList struct {
+	Colors []struct {
+		Name string `json:"name"`
+		Hex  string `json:"hex,omitempty"`
+	} `json:"colors"`
+}
+
+func TestBuildChatCompletionParamsJSONSchema(t *testing.T) {
+	outputSchema := map[string]any{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": map[string]any{"answer": map[string]any{"type": "string"}},
+	}
+	tests := []struct {
+		name   string
+		input  *ai.ModelRequest
+		schema string
+	}{
+		{
+			name: "go struct",
+			input: &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("Hi")},
+				Config:   map[string]any{"jsonSchema": &colorList{}},
+			},
+			schema: `{"additionalProperties":false,"properties":{"colors":{"items":{"additionalProperties":false,"properties":{"hex":{"type":"string"},"name":{"type":"string"}},"required":["hex","name"],"type":"object"},"type":"array"}},"required":["colors"],"type":"object"}`,
+		},
+		{
+			name: "request output schema",
+			input: &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("Hi")},
+				Config:   map[string]any{"response_format": "json_schema"},
+				Output:   &ai.ModelOutputConfig{Format: "json", Schema: outputSchema},
+			},
+			schema: `{"additionalProperties":false,"properties":{"answer":{"type":"string"}},"required":["answer"],"type":"object"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &AzureAIFoundry{}
+			params := plugin.buildChatCompletionParams(tt.input, "gpt-4o")
+			format := params.ResponseFormat.OfJSONSchema
+			if format == nil {
+				t.Fatalf("response_format = %+v, want json_schema", params.ResponseFormat)
+			}
+			if !format.JSONSchema.Strict.Value || format.JSONSchema.Name != "response" {
+				t.Fatalf("json_schema = %+v", format.JSONSchema)
+			}
+			schema, err := json.Marshal(format.JSONSchema.Schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(schema) != tt.schema {
+				t.Fatalf("schema = %s, want %s", schema, tt.schema)
+			}
+		})
+	}
+	// The caller's schema is left untouched
+	if _, ok := outputSchema["$schema"]; !ok {
+		t.Fatal("strictSchema() modified the request schema")
+	}
+}
+
+func TestGenerateWithOutputType(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(captureRequests(&bodies, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4o",`+
+		`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"answer\":\"42\"}"}}]}`))
+	defer server.Close()
+
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	gpt4o := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+	llama := plugin.DefineModel(g, ModelDefinition{Name: "Llama-3.3-70B-Instruct", Type: "chat"}, nil)
+
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+	tests := []struct {
+		name     string
+		model    ai.Model
+		wantType string
+	}{
+		{"constrained model", gpt4o, "json_schema"},
+		{"unconstrained model", llama, "json_object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(tt.model), ai.WithPrompt("What is the answer?"),
+				ai.WithOutputType(answer{}))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			format, _ := bodies[0]["response_format"].(map[string]any)
+			if format["type"] != tt.wantType {
+				t.Fatalf("response_format = %v, want type %q", bodies[0]["response_format"], tt.wantType)
+			}
+			var got answer
+			if err := resp.Output(&got); err != nil || got.Answer != "42" {
+				t.Fatalf("Output() = %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestValidateConfigResponseFormat(t *testing.T) {
+	if err := validateConfig("gpt-4o", OperationChat, map[string]any{"response_format": "json_object"}); err != nil {
+		t.Fatalf("validateConfig() error = %v", err)
+	}
+	if err := validateConfig("gpt-4o", OperationChat, map[string]any{"jsonSchema": colorList{}}); err != nil {
+		t.Fatalf("validateConfig() error = %v", err)
+	}
+	if err := validateConfig("gpt-4o", OperationChat, map[string]any{"jsonSchema": "object"}); err == nil {
+		t.Fatal("validateConfig() accepted a string schema")
+	}
+	if err := validateConfig("gpt-4o", OperationChat, map[string]any{"responseFormat": "yaml"}); err == nil {
+		t.Fatal("validateConfig() accepted an unknown response format")
+	}
+}
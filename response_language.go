@@ -0,0 +1,211 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+// LanguageAction selects how an answer in the wrong language is corrected.
+type LanguageAction string
+
+const (
+	// LanguageReprompt asks the model again to answer in the required language.
+	LanguageReprompt LanguageAction = "reprompt"
+	// LanguageTranslate translates the answer into the required language.
+	LanguageTranslate LanguageAction = "translate"
+	// LanguageAnnotate only records the mismatch in the response metadata.
+	LanguageAnnotate LanguageAction = "annotate"
+)
+
+// defaultLanguageMinLength is the shortest answer, in characters, whose language is checked
+const defaultLanguageMinLength = 20
+
+// LanguageEnforcement configures how chat answers are kept in the language required by the
+// "responseLanguage" config option, an ISO 639-1 code such as "es". Answers are checked with
+// LanguageDetector, or DetectLanguage. Streamed answers cannot be taken back, so they are only
+// annotated.
+type LanguageEnforcement struct {
+	Action     LanguageAction // How answers in another language are corrected. Defaults to LanguageReprompt
+	Attempts   int            // Times LanguageReprompt asks again before returning the last answer. Defaults to 1
+	Translator ai.Model       // Model answers are translated with by LanguageTranslate. Defaults to the answering deployment
+	MinLength  int            // Answers shorter than this many characters are not checked, as detection is unreliable on them. Defaults to 20
+}
+
+// LanguageCheck is recorded in the "responseLanguage" custom value of chat responses to
+// requests with a required language.
+type LanguageCheck struct {
+	Required string         `json:"required"`
+	Detected string         `json:"detected,omitempty"` // Language of the first answer, empty when it could not be told
+	Action   LanguageAction `json:"action,omitempty"`   // How the answer was corrected, if it was
+	Matched  bool           `json:"matched"`            // Whether the returned answer is in the required language
+}
+
+// languageNames are the names the model is asked to answer or translate in
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "el": "Greek", "en": "English", "es": "Spanish", "fr": "French",
+	"he": "Hebrew", "hi": "Hindi", "it": "Italian", "ja": "Japanese", "ko": "Korean", "nl": "Dutch",
+	"pt": "Portuguese", "ru": "Russian", "th": "Thai", "zh": "Chinese",
+}
+
+// withResponseLanguage corrects answers that are not in the language required by the
+// "responseLanguage" config option
+func (a *AzureAIFoundry) withResponseLanguage(fn ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		resp, err := fn(ctx, input, cb)
+		config, _ := normalizeConfig(input.Config)
+		required, _ := config["responseLanguage"].(string)
+		if err != nil || required == "" || resp == nil || resp.Message == nil || hasToolRequests(resp.Message) {
+			return resp, err
+		}
+		return a.enforceLanguage(ctx, fn, input, cb != nil, resp, required), nil
+	}
+}
+
+// enforceLanguage checks the language of an answer and corrects it as LanguageEnforcement
+// says. Failed corrections leave the answer as it was
+func (a *AzureAIFoundry) enforceLanguage(ctx context.Context, fn ai.ModelFunc, input *ai.ModelRequest, streamed bool, resp *ai.ModelResponse, required string) *ai.ModelResponse {
+	settings := LanguageEnforcement{}
+	if a.LanguageEnforcement != nil {
+		settings = *a.LanguageEnforcement
+	}
+	if settings.Action == "" {
+		settings.Action = LanguageReprompt
+	}
+	if settings.Attempts <= 0 {
+		settings.Attempts = 1
+	}
+	if settings.MinLength <= 0 {
+		settings.MinLength = defaultLanguageMinLength
+	}
+
+	check := &LanguageCheck{Required: required}
+	var matched bool
+	check.Detected, matched = a.answerInLanguage(resp, required, settings.MinLength)
+	check.Matched = matched
+	if matched || streamed || settings.Action == LanguageAnnotate {
+		resp.Custom = withCustomValue(resp.Custom, "responseLanguage", check)
+		return resp
+	}
+
+	name := languageName(required)
+	corrected := resp
+	var err error
+	switch settings.Action {
+	case LanguageReprompt:
+		for range settings.Attempts {
+			retry := *input
+			retry.Messages = append(slices.Clone(input.Messages), corrected.Message,
+				ai.NewUserTextMessage(fmt.Sprintf("Answer again in %s, the language required for this conversation.", name)))
+			var next *ai.ModelResponse
+			if next, err = fn(ctx, &retry, nil); err != nil {
+				break
+			}
+			next.Usage = sumUsage(corrected.Usage, next.Usage)
+			next.Request = resp.Request
+			corrected = next
+			if _, matched = a.answerInLanguage(corrected, required, settings.MinLength); matched {
+				break
+			}
+		}
+	case LanguageTranslate:
+		translate := &ai.ModelRequest{Messages: []*ai.Message{
+			ai.NewSystemTextMessage(fmt.Sprintf("Translate the user's text into %s. Keep its formatting and reply with the translation only.", name)),
+			ai.NewUserTextMessage(resp.Text()),
+		}}
+		var translated *ai.ModelResponse
+		if settings.Translator != nil {
+			translated, err = settings.Translator.Generate(ctx, translate, nil)
+		} else {
+			translated, err = fn(ctx, translate, nil)
+		}
+		if err == nil {
+			message := *resp.Message
+			message.Content = []*ai.Part{ai.NewTextPart(translated.Text())}
+			copied := *resp
+			copied.Message = &message
+			copied.Usage = sumUsage(resp.Usage, translated.Usage)
+			corrected = &copied
+			_, matched = a.answerInLanguage(corrected, required, settings.MinLength)
+		}
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("azureaifoundry: could not correct the language of an answer",
+			"required", required, "detected", check.Detected, "err", a.redactError(err))
+	} else if corrected != resp {
+		check.Action = settings.Action
+	}
+	check.Matched = matched
+	corrected.Custom = withCustomValue(corrected.Custom, "responseLanguage", check)
+	return corrected
+}
+
+// answerInLanguage detects the language of an answer and whether it is the required one.
+// Answers too short or too ambiguous to tell count as matching
+func (a *AzureAIFoundry) answerInLanguage(resp *ai.ModelResponse, required string, minLength int) (string, bool) {
+	text := resp.Text()
+	if len([]rune(strings.TrimSpace(text))) < minLength {
+		return "", true
+	}
+	detect := a.LanguageDetector
+	if detect == nil {
+		detect = DetectLanguage
+	}
+	detected := detect(text)
+	return detected, detected == "" || baseLanguage(detected) == baseLanguage(required)
+}
+
+// baseLanguage returns the lowercase language of a code without its region, e.g. "es" for "es-MX"
+func baseLanguage(code string) string {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+	return base
+}
+
+// languageName returns the English name of a language code, or the code itself
+func languageName(code string) string {
+	if name, ok := languageNames[baseLanguage(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// hasToolRequests reports whether a message asks for tool calls
+func hasToolRequests(msg *ai.Message) bool {
+	return slices.ContainsFunc(msg.Content, func(part *ai.Part) bool { return part.IsToolRequest() })
+}
+
+// sumUsage adds the token counts of two requests
+func sumUsage(a, b *ai.GenerationUsage) *ai.GenerationUsage {
+	if a == nil || b == nil {
+		return cmp.Or(b, a)
+	}
+	return &ai.GenerationUsage{
+		InputTokens:         a.InputTokens + b.InputTokens,
+		OutputTokens:        a.OutputTokens + b.OutputTokens,
+		TotalTokens:         a.TotalTokens + b.TotalTokens,
+		CachedContentTokens: a.CachedContentTokens + b.CachedContentTokens,
+		ThoughtsTokens:      a.ThoughtsTokens + b.ThoughtsTokens,
+	}
+}
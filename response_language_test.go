@@ -0,0 +1,180 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+const (
+	englishAnswer = "The sales report is very good and the results are for everyone."
+	spanishAnswer = "El informe de ventas es muy bueno y los resultados son para todos."
+)
+
+func TestResponseLanguage(t *testing.T) {
+	tests := []struct {
+		name        string
+		enforcement *LanguageEnforcement
+		language    string
+		streamed    bool
+		answers     []string // Answers of the model, in order; "error" fails the call
+		wantText    string
+		wantCalls   int
+		wantCheck   *LanguageCheck
+	}{
+		{
+			name: "answer in the required language", language: "es",
+			answers: []string{spanishAnswer}, wantText: spanishAnswer, wantCalls: 1,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "es", Matched: true},
+		},
+		{
+			name: "regional code matches its language", language: "es-MX",
+			answers: []string{spanishAnswer}, wantText: spanishAnswer, wantCalls: 1,
+			wantCheck: &LanguageCheck{Required: "es-MX", Detected: "es", Matched: true},
+		},
+		{
+			name: "reprompted by default", language: "es",
+			answers: []string{englishAnswer, spanishAnswer}, wantText: spanishAnswer, wantCalls: 2,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "en", Action: LanguageReprompt, Matched: true},
+		},
+		{
+			name: "reprompt attempts exhausted", enforcement: &LanguageEnforcement{Attempts: 2}, language: "es",
+			answers: []string{englishAnswer, englishAnswer, englishAnswer}, wantText: englishAnswer, wantCalls: 3,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "en", Action: LanguageReprompt},
+		},
+		{
+			name: "translated", enforcement: &LanguageEnforcement{Action: LanguageTranslate}, language: "es",
+			answers: []string{englishAnswer, spanishAnswer}, wantText: spanishAnswer, wantCalls: 2,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "en", Action: LanguageTranslate, Matched: true},
+		},
+		{
+			name: "annotated only", enforcement: &LanguageEnforcement{Action: LanguageAnnotate}, language: "es",
+			answers: []string{englishAnswer}, wantText: englishAnswer, wantCalls: 1,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "en"},
+		},
+		{
+			name: "streamed answers are annotated", language: "es", streamed: true,
+			answers: []string{englishAnswer}, wantText: englishAnswer, wantCalls: 1,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "en"},
+		},
+		{
+			name: "short answers are not checked", language: "es",
+			answers: []string{"OK, done."}, wantText: "OK, done.", wantCalls: 1,
+			wantCheck: &LanguageCheck{Required: "es", Matched: true},
+		},
+		{
+			name: "failed correction keeps the answer", language: "es",
+			answers: []string{englishAnswer, "error"}, wantText: englishAnswer, wantCalls: 2,
+			wantCheck: &LanguageCheck{Required: "es", Detected: "en"},
+		},
+		{
+			name:    "no required language",
+			answers: []string{englishAnswer}, wantText: englishAnswer, wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*ai.ModelRequest
+			fn := func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+				answer := tt.answers[len(requests)]
+				requests = append(requests, input)
+				if answer == "error" {
+					return nil, errors.New("unavailable")
+				}
+				return &ai.ModelResponse{
+					Message: ai.NewModelTextMessage(answer),
+					Request: input,
+					Usage:   &ai.GenerationUsage{InputTokens: 5, OutputTokens: 1, TotalTokens: 6},
+				}, nil
+			}
+			plugin := &AzureAIFoundry{LanguageEnforcement: tt.enforcement}
+			input := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("¿Cómo van las ventas?")}}
+			if tt.language != "" {
+				input.Config = map[string]any{"responseLanguage": tt.language}
+			}
+			var cb ai.ModelStreamCallback
+			if tt.streamed {
+				cb = func(context.Context, *ai.ModelResponseChunk) error { return nil }
+			}
+
+			resp, err := plugin.withResponseLanguage(fn)(t.Context(), input, cb)
+			if err != nil {
+				t.Fatalf("generate error = %v", err)
+			}
+			if resp.Text() != tt.wantText {
+				t.Errorf("text = %q, want %q", resp.Text(), tt.wantText)
+			}
+			if len(requests) != tt.wantCalls {
+				t.Errorf("model called %d times, want %d", len(requests), tt.wantCalls)
+			}
+			custom, _ := resp.Custom.(map[string]any)
+			check, _ := custom["responseLanguage"].(*LanguageCheck)
+			if (check == nil) != (tt.wantCheck == nil) || (check != nil && *check != *tt.wantCheck) {
+				t.Errorf("responseLanguage = %+v, want %+v", check, tt.wantCheck)
+			}
+			if resp.Request != input {
+				t.Error("response request is not the original request")
+			}
+			if tt.wantCalls > 1 && tt.answers[tt.wantCalls-1] != "error" && resp.Usage.TotalTokens != 6*tt.wantCalls {
+				t.Errorf("total tokens = %d, want the usage of every call", resp.Usage.TotalTokens)
+			}
+		})
+	}
+}
+
+func TestResponseLanguagePrompts(t *testing.T) {
+	tests := []struct {
+		action LanguageAction
+		want   string // Text of the last message of the correcting request
+	}{
+		{LanguageReprompt, "Answer again in Spanish"},
+		{LanguageTranslate, englishAnswer},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			var last *ai.ModelRequest
+			translator := englishAnswer
+			fn := func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+				last = input
+				answer := translator
+				translator = spanishAnswer
+				return &ai.ModelResponse{Message: ai.NewModelTextMessage(answer)}, nil
+			}
+			plugin := &AzureAIFoundry{LanguageEnforcement: &LanguageEnforcement{Action: tt.action}}
+			input := &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("¿Cómo van las ventas?")},
+				Config:   map[string]any{"responseLanguage": "es"},
+			}
+			if _, err := plugin.withResponseLanguage(fn)(t.Context(), input, nil); err != nil {
+				t.Fatal(err)
+			}
+			text := last.Messages[len(last.Messages)-1].Text()
+			if !strings.HasPrefix(text, tt.want) {
+				t.Errorf("correcting request ends with %q, want %q", text, tt.want)
+			}
+			if tt.action == LanguageTranslate && !strings.Contains(last.Messages[0].Text(), "into Spanish") {
+				t.Errorf("translation prompt = %q", last.Messages[0].Text())
+			}
+		})
+	}
+}
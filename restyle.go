@@ -0,0 +1,119 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ImageSource is an input image. Set either URL or Data.
+type ImageSource struct {
+	URL         string // Publicly reachable, SAS or data URL of the image
+	Data        []byte // Raw image bytes, sent inline when URL is empty
+	ContentType string // MIME type of Data, e.g. "image/png"
+}
+
+// RestyleOptions configures RestyleImage.
+type RestyleOptions struct {
+	VisionModel ai.Model // Vision-capable chat model that describes the input image (required)
+	ImageModel  ai.Model // gpt-image-1 or DALL-E deployment that draws the new image (required)
+	Style       string   // Target style, e.g. "watercolor painting" or "isometric pixel art" (required)
+	Size        string   // Output size, e.g. "1024x1024". Defaults to the image model's default
+	Quality     string   // Output quality, e.g. "hd" for DALL-E 3 or "high" for gpt-image-1
+	MaxPrompt   int      // Maximum prompt length in characters. Defaults to 4000, the DALL-E 3 limit
+}
+
+// RestyleResult is the description of the input image and the regenerated image.
+type RestyleResult struct {
+	Description string // Style-neutral description of the input image
+	Prompt      string // Prompt sent to the image model
+	Image       string // Image URL, or base64 data for gpt-image models and b64_json responses
+}
+
+// restyleDescription is the structured output requested from the vision model
+type restyleDescription struct {
+	Description string `json:"description"`
+}
+
+// RestyleImage regenerates an image in a new style by describing it with a vision model and
+// drawing the description in the target style with an image model ("style transfer via
+// description"). The new image follows the content and composition of the original, not
+// its exact pixels.
+func RestyleImage(ctx context.Context, g *genkit.Genkit, image ImageSource, opts RestyleOptions) (*RestyleResult, error) {
+	if opts.VisionModel == nil || opts.ImageModel == nil {
+		return nil, fmt.Errorf("restyling requires a vision model and an image model")
+	}
+	if strings.TrimSpace(opts.Style) == "" {
+		return nil, fmt.Errorf("restyling requires a target style")
+	}
+	if opts.MaxPrompt <= 0 {
+		opts.MaxPrompt = 4000
+	}
+	part := imagePart(image.URL, image.Data, image.ContentType)
+	if part == nil {
+		return nil, fmt.Errorf("image has no URL or data")
+	}
+
+	instruction := "Describe this image so an artist could redraw it: the subjects, their poses and " +
+		"expressions, the setting, the composition and the colors. Describe only the content, not " +
+		"the artistic style or medium. Do not name real people."
+	out, _, err := genkit.GenerateData[restyleDescription](ctx, g,
+		ai.WithModel(opts.VisionModel),
+		ai.WithMessages(ai.NewUserMessage(ai.NewTextPart(instruction), part)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe image: %w", err)
+	}
+	description := strings.TrimSpace(out.Description)
+	if description == "" {
+		return nil, fmt.Errorf("vision model returned an empty description")
+	}
+
+	suffix := fmt.Sprintf("\n\nRender this scene as %s.", strings.TrimSpace(opts.Style))
+	prompt := description
+	if budget := opts.MaxPrompt - len([]rune(suffix)); len([]rune(prompt)) > budget {
+		prompt = string([]rune(prompt)[:max(budget, 0)])
+	}
+	prompt += suffix
+
+	config := map[string]interface{}{}
+	if opts.Size != "" {
+		config["size"] = opts.Size
+	}
+	if opts.Quality != "" {
+		config["quality"] = opts.Quality
+	}
+	resp, err := genkit.Generate(ctx, g,
+		ai.WithModel(opts.ImageModel),
+		ai.WithPrompt(prompt),
+		ai.WithConfig(config),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate restyled image: %w", err)
+	}
+	if resp.Text() == "" {
+		return nil, fmt.Errorf("image model returned no image")
+	}
+
+	return &RestyleResult{Description: description, Prompt: prompt, Image: resp.Text()}, nil
+}
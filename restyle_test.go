@@ -0,0 +1,98 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestRestyleImage(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, `{"created":1,"data":[{"b64_json":"aW1hZ2U="}]}`))
+	g := genkit.Init(context.Background())
+	imageModel := plugin.DefineModel(g, ModelDefinition{Name: "gpt-image-1"}, nil)
+
+	var visionParts []*ai.Part
+	visionModel := genkit.DefineModel(g, "test/vision", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, Media: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			visionParts = req.Messages[0].Content
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(`{"description":"A red bicycle leaning on a brick wall."}`)}, nil
+		})
+
+	result, err := RestyleImage(context.Background(), g, ImageSource{Data: []byte("png"), ContentType: "image/png"}, RestyleOptions{
+		VisionModel: visionModel,
+		ImageModel:  imageModel,
+		Style:       "watercolor painting",
+		Quality:     "high",
+	})
+	if err != nil {
+		t.Fatalf("RestyleImage() error = %v", err)
+	}
+
+	if len(visionParts) < 2 || !strings.HasPrefix(visionParts[1].Text, "data:image/png;base64,") {
+		t.Fatalf("vision request parts = %v", visionParts)
+	}
+	wantPrompt := "A red bicycle leaning on a brick wall.\n\nRender this scene as watercolor painting."
+	if result.Description != "A red bicycle leaning on a brick wall." || result.Prompt != wantPrompt || result.Image != "aW1hZ2U=" {
+		t.Fatalf("result = %+v", result)
+	}
+
+	body := bodies[0]
+	if body["prompt"] != wantPrompt || body["quality"] != "high" || body["model"] != "gpt-image-1" {
+		t.Fatalf("image request = %v", body)
+	}
+	if _, ok := body["style"]; ok {
+		t.Fatalf("gpt-image request has a style: %v", body)
+	}
+	if _, ok := body["response_format"]; ok {
+		t.Fatalf("gpt-image request has a response format: %v", body)
+	}
+}
+
+func TestRestyleImagePromptLimit(t *testing.T) {
+	g := genkit.Init(context.Background())
+	long := strings.Repeat("a", 100)
+	visionModel := genkit.DefineModel(g, "test/vision", &ai.ModelOptions{Supports: &ai.ModelSupports{Media: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(`{"description":"` + long + `"}`)}, nil
+		})
+	var prompt string
+	imageModel := genkit.DefineModel(g, "test/image", nil,
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			prompt = req.Messages[0].Text()
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage("https://example.com/out.png")}, nil
+		})
+
+	_, err := RestyleImage(context.Background(), g, ImageSource{URL: "https://example.com/in.jpg"}, RestyleOptions{
+		VisionModel: visionModel,
+		ImageModel:  imageModel,
+		Style:       "pixel art",
+		MaxPrompt:   50,
+	})
+	if err != nil {
+		t.Fatalf("RestyleImage() error = %v", err)
+	}
+	if len(prompt) != 50 || !strings.HasSuffix(prompt, "Render this scene as pixel art.") {
+		t.Fatalf("prompt = %q (%d characters)", prompt, len(prompt))
+	}
+}
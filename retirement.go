@@ -0,0 +1,156 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+// ModelRetirement describes when an Azure OpenAI model version is retired.
+type ModelRetirement struct {
+	Retires     time.Time // Date the model version stops serving requests
+	Replacement string    // Suggested replacement model
+}
+
+// defaultRetirementWindow is how long before retirement warnings start
+const defaultRetirementWindow = 90 * 24 * time.Hour
+
+// retirementWarnInterval limits runtime warnings to one per deployment per interval
+const retirementWarnInterval = 24 * time.Hour
+
+// ModelRetirements lists retirement dates of Azure OpenAI model versions, keyed by model name
+// and version as in "gpt-4o-2024-05-13". Unversioned keys cover every version of a model.
+// Dates follow Microsoft's "Azure OpenAI model retirements" page at the time of release and
+// may move; use AzureAIFoundry.Retirements to override or extend them.
+var ModelRetirements = map[string]ModelRetirement{
+	"dall-e-2":                     {Retires: retirementDate(2025, 2, 17), Replacement: "gpt-image-1"},
+	"dall-e-3":                     {Retires: retirementDate(2026, 3, 4), Replacement: "gpt-image-1"},
+	"gpt-35-turbo":                 {Retires: retirementDate(2025, 11, 14), Replacement: "gpt-4.1-mini"},
+	"gpt-35-turbo-0125":            {Retires: retirementDate(2025, 11, 14), Replacement: "gpt-4.1-mini"},
+	"gpt-35-turbo-0301":            {Retires: retirementDate(2025, 2, 13), Replacement: "gpt-4.1-mini"},
+	"gpt-35-turbo-0613":            {Retires: retirementDate(2025, 2, 13), Replacement: "gpt-4.1-mini"},
+	"gpt-35-turbo-1106":            {Retires: retirementDate(2025, 11, 14), Replacement: "gpt-4.1-mini"},
+	"gpt-35-turbo-16k":             {Retires: retirementDate(2025, 4, 30), Replacement: "gpt-4.1-mini"},
+	"gpt-35-turbo-instruct":        {Retires: retirementDate(2025, 11, 14), Replacement: "gpt-4.1-mini"},
+	"gpt-4":                        {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-0125-preview":           {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-0314":                   {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-0613":                   {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-1106-preview":           {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-32k":                    {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-32k-0613":               {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-turbo":                  {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-turbo-2024-04-09":       {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4-vision-preview":         {Retires: retirementDate(2025, 6, 6), Replacement: "gpt-4.1"},
+	"gpt-4.5-preview":              {Retires: retirementDate(2025, 7, 14), Replacement: "gpt-4.1"},
+	"gpt-4o-2024-05-13":            {Retires: retirementDate(2026, 3, 31), Replacement: "gpt-4.1"},
+	"gpt-4o-2024-08-06":            {Retires: retirementDate(2026, 3, 31), Replacement: "gpt-4.1"},
+	"gpt-4o-audio-preview":         {Retires: retirementDate(2026, 2, 27), Replacement: "gpt-audio"},
+	"gpt-4o-mini-2024-07-18":       {Retires: retirementDate(2026, 3, 31), Replacement: "gpt-4.1-mini"},
+	"gpt-4o-mini-realtime-preview": {Retires: retirementDate(2026, 2, 27), Replacement: "gpt-realtime-mini"},
+	"gpt-4o-realtime-preview":      {Retires: retirementDate(2026, 2, 27), Replacement: "gpt-realtime"},
+	"o1-mini":                      {Retires: retirementDate(2025, 10, 27), Replacement: "o4-mini"},
+	"o1-preview":                   {Retires: retirementDate(2025, 7, 28), Replacement: "o3"},
+	"text-embedding-ada-002":       {Retires: retirementDate(2026, 4, 30), Replacement: "text-embedding-3-small"},
+}
+
+// retirementDate returns midnight UTC of a date
+func retirementDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// RetirementNotice is reported in EventModelRetirement for a deployment near or past retirement.
+type RetirementNotice struct {
+	Deployment string
+	Model      string // Underlying model version looked up in the retirement table
+	ModelRetirement
+}
+
+// Retired reports whether the model version was retired at the given time.
+func (n *RetirementNotice) Retired(now time.Time) bool {
+	return !now.Before(n.Retires)
+}
+
+// String returns the warning message, including the suggested replacement.
+func (n *RetirementNotice) String() string {
+	msg := fmt.Sprintf("model %q used by deployment %q retires on %s", n.Model, n.Deployment, n.Retires.Format("2006-01-02"))
+	if n.Retired(time.Now()) {
+		msg = fmt.Sprintf("model %q used by deployment %q was retired on %s", n.Model, n.Deployment, n.Retires.Format("2006-01-02"))
+	}
+	if n.Replacement != "" {
+		msg += "; migrate to " + n.Replacement
+	}
+	return msg
+}
+
+// retirementNotice returns a notice when the model behind a deployment retires within the
+// warning window, or nil
+func (a *AzureAIFoundry) retirementNotice(model ModelDefinition, now time.Time) *RetirementNotice {
+	name := model.ModelVersion
+	if name == "" {
+		name = model.Name
+	}
+	name = strings.ToLower(name)
+
+	retirement, ok := a.Retirements[name]
+	if !ok {
+		retirement, ok = ModelRetirements[name]
+	}
+	if !ok {
+		return nil
+	}
+
+	window := a.RetirementWarningWindow
+	if window == 0 {
+		window = defaultRetirementWindow
+	}
+	if retirement.Retires.IsZero() || now.Add(window).Before(retirement.Retires) {
+		return nil
+	}
+	return &RetirementNotice{Deployment: model.Name, Model: name, ModelRetirement: retirement}
+}
+
+// warnRetirement logs and emits a retirement warning for a deployment, at most once per
+// retirementWarnInterval
+func (a *AzureAIFoundry) warnRetirement(ctx context.Context, model ModelDefinition) {
+	now := time.Now()
+	notice := a.retirementNotice(model, now)
+	if notice == nil {
+		return
+	}
+
+	a.retirementMu.Lock()
+	if last, ok := a.retirementWarned[model.Name]; ok && now.Sub(last) < retirementWarnInterval {
+		a.retirementMu.Unlock()
+		return
+	}
+	if a.retirementWarned == nil {
+		a.retirementWarned = make(map[string]time.Time)
+	}
+	a.retirementWarned[model.Name] = now
+	a.retirementMu.Unlock()
+
+	logger.FromContext(ctx).Warn("azureaifoundry: "+notice.String(),
+		"deployment", notice.Deployment, "model", notice.Model, "replacement", notice.Replacement)
+	a.emit(ctx, Event{Type: EventModelRetirement, Model: model.Name, Retirement: notice})
+}
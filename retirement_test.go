@@ -0,0 +1,104 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestRetirementNotice(t *testing.T) {
+	plugin := &AzureAIFoundry{
+		Retirements: map[string]ModelRetirement{
+			"my-model-2025-01-01": {Retires: retirementDate(2026, 6, 1), Replacement: "my-model-2026-01-01"},
+		},
+	}
+	now := retirementDate(2026, 4, 1)
+
+	tests := []struct {
+		name        string
+		model       ModelDefinition
+		want        string
+		wantRetired bool
+	}{
+		{"within window", ModelDefinition{Name: "prod", ModelVersion: "my-model-2025-01-01"}, "my-model-2026-01-01", false},
+		{"already retired", ModelDefinition{Name: "gpt-4"}, "gpt-4.1", true},
+		{"version is case-insensitive", ModelDefinition{Name: "chat", ModelVersion: "GPT-4o-2024-08-06"}, "gpt-4.1", true},
+		{"unknown model", ModelDefinition{Name: "gpt-5"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notice := plugin.retirementNotice(tt.model, now)
+			if tt.want == "" {
+				if notice != nil {
+					t.Fatalf("retirementNotice() = %+v, want nil", notice)
+				}
+				return
+			}
+			if notice == nil || notice.Replacement != tt.want || notice.Retired(now) != tt.wantRetired {
+				t.Fatalf("retirementNotice() = %+v", notice)
+			}
+			if !strings.Contains(notice.String(), "migrate to "+tt.want) {
+				t.Fatalf("String() = %q", notice.String())
+			}
+		})
+	}
+
+	plugin.RetirementWarningWindow = 30 * 24 * time.Hour
+	if notice := plugin.retirementNotice(tests[0].model, now); notice != nil {
+		t.Fatalf("notice outside the warning window: %+v", notice)
+	}
+}
+
+func TestRetirementWarningEvents(t *testing.T) {
+	var notices []*RetirementNotice
+	plugin := newTestPlugin(t, captureRequests(new([]map[string]any), chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+			if event.Type == EventModelRetirement {
+				notices = append(notices, event.Retirement)
+			}
+		}))
+	})
+	g := genkit.Init(context.Background())
+	model := plugin.DefineModel(g, ModelDefinition{Name: "chat", Type: "chat", ModelVersion: "gpt-35-turbo-0613"}, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := genkit.Generate(context.Background(), g, ai.WithModel(model), ai.WithPrompt("hi")); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+
+	// One warning when the model is defined; runtime warnings are rate-limited per deployment
+	if len(notices) != 1 || notices[0].Deployment != "chat" || notices[0].Model != "gpt-35-turbo-0613" {
+		t.Fatalf("notices = %+v", notices)
+	}
+
+	plugin.retirementWarned["chat"] = time.Now().Add(-2 * retirementWarnInterval)
+	if _, err := genkit.Generate(context.Background(), g, ai.WithModel(model), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(notices) != 2 {
+		t.Fatalf("got %d notices after the interval, want 2", len(notices))
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// defaultMaxRetries is how often the client retries failed requests by default
+const defaultMaxRetries = 2
+
+// retryOptions returns the client options that apply MaxRetries, RetryBackoff and
+// RetryableStatuses. The client keeps retrying requests itself; the middleware only tells
+// it which responses to retry and how long to wait.
+func (a *AzureAIFoundry) retryOptions() []option.RequestOption {
+	var opts []option.RequestOption
+	if a.MaxRetries != 0 {
+		opts = append(opts, option.WithMaxRetries(max(0, a.MaxRetries)))
+	}
+	if a.RetryBackoff != nil || a.RetryableStatuses != nil {
+		opts = append(opts, option.WithMiddleware(a.retryPolicyMiddleware()))
+	}
+	return opts
+}
+
+// retryPolicyMiddleware marks responses the client should retry with x-should-retry and,
+// unless Azure asked for a wait, sets the RetryBackoff delay with Retry-After-Ms
+func (a *AzureAIFoundry) retryPolicyMiddleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil || resp == nil || resp.Header.Get("X-Should-Retry") != "" {
+			return resp, err
+		}
+		if !a.isRetryableStatus(resp.StatusCode) {
+			resp.Header.Set("X-Should-Retry", "false")
+			return resp, err
+		}
+		resp.Header.Set("X-Should-Retry", "true")
+
+		// The last attempt's headers end up in the returned error, so leave them as Azure sent them
+		attempt, _ := strconv.Atoi(req.Header.Get("X-Stainless-Retry-Count"))
+		if a.RetryBackoff != nil && attempt < a.maxRetries() &&
+			resp.Header.Get("Retry-After-Ms") == "" && resp.Header.Get("Retry-After") == "" {
+			delay := a.RetryBackoff(attempt + 1)
+			resp.Header.Set("Retry-After-Ms", strconv.FormatInt(max(0, delay.Milliseconds()), 10))
+		}
+		return resp, err
+	}
+}
+
+// isRetryableStatus reports whether a response status is retried
+func (a *AzureAIFoundry) isRetryableStatus(status int) bool {
+	if a.RetryableStatuses != nil {
+		return slices.Contains(a.RetryableStatuses, status)
+	}
+	return status == http.StatusRequestTimeout || status == http.StatusConflict ||
+		status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// maxRetries returns how often the client retries failed requests
+func (a *AzureAIFoundry) maxRetries() int {
+	if a.MaxRetries == 0 {
+		return defaultMaxRetries
+	}
+	return max(0, a.MaxRetries)
+}
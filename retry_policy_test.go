@@ -0,0 +1,161 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// statusSequenceHandler answers requests with the given statuses in turn, repeating the
+// last one, and counts them
+func statusSequenceHandler(calls *atomic.Int32, statuses []int, header http.Header) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[min(int(calls.Add(1)), len(statuses))-1]
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		if status != http.StatusOK {
+			http.Error(w, `{"error":{"code":"Failed","message":"failed"}}`, status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/openai/deployments/text-embedding-3-small/embeddings" {
+			_, _ = io.WriteString(w, `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"model":"text-embedding-3-small","usage":{"prompt_tokens":1,"total_tokens":1}}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		statuses     []int
+		retryable    []int
+		backoff      time.Duration
+		header       http.Header
+		embed        bool
+		wantCalls    int32
+		wantAttempts []int
+		wantErr      bool
+	}{
+		{
+			name:         "transient error retried",
+			statuses:     []int{503, 200},
+			wantCalls:    2,
+			wantAttempts: []int{1},
+		},
+		{
+			name:         "embeddings retried",
+			statuses:     []int{429, 200},
+			embed:        true,
+			wantCalls:    2,
+			wantAttempts: []int{1},
+		},
+		{
+			name:         "more retries",
+			maxRetries:   3,
+			statuses:     []int{503},
+			wantCalls:    4,
+			wantAttempts: []int{1, 2, 3},
+			wantErr:      true,
+		},
+		{
+			name:       "retries disabled",
+			maxRetries: -1,
+			statuses:   []int{503, 200},
+			wantCalls:  1,
+			wantErr:    true,
+		},
+		{
+			name:      "status not retryable",
+			statuses:  []int{429, 200},
+			retryable: []int{503},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:         "custom retryable status",
+			statuses:     []int{400, 200},
+			retryable:    []int{400},
+			wantCalls:    2,
+			wantAttempts: []int{1},
+		},
+		{
+			name:      "retry-after takes precedence",
+			statuses:  []int{429, 200},
+			backoff:   time.Hour,
+			header:    http.Header{"Retry-After-Ms": {"1"}},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var mu sync.Mutex
+			var attempts []int
+			backoff := tt.backoff
+			if backoff == 0 {
+				backoff = time.Millisecond
+			}
+			plugin := newTestPlugin(t, statusSequenceHandler(&calls, tt.statuses, tt.header), func(a *AzureAIFoundry) {
+				a.MaxRetries = tt.maxRetries
+				a.RetryableStatuses = tt.retryable
+				a.RetryBackoff = func(attempt int) time.Duration {
+					mu.Lock()
+					defer mu.Unlock()
+					attempts = append(attempts, attempt)
+					return backoff
+				}
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			g := genkit.Init(ctx)
+
+			var err error
+			if tt.embed {
+				embedder := plugin.DefineEmbedder(g, "text-embedding-3-small")
+				_, err = genkit.Embed(ctx, g, ai.WithEmbedder(embedder), ai.WithTextDocs("hi"))
+			} else {
+				model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+				_, err = genkit.Generate(ctx, g, ai.WithModel(model), ai.WithPrompt("hi"))
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+			if tt.header == nil && !slices.Equal(attempts, tt.wantAttempts) {
+				t.Fatalf("backoff attempts = %v, want %v", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/openai/openai-go/v3"
+)
+
+// GenerationJob is a fire-and-forget generation request that is persisted in a JobQueue
+// until it succeeds or runs out of attempts. Jobs are serialized as JSON.
+type GenerationJob struct {
+	ID        string            `json:"id"`
+	Model     string            `json:"model"` // Registered model name, e.g. "azureaifoundry/gpt-4o"
+	Messages  []*ai.Message     `json:"messages"`
+	Config    any               `json:"config,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"` // Application data passed back to the callbacks
+	Attempts  int               `json:"attempts"`
+	LastError string            `json:"lastError,omitempty"`
+
+	Receipt string `json:"-"` // Set by the queue when the job is received, used to delete it
+}
+
+// JobQueue is a durable queue backend, such as an Azure Storage Queue or a Service Bus
+// queue. Received jobs stay in the queue, hidden from other receivers, until they are
+// deleted, so a crashed worker does not lose them.
+type JobQueue interface {
+	// Send stores a job that becomes visible to receivers after delay.
+	Send(ctx context.Context, job *GenerationJob, delay time.Duration) error
+	// Receive returns the next visible job with its Receipt set, or nil when none is ready.
+	Receive(ctx context.Context) (*GenerationJob, error)
+	// Delete removes a received job from the queue.
+	Delete(ctx context.Context, job *GenerationJob) error
+}
+
+// RetryQueueOptions configures a RetryQueue.
+type RetryQueueOptions struct {
+	Queue        JobQueue                                                              // Queue backend (required)
+	MaxAttempts  int                                                                   // Attempts before a job is given up. Defaults to 5
+	Backoff      func(attempt int) time.Duration                                       // Delay before retrying after the given attempt. Defaults to 30s doubling up to 1h
+	PollInterval time.Duration                                                         // Wait between polls of an empty queue in Run. Defaults to 5s
+	OnComplete   func(ctx context.Context, job *GenerationJob, resp *ai.ModelResponse) // Called when a job succeeds
+	OnFailure    func(ctx context.Context, job *GenerationJob, err error)              // Called when a job is given up
+}
+
+// RetryQueue runs generation jobs from a durable queue and parks failed jobs back in the
+// queue with a backoff delay, so work is not lost during Azure incidents.
+type RetryQueue struct {
+	g    *genkit.Genkit
+	opts RetryQueueOptions
+}
+
+// NewRetryQueue returns a RetryQueue that runs jobs against the models registered in g.
+func NewRetryQueue(g *genkit.Genkit, opts RetryQueueOptions) *RetryQueue {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff == nil {
+		opts.Backoff = defaultJobBackoff
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	return &RetryQueue{g: g, opts: opts}
+}
+
+// Submit queues a job for execution and returns its ID.
+func (q *RetryQueue) Submit(ctx context.Context, job *GenerationJob) (string, error) {
+	if job.Model == "" {
+		return "", fmt.Errorf("generation job requires a model")
+	}
+	if job.ID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return "", fmt.Errorf("failed to generate job ID: %w", err)
+		}
+		job.ID = hex.EncodeToString(id)
+	}
+	if err := q.opts.Queue.Send(ctx, job, 0); err != nil {
+		return "", fmt.Errorf("failed to queue generation job: %w", err)
+	}
+	return job.ID, nil
+}
+
+// Run processes jobs until ctx is canceled, polling the queue when it is empty.
+func (q *RetryQueue) Run(ctx context.Context) error {
+	for {
+		processed, err := q.ProcessNext(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("azureaifoundry: retry queue error", "err", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(q.opts.PollInterval):
+		}
+	}
+}
+
+// ProcessNext runs the next visible job, if any, and reports whether one was processed.
+// A failed job is sent back to the queue with a backoff delay, or handed to OnFailure once
+// it is out of attempts or the error is permanent. It is useful for queue-triggered
+// functions that handle one message per invocation.
+func (q *RetryQueue) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := q.opts.Queue.Receive(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to receive generation job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	resp, genErr := q.generate(ctx, job)
+	job.Attempts++
+	switch {
+	case genErr == nil:
+		if q.opts.OnComplete != nil {
+			q.opts.OnComplete(ctx, job, resp)
+		}
+	case job.Attempts < q.opts.MaxAttempts && isRetryableJobError(genErr):
+		job.LastError = genErr.Error()
+		if err := q.opts.Queue.Send(ctx, job, q.opts.Backoff(job.Attempts)); err != nil {
+			// Leave the received job in place so it becomes visible again
+			return true, fmt.Errorf("failed to park generation job %s: %w", job.ID, err)
+		}
+	default:
+		job.LastError = genErr.Error()
+		if q.opts.OnFailure != nil {
+			q.opts.OnFailure(ctx, job, genErr)
+		}
+	}
+
+	if err := q.opts.Queue.Delete(ctx, job); err != nil {
+		return true, fmt.Errorf("failed to delete generation job %s: %w", job.ID, err)
+	}
+	return true, nil
+}
+
+// generate runs a job against its registered model
+func (q *RetryQueue) generate(ctx context.Context, job *GenerationJob) (*ai.ModelResponse, error) {
+	model := genkit.LookupModel(q.g, job.Model)
+	if model == nil {
+		return nil, fmt.Errorf("model %q is not registered", job.Model)
+	}
+	return genkit.Generate(ctx, q.g,
+		ai.WithModel(model),
+		ai.WithMessages(job.Messages...),
+		ai.WithConfig(job.Config),
+	)
+}
+
+// isRetryableJobError reports whether a failed job may succeed later. Azure errors are
+// retried on throttling, timeouts and server errors; other errors, such as network
+// failures, are always retried.
+func isRetryableJobError(err error) bool {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+			return true
+		}
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// defaultJobBackoff waits 30 seconds after the first attempt, doubling up to an hour
+func defaultJobBackoff(attempt int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, time.Hour)
+}
+
+// MemoryJobQueue is an in-process JobQueue for development and tests. It is not durable.
+type MemoryJobQueue struct {
+	mu         sync.Mutex
+	visibility time.Duration
+	entries    []*memoryJobEntry
+	receipts   int
+	now        func() time.Time
+}
+
+// memoryJobEntry is a serialized job and the time it becomes visible
+type memoryJobEntry struct {
+	data      []byte
+	visibleAt time.Time
+	receipt   string
+}
+
+// NewMemoryJobQueue returns an empty in-memory queue. Received jobs that are not deleted
+// become visible again after visibility, defaulting to five minutes.
+func NewMemoryJobQueue(visibility time.Duration) *MemoryJobQueue {
+	if visibility <= 0 {
+		visibility = 5 * time.Minute
+	}
+	return &MemoryJobQueue{visibility: visibility, now: time.Now}
+}
+
+// Send implements JobQueue.
+func (m *MemoryJobQueue) Send(_ context.Context, job *GenerationJob, delay time.Duration) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, &memoryJobEntry{data: data, visibleAt: m.now().Add(delay)})
+	return nil
+}
+
+// Receive implements JobQueue.
+func (m *MemoryJobQueue) Receive(_ context.Context) (*GenerationJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		return m.entries[i].visibleAt.Before(m.entries[j].visibleAt)
+	})
+	for _, entry := range m.entries {
+		if entry.visibleAt.After(now) {
+			break
+		}
+		var job GenerationJob
+		if err := json.Unmarshal(entry.data, &job); err != nil {
+			return nil, err
+		}
+		m.receipts++
+		entry.receipt = fmt.Sprintf("%s/%d", job.ID, m.receipts)
+		entry.visibleAt = now.Add(m.visibility)
+		job.Receipt = entry.receipt
+		return &job, nil
+	}
+	return nil, nil
+}
+
+// Delete implements JobQueue.
+func (m *MemoryJobQueue) Delete(_ context.Context, job *GenerationJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, entry := range m.entries {
+		if entry.receipt != "" && entry.receipt == job.Receipt {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("job %s is not in the queue or its receipt expired", job.ID)
+}
+
+// Len returns the number of jobs in the queue, visible or not.
+func (m *MemoryJobQueue) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
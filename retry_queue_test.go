@@ -0,0 +1,156 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/openai/openai-go/v3"
+)
+
+func TestRetryQueue(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+
+	calls := map[string]int{}
+	genkit.DefineModel(g, "test/flaky", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			calls["flaky"]++
+			if calls["flaky"] < 3 {
+				return nil, errors.New("connection reset")
+			}
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage("done")}, nil
+		})
+	genkit.DefineModel(g, "test/rejected", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			calls["rejected"]++
+			return nil, &openai.Error{
+				StatusCode: http.StatusBadRequest,
+				Request:    httptest.NewRequest(http.MethodPost, "/chat/completions", nil),
+				Response:   &http.Response{StatusCode: http.StatusBadRequest},
+			}
+		})
+
+	now := time.Unix(0, 0)
+	queue := NewMemoryJobQueue(time.Minute)
+	queue.now = func() time.Time { return now }
+
+	var completed, failed []*GenerationJob
+	retries := NewRetryQueue(g, RetryQueueOptions{
+		Queue:       queue,
+		MaxAttempts: 5,
+		OnComplete: func(_ context.Context, job *GenerationJob, resp *ai.ModelResponse) {
+			if resp.Text() != "done" {
+				t.Errorf("response = %q", resp.Text())
+			}
+			completed = append(completed, job)
+		},
+		OnFailure: func(_ context.Context, job *GenerationJob, _ error) {
+			failed = append(failed, job)
+		},
+	})
+
+	for _, model := range []string{"test/flaky", "test/rejected"} {
+		if _, err := retries.Submit(ctx, &GenerationJob{
+			Model:    model,
+			Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+			Metadata: map[string]string{"order": "42"},
+		}); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	// Drain the queue, advancing the clock past each backoff
+	for i := 0; i < 10 && queue.Len() > 0; i++ {
+		for {
+			processed, err := retries.ProcessNext(ctx)
+			if err != nil {
+				t.Fatalf("ProcessNext() error = %v", err)
+			}
+			if !processed {
+				break
+			}
+		}
+		now = now.Add(time.Hour)
+	}
+
+	if queue.Len() != 0 {
+		t.Fatalf("queue has %d jobs left", queue.Len())
+	}
+	if len(completed) != 1 || completed[0].Attempts != 3 || completed[0].Metadata["order"] != "42" {
+		t.Fatalf("completed = %+v", completed)
+	}
+	if len(failed) != 1 || failed[0].Model != "test/rejected" || failed[0].Attempts != 1 {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if calls["rejected"] != 1 {
+		t.Fatalf("permanent error retried %d times", calls["rejected"])
+	}
+}
+
+func TestMemoryJobQueueVisibility(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	queue := NewMemoryJobQueue(time.Minute)
+	queue.now = func() time.Time { return now }
+
+	if err := queue.Send(ctx, &GenerationJob{ID: "a"}, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := queue.Receive(ctx); job != nil {
+		t.Fatalf("delayed job received early: %+v", job)
+	}
+
+	now = now.Add(10 * time.Second)
+	job, _ := queue.Receive(ctx)
+	if job == nil || job.ID != "a" {
+		t.Fatalf("Receive() = %+v", job)
+	}
+	if again, _ := queue.Receive(ctx); again != nil {
+		t.Fatalf("received job is still visible: %+v", again)
+	}
+
+	// An unacknowledged job reappears with a new receipt, invalidating the old one
+	now = now.Add(time.Minute)
+	redelivered, _ := queue.Receive(ctx)
+	if redelivered == nil || redelivered.Receipt == job.Receipt {
+		t.Fatalf("redelivered = %+v", redelivered)
+	}
+	if err := queue.Delete(ctx, job); err == nil {
+		t.Fatal("Delete() with an expired receipt succeeded")
+	}
+	if err := queue.Delete(ctx, redelivered); err != nil || queue.Len() != 0 {
+		t.Fatalf("Delete() error = %v, len = %d", err, queue.Len())
+	}
+}
+
+func TestDefaultJobBackoff(t *testing.T) {
+	tests := map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 10: time.Hour}
+	for attempt, want := range tests {
+		if got := defaultJobBackoff(attempt); got != want {
+			t.Errorf("defaultJobBackoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
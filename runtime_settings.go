@@ -0,0 +1,153 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// DefaultModelAlias is the name of the model that serves RuntimeSettings.DefaultModel, e.g.
+// "azureaifoundry/default". Pass it to genkit.WithDefaultModel to switch the default model at
+// runtime.
+const DefaultModelAlias = "default"
+
+// RuntimeSettings are plugin settings that operations can change while the service runs, e.g.
+// from Azure App Configuration.
+type RuntimeSettings struct {
+	DefaultModel    string                `json:"defaultModel,omitempty"`    // Model served by DefaultModelAlias, e.g. "gpt-4o"
+	MaxTemperature  float64               `json:"maxTemperature,omitempty"`  // Caps the temperature of chat requests. 0 means no cap
+	DisableFailover bool                  `json:"disableFailover,omitempty"` // Failover groups only use their first deployment
+	Experiments     map[string]Experiment `json:"experiments,omitempty"`     // Traffic splits by model name
+}
+
+// Experiment routes a share of a model's requests to another deployment. Responses served by
+// the experiment deployment carry its name in the "experiment" custom value.
+type Experiment struct {
+	Deployment string  `json:"deployment"` // Deployment that receives the experiment traffic
+	Percent    float64 `json:"percent"`    // Share of requests routed to Deployment, from 0 to 100
+}
+
+// SettingsSource loads the current runtime settings.
+type SettingsSource func(ctx context.Context) (*RuntimeSettings, error)
+
+// Settings returns the current runtime settings.
+func (a *AzureAIFoundry) Settings() RuntimeSettings {
+	return *a.settings()
+}
+
+// settings returns the current runtime settings, which must not be modified
+func (a *AzureAIFoundry) settings() *RuntimeSettings {
+	if settings := a.runtimeSettings.Load(); settings != nil {
+		return settings
+	}
+	return &RuntimeSettings{}
+}
+
+// UpdateSettings replaces the runtime settings. The first time a default model is set, the
+// DefaultModelAlias model is registered with g. An EventSettingsChanged event is emitted
+// when the settings differ from the current ones.
+func (a *AzureAIFoundry) UpdateSettings(ctx context.Context, g *genkit.Genkit, settings RuntimeSettings) {
+	experiments := settings.Experiments
+	settings.Experiments = nil
+	for model, experiment := range experiments {
+		if experiment.Deployment == "" || experiment.Percent < 0 || experiment.Percent > 100 {
+			logger.FromContext(ctx).Warn("azureaifoundry: ignoring invalid experiment",
+				"model", model, "deployment", experiment.Deployment, "percent", experiment.Percent)
+			continue
+		}
+		if settings.Experiments == nil {
+			settings.Experiments = make(map[string]Experiment, len(experiments))
+		}
+		settings.Experiments[model] = experiment
+	}
+	if settings.DefaultModel != "" {
+		a.declareDefaultModel(g)
+	}
+
+	previous := a.runtimeSettings.Swap(&settings)
+	if previous == nil {
+		previous = &RuntimeSettings{}
+	}
+	if !reflect.DeepEqual(*previous, settings) {
+		a.emit(ctx, Event{Type: EventSettingsChanged, Settings: &settings})
+	}
+}
+
+// WatchSettings polls source every interval (30 seconds by default) and applies the settings
+// with UpdateSettings. Sources that fail to load are logged and the current settings are
+// kept. It blocks until ctx is done or Close is called.
+func (a *AzureAIFoundry) WatchSettings(ctx context.Context, g *genkit.Genkit, source SettingsSource, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return a.poll(ctx, interval, func() {
+		settings, err := source(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("azureaifoundry: failed to load runtime settings", "err", err)
+			return
+		}
+		a.UpdateSettings(ctx, g, *settings)
+	})
+}
+
+// declareDefaultModel registers the DefaultModelAlias model once. It forwards every request
+// to the model named by the current settings.
+func (a *AzureAIFoundry) declareDefaultModel(g *genkit.Genkit) {
+	a.defaultModelMu.Lock()
+	defer a.defaultModelMu.Unlock()
+	if a.defaultModelDeclared {
+		return
+	}
+	a.defaultModelDeclared = true
+
+	// The alias accepts everything; the target model validates the request
+	meta := &ai.ModelOptions{
+		Label:    a.Name() + "-" + DefaultModelAlias,
+		Supports: &ai.ModelSupports{Multiturn: true, Tools: true, SystemRole: true, Media: true, ToolChoice: true},
+	}
+	genkit.DefineModel(g, api.NewName(a.Name(), DefaultModelAlias), meta, func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		name := a.settings().DefaultModel
+		if name == "" {
+			return nil, fmt.Errorf("no default model is set in the runtime settings")
+		}
+		model := a.Model(g, name)
+		if model == nil {
+			return nil, fmt.Errorf("default model %q is not defined", name)
+		}
+		return model.Generate(ctx, input, cb)
+	})
+}
+
+// experimentDeployment returns the deployment to send a request for a model to, applying
+// the model's experiment if it has one
+func (a *AzureAIFoundry) experimentDeployment(model string) string {
+	experiment, ok := a.settings().Experiments[model]
+	if ok && rand.Float64()*100 < experiment.Percent {
+		return experiment.Deployment
+	}
+	return model
+}
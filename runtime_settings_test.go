@@ -0,0 +1,182 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestUpdateSettings(t *testing.T) {
+	var bodies []map[string]any
+	plugin, g := newRegistryPlugin(t, &bodies, "models:\n  - {name: gpt-4o}\n  - {name: gpt-4o-mini}\n")
+	var changes []*RuntimeSettings
+	plugin.Subscribe(EventSubscriberFunc(func(ctx context.Context, event Event) {
+		if event.Type == EventSettingsChanged {
+			changes = append(changes, event.Settings)
+		}
+	}))
+	ctx := context.Background()
+
+	if IsDefinedModel(g, DefaultModelAlias) {
+		t.Fatal("default model alias is registered before a default model is set")
+	}
+	settings := RuntimeSettings{
+		DefaultModel:   "gpt-4o",
+		MaxTemperature: 0.5,
+		Experiments: map[string]Experiment{
+			"gpt-4o":      {Deployment: "gpt-4o-mini", Percent: 100},
+			"gpt-4o-mini": {Percent: 50}, // No deployment
+		},
+	}
+	plugin.UpdateSettings(ctx, g, settings)
+	if len(changes) != 1 || len(changes[0].Experiments) != 1 {
+		t.Fatalf("changes = %+v, want the invalid experiment dropped", changes)
+	}
+
+	resp, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, DefaultModelAlias)), ai.WithPrompt("hi"),
+		ai.WithConfig(map[string]any{"temperature": 0.9}))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if bodies[0]["model"] != "gpt-4o-mini" || bodies[0]["temperature"] != 0.5 {
+		t.Fatalf("request = %v, want the experiment deployment and a capped temperature", bodies[0])
+	}
+	if custom, _ := resp.Custom.(map[string]any); custom["experiment"] != "gpt-4o-mini" {
+		t.Fatalf("Custom = %v", resp.Custom)
+	}
+
+	// A 0% experiment keeps all traffic on the model
+	settings.Experiments = map[string]Experiment{"gpt-4o": {Deployment: "gpt-4o-mini", Percent: 0}}
+	settings.DefaultModel = "gpt-4o-mini"
+	plugin.UpdateSettings(ctx, g, settings)
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if bodies[1]["model"] != "gpt-4o" {
+		t.Fatalf("request = %v, want no experiment", bodies[1])
+	}
+	// The alias follows the new default model
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, DefaultModelAlias)), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if bodies[2]["model"] != "gpt-4o-mini" {
+		t.Fatalf("request = %v, want the new default model", bodies[2])
+	}
+
+	// Unchanged settings emit no event
+	plugin.UpdateSettings(ctx, g, settings)
+	if len(changes) != 2 {
+		t.Fatalf("got %d change events, want 2", len(changes))
+	}
+	if got := plugin.Settings(); got.DefaultModel != "gpt-4o-mini" || got.MaxTemperature != 0.5 {
+		t.Fatalf("Settings() = %+v", got)
+	}
+}
+
+func TestSettingsDisableFailover(t *testing.T) {
+	var models []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		models = append(models, body["model"])
+		w.Header().Set("Content-Type", "application/json")
+		if body["model"] == "gpt-4o-eastus" {
+			w.Header().Set("x-should-retry", "false")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":"503","message":"Service unavailable"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	defer server.Close()
+
+	registry, err := LoadModelsFromConfig([]byte(registryYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Registry: registry}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+
+	plugin.UpdateSettings(ctx, g, RuntimeSettings{DisableFailover: true})
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi")); err == nil {
+		t.Fatal("Generate() succeeded with failover disabled")
+	}
+	if len(models) != 1 {
+		t.Fatalf("requested models = %v, want only the primary", models)
+	}
+
+	plugin.UpdateSettings(ctx, g, RuntimeSettings{})
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() with failover enabled error = %v", err)
+	}
+	if len(models) != 3 || models[2] != "gpt-4o-westeurope" {
+		t.Fatalf("requested models = %v, want failover to westeurope", models)
+	}
+}
+
+func TestWatchSettings(t *testing.T) {
+	var bodies []map[string]any
+	plugin, g := newRegistryPlugin(t, &bodies, "models:\n  - {name: gpt-4o}\n")
+	changes := make(chan *RuntimeSettings, 4)
+	plugin.Subscribe(EventSubscriberFunc(func(ctx context.Context, event Event) {
+		if event.Type == EventSettingsChanged {
+			changes <- event.Settings
+		}
+	}))
+
+	calls := 0
+	source := func(ctx context.Context) (*RuntimeSettings, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return &RuntimeSettings{MaxTemperature: 0.3}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- plugin.WatchSettings(ctx, g, source, 5*time.Millisecond) }()
+	select {
+	case settings := <-changes:
+		if settings.MaxTemperature != 0.3 {
+			t.Fatalf("settings = %+v", settings)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("settings were not applied")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("WatchSettings() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("unexpected changes: %d", len(changes))
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ScopeOptions are the settings a scope overrides.
+type ScopeOptions struct {
+	DefaultConfig map[string]any // Config options for requests that do not set them, applied before the model's own defaults
+	RateLimit     *RateLimit     // Limit shared by all models and embedders of the scope
+	Attribution   Attribution    // Labels every request of the scope, e.g. with the owning team
+}
+
+// Usage is the token usage and cost accumulated by a scope.
+type Usage struct {
+	Requests          int     `json:"requests"`
+	InputTokens       int     `json:"inputTokens"`
+	CachedInputTokens int     `json:"cachedInputTokens,omitempty"` // Part of InputTokens served from the prompt cache
+	OutputTokens      int     `json:"outputTokens"`
+	TotalTokens       int     `json:"totalTokens"`
+	ReasoningTokens   int     `json:"reasoningTokens,omitempty"` // Part of OutputTokens spent on hidden reasoning by o-series and GPT-5 models
+	Cost              float64 `json:"cost,omitempty"`            // USD, for deployments with Pricing
+}
+
+// CacheHitRate returns the fraction of input tokens served from the prompt cache.
+func (u Usage) CacheHitRate() float64 {
+	if u.InputTokens == 0 {
+		return 0
+	}
+	return float64(u.CachedInputTokens) / float64(u.InputTokens)
+}
+
+// Scope is a view of the plugin registered as its own provider, e.g. for one team. Its models
+// share the plugin's client, authentication and policies, but apply the scope's defaults and
+// rate limit and keep their own usage accounting.
+type Scope struct {
+	plugin  *AzureAIFoundry
+	name    string
+	opts    ScopeOptions
+	limiter *rateLimiter
+
+	mu    sync.Mutex
+	usage map[string]Usage // Usage per deployment
+}
+
+// Scoped returns a scope whose models are registered under the provider name, e.g.
+// "team-search/gpt-4o". The plugin must be initialized.
+func (a *AzureAIFoundry) Scoped(name string, overrides ScopeOptions) *Scope {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.initted {
+		panic("azureaifoundry: Init not called")
+	}
+	if name == "" || name == a.Name() {
+		panic(fmt.Sprintf("azureaifoundry: invalid scope name %q", name))
+	}
+
+	s := &Scope{plugin: a, name: name, opts: overrides}
+	s.restoreUsage(a.restoredUsage[name])
+	if overrides.RateLimit != nil {
+		s.limiter = newRateLimiter(*overrides.RateLimit)
+	}
+	if a.scopes == nil {
+		a.scopes = make(map[string]*Scope)
+	}
+	a.scopes[name] = s
+	return s
+}
+
+// Name returns the provider name of the scope.
+func (s *Scope) Name() string {
+	return s.name
+}
+
+// DefineModel defines a model of the scope.
+func (s *Scope) DefineModel(g *genkit.Genkit, model ModelDefinition, info *ai.ModelInfo) ai.Model {
+	if len(s.opts.DefaultConfig) > 0 {
+		config := maps.Clone(s.opts.DefaultConfig)
+		maps.Copy(config, model.DefaultConfig)
+		model.DefaultConfig = config
+	}
+	meta, fn := s.plugin.modelAction(model, info)
+	meta.Label = s.name + "-" + model.Name
+
+	return genkit.DefineModel(g, api.NewName(s.name, model.Name), meta, func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		ctx, request, err := s.begin(ctx, func() int { return s.plugin.estimatedTokens(model.Name, input) })
+		if err != nil {
+			return nil, err
+		}
+		resp, err := fn(ctx, input, cb)
+		var usage *ai.GenerationUsage
+		if resp != nil {
+			usage = resp.Usage
+		}
+		s.record(model.Name, request, usage, err)
+		return resp, err
+	})
+}
+
+// DefineEmbedder defines an embedder of the scope.
+func (s *Scope) DefineEmbedder(g *genkit.Genkit, modelName string) ai.Embedder {
+	fn := s.plugin.embedderFunc(modelName)
+	return genkit.DefineEmbedder(g, api.NewName(s.name, modelName), nil, func(ctx context.Context, req *ai.EmbedRequest) (*ai.EmbedResponse, error) {
+		ctx, request, err := s.begin(ctx, func() int { return s.plugin.estimatedEmbedTokens(modelName, req) })
+		if err != nil {
+			return nil, err
+		}
+		resp, err := fn(ctx, req)
+		s.record(modelName, request, nil, err)
+		return resp, err
+	})
+}
+
+// Model returns the Model with the given name defined by the scope.
+func (s *Scope) Model(g *genkit.Genkit, name string) ai.Model {
+	return genkit.LookupModel(g, api.NewName(s.name, name))
+}
+
+// Embedder returns the Embedder with the given name defined by the scope.
+func (s *Scope) Embedder(g *genkit.Genkit, name string) ai.Embedder {
+	return genkit.LookupEmbedder(g, api.NewName(s.name, name))
+}
+
+// Usage returns the usage of all models and embedders of the scope.
+func (s *Scope) Usage() Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total Usage
+	for _, usage := range s.usage {
+		total.add(usage)
+	}
+	return total
+}
+
+// UsageByModel returns the usage of the scope per deployment name.
+func (s *Scope) UsageByModel() map[string]Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.usage)
+}
+
+// begin attributes a request to the scope and waits for its rate limit, counting the tokens
+// estimated by tokens
+func (s *Scope) begin(ctx context.Context, tokens func() int) (context.Context, *rateRequest, error) {
+	if !s.opts.Attribution.IsZero() {
+		ctx = WithAttribution(ctx, s.opts.Attribution)
+	}
+	if s.limiter == nil {
+		return ctx, nil, nil
+	}
+	request, err := s.limiter.wait(ctx, tokens())
+	if err != nil {
+		return ctx, nil, fmt.Errorf("rate limit of scope %s: %w", s.name, err)
+	}
+	return ctx, request, nil
+}
+
+// record adds a finished request to the scope's usage and rate limit
+func (s *Scope) record(modelName string, request *rateRequest, usage *ai.GenerationUsage, err error) {
+	added := Usage{Requests: 1}
+	if usage != nil {
+		added.InputTokens = usage.InputTokens
+		added.CachedInputTokens = usage.CachedContentTokens
+		added.OutputTokens = usage.OutputTokens
+		added.TotalTokens = usage.TotalTokens
+		added.ReasoningTokens = usage.ThoughtsTokens
+		if pricing, ok := s.plugin.Pricing[modelName]; ok {
+			added.Cost = pricing.Cost(usage.InputTokens, usage.OutputTokens)
+		}
+	}
+	if request != nil {
+		s.limiter.finish(request, usage, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.usage[modelName]
+	current.add(added)
+	s.usage[modelName] = current
+}
+
+// add accumulates other into u
+func (u *Usage) add(other Usage) {
+	u.Requests += other.Requests
+	u.InputTokens += other.InputTokens
+	u.CachedInputTokens += other.CachedInputTokens
+	u.OutputTokens += other.OutputTokens
+	u.TotalTokens += other.TotalTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.Cost += other.Cost
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestScoped(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.Pricing = map[string]ModelPricing{"gpt-4o": {InputPerMillion: 1e6, OutputPerMillion: 2e6}}
+	})
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+
+	search := plugin.Scoped("team-search", ScopeOptions{
+		DefaultConfig: map[string]any{"temperature": 0.1, "maxOutputTokens": 50},
+		RateLimit:     &RateLimit{RequestsPerMinute: 2},
+		Attribution:   Attribution{Team: "search"},
+	})
+	support := plugin.Scoped("team-support", ScopeOptions{})
+	search.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat", DefaultConfig: map[string]any{"temperature": 0.3}}, nil)
+	support.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat"}, nil)
+
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(search.Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	// The model's defaults take precedence over the scope's
+	if _, ok := bodies[0]["user"]; bodies[0]["temperature"] != 0.3 || bodies[0]["max_tokens"] != float64(50) || ok {
+		t.Fatalf("request = %v", bodies[0])
+	}
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(support.Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, ok := bodies[1]["temperature"]; ok {
+		t.Fatalf("request = %v, want no scope defaults", bodies[1])
+	}
+
+	want := Usage{Requests: 1, InputTokens: 5, OutputTokens: 1, TotalTokens: 6, Cost: 7}
+	if got := search.Usage(); got != want {
+		t.Fatalf("search Usage() = %+v, want %+v", got, want)
+	}
+	if got := support.UsageByModel()["gpt-4o"]; got != want {
+		t.Fatalf("support UsageByModel() = %+v, want %+v", got, want)
+	}
+
+	// The scope's rate limit applies across its requests
+	if _, err := genkit.Generate(ctx, g, ai.WithModel(search.Model(g, "gpt-4o")), ai.WithPrompt("hi")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	limited, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := genkit.Generate(limited, g, ai.WithModel(search.Model(g, "gpt-4o")), ai.WithPrompt("hi")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Generate() over the rate limit error = %v", err)
+	}
+	if len(bodies) != 3 || search.Usage().Requests != 2 {
+		t.Fatalf("sent %d requests, search usage %+v", len(bodies), search.Usage())
+	}
+}
+
+func TestScopeUsageDetails(t *testing.T) {
+	plugin := newTestPlugin(t, nil)
+	scope := plugin.Scoped("team-agents", ScopeOptions{})
+	scope.record("o3-mini", nil, &ai.GenerationUsage{InputTokens: 2048, CachedContentTokens: 1024, OutputTokens: 500, ThoughtsTokens: 400, TotalTokens: 2548}, nil)
+	scope.record("o3-mini", nil, &ai.GenerationUsage{InputTokens: 2048, OutputTokens: 100, TotalTokens: 2148}, nil)
+
+	got := scope.Usage()
+	want := Usage{Requests: 2, InputTokens: 4096, CachedInputTokens: 1024, OutputTokens: 600, TotalTokens: 4696, ReasoningTokens: 400}
+	if got != want {
+		t.Fatalf("Usage() = %+v, want %+v", got, want)
+	}
+	if rate := got.CacheHitRate(); rate != 0.25 {
+		t.Fatalf("CacheHitRate() = %v, want 0.25", rate)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// SpeechSentimentOptions configures AnalyzeSpeechSentiment.
+type SpeechSentimentOptions struct {
+	TranscriptionModel string   // Transcription deployment, e.g. "gpt-4o-transcribe-diarize" or "whisper" (required)
+	Classifier         ai.Model // Chat model that classifies each segment (required)
+	Audio              []byte   // Audio file content (required)
+	Filename           string   // Filename with extension, used for format detection. Defaults to "audio.mp3"
+	Language           string   // Optional language code (e.g., "en")
+}
+
+// SentimentSegment is the classification of one transcript segment.
+type SentimentSegment struct {
+	TranscriptSegment
+	Sentiment  string  `json:"sentiment"`  // "positive", "neutral" or "negative"
+	Emotion    string  `json:"emotion"`    // One of the emotions listed in SpeechEmotions
+	Confidence float64 `json:"confidence"` // Classifier confidence from 0 to 1
+}
+
+// SentimentTimeline is the result of AnalyzeSpeechSentiment, ordered by time.
+type SentimentTimeline struct {
+	Transcript string             `json:"transcript"`
+	Duration   float64            `json:"duration,omitempty"`
+	Segments   []SentimentSegment `json:"segments"`
+}
+
+// SpeechEmotions lists the emotions the classifier chooses from.
+var SpeechEmotions = []string{"neutral", "joy", "gratitude", "surprise", "confusion", "frustration", "anger", "sadness", "fear"}
+
+// sentimentOutput is the structured output requested from the classifier
+type sentimentOutput struct {
+	Segments []struct {
+		Index      int     `json:"index"`
+		Sentiment  string  `json:"sentiment" jsonschema:"enum=positive,enum=neutral,enum=negative"`
+		Emotion    string  `json:"emotion"`
+		Confidence float64 `json:"confidence"`
+	} `json:"segments"`
+}
+
+// AnalyzeSpeechSentiment transcribes audio and classifies the sentiment and emotion of every
+// segment, returning a timeline. Diarization deployments (names containing "diarize") add
+// speaker labels; Whisper deployments return timed segments without speakers; other
+// transcription models produce a single segment for the whole recording.
+func (a *AzureAIFoundry) AnalyzeSpeechSentiment(ctx context.Context, g *genkit.Genkit, opts SpeechSentimentOptions) (*SentimentTimeline, error) {
+	if opts.TranscriptionModel == "" || opts.Classifier == nil {
+		return nil, fmt.Errorf("speech sentiment analysis requires a transcription model and a classifier")
+	}
+
+	transcript, err := a.transcribeSegments(ctx, opts.TranscriptionModel, &STTRequest{
+		Audio:    opts.Audio,
+		Filename: opts.Filename,
+		Language: opts.Language,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &SentimentTimeline{Transcript: transcript.Text, Duration: transcript.Duration}
+	segments := transcript.Segments
+	if len(segments) == 0 {
+		return timeline, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Classify the sentiment (positive, neutral or negative) and the dominant emotion of each ")
+	fmt.Fprintf(&prompt, "numbered transcript segment below. Choose the emotion from: %s. ", strings.Join(SpeechEmotions, ", "))
+	prompt.WriteString("Give a confidence from 0 to 1 and return one entry per segment with its index.\n\n")
+	for i, segment := range segments {
+		if segment.Speaker != "" {
+			fmt.Fprintf(&prompt, "%d. [%s] %s\n", i, segment.Speaker, strings.TrimSpace(segment.Text))
+		} else {
+			fmt.Fprintf(&prompt, "%d. %s\n", i, strings.TrimSpace(segment.Text))
+		}
+	}
+
+	out, _, err := genkit.GenerateData[sentimentOutput](ctx, g,
+		ai.WithModel(opts.Classifier),
+		ai.WithMessages(ai.NewUserTextMessage(prompt.String())),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sentiment classification failed: %w", err)
+	}
+
+	timeline.Segments = make([]SentimentSegment, len(segments))
+	for i, segment := range segments {
+		timeline.Segments[i] = SentimentSegment{TranscriptSegment: segment, Sentiment: "neutral", Emotion: "neutral"}
+	}
+	for _, result := range out.Segments {
+		if result.Index < 0 || result.Index >= len(segments) {
+			continue
+		}
+		classified := &timeline.Segments[result.Index]
+		classified.Sentiment = result.Sentiment
+		classified.Emotion = result.Emotion
+		classified.Confidence = result.Confidence
+	}
+
+	return timeline, nil
+}
+
+// transcribeSegments transcribes audio in the most detailed format the deployment supports:
+// diarized segments for diarization models, timed segments for Whisper and a single segment
+// covering the whole recording otherwise
+func (a *AzureAIFoundry) transcribeSegments(ctx context.Context, modelName string, req *STTRequest) (*STTResponse, error) {
+	req.ResponseFormat = "json"
+	modelLower := strings.ToLower(modelName)
+	switch {
+	case strings.Contains(modelLower, "diarize"):
+		req.ResponseFormat = "diarized_json"
+		req.AutoChunking = true
+	case strings.Contains(modelLower, "whisper"):
+		req.ResponseFormat = "verbose_json"
+	}
+
+	transcript, err := a.transcribeAudioInternal(ctx, modelName, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(transcript.Segments) == 0 && strings.TrimSpace(transcript.Text) != "" {
+		transcript.Segments = []TranscriptSegment{{End: transcript.Duration, Text: transcript.Text}}
+	}
+	return transcript, nil
+}
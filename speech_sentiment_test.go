@@ -0,0 +1,83 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestAnalyzeSpeechSentiment(t *testing.T) {
+	var form map[string][]string
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+		}
+		form = r.MultipartForm.Value
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"text":"My order is late. I'm sorry, let me fix that.","duration":6.5,"segments":[
+			{"id":"seg_0","type":"transcript.text.segment","start":0,"end":2.1,"text":"My order is late.","speaker":"A"},
+			{"id":"seg_1","type":"transcript.text.segment","start":2.4,"end":6.5,"text":"I'm sorry, let me fix that.","speaker":"B"}
+		]}`)
+	})
+
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	var classifierPrompt string
+	classifier := genkit.DefineModel(g, "test/classifier", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true}},
+		func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			classifierPrompt = req.Messages[0].Text()
+			return &ai.ModelResponse{Message: ai.NewModelTextMessage(`{"segments":[
+				{"index":0,"sentiment":"negative","emotion":"frustration","confidence":0.9},
+				{"index":1,"sentiment":"positive","emotion":"gratitude","confidence":0.7}
+			]}`)}, nil
+		})
+
+	timeline, err := plugin.AnalyzeSpeechSentiment(ctx, g, SpeechSentimentOptions{
+		TranscriptionModel: "gpt-4o-transcribe-diarize",
+		Classifier:         classifier,
+		Audio:              []byte("audio"),
+		Filename:           "call.wav",
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeSpeechSentiment() error = %v", err)
+	}
+
+	if form["response_format"][0] != "diarized_json" || form["chunking_strategy"][0] != "auto" {
+		t.Fatalf("transcription form = %v", form)
+	}
+	if !strings.Contains(classifierPrompt, "1. [B] I'm sorry, let me fix that.") {
+		t.Fatalf("classifier prompt = %q", classifierPrompt)
+	}
+	if len(timeline.Segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(timeline.Segments))
+	}
+	first, second := timeline.Segments[0], timeline.Segments[1]
+	if first.Speaker != "A" || first.Sentiment != "negative" || first.Emotion != "frustration" || first.End != 2.1 {
+		t.Fatalf("first segment = %+v", first)
+	}
+	if second.Speaker != "B" || second.Sentiment != "positive" || second.Start != 2.4 {
+		t.Fatalf("second segment = %+v", second)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// standbyProbeTimeout bounds a single standby health probe
+const standbyProbeTimeout = 10 * time.Second
+
+// DeploymentStatus is the health of a standby deployment, as last found by a probe.
+type DeploymentStatus struct {
+	Name      string
+	Healthy   bool
+	CheckedAt time.Time     // When the last probe finished
+	Latency   time.Duration // Response time of the last probe
+	Err       string        // Why the last probe failed
+}
+
+// standbyState tracks the probed health of standby deployments
+type standbyState struct {
+	once   sync.Once
+	mu     sync.Mutex
+	status map[string]DeploymentStatus // By deployment name
+}
+
+// StandbyHealth returns the health of the standby deployments of failover groups with
+// ProbeStandby, sorted by name. Deployments not probed yet are left out.
+func (a *AzureAIFoundry) StandbyHealth() []DeploymentStatus {
+	a.standby.mu.Lock()
+	defer a.standby.mu.Unlock()
+	statuses := make([]DeploymentStatus, 0, len(a.standby.status))
+	for _, status := range a.standby.status {
+		statuses = append(statuses, status)
+	}
+	slices.SortFunc(statuses, func(x, y DeploymentStatus) int { return strings.Compare(x.Name, y.Name) })
+	return statuses
+}
+
+// startStandbyProbes starts probing standby deployments in the background the first time a
+// registry has a failover group with ProbeStandby. The probes stop when the plugin is closed.
+func (a *AzureAIFoundry) startStandbyProbes() {
+	a.standby.once.Do(func() {
+		interval := a.StandbyProbeInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		go func() { _ = a.poll(context.Background(), interval, a.probeStandbys) }()
+	})
+}
+
+// probeStandbys probes the standby deployments of the current registry's failover groups
+// with ProbeStandby, concurrently
+func (a *AzureAIFoundry) probeStandbys() {
+	var wg sync.WaitGroup
+	for _, name := range a.standbyDeployments() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.probeStandby(name)
+		}()
+	}
+	wg.Wait()
+}
+
+// standbyDeployments returns the deployments behind the members after the first of every
+// failover group with ProbeStandby, expanding load-balanced groups
+func (a *AzureAIFoundry) standbyDeployments() []string {
+	a.registry.mu.RLock()
+	defer a.registry.mu.RUnlock()
+	config := a.registry.config
+	if config == nil {
+		return nil
+	}
+
+	var names []string
+	for _, group := range config.FailoverGroups {
+		if !group.ProbeStandby {
+			continue
+		}
+		for _, member := range group.Models[1:] {
+			for _, name := range config.deployments(member) {
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// deployments returns the deployments behind a model name: the members of a load-balanced
+// group, or the name itself
+func (c *RegistryConfig) deployments(name string) []string {
+	for _, group := range c.LoadBalancedGroups {
+		if group.Name == name {
+			names := make([]string, 0, len(group.Models))
+			for _, member := range group.Models {
+				names = append(names, member.Name)
+			}
+			return names
+		}
+	}
+	return []string{name}
+}
+
+// probeStandby sends a one-token chat request to a deployment and records whether it answered
+func (a *AzureAIFoundry) probeStandby(name string) {
+	ctx, end, err := a.lifecycle.track(context.Background())
+	if err != nil {
+		return
+	}
+	defer end()
+	ctx, cancel := context.WithTimeout(ctx, standbyProbeTimeout)
+	defer cancel()
+	// Close stops probes at once instead of waiting for them
+	defer context.AfterFunc(a.lifecycle.stop, cancel)()
+
+	params := a.buildChatCompletionParams(&ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("ping")},
+		Config:   map[string]any{"maxOutputTokens": 1},
+	}, name)
+	start := time.Now()
+	// A failing probe is retried on the next round instead
+	_, err = a.client.Chat.Completions.New(ctx, params, option.WithMaxRetries(0))
+	if a.lifecycle.stop.Err() != nil {
+		return
+	}
+	status := DeploymentStatus{Name: name, Healthy: err == nil, CheckedAt: time.Now(), Latency: time.Since(start)}
+	if err != nil {
+		status.Err = a.redactError(err).Error()
+	}
+
+	a.standby.mu.Lock()
+	if a.standby.status == nil {
+		a.standby.status = make(map[string]DeploymentStatus)
+	}
+	previous, probed := a.standby.status[name]
+	a.standby.status[name] = status
+	a.standby.mu.Unlock()
+
+	switch {
+	case !status.Healthy && (!probed || previous.Healthy):
+		logger.FromContext(ctx).Warn("azureaifoundry: standby deployment failed its health probe", "model", name, "err", status.Err)
+		a.emit(ctx, Event{Type: EventStandbyUnhealthy, Model: name, Operation: OperationChat, Err: err})
+	case status.Healthy && probed && !previous.Healthy:
+		a.emit(ctx, Event{Type: EventStandbyRecovered, Model: name, Operation: OperationChat})
+	}
+}
+
+// standbyOrder returns the members of a failover group with the primary first, then the
+// standbys whose last probe succeeded or that were not probed yet, then those found failing.
+// Failing standbys are kept as a last resort.
+func (a *AzureAIFoundry) standbyOrder(config *RegistryConfig, members []string) []string {
+	a.standby.mu.Lock()
+	defer a.standby.mu.Unlock()
+
+	healthy := []string{members[0]}
+	var failing []string
+	for _, member := range members[1:] {
+		up := false
+		for _, name := range config.deployments(member) {
+			if status, probed := a.standby.status[name]; !probed || status.Healthy {
+				up = true
+				break
+			}
+		}
+		if up {
+			healthy = append(healthy, member)
+		} else {
+			failing = append(failing, member)
+		}
+	}
+	return append(healthy, failing...)
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestProbeStandby(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string // Deployments of requests that are not probes
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		if body.Messages[0].Content != "ping" {
+			mu.Lock()
+			requested = append(requested, body.Model)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if body.Model == "gpt-4o-eastus" || body.Model == "gpt-4o-westeurope" {
+			w.Header().Set("x-should-retry", "false")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, `{"error":{"code":"500","message":"Internal server error"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, chatCompletionJSON)
+	}))
+	defer server.Close()
+
+	registry, err := LoadModelsFromConfig([]byte(`
+models:
+  - {name: gpt-4o-eastus}
+  - {name: gpt-4o-westeurope}
+  - {name: gpt-4o-swedencentral}
+  - {name: gpt-4o-westus}
+loadBalancedGroups:
+  - {name: gpt-4o-us, models: [{name: gpt-4o-westus}]}
+failoverGroups:
+  - name: gpt-4o
+    models: [gpt-4o-eastus, gpt-4o-westeurope, gpt-4o-swedencentral]
+    probeStandby: true
+  - name: gpt-4o-unprobed
+    models: [gpt-4o-eastus, gpt-4o-westeurope, gpt-4o-us]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test", Registry: registry, StandbyProbeInterval: time.Hour}
+	var events []Event
+	plugin.Subscribe(EventSubscriberFunc(func(ctx context.Context, event Event) {
+		if event.Type == EventStandbyUnhealthy || event.Type == EventStandbyRecovered {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		}
+	}))
+	g := genkit.Init(context.Background(), genkit.WithPlugins(plugin))
+	defer plugin.Close(context.Background())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(plugin.StandbyHealth()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("StandbyHealth() = %+v, want both standbys probed", plugin.StandbyHealth())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	health := plugin.StandbyHealth()
+	if health[0].Name != "gpt-4o-swedencentral" || !health[0].Healthy ||
+		health[1].Name != "gpt-4o-westeurope" || health[1].Healthy || health[1].Err == "" {
+		t.Fatalf("StandbyHealth() = %+v", health)
+	}
+	mu.Lock()
+	if len(events) != 1 || events[0].Type != EventStandbyUnhealthy || events[0].Model != "gpt-4o-westeurope" {
+		t.Fatalf("events = %+v", events)
+	}
+	mu.Unlock()
+
+	tests := []struct {
+		model string
+		want  []string
+	}{
+		{"gpt-4o", []string{"gpt-4o-eastus", "gpt-4o-swedencentral"}},
+		{"gpt-4o-unprobed", []string{"gpt-4o-eastus", "gpt-4o-westeurope", "gpt-4o-westus"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			mu.Lock()
+			requested = nil
+			mu.Unlock()
+			if _, err := genkit.Generate(context.Background(), g, ai.WithModelName("azureaifoundry/"+tt.model), ai.WithPrompt("hi")); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(requested, tt.want) {
+				t.Fatalf("requested = %v, want %v", requested, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core/logger"
+	"github.com/openai/openai-go/v3"
+)
+
+// resumeInstruction follows the text of a broken stream when it is replayed, because chat
+// completions answer anew instead of continuing a trailing assistant message
+const resumeInstruction = "Your previous reply was cut off. Continue it exactly where it ends. " +
+	"Do not repeat any of it, do not start over and do not mention the interruption."
+
+// maxResumeOverlap bounds how much of the end of a broken answer a resumed answer is checked
+// for repeating
+const maxResumeOverlap = 200
+
+// generateTextStreamResumable streams a chat completion and, when the stream breaks with a
+// transient network error, replays the request with the text generated so far and an
+// instruction to continue it, up to StreamResumeAttempts times. Text the resumed answer
+// repeats is dropped, so the caller sees one stream and one response holding the whole
+// answer. Streams that broke after sending tool calls or reasoning are not resumed.
+func (a *AzureAIFoundry) generateTextStreamResumable(ctx context.Context, params openai.ChatCompletionNewParams, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+	var generated strings.Builder // Answer text streamed by the failed attempts
+	var attemptText strings.Builder
+	var overlap *resumeOverlap
+	var cbErr error
+	tracked := func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+		if overlap != nil {
+			if chunk = overlap.filter(chunk); chunk == nil {
+				return nil
+			}
+		}
+		for _, part := range chunk.Content {
+			if part.IsText() {
+				attemptText.WriteString(part.Text)
+			}
+		}
+		cbErr = cb(ctx, chunk)
+		return cbErr
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptParams := params
+		overlap = nil
+		if generated.Len() > 0 {
+			attemptParams.Messages = append(slices.Clone(params.Messages),
+				openai.AssistantMessage(generated.String()), openai.UserMessage(resumeInstruction))
+			overlap = newResumeOverlap(generated.String())
+		}
+		attemptText.Reset()
+		resp, err := a.generateTextStream(ctx, attemptParams, input, tracked)
+		if err == nil && overlap != nil {
+			if text := overlap.flush(); text != "" {
+				cbErr = cb(ctx, &ai.ModelResponseChunk{Role: ai.RoleModel, Content: []*ai.Part{ai.NewTextPart(text)}})
+				err = cbErr
+			}
+		}
+		if err == nil {
+			if attempt > 0 {
+				if overlap != nil {
+					trimText(resp, overlap.trimmed)
+				}
+				prependText(resp, generated.String())
+				resp.Custom = withCustomValue(resp.Custom, "streamResumes", attempt)
+			}
+			return resp, nil
+		}
+		generated.WriteString(attemptText.String())
+		if attempt >= a.StreamResumeAttempts || cbErr != nil || !isBrokenStreamError(ctx, err) {
+			return nil, err
+		}
+
+		logger.FromContext(ctx).Warn("azureaifoundry: resuming broken stream",
+			"model", params.Model, "attempt", attempt+1, "generated", generated.Len(), "err", a.redactError(err))
+		a.emit(ctx, Event{
+			Type:      EventStreamResumed,
+			Model:     string(params.Model),
+			Operation: OperationChat,
+			Streaming: true,
+			Attempt:   attempt + 1,
+			Err:       err,
+		})
+	}
+}
+
+// resumeOverlap holds back the start of a resumed answer until it can drop the text that
+// repeats the end of the answer streamed before the stream broke
+type resumeOverlap struct {
+	tail    string // End of the answer streamed so far
+	pending strings.Builder
+	done    bool
+	trimmed int // Bytes dropped from the start of the resumed answer
+}
+
+func newResumeOverlap(generated string) *resumeOverlap {
+	tail := generated
+	if len(tail) > maxResumeOverlap {
+		tail = tail[len(tail)-maxResumeOverlap:]
+		for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+			tail = tail[1:]
+		}
+	}
+	return &resumeOverlap{tail: tail, done: tail == ""}
+}
+
+// filter returns the chunk without the text held back, or nil when nothing is left to stream
+func (o *resumeOverlap) filter(chunk *ai.ModelResponseChunk) *ai.ModelResponseChunk {
+	if o.done {
+		return chunk
+	}
+	filtered := *chunk
+	filtered.Content = nil
+	for _, part := range chunk.Content {
+		if part.IsText() {
+			if text := o.write(part.Text); text != "" {
+				textPart := *part
+				textPart.Text = text
+				filtered.Content = append(filtered.Content, &textPart)
+			}
+			continue
+		}
+		// Other parts end the text that may repeat the answer
+		if text := o.flush(); text != "" {
+			filtered.Content = append(filtered.Content, ai.NewTextPart(text))
+		}
+		filtered.Content = append(filtered.Content, part)
+	}
+	if len(filtered.Content) == 0 {
+		return nil
+	}
+	return &filtered
+}
+
+// write holds back resumed text until there is as much as the tail, and returns what can be
+// streamed
+func (o *resumeOverlap) write(text string) string {
+	if o.done {
+		return text
+	}
+	o.pending.WriteString(text)
+	if o.pending.Len() < len(o.tail) {
+		return ""
+	}
+	return o.flush()
+}
+
+// flush drops the longest start of the held back text that the tail ends with, and returns
+// the rest
+func (o *resumeOverlap) flush() string {
+	if o.done {
+		return ""
+	}
+	o.done = true
+	pending := o.pending.String()
+	for k := min(len(pending), len(o.tail)); k > 0; k-- {
+		if strings.HasSuffix(o.tail, pending[:k]) {
+			o.trimmed = k
+			return pending[k:]
+		}
+	}
+	return pending
+}
+
+// trimText drops the first n bytes of a response's answer
+func trimText(resp *ai.ModelResponse, n int) {
+	if resp.Message == nil || n == 0 {
+		return
+	}
+	for _, part := range resp.Message.Content {
+		if part.IsText() {
+			part.Text = part.Text[min(n, len(part.Text)):]
+			return
+		}
+	}
+}
+
+// prependText adds the text of earlier attempts to the start of a response's answer
+func prependText(resp *ai.ModelResponse, text string) {
+	if resp.Message == nil || text == "" {
+		return
+	}
+	for _, part := range resp.Message.Content {
+		if part.IsText() {
+			part.Text = text + part.Text
+			return
+		}
+	}
+	// The resumed attempt only produced reasoning or tool calls; the text goes after the reasoning
+	at := 0
+	for at < len(resp.Message.Content) && resp.Message.Content[at].IsReasoning() {
+		at++
+	}
+	resp.Message.Content = slices.Insert(resp.Message.Content, at, ai.NewTextPart(text))
+}
+
+// partialStreamError is a stream that broke after it sent tool calls or reasoning, which a
+// replay cannot continue
+type partialStreamError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *partialStreamError) Error() string {
+	return "broke after tool calls or reasoning: " + e.err.Error()
+}
+
+// Unwrap returns the network error.
+func (e *partialStreamError) Unwrap() error {
+	return e.err
+}
+
+// isBrokenStreamError reports whether a stream failed because the connection broke, as
+// opposed to an error returned by Azure or a cancelled request, and can be resumed
+func isBrokenStreamError(ctx context.Context, err error) bool {
+	var partialErr *partialStreamError
+	if ctx.Err() != nil || errors.As(err, &partialErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// brokenStreamHandler streams the given content deltas for each request in turn. Deltas
+// starting with "{" are sent as raw delta objects. Requests with broken set drop the
+// connection after their deltas instead of finishing the stream.
+func brokenStreamHandler(t *testing.T, bodies *[]map[string]any, attempts []struct {
+	deltas []string
+	broken bool
+}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		*bodies = append(*bodies, body)
+		attempt := attempts[len(*bodies)-1]
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range attempt.deltas {
+			if !strings.HasPrefix(delta, "{") {
+				delta = fmt.Sprintf("{\"content\":%q}", delta)
+			}
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+		}
+		if attempt.broken {
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack() error = %v", err)
+				return
+			}
+			// Closing mid-chunk leaves the client with an unexpected EOF
+			_ = conn.Close()
+			return
+		}
+		_, _ = io.WriteString(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}
+}
+
+func TestStreamResume(t *testing.T) {
+	type attempt = struct {
+		deltas []string
+		broken bool
+	}
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		attempts     []attempt
+		wantText     string // Empty means the request fails
+		wantPrefixes []any  // Assistant prefix sent with each request, nil for none
+	}{
+		{
+			name:         "resumed once",
+			maxAttempts:  2,
+			attempts:     []attempt{{[]string{"The answer ", "is "}, true}, {[]string{"42."}, false}},
+			wantText:     "The answer is 42.",
+			wantPrefixes: []any{nil, "The answer is "},
+		},
+		{
+			name:         "resumed twice",
+			maxAttempts:  2,
+			attempts:     []attempt{{[]string{"One, "}, true}, {[]string{"two, "}, true}, {[]string{"three."}, false}},
+			wantText:     "One, two, three.",
+			wantPrefixes: []any{nil, "One, ", "One, two, "},
+		},
+		{
+			name:         "repeated text dropped",
+			maxAttempts:  1,
+			attempts:     []attempt{{[]string{"The answer ", "is "}, true}, {[]string{"answer is ", "42."}, false}},
+			wantText:     "The answer is 42.",
+			wantPrefixes: []any{nil, "The answer is "},
+		},
+		{
+			name:         "restarted answer kept",
+			maxAttempts:  1,
+			attempts:     []attempt{{[]string{"One, "}, true}, {[]string{"Sorry, ", "two, three."}, false}},
+			wantText:     "One, Sorry, two, three.",
+			wantPrefixes: []any{nil, "One, "},
+		},
+		{
+			name:         "tool call not resumed",
+			maxAttempts:  2,
+			attempts:     []attempt{{[]string{"Checking. ", `{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"ci"}}]}`}, true}},
+			wantPrefixes: []any{nil},
+		},
+		{
+			name:         "attempts exhausted",
+			maxAttempts:  1,
+			attempts:     []attempt{{[]string{"One, "}, true}, {[]string{"two, "}, true}},
+			wantPrefixes: []any{nil, "One, "},
+		},
+		{
+			name:         "disabled",
+			attempts:     []attempt{{[]string{"One, "}, true}},
+			wantPrefixes: []any{nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]any
+			var events []Event
+			plugin := newTestPlugin(t, brokenStreamHandler(t, &bodies, tt.attempts), func(a *AzureAIFoundry) {
+				a.StreamResumeAttempts = tt.maxAttempts
+				a.Subscribers = []EventSubscriber{EventSubscriberFunc(func(_ context.Context, event Event) {
+					if event.Type == EventStreamResumed {
+						events = append(events, event)
+					}
+				})}
+			})
+
+			var streamed string
+			resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("Count to three")},
+			}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+				streamed += chunk.Text()
+				return nil
+			})
+
+			if len(bodies) != len(tt.wantPrefixes) {
+				t.Fatalf("sent %d requests, want %d", len(bodies), len(tt.wantPrefixes))
+			}
+			for i, body := range bodies {
+				messages := body["messages"].([]any)
+				var prefix any
+				if last := messages[len(messages)-1].(map[string]any); last["role"] == "user" && last["content"] == resumeInstruction {
+					prefix = messages[len(messages)-2].(map[string]any)["content"]
+				}
+				if prefix != tt.wantPrefixes[i] {
+					t.Fatalf("request %d assistant prefix = %v, want %v", i, prefix, tt.wantPrefixes[i])
+				}
+			}
+			if len(events) != len(tt.wantPrefixes)-1 {
+				t.Fatalf("got %d resume events, want %d", len(events), len(tt.wantPrefixes)-1)
+			}
+
+			if tt.wantText == "" {
+				if err == nil {
+					t.Fatal("generateText() succeeded, want the stream error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generateText() error = %v", err)
+			}
+			if streamed != tt.wantText || resp.Text() != tt.wantText {
+				t.Fatalf("streamed = %q, Text() = %q, want %q", streamed, resp.Text(), tt.wantText)
+			}
+			if resp.Custom.(map[string]any)["streamResumes"] != len(tt.attempts)-1 {
+				t.Fatalf("Custom = %v", resp.Custom)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// StreamTransformer rewrites the answer text of a model response before it reaches the
+// caller, such as to mask words or enforce stop sequences. Transform is called with each
+// streamed text delta and may hold text back for a later call; Flush returns the text still
+// held back when the response ends. A transformer is used for a single response.
+type StreamTransformer interface {
+	Transform(text string) string
+	Flush() string
+}
+
+// StreamTransformerFactory creates a StreamTransformer for each response
+type StreamTransformerFactory func() StreamTransformer
+
+// transformerChain applies transformers in order, each to the output of the previous one
+type transformerChain []StreamTransformer
+
+// newTransformerChain creates the transformers of a model for a response
+func newTransformerChain(factories []StreamTransformerFactory) transformerChain {
+	chain := make(transformerChain, len(factories))
+	for i, factory := range factories {
+		chain[i] = factory()
+	}
+	return chain
+}
+
+// Transform implements StreamTransformer.
+func (c transformerChain) Transform(text string) string {
+	for _, t := range c {
+		text = t.Transform(text)
+	}
+	return text
+}
+
+// Flush implements StreamTransformer. Text flushed by a transformer goes through the rest of
+// the chain before those are flushed.
+func (c transformerChain) Flush() string {
+	var text string
+	for _, t := range c {
+		text = t.Transform(text) + t.Flush()
+	}
+	return text
+}
+
+// withStreamTransformers wraps a model function so its answer text goes through the
+// transformers, both in streamed chunks and in the final response
+func withStreamTransformers(factories []StreamTransformerFactory, fn ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		return transformResponse(ctx, newTransformerChain(factories), cb, func(cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			return fn(ctx, input, cb)
+		})
+	}
+}
+
+// transformResponse runs a model call with its answer text going through a transformer
+func transformResponse(ctx context.Context, transformer StreamTransformer, cb ai.ModelStreamCallback, call func(ai.ModelStreamCallback) (*ai.ModelResponse, error)) (*ai.ModelResponse, error) {
+	var streamed strings.Builder
+	transformed := cb
+	if cb != nil {
+		transformed = func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+			content := make([]*ai.Part, 0, len(chunk.Content))
+			for _, part := range chunk.Content {
+				if part.IsText() {
+					text := transformer.Transform(part.Text)
+					streamed.WriteString(text)
+					if text == "" {
+						continue
+					}
+					part = ai.NewTextPart(text)
+				}
+				content = append(content, part)
+			}
+			if len(content) == 0 && len(chunk.Content) > 0 && chunk.Custom == nil {
+				return nil
+			}
+			transformedChunk := *chunk
+			transformedChunk.Content = content
+			return cb(ctx, &transformedChunk)
+		}
+	}
+
+	resp, err := call(transformed)
+	if err != nil || resp == nil || resp.Message == nil {
+		return resp, err
+	}
+
+	var text string
+	if cb != nil {
+		tail := transformer.Flush()
+		if tail != "" {
+			if err := cb(ctx, &ai.ModelResponseChunk{Role: ai.RoleModel, Content: []*ai.Part{ai.NewTextPart(tail)}}); err != nil {
+				return nil, err
+			}
+		}
+		text = streamed.String() + tail
+	} else {
+		for _, part := range resp.Message.Content {
+			if part.IsText() {
+				text += transformer.Transform(part.Text)
+			}
+		}
+		text += transformer.Flush()
+	}
+	resp.Message.Content = replaceText(resp.Message.Content, text)
+	return resp, nil
+}
+
+// replaceText replaces the text parts of a message with a single text part, kept at the
+// position of the first one
+func replaceText(content []*ai.Part, text string) []*ai.Part {
+	replaced := make([]*ai.Part, 0, len(content)+1)
+	done := false
+	for _, part := range content {
+		if !part.IsText() {
+			replaced = append(replaced, part)
+			continue
+		}
+		if !done && text != "" {
+			replaced = append(replaced, ai.NewTextPart(text))
+		}
+		done = true
+	}
+	if !done && text != "" {
+		replaced = append(replaced, ai.NewTextPart(text))
+	}
+	return replaced
+}
+
+// stopSequenceTransformer ends the text at the first stop sequence
+type stopSequenceTransformer struct {
+	sequences []string
+	pending   string
+	stopped   bool
+}
+
+// NewStopSequenceTransformer returns a factory for transformers that cut the text at the first
+// of the stop sequences, which is not included. Unlike the stopSequences config option, it
+// has no limit on the number of sequences and works with models that ignore stop sequences.
+func NewStopSequenceTransformer(sequences ...string) StreamTransformerFactory {
+	return func() StreamTransformer {
+		return &stopSequenceTransformer{sequences: sequences}
+	}
+}
+
+// Transform implements StreamTransformer.
+func (s *stopSequenceTransformer) Transform(text string) string {
+	if s.stopped {
+		return ""
+	}
+	buffer := s.pending + text
+	s.pending = ""
+
+	end := -1
+	for _, seq := range s.sequences {
+		if i := strings.Index(buffer, seq); seq != "" && i != -1 && (end == -1 || i < end) {
+			end = i
+		}
+	}
+	if end != -1 {
+		s.stopped = true
+		return buffer[:end]
+	}
+
+	// Hold back text that might be the start of a stop sequence
+	keep := 0
+	for _, seq := range s.sequences {
+		keep = max(keep, partialTagLength(buffer, seq))
+	}
+	s.pending = buffer[len(buffer)-keep:]
+	return buffer[:len(buffer)-keep]
+}
+
+// Flush implements StreamTransformer.
+func (s *stopSequenceTransformer) Flush() string {
+	text := s.pending
+	s.pending = ""
+	return text
+}
+
+// wordMaskTransformer replaces words with asterisks
+type wordMaskTransformer struct {
+	pattern *regexp.Regexp
+	pending string
+}
+
+// NewWordMaskTransformer returns a factory for transformers that replace whole words, matched
+// case-insensitively, with asterisks of the same length, such as to mask profanity. Words
+// split across chunks are held back until they are complete.
+func NewWordMaskTransformer(words ...string) StreamTransformerFactory {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	var pattern *regexp.Regexp
+	if len(quoted) > 0 {
+		pattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	}
+	return func() StreamTransformer {
+		return &wordMaskTransformer{pattern: pattern}
+	}
+}
+
+// Transform implements StreamTransformer.
+func (w *wordMaskTransformer) Transform(text string) string {
+	if w.pattern == nil {
+		return text
+	}
+	buffer := w.pending + text
+
+	// Hold back the trailing word, which may continue in the next chunk
+	end := strings.LastIndexFunc(buffer, func(r rune) bool { return !isWordRune(r) })
+	if end == -1 {
+		w.pending = buffer
+		return ""
+	}
+	_, size := utf8.DecodeRuneInString(buffer[end:])
+	w.pending = buffer[end+size:]
+	return w.mask(buffer[:end+size])
+}
+
+// Flush implements StreamTransformer.
+func (w *wordMaskTransformer) Flush() string {
+	text := w.pending
+	w.pending = ""
+	if w.pattern == nil {
+		return text
+	}
+	return w.mask(text)
+}
+
+// mask replaces the matched words in complete text
+func (w *wordMaskTransformer) mask(text string) string {
+	return w.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+}
+
+// isWordRune reports whether a rune is part of a word, matching \b in regular expressions
+func isWordRune(r rune) bool {
+	return r == '_' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
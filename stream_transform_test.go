@@ -0,0 +1,104 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestStreamTransformers(t *testing.T) {
+	tests := []struct {
+		name      string
+		factories []StreamTransformerFactory
+		chunks    []string
+		want      string
+	}{
+		{"stop sequence", []StreamTransformerFactory{NewStopSequenceTransformer("END")}, []string{"one two ", "E", "ND three"}, "one two "},
+		{"earliest stop sequence", []StreamTransformerFactory{NewStopSequenceTransformer("##", "\n\n")}, []string{"a\n", "\nb##c"}, "a"},
+		{"partial stop sequence is released", []StreamTransformerFactory{NewStopSequenceTransformer("END")}, []string{"the E", "nd"}, "the End"},
+		{"masked words", []StreamTransformerFactory{NewWordMaskTransformer("darn")}, []string{"Oh da", "rn it, DARN", "!"}, "Oh **** it, ****!"},
+		{"partial words are not masked", []StreamTransformerFactory{NewWordMaskTransformer("darn")}, []string{"darned ", "undarn"}, "darned undarn"},
+		{"chained", []StreamTransformerFactory{NewWordMaskTransformer("darn"), NewStopSequenceTransformer("STOP")}, []string{"darn", " STOP darn"}, "**** "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newTransformerChain(tt.factories)
+			var got strings.Builder
+			for _, chunk := range tt.chunks {
+				got.WriteString(chain.Transform(chunk))
+			}
+			got.WriteString(chain.Flush())
+			if got.String() != tt.want {
+				t.Fatalf("transformed = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestModelStreamTransformers(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"id":"1","object":"chat.completion","choices":[{"index":0,"finish_reason":"stop",`+
+				`"message":{"role":"assistant","content":"<think>darn</think>Darn, the answer is 42. Done"}}]}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"<think>darn</think>", "Da", "rn, the answer is 42.", " Do", "ne"} {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+	_, fn := plugin.modelAction(ModelDefinition{
+		Name:               "DeepSeek-R1",
+		StreamTransformers: []StreamTransformerFactory{NewWordMaskTransformer("darn"), NewStopSequenceTransformer(" Done")},
+	}, nil)
+	request := &ai.ModelRequest{Messages: []*ai.Message{ai.NewUserTextMessage("What is the answer?")}}
+
+	var streamed strings.Builder
+	resp, err := fn(context.Background(), request, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		streamed.WriteString(chunk.Text())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("model error = %v", err)
+	}
+	if streamed.String() != "****, the answer is 42." || resp.Text() != streamed.String() {
+		t.Fatalf("streamed = %q, text = %q", streamed.String(), resp.Text())
+	}
+	// Reasoning is left as is
+	if resp.Reasoning() != "darn" {
+		t.Fatalf("Reasoning() = %q", resp.Reasoning())
+	}
+
+	resp, err = fn(context.Background(), request, nil)
+	if err != nil {
+		t.Fatalf("model error = %v", err)
+	}
+	if resp.Text() != "****, the answer is 42." {
+		t.Fatalf("Text() = %q", resp.Text())
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SynthesizeTo converts req.Input to speech with the req.Model deployment and writes the
+// audio to w as Azure sends it, without base64 encoding or buffering the whole synthesis. It
+// returns the number of bytes written. Voice defaults to "alloy", or is picked from Voices
+// when "auto", and ResponseFormat defaults to "mp3".
+//
+// When w is an http.ResponseWriter, its Content-Type is set from the response format unless
+// already set, and every chunk is flushed to the client. Once audio was written, an error can
+// no longer be sent as an HTTP status.
+func (a *AzureAIFoundry) SynthesizeTo(ctx context.Context, w io.Writer, req TTSRequest) (written int64, err error) {
+	if req.Model == "" {
+		return 0, errors.New("azureaifoundry: TTSRequest.Model is required")
+	}
+	ctx, client, end, err := a.rawClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer end()
+
+	done := a.emitRawStarted(ctx, req.Model, OperationSpeech)
+	defer func() {
+		err = a.redactError(err)
+		done(nil, err)
+	}()
+
+	switch req.Voice {
+	case "":
+		req.Voice = "alloy"
+	case autoVoice:
+		profile := a.selectVoice(req.Input, "")
+		req.Voice = profile.Voice
+		if req.Speed <= 0 {
+			req.Speed = profile.Speed
+		}
+	}
+	if req.ResponseFormat == "" {
+		req.ResponseFormat = "mp3"
+	}
+
+	resp, err := client.Audio.Speech.New(ctx, speechParams(req.Model, &req))
+	if err != nil {
+		return 0, fmt.Errorf("speech generation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var rc *http.ResponseController
+	if rw, ok := w.(http.ResponseWriter); ok {
+		if contentType := speechMIMETypes[req.ResponseFormat]; contentType != "" && rw.Header().Get("Content-Type") == "" {
+			rw.Header().Set("Content-Type", contentType)
+		}
+		rc = http.NewResponseController(rw)
+	}
+
+	buf := make([]byte, speechChunkSize)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			if rc != nil {
+				if err := flush(rc); err != nil {
+					return written, err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, fmt.Errorf("failed to read audio data: %w", readErr)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSynthesizeTo(t *testing.T) {
+	// Audio bytes that are not valid UTF-8 and span several chunks
+	audio := bytes.Repeat([]byte{0x00, 0xff, 0xfe, 0x80, 'I', 'D', '3'}, 10000)
+
+	tests := []struct {
+		name            string
+		req             TTSRequest
+		status          int
+		contentType     string // Content-Type set on the writer before the call
+		wantErr         string
+		wantVoice       string
+		wantFormat      string
+		wantContentType string
+	}{
+		{
+			name: "defaults", req: TTSRequest{Model: "tts", Input: "Hello"},
+			wantVoice: "alloy", wantFormat: "mp3", wantContentType: "audio/mpeg",
+		},
+		{
+			name: "explicit voice and format", req: TTSRequest{Model: "tts", Input: "Hello", Voice: "nova", ResponseFormat: "wav"},
+			wantVoice: "nova", wantFormat: "wav", wantContentType: "audio/wav",
+		},
+		{
+			name: "content type set by caller", req: TTSRequest{Model: "tts", Input: "Hello"}, contentType: "audio/x-custom",
+			wantVoice: "alloy", wantFormat: "mp3", wantContentType: "audio/x-custom",
+		},
+		{
+			name: "model required", req: TTSRequest{Input: "Hello"},
+			wantErr: "TTSRequest.Model is required",
+		},
+		{
+			name: "azure error", req: TTSRequest{Model: "tts", Input: "Hello"}, status: http.StatusBadRequest,
+			wantErr: "speech generation failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(data, &body)
+				if tt.status != 0 {
+					w.Header().Set("x-should-retry", "false")
+					http.Error(w, `{"error":{"message":"bad voice"}}`, tt.status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/octet-stream")
+				_, _ = w.Write(audio)
+			})
+
+			rec := httptest.NewRecorder()
+			if tt.contentType != "" {
+				rec.Header().Set("Content-Type", tt.contentType)
+			}
+			written, err := plugin.SynthesizeTo(t.Context(), rec, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SynthesizeTo() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SynthesizeTo() error = %v", err)
+			}
+			if written != int64(len(audio)) || !bytes.Equal(rec.Body.Bytes(), audio) {
+				t.Fatalf("wrote %d bytes, want the %d audio bytes unchanged", written, len(audio))
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if !rec.Flushed {
+				t.Error("audio was not flushed")
+			}
+			if body["model"] != tt.req.Model || body["voice"] != tt.wantVoice || body["response_format"] != tt.wantFormat {
+				t.Errorf("request = %v", body)
+			}
+		})
+	}
+}
+
+// failingWriter accepts limit bytes, then fails
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, io.ErrShortWrite
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestSynthesizeToWriterError(t *testing.T) {
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 4*speechChunkSize))
+	})
+	written, err := plugin.SynthesizeTo(t.Context(), &failingWriter{limit: speechChunkSize}, TTSRequest{Model: "tts", Input: "Hello"})
+	if err != io.ErrShortWrite {
+		t.Fatalf("SynthesizeTo() error = %v, want the writer's error", err)
+	}
+	if written > speechChunkSize {
+		t.Fatalf("wrote %d bytes after the writer failed", written)
+	}
+}
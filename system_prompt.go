@@ -0,0 +1,72 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// systemPromptTemplate parses the plugin template named by a model definition. It panics
+// when the template is missing or invalid, like other model definition errors.
+func (a *AzureAIFoundry) systemPromptTemplate(model ModelDefinition) *template.Template {
+	if model.SystemPrompt == "" {
+		return nil
+	}
+	text, ok := a.SystemPrompts[model.SystemPrompt]
+	if !ok {
+		panic(fmt.Sprintf("azureaifoundry: unknown system prompt %q for model %q", model.SystemPrompt, model.Name))
+	}
+	tmpl, err := template.New(model.SystemPrompt).Option("missingkey=error").Parse(text)
+	if err != nil {
+		panic(fmt.Sprintf("azureaifoundry: invalid system prompt %q: %v", model.SystemPrompt, err))
+	}
+	return tmpl
+}
+
+// withSystemPrompt returns a copy of the request with the rendered template prepended as a
+// system message. The "systemPromptVars" config option overrides the model's variables and
+// "skipSystemPrompt": true sends the request unchanged.
+func withSystemPrompt(input *ai.ModelRequest, tmpl *template.Template, vars map[string]any) (*ai.ModelRequest, error) {
+	config, _ := normalizeConfig(input.Config)
+	if skip, _ := config["skipSystemPrompt"].(bool); skip {
+		return input, nil
+	}
+
+	data := make(map[string]any, len(vars))
+	for key, val := range vars {
+		data[key] = val
+	}
+	if overrides, ok := config["systemPromptVars"].(map[string]interface{}); ok {
+		for key, val := range overrides {
+			data[key] = val
+		}
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render system prompt %q: %w", tmpl.Name(), err)
+	}
+
+	req := *input
+	req.Messages = append([]*ai.Message{ai.NewSystemTextMessage(text.String())}, input.Messages...)
+	return &req, nil
+}
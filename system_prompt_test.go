@@ -0,0 +1,102 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestSystemPromptTemplates(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.SystemPrompts = map[string]string{"guardrails": "You work for {{.Company}}. Never share secrets."}
+	})
+	g := genkit.Init(context.Background())
+	model := plugin.DefineModel(g, ModelDefinition{
+		Name:             "gpt-4o",
+		Type:             "chat",
+		SystemPrompt:     "guardrails",
+		SystemPromptVars: map[string]any{"Company": "Contoso"},
+	}, nil)
+
+	tests := []struct {
+		name   string
+		config any
+		want   []string // roles and first message content
+	}{
+		{
+			name: "prepended before caller system message",
+			want: []string{"system", "You work for Contoso. Never share secrets.", "system", "user"},
+		},
+		{
+			name:   "per-request variables",
+			config: map[string]any{"systemPromptVars": map[string]any{"Company": "Fabrikam"}},
+			want:   []string{"system", "You work for Fabrikam. Never share secrets.", "system", "user"},
+		},
+		{
+			name:   "escape hatch",
+			config: map[string]any{"skipSystemPrompt": true},
+			want:   []string{"system", "Be brief.", "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			_, err := genkit.Generate(context.Background(), g,
+				ai.WithModel(model),
+				ai.WithConfig(tt.config),
+				ai.WithMessages(ai.NewSystemTextMessage("Be brief."), ai.NewUserTextMessage("hi")),
+			)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			messages := bodies[0]["messages"].([]any)
+			first := messages[0].(map[string]any)
+			got := []string{first["role"].(string), first["content"].(string)}
+			for _, msg := range messages[1:] {
+				got = append(got, msg.(map[string]any)["role"].(string))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("messages = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("messages = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSystemPromptMissingVariable(t *testing.T) {
+	plugin := newTestPlugin(t, captureRequests(new([]map[string]any), chatCompletionJSON), func(a *AzureAIFoundry) {
+		a.SystemPrompts = map[string]string{"guardrails": "You work for {{.Company}}."}
+	})
+	g := genkit.Init(context.Background())
+	model := plugin.DefineModel(g, ModelDefinition{Name: "gpt-4o", Type: "chat", SystemPrompt: "guardrails"}, nil)
+
+	if _, err := genkit.Generate(context.Background(), g, ai.WithModel(model), ai.WithPrompt("hi")); err == nil {
+		t.Fatal("Generate() error = nil, want missing variable error")
+	}
+}
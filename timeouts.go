@@ -0,0 +1,101 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// FirstTokenTimeoutError is returned when a streamed request receives no chunk within
+// AzureAIFoundry.FirstTokenTimeout. The deployment is treated as unreachable, so failover
+// groups move on to their next member and the Fallback may serve the request.
+type FirstTokenTimeoutError struct {
+	Model   string
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *FirstTokenTimeoutError) Error() string {
+	return fmt.Sprintf("no streamed token from %s within %s", e.Model, e.Timeout)
+}
+
+// GenerationTimeoutError is returned when a request takes longer than
+// AzureAIFoundry.GenerationTimeout. It is not retried on another deployment, which would
+// take as long.
+type GenerationTimeoutError struct {
+	Model   string
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *GenerationTimeoutError) Error() string {
+	return fmt.Sprintf("generation by %s took longer than %s", e.Model, e.Timeout)
+}
+
+// withTimeouts bounds a request by GenerationTimeout and, when it streams, by
+// FirstTokenTimeout until the first chunk is delivered. The returned done function stops
+// the timers and replaces the error of a request they cancelled with the timeout error.
+func (a *AzureAIFoundry) withTimeouts(ctx context.Context, modelName string, cb ai.ModelStreamCallback) (context.Context, ai.ModelStreamCallback, func(error) error) {
+	total, firstToken := a.GenerationTimeout, a.FirstTokenTimeout
+	if cb == nil {
+		firstToken = 0
+	}
+	if total <= 0 && firstToken <= 0 {
+		return ctx, cb, func(err error) error { return err }
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	var timers []*time.Timer
+	if total > 0 {
+		timers = append(timers, time.AfterFunc(total, func() {
+			cancel(&GenerationTimeoutError{Model: modelName, Timeout: total})
+		}))
+	}
+	if firstToken > 0 {
+		timer := time.AfterFunc(firstToken, func() {
+			cancel(&FirstTokenTimeoutError{Model: modelName, Timeout: firstToken})
+		})
+		timers = append(timers, timer)
+		next := cb
+		cb = func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+			timer.Stop()
+			return next(ctx, chunk)
+		}
+	}
+
+	return ctx, cb, func(err error) error {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+		defer cancel(nil)
+		if err == nil || ctx.Err() == nil {
+			return err
+		}
+		var firstTokenErr *FirstTokenTimeoutError
+		var totalErr *GenerationTimeoutError
+		if cause := context.Cause(ctx); errors.As(cause, &firstTokenErr) || errors.As(cause, &totalErr) {
+			return cause
+		}
+		return err
+	}
+}
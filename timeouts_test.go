@@ -0,0 +1,134 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// slowStreamHandler streams three words, waiting before the first one and between the others
+func slowStreamHandler(beforeFirst, between time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i, word := range []string{"one ", "two ", "three"} {
+			wait := between
+			if i == 0 {
+				wait = beforeFirst
+			}
+			select {
+			case <-time.After(wait):
+			case <-r.Context().Done():
+				return
+			}
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", word)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		beforeFirst time.Duration
+		between     time.Duration
+		firstToken  time.Duration
+		total       time.Duration
+		stream      bool
+		wantErr     any
+	}{
+		{name: "first token in time", between: 60 * time.Millisecond, firstToken: 50 * time.Millisecond, total: time.Second, stream: true},
+		{name: "first token late", beforeFirst: 400 * time.Millisecond, firstToken: 50 * time.Millisecond, stream: true, wantErr: &FirstTokenTimeoutError{}},
+		{name: "generation too long", between: 400 * time.Millisecond, firstToken: 50 * time.Millisecond, total: 100 * time.Millisecond, stream: true, wantErr: &GenerationTimeoutError{}},
+		{name: "first token timeout ignored without streaming", beforeFirst: 100 * time.Millisecond, firstToken: 50 * time.Millisecond},
+		{name: "generation too long without streaming", beforeFirst: 400 * time.Millisecond, total: 50 * time.Millisecond, wantErr: &GenerationTimeoutError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := slowStreamHandler(tt.beforeFirst, tt.between)
+			if !tt.stream {
+				handler = func(w http.ResponseWriter, r *http.Request) {
+					select {
+					case <-time.After(tt.beforeFirst):
+						captureRequests(new([]map[string]any), chatCompletionJSON)(w, r)
+					case <-r.Context().Done():
+					}
+				}
+			}
+			plugin := newTestPlugin(t, handler, func(a *AzureAIFoundry) {
+				a.FirstTokenTimeout = tt.firstToken
+				a.GenerationTimeout = tt.total
+			})
+			var completed Event
+			plugin.Subscribe(EventSubscriberFunc(func(_ context.Context, event Event) {
+				if event.Type == EventCompleted {
+					completed = event
+				}
+			}))
+			var cb ai.ModelStreamCallback
+			if tt.stream {
+				cb = func(context.Context, *ai.ModelResponseChunk) error { return nil }
+			}
+
+			start := time.Now()
+			_, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage("count")},
+			}, cb)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("generateText() error = %v", err)
+				}
+			case *FirstTokenTimeoutError:
+				if !errors.As(err, &want) || want.Timeout != tt.firstToken {
+					t.Fatalf("generateText() error = %v, want *FirstTokenTimeoutError", err)
+				}
+			case *GenerationTimeoutError:
+				if !errors.As(err, &want) || want.Timeout != tt.total {
+					t.Fatalf("generateText() error = %v, want *GenerationTimeoutError", err)
+				}
+			}
+			if tt.wantErr != nil && time.Since(start) > 250*time.Millisecond {
+				t.Fatalf("request took %v after timing out", time.Since(start))
+			}
+			if completed.Err != err {
+				t.Fatalf("completed event error = %v, want %v", completed.Err, err)
+			}
+		})
+	}
+}
+
+func TestTimeoutFailover(t *testing.T) {
+	ctx := context.Background()
+	if !isFailoverError(ctx, &FirstTokenTimeoutError{Model: "gpt-4o", Timeout: time.Second}) {
+		t.Fatal("first token timeout does not fail over")
+	}
+	if !isUnreachableError(ctx, &FirstTokenTimeoutError{Model: "gpt-4o", Timeout: time.Second}) {
+		t.Fatal("first token timeout does not use the fallback")
+	}
+	if isFailoverError(ctx, &GenerationTimeoutError{Model: "gpt-4o", Timeout: time.Second}) {
+		t.Fatal("generation timeout fails over")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultReservedOutputTokens is reserved for the output of requests without maxOutputTokens
+const defaultReservedOutputTokens = 1024
+
+// TokenBudget caps the estimated tokens of chat requests in flight across all deployments of
+// the plugin, such as the share of a provisioned (PTU) deployment a service may use. Under a
+// burst, new requests wait for capacity in priority order or fail with *BusyError, instead of
+// slowing down every stream already running.
+type TokenBudget struct {
+	MaxTokensInFlight    int           // Prompt plus reserved output tokens of the requests in flight (required)
+	MaxWait              time.Duration // How long a request waits for capacity. 0 rejects requests at once when the budget is full
+	ReservedOutputTokens int           // Output tokens reserved for requests without maxOutputTokens. Defaults to 1024
+}
+
+// BusyError is returned when a request does not get capacity from the TokenBudget in time.
+type BusyError struct {
+	Tokens   int           // Estimated tokens of the request
+	InFlight int           // Tokens in flight when the request gave up
+	Limit    int           // MaxTokensInFlight
+	Waited   time.Duration // How long the request waited
+}
+
+// Error implements the error interface.
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("token budget busy: request of about %d tokens waited %s with %d of %d tokens in flight",
+		e.Tokens, e.Waited, e.InFlight, e.Limit)
+}
+
+// priorityKey is the context key for request priorities
+type priorityKey struct{}
+
+// WithPriority sets the priority of the requests made with the returned context. When the
+// TokenBudget is full, waiting requests with a higher priority are admitted first; requests
+// without a priority have priority 0.
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the request priority of ctx, 0 if none is set.
+func PriorityFromContext(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityKey{}).(int)
+	return priority
+}
+
+// tokenBudget enforces a TokenBudget
+type tokenBudget struct {
+	config TokenBudget
+
+	mu       sync.Mutex
+	inFlight int
+	waiters  []*budgetWaiter // Highest priority first, then in arrival order
+}
+
+// budgetWaiter is a request waiting for capacity
+type budgetWaiter struct {
+	tokens   int
+	priority int
+	admitted chan struct{} // Closed when the request is admitted
+}
+
+func newTokenBudget(config TokenBudget) *tokenBudget {
+	return &tokenBudget{config: config}
+}
+
+// acquire admits a request of the given tokens, waiting behind requests of the same or higher
+// priority, and returns the function that releases its tokens
+func (b *tokenBudget) acquire(ctx context.Context, tokens, priority int) (func(), error) {
+	start := time.Now()
+	release := func() { b.release(tokens) }
+
+	b.mu.Lock()
+	if b.ahead(priority) == 0 && b.fits(tokens) {
+		b.inFlight += tokens
+		b.mu.Unlock()
+		return release, nil
+	}
+	if b.config.MaxWait <= 0 {
+		err := &BusyError{Tokens: tokens, InFlight: b.inFlight, Limit: b.config.MaxTokensInFlight}
+		b.mu.Unlock()
+		return nil, err
+	}
+	waiter := &budgetWaiter{tokens: tokens, priority: priority, admitted: make(chan struct{})}
+	position := b.ahead(priority)
+	b.waiters = append(b.waiters[:position], append([]*budgetWaiter{waiter}, b.waiters[position:]...)...)
+	b.mu.Unlock()
+
+	timer := time.NewTimer(b.config.MaxWait)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-waiter.admitted:
+		return release, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timer.C:
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-waiter.admitted:
+		// Admitted while giving up
+		return release, nil
+	default:
+	}
+	for i, w := range b.waiters {
+		if w == waiter {
+			b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+			break
+		}
+	}
+	// A large request leaving the head of the queue may unblock smaller ones behind it
+	b.admitWaiters()
+	if err == nil {
+		err = &BusyError{Tokens: tokens, InFlight: b.inFlight, Limit: b.config.MaxTokensInFlight, Waited: time.Since(start)}
+	}
+	return nil, err
+}
+
+// release returns the tokens of a finished request and admits the waiters that now fit
+func (b *tokenBudget) release(tokens int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight -= tokens
+	b.admitWaiters()
+}
+
+// admitWaiters admits waiters from the head of the queue while they fit
+func (b *tokenBudget) admitWaiters() {
+	for len(b.waiters) > 0 && b.fits(b.waiters[0].tokens) {
+		waiter := b.waiters[0]
+		b.waiters = b.waiters[1:]
+		b.inFlight += waiter.tokens
+		close(waiter.admitted)
+	}
+}
+
+// ahead returns the number of waiters with the same or a higher priority
+func (b *tokenBudget) ahead(priority int) int {
+	n := 0
+	for n < len(b.waiters) && b.waiters[n].priority >= priority {
+		n++
+	}
+	return n
+}
+
+// fits reports whether a request fits the budget. A request larger than the whole budget is
+// admitted when nothing else is in flight.
+func (b *tokenBudget) fits(tokens int) bool {
+	return b.inFlight == 0 || b.inFlight+tokens <= b.config.MaxTokensInFlight
+}
+
+// reservedTokens estimates the tokens a chat request holds while in flight: its prompt plus
+// its output limit
+func (a *AzureAIFoundry) reservedTokens(promptTokens int, maxOutputTokens *int64) int {
+	if maxOutputTokens != nil {
+		return promptTokens + int(*maxOutputTokens)
+	}
+	if a.TokenBudget.ReservedOutputTokens > 0 {
+		return promptTokens + a.TokenBudget.ReservedOutputTokens
+	}
+	return promptTokens + defaultReservedOutputTokens
+}
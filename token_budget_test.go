@@ -0,0 +1,150 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestTokenBudgetAdmission(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxWait  time.Duration
+		inFlight []int // Tokens of requests admitted first
+		tokens   int
+		wantBusy bool
+	}{
+		{"fits", 0, []int{60}, 40, false},
+		{"full without waiting", 0, []int{60}, 50, true},
+		{"full after waiting", 10 * time.Millisecond, []int{60}, 50, true},
+		{"larger than the budget when idle", 0, nil, 500, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := newTokenBudget(TokenBudget{MaxTokensInFlight: 100, MaxWait: tt.maxWait})
+			for _, tokens := range tt.inFlight {
+				if _, err := budget.acquire(context.Background(), tokens, 0); err != nil {
+					t.Fatalf("acquire(%d) error = %v", tokens, err)
+				}
+			}
+			_, err := budget.acquire(context.Background(), tt.tokens, 0)
+			var busy *BusyError
+			if errors.As(err, &busy) != tt.wantBusy {
+				t.Fatalf("acquire(%d) error = %v, want busy %v", tt.tokens, err, tt.wantBusy)
+			}
+			if tt.wantBusy && (busy.Tokens != tt.tokens || busy.Limit != 100 || busy.Waited < tt.maxWait) {
+				t.Fatalf("BusyError = %+v", busy)
+			}
+		})
+	}
+}
+
+func TestTokenBudgetPriority(t *testing.T) {
+	budget := newTokenBudget(TokenBudget{MaxTokensInFlight: 100, MaxWait: time.Minute})
+	release, err := budget.acquire(context.Background(), 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan int, 3)
+	wait := func(priority, queued int) {
+		go func() {
+			if release, err := budget.acquire(context.Background(), 100, priority); err == nil {
+				admitted <- priority
+				release()
+			}
+		}()
+		// Let the request join the queue before the next one
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			budget.mu.Lock()
+			n := len(budget.waiters)
+			budget.mu.Unlock()
+			if n == queued {
+				return
+			}
+		}
+	}
+	wait(0, 1)
+	wait(5, 2)
+	wait(1, 3)
+
+	release()
+	for _, want := range []int{5, 1, 0} {
+		if got := <-admitted; got != want {
+			t.Fatalf("admitted priority %d, want %d", got, want)
+		}
+	}
+}
+
+func TestTokenBudgetCancel(t *testing.T) {
+	budget := newTokenBudget(TokenBudget{MaxTokensInFlight: 100, MaxWait: time.Minute})
+	if _, err := budget.acquire(context.Background(), 80, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := budget.acquire(ctx, 80, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() error = %v, want context.DeadlineExceeded", err)
+	}
+	// The cancelled request no longer blocks smaller ones
+	if _, err := budget.acquire(context.Background(), 20, 0); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+}
+
+func TestTokenBudgetGenerate(t *testing.T) {
+	started, finish := make(chan struct{}), make(chan struct{})
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-finish
+		captureRequests(new([]map[string]any), chatCompletionJSON)(w, r)
+	}, func(a *AzureAIFoundry) {
+		a.TokenBudget = &TokenBudget{MaxTokensInFlight: 1000}
+	})
+	request := &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+		Config:   map[string]any{"maxOutputTokens": 600},
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := plugin.generateText(context.Background(), "gpt-4o", request, nil)
+		done <- err
+	}()
+	<-started
+
+	var busy *BusyError
+	if _, err := plugin.generateText(context.Background(), "gpt-4o", request, nil); !errors.As(err, &busy) {
+		t.Fatalf("generateText() error = %v, want *BusyError", err)
+	}
+	close(finish)
+	if err := <-done; err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	// The finished request released its tokens
+	go func() { <-started }()
+	if _, err := plugin.generateText(context.Background(), "gpt-4o", request, nil); err != nil {
+		t.Fatalf("generateText() after release error = %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// TokenRouter registers a model that picks a deployment by prompt size, e.g. a mini model for
+// short prompts and a long-context model for huge ones. The decision is recorded in the
+// "route" custom value of the response as a RouteDecision.
+type TokenRouter struct {
+	Name   string       `json:"name"`   // Name of the router model (required)
+	Routes []TokenRoute `json:"routes"` // Routes by increasing MaxPromptTokens (required)
+}
+
+// TokenRoute is a route of a TokenRouter.
+type TokenRoute struct {
+	Model           string `json:"model"`                     // Model or group name from the registry (required)
+	MaxPromptTokens int    `json:"maxPromptTokens,omitempty"` // Largest prompt sent to Model. 0 on the last route takes any size
+}
+
+// RouteDecision records which route a TokenRouter took.
+type RouteDecision struct {
+	Model        string `json:"model"`
+	PromptTokens int    `json:"promptTokens"` // Counted with the route model's tokenizer
+}
+
+// validate checks that the routes reference declared names and grow in size
+func (r TokenRouter) validate(declared map[string]bool) error {
+	if len(r.Routes) == 0 {
+		return fmt.Errorf("model registry: router %q has no routes", r.Name)
+	}
+	previous := 0
+	for i, route := range r.Routes {
+		if !declared[route.Model] {
+			return fmt.Errorf("model registry: router %q references undeclared model %q", r.Name, route.Model)
+		}
+		if route.MaxPromptTokens == 0 && i < len(r.Routes)-1 {
+			return fmt.Errorf("model registry: router %q route %d has no maxPromptTokens; only the last route may omit it", r.Name, i+1)
+		}
+		if route.MaxPromptTokens < 0 || (route.MaxPromptTokens > 0 && route.MaxPromptTokens <= previous) {
+			return fmt.Errorf("model registry: router %q routes must have increasing maxPromptTokens", r.Name)
+		}
+		previous = route.MaxPromptTokens
+	}
+	return nil
+}
+
+// tokenRouterFunc returns a model function that sends each request to the first route the
+// prompt fits
+func (a *AzureAIFoundry) tokenRouterFunc(router TokenRouter, member func(name string) ai.ModelFunc) ai.ModelFunc {
+	return func(ctx context.Context, input *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		var decision RouteDecision
+		for _, route := range router.Routes {
+			decision = RouteDecision{Model: route.Model, PromptTokens: a.CountTokens(route.Model, input.Messages)}
+			if route.MaxPromptTokens == 0 || decision.PromptTokens <= route.MaxPromptTokens {
+				fn := member(route.Model)
+				if fn == nil {
+					return nil, fmt.Errorf("model %q was removed from the model registry", route.Model)
+				}
+				resp, err := fn(ctx, input, cb)
+				if resp != nil {
+					resp.Custom = withCustomValue(resp.Custom, "route", &decision)
+				}
+				return resp, err
+			}
+		}
+		largest := router.Routes[len(router.Routes)-1].MaxPromptTokens
+		return nil, fmt.Errorf("prompt of %d tokens is larger than the largest route of %s (%d tokens)",
+			decision.PromptTokens, router.Name, largest)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestTokenRouter(t *testing.T) {
+	var bodies []map[string]any
+	_, g := newRegistryPlugin(t, &bodies, `
+models:
+  - {name: gpt-4o-mini}
+  - {name: gpt-4o}
+  - {name: gpt-4.1}
+routers:
+  - name: auto
+    routes:
+      - {model: gpt-4o-mini, maxPromptTokens: 20}
+      - {model: gpt-4o, maxPromptTokens: 100}
+      - {model: gpt-4.1}
+  - name: bounded
+    routes:
+      - {model: gpt-4o-mini, maxPromptTokens: 20}
+`)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{"short prompt", "hi", "gpt-4o-mini"},
+		{"medium prompt", strings.Repeat("word ", 50), "gpt-4o"},
+		{"huge prompt", strings.Repeat("word ", 500), "gpt-4.1"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "auto")), ai.WithPrompt(tt.prompt))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if bodies[i]["model"] != tt.want {
+				t.Fatalf("request model = %v, want %s", bodies[i]["model"], tt.want)
+			}
+			custom, _ := resp.Custom.(map[string]any)
+			if decision, _ := custom["route"].(*RouteDecision); decision == nil || decision.Model != tt.want || decision.PromptTokens == 0 {
+				t.Fatalf("route = %+v", custom["route"])
+			}
+		})
+	}
+
+	_, err := genkit.Generate(ctx, g, ai.WithModel(Model(g, "bounded")), ai.WithPrompt(strings.Repeat("word ", 50)))
+	if err == nil || !strings.Contains(err.Error(), "larger than the largest route of bounded (20 tokens)") {
+		t.Fatalf("Generate() with an oversized prompt error = %v", err)
+	}
+}
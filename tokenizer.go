@@ -0,0 +1,89 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"encoding/json"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Tokenizer counts the tokens a model's tokenizer produces for a text.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+// CountTokens calls f(text).
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// ApproximateTokenizer estimates tokens at roughly four characters per token, which is close
+// for English text with the tiktoken encodings used by OpenAI models. Inject an exact
+// tokenizer through AzureAIFoundry.Tokenizers for other model families.
+var ApproximateTokenizer Tokenizer = TokenizerFunc(func(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+})
+
+// messageOverheadTokens approximates the tokens used by role and separators per message
+const messageOverheadTokens = 4
+
+// Tokenizer returns the tokenizer registered for a deployment in Tokenizers, or
+// ApproximateTokenizer if none is registered.
+func (a *AzureAIFoundry) Tokenizer(modelName string) Tokenizer {
+	if tokenizer, ok := a.Tokenizers[modelName]; ok && tokenizer != nil {
+		return tokenizer
+	}
+	return ApproximateTokenizer
+}
+
+// CountTokens counts the prompt tokens of messages for a deployment using its tokenizer.
+// Media parts are not counted.
+func (a *AzureAIFoundry) CountTokens(modelName string, messages []*ai.Message) int {
+	tokenizer := a.Tokenizer(modelName)
+	total := 0
+	for _, msg := range messages {
+		total += messageOverheadTokens
+		for _, part := range msg.Content {
+			switch {
+			case part.IsText() || part.IsReasoning():
+				total += tokenizer.CountTokens(part.Text)
+			case part.IsToolRequest():
+				total += tokenizer.CountTokens(part.ToolRequest.Name + toJSONString(part.ToolRequest.Input))
+			case part.IsToolResponse():
+				total += tokenizer.CountTokens(toJSONString(part.ToolResponse.Output))
+			}
+		}
+	}
+	return total
+}
+
+// toJSONString marshals v for token counting, returning "" if it cannot be marshaled
+func toJSONString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestTokenizerSelection(t *testing.T) {
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+	plugin := &AzureAIFoundry{Tokenizers: map[string]Tokenizer{"Llama-3.3-70B-Instruct": words}}
+	messages := []*ai.Message{
+		ai.NewSystemTextMessage("Be brief."),
+		ai.NewUserTextMessage("What is the capital of Spain?"),
+	}
+
+	tests := []struct {
+		name      string
+		modelName string
+		want      int
+	}{
+		{name: "custom tokenizer", modelName: "Llama-3.3-70B-Instruct", want: 2*messageOverheadTokens + 2 + 6},
+		{name: "approximate by default", modelName: "gpt-4o", want: 2*messageOverheadTokens + 3 + 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plugin.CountTokens(tt.modelName, messages); got != tt.want {
+				t.Fatalf("CountTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackContextUsesTokenizer(t *testing.T) {
+	docs := []*ai.Document{
+		ai.DocumentFromText("one two three", nil),
+		ai.DocumentFromText("four five six", nil),
+	}
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+
+	packed := PackContext(docs, ContextPackOptions{MaxTokens: 4, Tokenizer: words})
+	if len(packed.Documents) != 1 || packed.Tokens != 4 {
+		t.Fatalf("packed %d documents using %d tokens, want 1 using 4", len(packed.Documents), packed.Tokens)
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// emulatedToolRetries is how many times an emulated tool call that does not parse or match
+// the tool's input schema is sent back to the model for correction
+const emulatedToolRetries = 2
+
+// emulatedToolsLabel is appended to the label of models whose tool support is emulated
+const emulatedToolsLabel = " (emulated tools)"
+
+// EmulatedToolKey is the metadata key set to true on tool request parts produced by tool
+// emulation rather than by the model's native tool calling.
+const EmulatedToolKey = "emulated"
+
+// emulatedToolsInstruction explains the JSON protocol of emulated tool calls; the tool list
+// is appended to it
+const emulatedToolsInstruction = `You can call tools to help answer the user. Think step by step, then respond with only a JSON object and no other text.
+
+To call one or more tools, respond with:
+{"thought": "<why the tools are needed>", "toolCalls": [{"name": "<tool name>", "arguments": {<arguments matching the tool's input schema>}}]}
+
+When you can answer without calling another tool, respond with:
+{"thought": "<your reasoning>", "answer": "<your answer to the user>"}
+
+Tool results are sent back to you in messages that start with "Tool result".`
+
+// emulatedTurn is the JSON object a model returns when tool calling is emulated
+type emulatedTurn struct {
+	Thought   string             `json:"thought,omitempty"`
+	ToolCalls []emulatedToolCall `json:"toolCalls,omitempty"`
+	Answer    json.RawMessage    `json:"answer,omitempty"`
+}
+
+// emulatedToolCall is a tool call within an emulatedTurn
+type emulatedToolCall struct {
+	Name      string `json:"name"`
+	Arguments any    `json:"arguments"`
+}
+
+// generateWithEmulatedTools generates a response for a model without native tool calling.
+// Tools are described in a system instruction, the model answers in JSON mode and its tool
+// calls are validated against the tools' input schemas before they are returned as tool
+// request parts.
+func (a *AzureAIFoundry) generateWithEmulatedTools(ctx context.Context, modelName string, input *ai.ModelRequest, cb func(context.Context, *ai.ModelResponseChunk) error) (*ai.ModelResponse, error) {
+	if len(input.Tools) == 0 && !hasToolParts(input.Messages) {
+		return a.generateText(ctx, modelName, input, cb)
+	}
+	config := a.extractConfigFromRequest(input)
+
+	emulated := withDefaultConfig(input, map[string]any{})
+	emulated.Tools = nil
+	emulated.ToolChoice = ""
+	emulated.Messages = emulatedToolMessages(input.Messages)
+	options := emulated.Config.(map[string]any)
+	delete(options, "toolChoice")
+	delete(options, "tool_choice")
+	if len(input.Tools) == 0 || config.toolChoice == "none" || input.ToolChoice == ai.ToolChoiceNone {
+		// Tool results are still rewritten, but no more calls are offered
+		resp, err := a.generateText(ctx, modelName, emulated, cb)
+		if resp != nil {
+			resp.Request = input
+		}
+		return resp, err
+	}
+	required := config.toolChoice == "required" || input.ToolChoice == ai.ToolChoiceRequired
+	options["responseFormat"] = ResponseFormatJSONObject
+	delete(options, "response_format")
+	delete(options, "jsonSchema")
+	instruction := ai.NewSystemTextMessage(emulatedToolsPrompt(input.Tools, required))
+	emulated.Messages = append([]*ai.Message{instruction}, emulated.Messages...)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := a.generateText(ctx, modelName, emulated, nil)
+		if err != nil {
+			return nil, err
+		}
+		parts, problem := parseEmulatedTurn(resp.Text(), input.Tools, required, config.stripReasoning)
+		if problem != nil {
+			if attempt == emulatedToolRetries {
+				return nil, fmt.Errorf("model %q returned an invalid emulated tool call: %w", modelName, problem)
+			}
+			emulated.Messages = append(emulated.Messages, resp.Message,
+				ai.NewUserTextMessage(fmt.Sprintf("That response is invalid: %v. Respond again with only a JSON object in the format described.", problem)))
+			continue
+		}
+
+		resp.Request = input
+		resp.Message = &ai.Message{Role: ai.RoleModel, Content: parts}
+		resp.Custom = withCustomValue(resp.Custom, "toolsEmulated", true)
+		if cb != nil {
+			if err := cb(ctx, &ai.ModelResponseChunk{Role: ai.RoleModel, Content: parts}); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+	}
+}
+
+// hasToolParts reports whether any message holds a tool request or response
+func hasToolParts(messages []*ai.Message) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Content {
+			if part.IsToolRequest() || part.IsToolResponse() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// emulatedToolsPrompt returns the system instruction describing the tools
+func emulatedToolsPrompt(tools []*ai.ToolDefinition, required bool) string {
+	var prompt strings.Builder
+	prompt.WriteString(emulatedToolsInstruction)
+	if required {
+		prompt.WriteString("\n\nYou must call at least one tool before answering.")
+	}
+	prompt.WriteString("\n\nTools:")
+	for _, tool := range tools {
+		fmt.Fprintf(&prompt, "\n- %s: %s", tool.Name, tool.Description)
+		if len(tool.InputSchema) > 0 {
+			schema, _ := json.Marshal(tool.InputSchema)
+			fmt.Fprintf(&prompt, "\n  Input schema: %s", schema)
+		}
+	}
+	return prompt.String()
+}
+
+// emulatedToolMessages rewrites tool requests as the JSON the model would have returned and
+// tool responses as user messages, since the model does not accept tool messages
+func emulatedToolMessages(messages []*ai.Message) []*ai.Message {
+	rewritten := make([]*ai.Message, 0, len(messages))
+	for _, msg := range messages {
+		if !hasToolParts([]*ai.Message{msg}) {
+			rewritten = append(rewritten, msg)
+			continue
+		}
+
+		var turn emulatedTurn
+		var results []string
+		var other []*ai.Part
+		for _, part := range msg.Content {
+			switch {
+			case part.IsToolRequest():
+				turn.ToolCalls = append(turn.ToolCalls, emulatedToolCall{Name: part.ToolRequest.Name, Arguments: part.ToolRequest.Input})
+			case part.IsToolResponse():
+				output, _ := json.Marshal(part.ToolResponse.Output)
+				results = append(results, fmt.Sprintf("Tool result for %s: %s", part.ToolResponse.Name, output))
+			case part.IsText():
+				turn.Thought += part.Text
+			case !part.IsReasoning():
+				other = append(other, part)
+			}
+		}
+
+		if len(turn.ToolCalls) > 0 {
+			data, _ := json.Marshal(turn)
+			rewritten = append(rewritten, ai.NewModelTextMessage(string(data)))
+		}
+		if len(results) > 0 {
+			parts := append([]*ai.Part{ai.NewTextPart(strings.Join(results, "\n"))}, other...)
+			rewritten = append(rewritten, ai.NewUserMessage(parts...))
+		}
+	}
+	return rewritten
+}
+
+// parseEmulatedTurn converts the JSON returned by the model into reasoning and either tool
+// request parts or the answer text
+func parseEmulatedTurn(text string, tools []*ai.ToolDefinition, required, stripReasoning bool) ([]*ai.Part, error) {
+	var turn emulatedTurn
+	if err := json.Unmarshal([]byte(trimCodeFence(text)), &turn); err != nil {
+		return nil, fmt.Errorf("response is not a JSON object: %w", err)
+	}
+
+	if len(turn.ToolCalls) == 0 {
+		if required {
+			return nil, errors.New("at least one tool call is required")
+		}
+		if len(turn.Answer) == 0 {
+			return nil, errors.New(`response has neither "toolCalls" nor "answer"`)
+		}
+		answer := string(turn.Answer)
+		var s string
+		if json.Unmarshal(turn.Answer, &s) == nil {
+			answer = s
+		}
+		return reasoningParts(turn.Thought, answer, stripReasoning), nil
+	}
+
+	byName := make(map[string]*ai.ToolDefinition, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	var problems []error
+	parts := reasoningParts(turn.Thought, "", stripReasoning)
+	for i, call := range turn.ToolCalls {
+		tool, ok := byName[call.Name]
+		if !ok {
+			problems = append(problems, fmt.Errorf("unknown tool %q", call.Name))
+			continue
+		}
+		if err := validateToolInput(tool.InputSchema, call.Arguments, "arguments"); err != nil {
+			problems = append(problems, fmt.Errorf("tool %q: %w", call.Name, err))
+			continue
+		}
+		part := ai.NewToolRequestPart(&ai.ToolRequest{
+			Name:  call.Name,
+			Ref:   fmt.Sprintf("emulated_%d", i),
+			Input: call.Arguments,
+		})
+		part.Metadata = map[string]any{EmulatedToolKey: true}
+		parts = append(parts, part)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return parts, nil
+}
+
+// trimCodeFence removes a Markdown code fence some models wrap JSON in
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if newline := strings.IndexByte(text, '\n'); newline != -1 {
+		text = text[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// validateToolInput checks a value against the subset of JSON schema used by tool input
+// schemas: types, required properties, enums and array items
+func validateToolInput(schema map[string]any, value any, path string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !matchesSchemaType(types, value) {
+		return fmt.Errorf("%s must be %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value))
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v, got %v", path, enum, value)
+		}
+	}
+
+	var problems []error
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range schemaRequired(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Errorf("%s.%s is required", path, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					problems = append(problems, fmt.Errorf("%s.%s is not allowed", path, name))
+				}
+				continue
+			}
+			if err := validateToolInput(property, v[name], path+"."+name); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateToolInput(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					problems = append(problems, err)
+				}
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// schemaTypes returns the types a schema allows, given as a string or a list
+func schemaTypes(val any) []string {
+	switch t := val.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	case []string:
+		return t
+	}
+	return nil
+}
+
+// schemaRequired returns the required property names of an object schema
+func schemaRequired(val any) []string {
+	switch r := val.(type) {
+	case []string:
+		return r
+	case []any:
+		var names []string
+		for _, item := range r {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// matchesSchemaType reports whether a decoded JSON value has one of the given types
+func matchesSchemaType(types []string, value any) bool {
+	for _, t := range types {
+		if t == jsonTypeName(value) || (t == "number" && jsonTypeName(value) == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName returns the JSON schema type of a decoded JSON value
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+func TestEmulatedTools(t *testing.T) {
+	replies := []string{
+		`{"thought":"I need the weather","toolCalls":[{"name":"getWeather","arguments":{}}]}`,
+		"```json\n" + `{"thought":"I need the weather","toolCalls":[{"name":"getWeather","arguments":{"city":"Paris"}}]}` + "\n```",
+		`{"thought":"It is sunny","answer":"Sunny in Paris."}`,
+	}
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, chatCompletionWith(replies[min(len(bodies), len(replies))-1]))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	plugin := &AzureAIFoundry{Endpoint: server.URL + "/", APIKey: "test"}
+	g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+	model := plugin.DefineModel(g, ModelDefinition{Name: "phi-4", EmulateTools: true}, nil)
+	var cities []string
+	weather := genkit.DefineTool(g, "getWeather", "Returns the weather in a city",
+		func(ctx *ai.ToolContext, input struct {
+			City string `json:"city"`
+		}) (string, error) {
+			cities = append(cities, input.City)
+			return "sunny", nil
+		})
+
+	resp, err := genkit.Generate(ctx, g, ai.WithModel(model), ai.WithTools(weather), ai.WithPrompt("Weather in Paris?"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Text() != "Sunny in Paris." {
+		t.Fatalf("Text() = %q", resp.Text())
+	}
+	if resp.Reasoning() != "It is sunny" {
+		t.Fatalf("Reasoning() = %q", resp.Reasoning())
+	}
+	if len(cities) != 1 || cities[0] != "Paris" {
+		t.Fatalf("tool called with %v, want [Paris]", cities)
+	}
+	if custom, _ := resp.Custom.(map[string]any); custom["toolsEmulated"] != true {
+		t.Fatalf("Custom = %v, want toolsEmulated", resp.Custom)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("got %d requests, want 3", len(bodies))
+	}
+	for _, body := range bodies {
+		if _, ok := body["tools"]; ok {
+			t.Fatalf("request sent native tools: %v", body["tools"])
+		}
+		if format, _ := body["response_format"].(map[string]any); format["type"] != "json_object" {
+			t.Fatalf("response_format = %v, want json_object", body["response_format"])
+		}
+	}
+	transcript := func(body map[string]any) string {
+		data, _ := json.Marshal(body["messages"])
+		return string(data)
+	}
+	if !strings.Contains(transcript(bodies[0]), "getWeather: Returns the weather in a city") {
+		t.Fatalf("first request does not describe the tool: %s", transcript(bodies[0]))
+	}
+	if !strings.Contains(transcript(bodies[1]), "arguments.city is required") {
+		t.Fatalf("retry does not explain the problem: %s", transcript(bodies[1]))
+	}
+	if !strings.Contains(transcript(bodies[2]), `Tool result for getWeather: \"sunny\"`) {
+		t.Fatalf("tool result not sent back: %s", transcript(bodies[2]))
+	}
+}
+
+func TestEmulatedToolsMetadata(t *testing.T) {
+	plugin := newTestPlugin(t, nil)
+	meta, _ := plugin.modelAction(ModelDefinition{Name: "phi-4", EmulateTools: true}, nil)
+	if !meta.Supports.Tools || !strings.HasSuffix(meta.Label, "(emulated tools)") {
+		t.Fatalf("Supports.Tools = %v, Label = %q", meta.Supports.Tools, meta.Label)
+	}
+	if native := plugin.inferModelCapabilities("phi-4", false); native.Supports.Tools {
+		t.Fatal("emulation changed the inferred capabilities")
+	}
+}
+
+func TestParseEmulatedTurn(t *testing.T) {
+	tools := []*ai.ToolDefinition{{
+		Name: "search",
+		InputSchema: map[string]any{
+			"type":     "object",
+			"required": []any{"query"},
+			"properties": map[string]any{
+				"query": map[string]any{"type": "string"},
+				"limit": map[string]any{"type": "integer"},
+				"sort":  map[string]any{"type": "string", "enum": []any{"date", "relevance"}},
+			},
+		},
+	}}
+	tests := []struct {
+		name     string
+		text     string
+		required bool
+		want     string // Tool name, answer text or error substring
+		wantErr  bool
+	}{
+		{name: "tool call", text: `{"toolCalls":[{"name":"search","arguments":{"query":"go","limit":3}}]}`, want: "search"},
+		{name: "answer", text: `{"thought":"done","answer":"42"}`, want: "42"},
+		{name: "object answer", text: `{"answer":{"total":42}}`, want: `{"total":42}`},
+		{name: "not JSON", text: "The answer is 42", want: "not a JSON object", wantErr: true},
+		{name: "empty", text: `{"thought":"hmm"}`, want: "neither", wantErr: true},
+		{name: "unknown tool", text: `{"toolCalls":[{"name":"browse","arguments":{}}]}`, want: `unknown tool "browse"`, wantErr: true},
+		{name: "wrong type", text: `{"toolCalls":[{"name":"search","arguments":{"query":"go","limit":2.5}}]}`, want: "arguments.limit must be integer, got number", wantErr: true},
+		{name: "not in enum", text: `{"toolCalls":[{"name":"search","arguments":{"query":"go","sort":"size"}}]}`, want: "arguments.sort must be one of", wantErr: true},
+		{name: "required tool call", text: `{"answer":"42"}`, required: true, want: "at least one tool call", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := parseEmulatedTurn(tt.text, tools, tt.required, false)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("parseEmulatedTurn() error = %v, want %q", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEmulatedTurn() error = %v", err)
+			}
+			last := parts[len(parts)-1]
+			switch {
+			case last.IsToolRequest():
+				if last.ToolRequest.Name != tt.want || last.Metadata[EmulatedToolKey] != true {
+					t.Fatalf("tool request = %+v, metadata = %v", last.ToolRequest, last.Metadata)
+				}
+			case last.Text != tt.want:
+				t.Fatalf("answer = %q, want %q", last.Text, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+// LowConfidenceAction decides what happens when a transcript has low-confidence segments.
+type LowConfidenceAction string
+
+const (
+	// LowConfidenceFlag only marks the low-confidence segments.
+	LowConfidenceFlag LowConfidenceAction = "flag"
+	// LowConfidenceRetranscribe transcribes the audio again with the fallback model and
+	// returns that transcript instead.
+	LowConfidenceRetranscribe LowConfidenceAction = "retranscribe"
+	// LowConfidenceReview marks the transcript as needing review and calls the review hook.
+	LowConfidenceReview LowConfidenceAction = "review"
+)
+
+// ConfidencePolicy scores transcript segments with the statistics Whisper returns in the
+// "verbose_json" format and decides what to do with low-confidence transcripts. Zero
+// thresholds use Whisper's own defaults.
+type ConfidencePolicy struct {
+	MinAvgLogprob       float64 // Segments with a lower average token log probability are low confidence. Defaults to -1
+	MaxNoSpeechProb     float64 // Segments more likely than this to be silence are low confidence. Defaults to 0.6
+	MaxCompressionRatio float64 // Segments whose text compresses better than this, usually repetition, are low confidence. Defaults to 2.4
+
+	Action        LowConfidenceAction                                      // Defaults to LowConfidenceFlag
+	FallbackModel string                                                   // Transcription deployment used by LowConfidenceRetranscribe
+	Review        func(ctx context.Context, transcript *STTResponse) error // Called by LowConfidenceReview, e.g. to queue the transcript for a person
+}
+
+// Confidence returns the segment's average token probability from 0 to 1, or 0 when the
+// transcription format has no log probabilities.
+func (s TranscriptSegment) Confidence() float64 {
+	if s.AvgLogprob == 0 && s.CompressionRatio == 0 {
+		return 0
+	}
+	return math.Exp(s.AvgLogprob)
+}
+
+// lowConfidence reports whether a scored segment falls outside the policy thresholds
+func (p *ConfidencePolicy) lowConfidence(segment TranscriptSegment) bool {
+	minLogprob, maxNoSpeech, maxCompression := p.MinAvgLogprob, p.MaxNoSpeechProb, p.MaxCompressionRatio
+	if minLogprob == 0 {
+		minLogprob = -1
+	}
+	if maxNoSpeech == 0 {
+		maxNoSpeech = 0.6
+	}
+	if maxCompression == 0 {
+		maxCompression = 2.4
+	}
+	return segment.AvgLogprob < minLogprob || segment.NoSpeechProb > maxNoSpeech || segment.CompressionRatio > maxCompression
+}
+
+// applyConfidencePolicy flags the low-confidence segments of a transcript and applies the
+// policy action when there are any. Transcripts without scored segments are returned as is.
+func (a *AzureAIFoundry) applyConfidencePolicy(ctx context.Context, modelName string, req *STTRequest, transcript *STTResponse) (*STTResponse, error) {
+	policy := a.TranscriptConfidence
+	if policy == nil {
+		return transcript, nil
+	}
+
+	var low []string
+	for i, segment := range transcript.Segments {
+		// Whisper always reports a compression ratio for scored segments
+		if segment.CompressionRatio == 0 || strings.TrimSpace(segment.Text) == "" || !policy.lowConfidence(segment) {
+			continue
+		}
+		transcript.Segments[i].LowConfidence = true
+		low = append(low, fmt.Sprintf("%.1fs", segment.Start))
+	}
+	if len(low) == 0 {
+		return transcript, nil
+	}
+
+	switch policy.Action {
+	case LowConfidenceRetranscribe:
+		if policy.FallbackModel == "" || policy.FallbackModel == modelName {
+			return transcript, nil
+		}
+		retry := *req
+		fallback, err := a.transcribeSegments(ctx, policy.FallbackModel, &retry)
+		if err != nil {
+			// Keep the flagged transcript rather than failing the request
+			logger.FromContext(ctx).Warn("azureaifoundry: low-confidence re-transcription failed",
+				"model", modelName, "fallback", policy.FallbackModel, "err", a.redactError(err))
+			return transcript, nil
+		}
+		return fallback, nil
+	case LowConfidenceReview:
+		transcript.NeedsReview = true
+		if policy.Review != nil {
+			if err := policy.Review(ctx, transcript); err != nil {
+				return nil, fmt.Errorf("failed to request review of low-confidence segments at %s: %w", strings.Join(low, ", "), err)
+			}
+		}
+	}
+	return transcript, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// confidenceHandler answers whisper with one confident and one doubtful segment, and any
+// other model with a plain transcript
+func confidenceHandler(models *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseMultipartForm(1 << 20)
+		model := r.MultipartForm.Value["model"][0]
+		*models = append(*models, model)
+		w.Header().Set("Content-Type", "application/json")
+		if model == "whisper" {
+			_, _ = io.WriteString(w, `{"text":"Hello there. Thank you thank you thank you.","duration":5,"segments":[
+				{"start":0,"end":1.5,"text":"Hello there.","avg_logprob":-0.2,"no_speech_prob":0.01,"compression_ratio":1.1},
+				{"start":1.5,"end":5,"text":"Thank you thank you thank you.","avg_logprob":-1.4,"no_speech_prob":0.3,"compression_ratio":2.9}
+			]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"text":"Hello there. Thanks for waiting."}`)
+	}
+}
+
+func TestTranscriptSegmentConfidence(t *testing.T) {
+	if got := (TranscriptSegment{AvgLogprob: -0.5, CompressionRatio: 1.2}).Confidence(); math.Abs(got-math.Exp(-0.5)) > 1e-9 {
+		t.Fatalf("Confidence() = %v", got)
+	}
+	if got := (TranscriptSegment{Text: "unscored"}).Confidence(); got != 0 {
+		t.Fatalf("Confidence() of an unscored segment = %v, want 0", got)
+	}
+}
+
+func TestConfidencePolicyLowConfidence(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ConfidencePolicy
+		segment TranscriptSegment
+		want    bool
+	}{
+		{"confident", ConfidencePolicy{}, TranscriptSegment{AvgLogprob: -0.3, NoSpeechProb: 0.1, CompressionRatio: 1.5}, false},
+		{"low log probability", ConfidencePolicy{}, TranscriptSegment{AvgLogprob: -1.2, CompressionRatio: 1.5}, true},
+		{"likely silence", ConfidencePolicy{}, TranscriptSegment{AvgLogprob: -0.3, NoSpeechProb: 0.8, CompressionRatio: 1.5}, true},
+		{"repetitive", ConfidencePolicy{}, TranscriptSegment{AvgLogprob: -0.3, CompressionRatio: 2.6}, true},
+		{"custom threshold", ConfidencePolicy{MinAvgLogprob: -0.2}, TranscriptSegment{AvgLogprob: -0.3, CompressionRatio: 1.5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.lowConfidence(tt.segment); got != tt.want {
+				t.Fatalf("lowConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptConfidencePolicy(t *testing.T) {
+	ctx := context.Background()
+	req := func() *STTRequest {
+		return &STTRequest{Audio: []byte("audio"), Filename: "call.mp3", ResponseFormat: "verbose_json"}
+	}
+
+	t.Run("flag", func(t *testing.T) {
+		var models []string
+		plugin := newTestPlugin(t, confidenceHandler(&models))
+		plugin.TranscriptConfidence = &ConfidencePolicy{}
+		resp, err := plugin.transcribeAudioInternal(ctx, "whisper", req())
+		if err != nil {
+			t.Fatalf("transcribeAudioInternal() error = %v", err)
+		}
+		if resp.Segments[0].LowConfidence || !resp.Segments[1].LowConfidence || resp.NeedsReview {
+			t.Fatalf("response = %+v", resp)
+		}
+		if resp.Segments[1].AvgLogprob != -1.4 || resp.Segments[1].CompressionRatio != 2.9 {
+			t.Fatalf("segment statistics = %+v", resp.Segments[1])
+		}
+	})
+
+	t.Run("retranscribe", func(t *testing.T) {
+		var models []string
+		plugin := newTestPlugin(t, confidenceHandler(&models))
+		plugin.TranscriptConfidence = &ConfidencePolicy{Action: LowConfidenceRetranscribe, FallbackModel: "gpt-4o-transcribe"}
+		resp, err := plugin.transcribeAudioInternal(ctx, "whisper", req())
+		if err != nil {
+			t.Fatalf("transcribeAudioInternal() error = %v", err)
+		}
+		if strings.Join(models, ",") != "whisper,gpt-4o-transcribe" {
+			t.Fatalf("models called = %v", models)
+		}
+		if resp.Model != "gpt-4o-transcribe" || resp.Text != "Hello there. Thanks for waiting." {
+			t.Fatalf("response = %+v", resp)
+		}
+	})
+
+	t.Run("review", func(t *testing.T) {
+		var models []string
+		plugin := newTestPlugin(t, confidenceHandler(&models))
+		var reviewed *STTResponse
+		plugin.TranscriptConfidence = &ConfidencePolicy{Action: LowConfidenceReview, Review: func(ctx context.Context, transcript *STTResponse) error {
+			reviewed = transcript
+			return nil
+		}}
+		resp, err := plugin.transcribeAudioInternal(ctx, "whisper", req())
+		if err != nil {
+			t.Fatalf("transcribeAudioInternal() error = %v", err)
+		}
+		if !resp.NeedsReview || reviewed != resp {
+			t.Fatalf("NeedsReview = %v, reviewed = %v", resp.NeedsReview, reviewed)
+		}
+
+		plugin.TranscriptConfidence.Review = func(ctx context.Context, transcript *STTResponse) error {
+			return errors.New("queue unavailable")
+		}
+		_, err = plugin.transcribeAudioInternal(ctx, "whisper", req())
+		if err == nil || !strings.Contains(err.Error(), "segments at 1.5s: queue unavailable") {
+			t.Fatalf("error = %v", err)
+		}
+	})
+
+	t.Run("unscored transcripts are untouched", func(t *testing.T) {
+		var models []string
+		plugin := newTestPlugin(t, confidenceHandler(&models))
+		plugin.TranscriptConfidence = &ConfidencePolicy{Action: LowConfidenceReview}
+		resp, err := plugin.transcribeAudioInternal(ctx, "gpt-4o-transcribe", req())
+		if err != nil {
+			t.Fatalf("transcribeAudioInternal() error = %v", err)
+		}
+		if resp.NeedsReview {
+			t.Fatalf("response = %+v", resp)
+		}
+	})
+}
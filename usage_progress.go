@@ -0,0 +1,122 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// defaultStreamUsageInterval is the number of output tokens between usage progress chunks
+const defaultStreamUsageInterval = 50
+
+// ModelPricing is the price of a deployment in USD per million tokens.
+type ModelPricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Cost returns the price of the given token counts.
+func (p ModelPricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMillion + float64(outputTokens)*p.OutputPerMillion) / 1e6
+}
+
+// UsageProgress is streamed in the "usage" entry of a chunk's Custom metadata when the
+// "streamUsage" config option is enabled. Counts are estimated with the deployment's
+// tokenizer until the final chunk, which carries the usage reported by Azure.
+type UsageProgress struct {
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	TotalTokens  int     `json:"totalTokens"`
+	Cost         float64 `json:"cost,omitempty"` // USD, when the deployment has Pricing
+	Final        bool    `json:"final"`          // Reconciled with the usage reported by Azure
+}
+
+// usageMeter tracks estimated token usage of a stream and reports it to the callback
+type usageMeter struct {
+	tokenizer Tokenizer
+	pricing   *ModelPricing
+	interval  int
+	input     int
+	output    int
+	pending   strings.Builder // Output not yet counted
+}
+
+// newUsageMeter returns a meter for a streamed request, or nil if the request did not enable
+// the "streamUsage" config option
+func (a *AzureAIFoundry) newUsageMeter(modelName string, input *ai.ModelRequest) *usageMeter {
+	config, _ := normalizeConfig(input.Config)
+	if enabled, _ := config["streamUsage"].(bool); !enabled {
+		return nil
+	}
+
+	meter := &usageMeter{
+		tokenizer: a.Tokenizer(modelName),
+		interval:  defaultStreamUsageInterval,
+		input:     a.CountTokens(modelName, input.Messages),
+	}
+	if interval, ok := configInt(config["streamUsageInterval"]); ok && interval > 0 {
+		meter.interval = int(interval)
+	}
+	if pricing, ok := a.Pricing[modelName]; ok {
+		meter.pricing = &pricing
+	}
+	return meter
+}
+
+// observe adds streamed output and reports progress once an interval worth of tokens has
+// accumulated since the last report
+func (m *usageMeter) observe(ctx context.Context, text string, cb func(context.Context, *ai.ModelResponseChunk) error) error {
+	m.pending.WriteString(text)
+	tokens := m.tokenizer.CountTokens(m.pending.String())
+	if tokens < m.interval {
+		return nil
+	}
+	m.output += tokens
+	m.pending.Reset()
+	return m.report(ctx, m.input, m.output, false, cb)
+}
+
+// finish reports the final usage, reconciled with Azure's count when usage is non-nil
+func (m *usageMeter) finish(ctx context.Context, usage *ai.GenerationUsage, cb func(context.Context, *ai.ModelResponseChunk) error) error {
+	if usage != nil {
+		return m.report(ctx, usage.InputTokens, usage.OutputTokens, true, cb)
+	}
+	m.output += m.tokenizer.CountTokens(m.pending.String())
+	m.pending.Reset()
+	return m.report(ctx, m.input, m.output, false, cb)
+}
+
+// report streams a usage progress chunk
+func (m *usageMeter) report(ctx context.Context, input, output int, final bool, cb func(context.Context, *ai.ModelResponseChunk) error) error {
+	progress := &UsageProgress{
+		InputTokens:  input,
+		OutputTokens: output,
+		TotalTokens:  input + output,
+		Final:        final,
+	}
+	if m.pricing != nil {
+		progress.Cost = m.pricing.Cost(input, output)
+	}
+	return cb(ctx, &ai.ModelResponseChunk{
+		Role:   ai.RoleModel,
+		Custom: map[string]any{"usage": progress},
+	})
+}
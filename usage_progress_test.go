@@ -0,0 +1,104 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestStreamUsageProgress(t *testing.T) {
+	var body map[string]any
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 4; i++ {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"word\"}}]}\n\n")
+		}
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[],"+
+			"\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":5,\"total_tokens\":15}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}, func(a *AzureAIFoundry) {
+		a.Pricing = map[string]ModelPricing{"gpt-4o": {InputPerMillion: 2, OutputPerMillion: 10}}
+	})
+
+	var progress []UsageProgress
+	resp, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+		Config:   map[string]any{"streamUsage": true, "streamUsageInterval": 2},
+	}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		if custom, ok := chunk.Custom.(map[string]any); ok {
+			progress = append(progress, *custom["usage"].(*UsageProgress))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(body["stream_options"], map[string]any{"include_usage": true}) {
+		t.Fatalf("stream_options = %v", body["stream_options"])
+	}
+	want := []UsageProgress{
+		{InputTokens: 5, OutputTokens: 2, TotalTokens: 7, Cost: 0.00003},
+		{InputTokens: 5, OutputTokens: 4, TotalTokens: 9, Cost: 0.00005},
+		{InputTokens: 10, OutputTokens: 5, TotalTokens: 15, Cost: 0.00007, Final: true},
+	}
+	if !reflect.DeepEqual(progress, want) {
+		t.Fatalf("progress = %+v, want %+v", progress, want)
+	}
+	if resp.Text() != "wordwordwordword" || resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Fatalf("response = %q, usage = %+v", resp.Text(), resp.Usage)
+	}
+}
+
+func TestStreamUsageProgressDisabled(t *testing.T) {
+	var body map[string]any
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	chunks := 0
+	_, err := plugin.generateText(context.Background(), "gpt-4o", &ai.ModelRequest{
+		Messages: []*ai.Message{ai.NewUserTextMessage("hi")},
+	}, func(_ context.Context, chunk *ai.ModelResponseChunk) error {
+		chunks++
+		if chunk.Custom != nil {
+			t.Fatalf("unexpected custom chunk %v", chunk.Custom)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generateText() error = %v", err)
+	}
+	if _, ok := body["stream_options"]; ok || chunks != 1 {
+		t.Fatalf("stream_options = %v, chunks = %d", body["stream_options"], chunks)
+	}
+}
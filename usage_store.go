@@ -0,0 +1,157 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+// UsageSnapshot is the usage accounting of the plugin at a point in time.
+type UsageSnapshot struct {
+	SavedAt     time.Time                   `json:"savedAt"`
+	Scopes      map[string]map[string]Usage `json:"scopes,omitempty"`      // Usage per deployment, by scope name
+	PromptCache map[string]PromptCacheStats `json:"promptCache,omitempty"` // Prompt cache usage per deployment
+}
+
+// UsageStore persists usage snapshots, so usage and cost accounting survives restarts.
+type UsageStore interface {
+	// Load returns the last saved snapshot, or nil if there is none.
+	Load(ctx context.Context) (*UsageSnapshot, error)
+	// Save stores a snapshot, replacing the previous one.
+	Save(ctx context.Context, snapshot UsageSnapshot) error
+}
+
+// FileUsageStore stores the usage snapshot as a JSON file.
+type FileUsageStore struct {
+	Path string
+}
+
+// Load implements UsageStore.
+func (s FileUsageStore) Load(ctx context.Context) (*UsageSnapshot, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage snapshot: %w", err)
+	}
+	var snapshot UsageSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse usage snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Save implements UsageStore. The file is replaced atomically, so a crash while saving leaves
+// the previous snapshot.
+func (s FileUsageStore) Save(ctx context.Context, snapshot UsageSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage snapshot: %w", err)
+	}
+	if err := writeFileAtomic(s.Path, data); err != nil {
+		return fmt.Errorf("failed to write usage snapshot: %w", err)
+	}
+	return nil
+}
+
+// UsageSnapshot returns the current usage of every scope and the prompt cache stats.
+func (a *AzureAIFoundry) UsageSnapshot() UsageSnapshot {
+	a.mu.Lock()
+	scopes := make([]*Scope, 0, len(a.scopes))
+	for _, s := range a.scopes {
+		scopes = append(scopes, s)
+	}
+	a.mu.Unlock()
+
+	snapshot := UsageSnapshot{SavedAt: time.Now(), PromptCache: a.PromptCacheStats()}
+	for _, s := range scopes {
+		if snapshot.Scopes == nil {
+			snapshot.Scopes = make(map[string]map[string]Usage)
+		}
+		snapshot.Scopes[s.name] = s.UsageByModel()
+	}
+	return snapshot
+}
+
+// RestoreUsage loads the last snapshot from store and replaces the usage counters with it.
+// Scopes created later start from their restored usage. Call it after Init, before serving
+// requests; a store without a snapshot leaves the counters as they are.
+func (a *AzureAIFoundry) RestoreUsage(ctx context.Context, store UsageStore) error {
+	snapshot, err := store.Load(ctx)
+	if err != nil || snapshot == nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.restoredUsage = snapshot.Scopes
+	scopes := maps.Clone(a.scopes)
+	a.mu.Unlock()
+	for name, s := range scopes {
+		s.restoreUsage(snapshot.Scopes[name])
+	}
+
+	a.cacheStatsMu.Lock()
+	a.cacheStats = maps.Clone(snapshot.PromptCache)
+	a.cacheStatsMu.Unlock()
+	return nil
+}
+
+// PersistUsage saves a usage snapshot to store every interval (30 seconds by default), and a
+// last one when it returns. Snapshots that fail to save are logged. It blocks until ctx is
+// done or Close is called; call RestoreUsage first, as the first save replaces the stored
+// snapshot.
+func (a *AzureAIFoundry) PersistUsage(ctx context.Context, store UsageStore, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	save := func(ctx context.Context) {
+		if err := store.Save(ctx, a.UsageSnapshot()); err != nil {
+			logger.FromContext(ctx).Error("azureaifoundry: failed to save usage snapshot", "err", err)
+		}
+	}
+
+	started := false
+	err := a.poll(ctx, interval, func() {
+		// The usage at the start was just restored or is empty, so there is nothing to save
+		if started {
+			save(ctx)
+		}
+		started = true
+	})
+	save(context.WithoutCancel(ctx))
+	return err
+}
+
+// restoreUsage replaces the usage of the scope with restored usage
+func (s *Scope) restoreUsage(usage map[string]Usage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = maps.Clone(usage)
+	if s.usage == nil {
+		s.usage = make(map[string]Usage)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestFileUsageStore(t *testing.T) {
+	store := FileUsageStore{Path: filepath.Join(t.TempDir(), "usage", "snapshot.json")}
+	ctx := context.Background()
+
+	snapshot, err := store.Load(ctx)
+	if err != nil || snapshot != nil {
+		t.Fatalf("Load() = %v, %v, want no snapshot", snapshot, err)
+	}
+
+	saved := UsageSnapshot{
+		SavedAt:     time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+		Scopes:      map[string]map[string]Usage{"search": {"gpt-4o": {Requests: 3, InputTokens: 300, OutputTokens: 30, TotalTokens: 330, Cost: 0.5}}},
+		PromptCache: map[string]PromptCacheStats{"gpt-4o": {Requests: 3, InputTokens: 300, CachedInputTokens: 128}},
+	}
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snapshot, err = store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(*snapshot, saved) {
+		t.Fatalf("Load() = %+v, want %+v", *snapshot, saved)
+	}
+}
+
+func TestPersistUsage(t *testing.T) {
+	store := FileUsageStore{Path: filepath.Join(t.TempDir(), "usage.json")}
+	usage := &ai.GenerationUsage{InputTokens: 100, CachedContentTokens: 64, OutputTokens: 10, TotalTokens: 110}
+
+	// The first process serves requests and saves its usage when it shuts down
+	plugin := newTestPlugin(t, nil)
+	if err := plugin.RestoreUsage(context.Background(), store); err != nil {
+		t.Fatalf("RestoreUsage() error = %v", err)
+	}
+	scope := plugin.Scoped("search", ScopeOptions{})
+	scope.record("gpt-4o", nil, usage, nil)
+	plugin.recordPromptCache("gpt-4o", usage)
+
+	done := make(chan error)
+	go func() { done <- plugin.PersistUsage(context.Background(), store, time.Hour) }()
+	if err := plugin.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Fatalf("PersistUsage() error = %v, want ErrClosed", err)
+	}
+
+	// The next process continues from the saved usage, including scopes created after restoring
+	restarted := newTestPlugin(t, nil)
+	if err := restarted.RestoreUsage(context.Background(), store); err != nil {
+		t.Fatalf("RestoreUsage() error = %v", err)
+	}
+	restartedScope := restarted.Scoped("search", ScopeOptions{})
+	restartedScope.record("gpt-4o", nil, usage, nil)
+	restarted.recordPromptCache("gpt-4o", usage)
+
+	wantUsage := Usage{Requests: 2, InputTokens: 200, CachedInputTokens: 128, OutputTokens: 20, TotalTokens: 220}
+	if got := restartedScope.Usage(); got != wantUsage {
+		t.Fatalf("Usage() = %+v, want %+v", got, wantUsage)
+	}
+	wantCache := PromptCacheStats{Requests: 2, InputTokens: 200, CachedInputTokens: 128}
+	if got := restarted.PromptCacheStats()["gpt-4o"]; got != wantCache {
+		t.Fatalf("PromptCacheStats() = %+v, want %+v", got, wantCache)
+	}
+	if got := restarted.UsageSnapshot().Scopes["search"]["gpt-4o"]; got != wantUsage {
+		t.Fatalf("UsageSnapshot() scope usage = %+v, want %+v", got, wantUsage)
+	}
+}
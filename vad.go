@@ -0,0 +1,224 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+// VADOptions configures energy-based silence trimming. Zero values use the defaults.
+type VADOptions struct {
+	Threshold  float64       // Frame level in dBFS above which audio counts as speech. Defaults to -40
+	Padding    time.Duration // Audio kept before and after each speech region. Defaults to 200ms
+	MinSilence time.Duration // Shorter pauses are kept inside a speech region. Defaults to 500ms
+}
+
+// SpeechRegion is a span of speech in the original audio.
+type SpeechRegion struct {
+	Start float64 // Start time in seconds
+	End   float64 // End time in seconds
+}
+
+// TrimmedAudio is WAV audio with the silence between speech regions removed.
+type TrimmedAudio struct {
+	Audio    []byte         // WAV file holding only the speech regions, back to back
+	Regions  []SpeechRegion // Speech regions in the original audio, in order
+	Duration float64        // Duration of the original audio in seconds
+}
+
+// OriginalTime maps a time in the trimmed audio back to the original audio.
+func (t *TrimmedAudio) OriginalTime(seconds float64) float64 {
+	offset := 0.0
+	for _, region := range t.Regions {
+		length := region.End - region.Start
+		if seconds <= offset+length {
+			return region.Start + max(seconds-offset, 0)
+		}
+		offset += length
+	}
+	if len(t.Regions) == 0 {
+		return seconds
+	}
+	return t.Regions[len(t.Regions)-1].End
+}
+
+// vadFrame is the analysis window for speech detection
+const vadFrame = 30 * time.Millisecond
+
+// wavAudio is decoded 16-bit PCM WAV audio
+type wavAudio struct {
+	format     []byte // The fmt chunk body
+	channels   int
+	sampleRate int
+	data       []byte // Interleaved little-endian 16-bit samples
+}
+
+// parseWAV decodes a 16-bit PCM WAV file
+func parseWAV(data []byte) (*wavAudio, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file")
+	}
+	audio := &wavAudio{}
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8 : min(pos+8+size, len(data))]
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, fmt.Errorf("invalid WAV format chunk")
+			}
+			formatTag := binary.LittleEndian.Uint16(body[0:2])
+			bits := binary.LittleEndian.Uint16(body[14:16])
+			if (formatTag != 1 && formatTag != 0xFFFE) || bits != 16 {
+				return nil, fmt.Errorf("unsupported WAV encoding: format %d with %d-bit samples, want 16-bit PCM", formatTag, bits)
+			}
+			audio.format = body
+			audio.channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			audio.sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+		case "data":
+			audio.data = body
+		}
+		pos += 8 + size + size%2 // Chunks are padded to an even size
+	}
+	if audio.format == nil || audio.data == nil || audio.channels == 0 || audio.sampleRate == 0 {
+		return nil, fmt.Errorf("WAV file has no audio data")
+	}
+	return audio, nil
+}
+
+// pcmFormat returns a WAV fmt chunk body for 16-bit PCM audio
+func pcmFormat(channels, sampleRate int) []byte {
+	format := binary.LittleEndian.AppendUint16(nil, 1) // PCM
+	format = binary.LittleEndian.AppendUint16(format, uint16(channels))
+	format = binary.LittleEndian.AppendUint32(format, uint32(sampleRate))
+	format = binary.LittleEndian.AppendUint32(format, uint32(sampleRate*channels*2)) // Byte rate
+	format = binary.LittleEndian.AppendUint16(format, uint16(channels*2))            // Block align
+	return binary.LittleEndian.AppendUint16(format, 16)                              // Bits per sample
+}
+
+// encode writes the audio as a WAV file
+func (w *wavAudio) encode(samples []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(4+8+len(w.format)+8+len(samples)))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(w.format)))
+	buf.Write(w.format)
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(samples)))
+	buf.Write(samples)
+	return buf.Bytes()
+}
+
+// TrimSilence removes silence from 16-bit PCM WAV audio with an energy-based voice activity
+// detector. Each 30ms frame whose RMS level, averaged over channels, reaches the threshold
+// counts as speech. Speech regions are padded and joined across short pauses. Audio with no
+// speech yields no regions and an empty WAV file.
+func TrimSilence(wav []byte, opts VADOptions) (*TrimmedAudio, error) {
+	audio, err := parseWAV(wav)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Threshold == 0 {
+		opts.Threshold = -40
+	}
+	if opts.Padding == 0 {
+		opts.Padding = 200 * time.Millisecond
+	}
+	if opts.MinSilence == 0 {
+		opts.MinSilence = 500 * time.Millisecond
+	}
+
+	frameSize := 2 * audio.channels // Bytes per sample frame
+	totalSamples := len(audio.data) / frameSize
+	samplesPerWindow := max(int(float64(audio.sampleRate)*vadFrame.Seconds()), 1)
+	threshold := math.Pow(10, opts.Threshold/20) * math.MaxInt16
+
+	// Find speech windows as sample ranges
+	type span struct{ start, end int }
+	var spans []span
+	for start := 0; start < totalSamples; start += samplesPerWindow {
+		end := min(start+samplesPerWindow, totalSamples)
+		var sum float64
+		for i := start * frameSize; i < end*frameSize; i += 2 {
+			sample := float64(int16(binary.LittleEndian.Uint16(audio.data[i : i+2])))
+			sum += sample * sample
+		}
+		if math.Sqrt(sum/float64((end-start)*audio.channels)) < threshold {
+			continue
+		}
+		if len(spans) > 0 && spans[len(spans)-1].end == start {
+			spans[len(spans)-1].end = end
+		} else {
+			spans = append(spans, span{start, end})
+		}
+	}
+
+	// Pad regions and merge those separated by short pauses
+	padding := int(opts.Padding.Seconds() * float64(audio.sampleRate))
+	minSilence := int(opts.MinSilence.Seconds() * float64(audio.sampleRate))
+	var merged []span
+	for _, s := range spans {
+		s.start = max(s.start-padding, 0)
+		s.end = min(s.end+padding, totalSamples)
+		if len(merged) > 0 && s.start-merged[len(merged)-1].end < minSilence {
+			merged[len(merged)-1].end = max(merged[len(merged)-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	trimmed := &TrimmedAudio{Duration: float64(totalSamples) / float64(audio.sampleRate)}
+	var samples []byte
+	for _, s := range merged {
+		samples = append(samples, audio.data[s.start*frameSize:s.end*frameSize]...)
+		trimmed.Regions = append(trimmed.Regions, SpeechRegion{
+			Start: float64(s.start) / float64(audio.sampleRate),
+			End:   float64(s.end) / float64(audio.sampleRate),
+		})
+	}
+	trimmed.Audio = audio.encode(samples)
+	return trimmed, nil
+}
+
+// trimSilence trims silence from the request audio when enabled and the audio is WAV. It
+// returns nil when the audio should be sent unchanged.
+func (a *AzureAIFoundry) trimSilence(ctx context.Context, req *STTRequest) *TrimmedAudio {
+	opts := req.TrimSilence
+	if opts == nil {
+		opts = a.TrimSilence
+	}
+	if opts == nil || req.KeepSilence || SniffMediaType(req.Audio) != "audio/wav" {
+		return nil
+	}
+	trimmed, err := TrimSilence(req.Audio, *opts)
+	if err != nil {
+		// Other WAV encodings are still valid input for the service
+		logger.FromContext(ctx).Debug("azureaifoundry: sending audio untrimmed", "err", err)
+		return nil
+	}
+	return trimmed
+}
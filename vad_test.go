@@ -0,0 +1,177 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+)
+
+// testWAV builds 16 kHz mono 16-bit PCM audio from alternating silence and tone durations
+// in seconds, starting with silence
+func testWAV(durations ...float64) []byte {
+	const rate = 16000
+	var samples []byte
+	for i, seconds := range durations {
+		for n := 0; n < int(seconds*rate); n++ {
+			var sample int16
+			if i%2 == 1 {
+				sample = int16(8000 * math.Sin(2*math.Pi*440*float64(n)/rate))
+			}
+			samples = binary.LittleEndian.AppendUint16(samples, uint16(sample))
+		}
+	}
+	return (&wavAudio{format: pcmFormat(1, rate)}).encode(samples)
+}
+
+func TestTrimSilence(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []float64
+		want      []SpeechRegion
+	}{
+		{
+			name:      "all silence",
+			durations: []float64{3},
+		},
+		{
+			name:      "leading and trailing silence",
+			durations: []float64{2, 1, 3},
+			want:      []SpeechRegion{{Start: 1.8, End: 3.2}},
+		},
+		{
+			name:      "long pause splits regions",
+			durations: []float64{1.2, 0.6, 3, 0.6, 1},
+			want:      []SpeechRegion{{Start: 1, End: 2}, {Start: 4.6, End: 5.6}},
+		},
+		{
+			name:      "short pause is kept",
+			durations: []float64{0, 0.6, 0.3, 0.6},
+			want:      []SpeechRegion{{Start: 0, End: 1.5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, err := TrimSilence(testWAV(tt.durations...), VADOptions{})
+			if err != nil {
+				t.Fatalf("TrimSilence() error = %v", err)
+			}
+			if len(trimmed.Regions) != len(tt.want) {
+				t.Fatalf("regions = %+v, want %+v", trimmed.Regions, tt.want)
+			}
+			for i, region := range trimmed.Regions {
+				if math.Abs(region.Start-tt.want[i].Start) > 0.05 || math.Abs(region.End-tt.want[i].End) > 0.05 {
+					t.Fatalf("regions = %+v, want %+v", trimmed.Regions, tt.want)
+				}
+			}
+			audio, err := parseWAV(trimmed.Audio)
+			if err != nil {
+				t.Fatalf("trimmed audio is not a WAV file: %v", err)
+			}
+			var speech float64
+			for _, region := range trimmed.Regions {
+				speech += region.End - region.Start
+			}
+			if got := float64(len(audio.data)) / 2 / 16000; math.Abs(got-speech) > 0.001 {
+				t.Fatalf("trimmed duration = %v, want %v", got, speech)
+			}
+		})
+	}
+}
+
+func TestTrimSilenceRejectsUnsupportedAudio(t *testing.T) {
+	if _, err := TrimSilence([]byte("ID3\x04\x00"), VADOptions{}); err == nil {
+		t.Fatal("TrimSilence() accepted MP3 audio")
+	}
+	wav := testWAV(1)
+	wav[34] = 8 // 8-bit samples
+	if _, err := TrimSilence(wav, VADOptions{}); err == nil {
+		t.Fatal("TrimSilence() accepted 8-bit audio")
+	}
+}
+
+func TestTrimmedAudioOriginalTime(t *testing.T) {
+	trimmed := &TrimmedAudio{Regions: []SpeechRegion{{Start: 2, End: 4}, {Start: 10, End: 11}}}
+	tests := []struct {
+		trimmed, want float64
+	}{
+		{0, 2},
+		{1.5, 3.5},
+		{2.5, 10.5},
+		{5, 11},
+	}
+	for _, tt := range tests {
+		if got := trimmed.OriginalTime(tt.trimmed); got != tt.want {
+			t.Errorf("OriginalTime(%v) = %v, want %v", tt.trimmed, got, tt.want)
+		}
+	}
+}
+
+func TestTranscribeTrimsSilence(t *testing.T) {
+	var uploaded []byte
+	calls := 0
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile() error = %v", err)
+		}
+		uploaded, _ = io.ReadAll(file)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"text":"hello","duration":1.4,"segments":[{"start":0.2,"end":1.2,"text":"hello"}]}`)
+	})
+	plugin.TrimSilence = &VADOptions{}
+	ctx := context.Background()
+
+	wav := testWAV(10, 1, 5)
+	resp, err := plugin.transcribeAudioInternal(ctx, "whisper", &STTRequest{Audio: wav, Filename: "call.wav", ResponseFormat: "verbose_json"})
+	if err != nil {
+		t.Fatalf("transcribeAudioInternal() error = %v", err)
+	}
+	if len(uploaded) >= len(wav)/5 {
+		t.Fatalf("uploaded %d of %d bytes, want silence trimmed", len(uploaded), len(wav))
+	}
+	if resp.Duration != 16 {
+		t.Fatalf("Duration = %v, want the original 16s", resp.Duration)
+	}
+	if segment := resp.Segments[0]; math.Abs(segment.Start-10) > 0.05 || math.Abs(segment.End-11) > 0.05 {
+		t.Fatalf("segment = %+v, want it mapped to the original timeline", segment)
+	}
+
+	// Requests can opt out of the plugin default
+	if _, err := plugin.transcribeAudioInternal(ctx, "whisper", &STTRequest{Audio: wav, Filename: "call.wav", KeepSilence: true}); err != nil {
+		t.Fatalf("transcribeAudioInternal() error = %v", err)
+	}
+	if !bytes.Equal(uploaded, wav) {
+		t.Fatal("KeepSilence request was trimmed")
+	}
+
+	// Silent audio is not sent at all
+	resp, err = plugin.transcribeAudioInternal(ctx, "whisper", &STTRequest{Audio: testWAV(4), Filename: "call.wav"})
+	if err != nil {
+		t.Fatalf("transcribeAudioInternal() error = %v", err)
+	}
+	if calls != 2 || resp.Text != "" || resp.Duration != 4 {
+		t.Fatalf("silent audio: calls = %d, response = %+v", calls, resp)
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// defaultVectorTopK is the number of documents a retrieval returns when it sets no K
+const defaultVectorTopK = 4
+
+// VectorScoreKey is the metadata key holding the cosine similarity of a retrieved document
+// to the query.
+const VectorScoreKey = "score"
+
+// VectorIndexOptions configures an in-memory vector index.
+type VectorIndexOptions struct {
+	Embedder ai.Embedder  // Embeds queries and the documents passed to Index (required)
+	TopK     int          // Documents returned by retrievals that set no K. Defaults to 4
+	HNSW     *HNSWOptions // Search an HNSW graph instead of scanning every vector (optional)
+}
+
+// HNSWOptions tunes the approximate nearest neighbor graph of a vector index. Larger values
+// improve recall at the cost of memory and speed.
+type HNSWOptions struct {
+	M              int // Neighbors kept per node and layer. Defaults to 16
+	EfConstruction int // Candidates considered when inserting a vector. Defaults to 200
+	EfSearch       int // Candidates considered when searching. Defaults to 64
+}
+
+// VectorSearchOptions are the options of a vector index retrieval, passed with ai.WithConfig.
+type VectorSearchOptions struct {
+	K int `json:"k,omitempty"` // Maximum number of documents returned
+}
+
+// VectorMatch is a document found by a vector search.
+type VectorMatch struct {
+	Document *ai.Document
+	Score    float64 // Cosine similarity to the query, from -1 to 1
+}
+
+// VectorIndex is an in-process vector index for development and tests, so retrieval-augmented
+// generation works without provisioning Azure AI Search. It searches exactly by default, or
+// approximately with an HNSW graph. Its Retrieve method has the signature of an
+// ai.RetrieverFunc, and DefineVectorIndexRetriever registers it as a Genkit retriever.
+type VectorIndex struct {
+	g    *genkit.Genkit
+	opts VectorIndexOptions
+
+	mu      sync.RWMutex
+	docs    []*ai.Document
+	vectors [][]float32 // Normalized to unit length, so the dot product is the cosine similarity
+	graph   *hnswGraph
+}
+
+// NewVectorIndex returns an empty vector index.
+func NewVectorIndex(g *genkit.Genkit, opts VectorIndexOptions) *VectorIndex {
+	if opts.Embedder == nil {
+		panic("azureaifoundry: vector index embedder is required")
+	}
+	if opts.TopK <= 0 {
+		opts.TopK = defaultVectorTopK
+	}
+	index := &VectorIndex{g: g, opts: opts}
+	if opts.HNSW != nil {
+		index.graph = newHNSWGraph(*opts.HNSW)
+	}
+	return index
+}
+
+// Index embeds documents and adds them to the index. Documents without text are skipped.
+func (ix *VectorIndex) Index(ctx context.Context, docs ...*ai.Document) error {
+	var input []*ai.Document
+	for _, doc := range docs {
+		if documentText(doc) != "" {
+			input = append(input, doc)
+		}
+	}
+	if len(input) == 0 {
+		return nil
+	}
+
+	resp, err := genkit.Embed(ctx, ix.g, ai.WithEmbedder(ix.opts.Embedder), ai.WithDocs(input...))
+	if err != nil {
+		return fmt.Errorf("indexing failed: %w", err)
+	}
+	if len(resp.Embeddings) != len(input) {
+		return fmt.Errorf("embedder returned %d embeddings for %d documents", len(resp.Embeddings), len(input))
+	}
+	batch := make([]EmbeddedDocument, len(input))
+	for i, doc := range input {
+		batch[i] = EmbeddedDocument{Index: i, Document: doc, Embedding: resp.Embeddings[i].Embedding}
+	}
+	return ix.Add(ctx, batch)
+}
+
+// Add adds documents that are already embedded. It can be used as the Sink of BatchEmbed.
+func (ix *VectorIndex) Add(ctx context.Context, batch []EmbeddedDocument) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, embedded := range batch {
+		if len(embedded.Embedding) == 0 {
+			return fmt.Errorf("document %d has no embedding", embedded.Index)
+		}
+		if len(ix.vectors) > 0 && len(embedded.Embedding) != len(ix.vectors[0]) {
+			return fmt.Errorf("document %d has %d dimensions, the index has %d", embedded.Index, len(embedded.Embedding), len(ix.vectors[0]))
+		}
+		ix.docs = append(ix.docs, embedded.Document)
+		ix.vectors = append(ix.vectors, normalizeVector(embedded.Embedding))
+		if ix.graph != nil {
+			ix.graph.insert(ix.vectors, len(ix.vectors)-1)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of documents in the index.
+func (ix *VectorIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Search returns the k documents most similar to an embedding, the most similar first.
+func (ix *VectorIndex) Search(query []float32, k int) []VectorMatch {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if len(ix.vectors) == 0 || k <= 0 || len(query) != len(ix.vectors[0]) {
+		return nil
+	}
+	query = normalizeVector(query)
+
+	var nearest []int
+	if ix.graph != nil {
+		nearest = ix.graph.search(ix.vectors, query, k)
+	} else {
+		nearest = make([]int, len(ix.vectors))
+		for i := range nearest {
+			nearest[i] = i
+		}
+		scores := make([]float64, len(ix.vectors))
+		for i, vector := range ix.vectors {
+			scores[i] = dotProduct(vector, query)
+		}
+		sort.SliceStable(nearest, func(i, j int) bool { return scores[nearest[i]] > scores[nearest[j]] })
+		nearest = nearest[:min(k, len(nearest))]
+	}
+
+	matches := make([]VectorMatch, len(nearest))
+	for i, id := range nearest {
+		matches[i] = VectorMatch{Document: ix.docs[id], Score: dotProduct(ix.vectors[id], query)}
+	}
+	return matches
+}
+
+// Retrieve embeds the query and returns the most similar documents, with their similarity in
+// the VectorScoreKey metadata. The request options may set VectorSearchOptions.
+func (ix *VectorIndex) Retrieve(ctx context.Context, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
+	options, err := vectorSearchOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	k := options.K
+	if k <= 0 {
+		k = ix.opts.TopK
+	}
+	if documentText(req.Query) == "" {
+		return &ai.RetrieverResponse{}, nil
+	}
+
+	resp, err := genkit.Embed(ctx, ix.g, ai.WithEmbedder(ix.opts.Embedder), ai.WithDocs(req.Query))
+	if err != nil {
+		return nil, fmt.Errorf("query embedding failed: %w", err)
+	}
+	if len(resp.Embeddings) != 1 {
+		return nil, fmt.Errorf("embedder returned %d embeddings for the query", len(resp.Embeddings))
+	}
+
+	var docs []*ai.Document
+	for _, match := range ix.Search(resp.Embeddings[0].Embedding, k) {
+		metadata := maps.Clone(match.Document.Metadata)
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		metadata[VectorScoreKey] = match.Score
+		docs = append(docs, &ai.Document{Content: match.Document.Content, Metadata: metadata})
+	}
+	return &ai.RetrieverResponse{Documents: docs}, nil
+}
+
+// DefineVectorIndexRetriever defines a retriever backed by an in-memory vector index, for
+// development and tests. It can stand in for a production retriever, since flows only see the
+// ai.Retriever interface.
+func (a *AzureAIFoundry) DefineVectorIndexRetriever(g *genkit.Genkit, name string, index *VectorIndex) ai.Retriever {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.initted {
+		panic("azureaifoundry: Init not called")
+	}
+	if index == nil {
+		panic("azureaifoundry: vector index is required")
+	}
+
+	return genkit.DefineRetriever(g, api.NewName(a.Name(), name), &ai.RetrieverOptions{
+		Label:        a.Name() + "-" + name,
+		ConfigSchema: core.InferSchemaMap(VectorSearchOptions{}),
+	}, index.Retrieve)
+}
+
+// vectorSearchOptions reads the options of a retrieval, given as VectorSearchOptions or as
+// a JSON object such as one sent by the Developer UI
+func vectorSearchOptions(options any) (VectorSearchOptions, error) {
+	switch o := options.(type) {
+	case nil:
+		return VectorSearchOptions{}, nil
+	case VectorSearchOptions:
+		return o, nil
+	case *VectorSearchOptions:
+		return *o, nil
+	}
+	var parsed VectorSearchOptions
+	data, err := json.Marshal(options)
+	if err == nil {
+		err = json.Unmarshal(data, &parsed)
+	}
+	if err != nil {
+		return VectorSearchOptions{}, fmt.Errorf("invalid vector search options: %w", err)
+	}
+	return parsed, nil
+}
+
+// normalizeVector returns a copy of v scaled to unit length
+func normalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	normalized := make([]float32, len(v))
+	if sum == 0 {
+		return normalized
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		normalized[i] = float32(float64(x) / norm)
+	}
+	return normalized
+}
+
+// dotProduct returns the dot product of two vectors of the same length
+func dotProduct(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// hnswGraph is a hierarchical navigable small world graph over the vectors of an index. Node
+// IDs are positions in the index's vector slice.
+type hnswGraph struct {
+	m              int
+	efConstruction int
+	efSearch       int
+	levelFactor    float64
+	random         *rand.Rand
+
+	neighbors [][][]int // Neighbors of each node on each of its layers
+	entry     int
+	maxLevel  int
+}
+
+// newHNSWGraph returns an empty graph with defaults applied to opts
+func newHNSWGraph(opts HNSWOptions) *hnswGraph {
+	if opts.M <= 1 {
+		opts.M = 16
+	}
+	if opts.EfConstruction <= 0 {
+		opts.EfConstruction = 200
+	}
+	if opts.EfSearch <= 0 {
+		opts.EfSearch = 64
+	}
+	return &hnswGraph{
+		m:              opts.M,
+		efConstruction: opts.EfConstruction,
+		efSearch:       opts.EfSearch,
+		levelFactor:    1 / math.Log(float64(opts.M)),
+		// A fixed seed keeps the graph, and so the results, reproducible across runs
+		random: rand.New(rand.NewSource(1)),
+		entry:  -1,
+	}
+}
+
+// insert links the node with the given ID into the graph
+func (h *hnswGraph) insert(vectors [][]float32, id int) {
+	level := int(-math.Log(1-h.random.Float64()) * h.levelFactor)
+	h.neighbors = append(h.neighbors, make([][]int, level+1))
+	if h.entry == -1 {
+		h.entry, h.maxLevel = id, level
+		return
+	}
+
+	vector := vectors[id]
+	entry := h.entry
+	for layer := h.maxLevel; layer > level; layer-- {
+		entry = h.searchLayer(vectors, vector, []int{entry}, 1, layer)[0]
+	}
+	entries := []int{entry}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(vectors, vector, entries, h.efConstruction, layer)
+		selected := candidates[:min(h.m, len(candidates))]
+		h.neighbors[id][layer] = append([]int(nil), selected...)
+		for _, neighbor := range selected {
+			links := append(h.neighbors[neighbor][layer], id)
+			if limit := h.maxNeighbors(layer); len(links) > limit {
+				links = h.closest(vectors, vectors[neighbor], links, limit)
+			}
+			h.neighbors[neighbor][layer] = links
+		}
+		entries = candidates
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = id, level
+	}
+}
+
+// search returns the IDs of the k nodes nearest to query, the nearest first
+func (h *hnswGraph) search(vectors [][]float32, query []float32, k int) []int {
+	if h.entry == -1 {
+		return nil
+	}
+	entry := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entry = h.searchLayer(vectors, query, []int{entry}, 1, layer)[0]
+	}
+	nearest := h.searchLayer(vectors, query, []int{entry}, max(h.efSearch, k), 0)
+	return nearest[:min(k, len(nearest))]
+}
+
+// maxNeighbors returns how many neighbors a node keeps on a layer; the base layer keeps twice
+// as many, as in the HNSW paper
+func (h *hnswGraph) maxNeighbors(layer int) int {
+	if layer == 0 {
+		return 2 * h.m
+	}
+	return h.m
+}
+
+// closest returns the n IDs nearest to vector
+func (h *hnswGraph) closest(vectors [][]float32, vector []float32, ids []int, n int) []int {
+	sorted := append([]int(nil), ids...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return dotProduct(vectors[sorted[i]], vector) > dotProduct(vectors[sorted[j]], vector)
+	})
+	return sorted[:n]
+}
+
+// searchLayer returns up to ef nodes of a layer nearest to query, the nearest first, found by
+// a best-first walk from the entry nodes
+func (h *hnswGraph) searchLayer(vectors [][]float32, query []float32, entries []int, ef, layer int) []int {
+	visited := make(map[int]bool, ef*2)
+	candidates := &nodeHeap{}            // Nodes to expand, nearest on top
+	results := &nodeHeap{farthest: true} // Best nodes so far, farthest on top
+	for _, id := range entries {
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		node := scoredNode{id: id, distance: 1 - dotProduct(vectors[id], query)}
+		heap.Push(candidates, node)
+		heap.Push(results, node)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(scoredNode)
+		if results.Len() >= ef && current.distance > results.nodes[0].distance {
+			break
+		}
+		if layer >= len(h.neighbors[current.id]) {
+			continue
+		}
+		for _, neighbor := range h.neighbors[current.id][layer] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			node := scoredNode{id: neighbor, distance: 1 - dotProduct(vectors[neighbor], query)}
+			if results.Len() < ef || node.distance < results.nodes[0].distance {
+				heap.Push(candidates, node)
+				heap.Push(results, node)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	nearest := make([]int, results.Len())
+	for i := len(nearest) - 1; i >= 0; i-- {
+		nearest[i] = heap.Pop(results).(scoredNode).id
+	}
+	return nearest
+}
+
+// scoredNode is a graph node with its distance to a query
+type scoredNode struct {
+	id       int
+	distance float64
+}
+
+// nodeHeap is a heap of nodes ordered by distance, nearest on top unless farthest is set
+type nodeHeap struct {
+	nodes    []scoredNode
+	farthest bool
+}
+
+func (h *nodeHeap) Len() int { return len(h.nodes) }
+
+func (h *nodeHeap) Less(i, j int) bool {
+	if h.farthest {
+		return h.nodes[i].distance > h.nodes[j].distance
+	}
+	return h.nodes[i].distance < h.nodes[j].distance
+}
+
+func (h *nodeHeap) Swap(i, j int) { h.nodes[i], h.nodes[j] = h.nodes[j], h.nodes[i] }
+
+func (h *nodeHeap) Push(x any) { h.nodes = append(h.nodes, x.(scoredNode)) }
+
+func (h *nodeHeap) Pop() any {
+	last := h.nodes[len(h.nodes)-1]
+	h.nodes = h.nodes[:len(h.nodes)-1]
+	return last
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// keywordEmbedder embeds text as counts of a few keywords, so similarity is predictable
+func keywordEmbedder(g *genkit.Genkit) ai.Embedder {
+	keywords := []string{"cat", "dog", "fish", "bird"}
+	return genkit.DefineEmbedder(g, "test/keywords", nil, func(ctx context.Context, req *ai.EmbedRequest) (*ai.EmbedResponse, error) {
+		resp := &ai.EmbedResponse{}
+		for _, doc := range req.Input {
+			text := strings.ToLower(documentText(doc))
+			vector := make([]float32, len(keywords))
+			for i, keyword := range keywords {
+				vector[i] = float32(strings.Count(text, keyword))
+			}
+			resp.Embeddings = append(resp.Embeddings, &ai.Embedding{Embedding: vector})
+		}
+		return resp, nil
+	})
+}
+
+func TestVectorIndexRetriever(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		hnsw *HNSWOptions
+	}{
+		{name: "exact"},
+		{name: "hnsw", hnsw: &HNSWOptions{M: 4}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			plugin := &AzureAIFoundry{Endpoint: "https://example.openai.azure.com/", APIKey: "test"}
+			g := genkit.Init(ctx, genkit.WithPlugins(plugin))
+			index := NewVectorIndex(g, VectorIndexOptions{Embedder: keywordEmbedder(g), TopK: 2, HNSW: tt.hnsw})
+			err := index.Index(ctx,
+				ai.DocumentFromText("The cat sat with another cat", map[string]any{"id": "cats"}),
+				ai.DocumentFromText("A dog chased a cat", nil),
+				ai.DocumentFromText("", nil), // Skipped
+				ai.DocumentFromText("Fish swim, birds fly: fish and bird", nil),
+			)
+			if err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+			if index.Len() != 3 {
+				t.Fatalf("Len() = %d, want 3", index.Len())
+			}
+
+			retriever := plugin.DefineVectorIndexRetriever(g, "pets", index)
+			resp, err := genkit.Retrieve(ctx, g, ai.WithRetriever(retriever), ai.WithTextDocs("my cat"))
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if len(resp.Documents) != 2 {
+				t.Fatalf("got %d documents, want TopK", len(resp.Documents))
+			}
+			top := resp.Documents[0]
+			if top.Metadata["id"] != "cats" || top.Metadata[VectorScoreKey].(float64) < 0.99 {
+				t.Fatalf("top document metadata = %v", top.Metadata)
+			}
+
+			resp, err = genkit.Retrieve(ctx, g, ai.WithRetriever(retriever), ai.WithTextDocs("fish"), ai.WithConfig(VectorSearchOptions{K: 1}))
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if len(resp.Documents) != 1 || !strings.HasPrefix(documentText(resp.Documents[0]), "Fish") {
+				t.Fatalf("documents = %v", resp.Documents)
+			}
+		})
+	}
+}
+
+func TestVectorIndexHNSWRecall(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	embedder := keywordEmbedder(g)
+	exact := NewVectorIndex(g, VectorIndexOptions{Embedder: embedder})
+	approximate := NewVectorIndex(g, VectorIndexOptions{Embedder: embedder, HNSW: &HNSWOptions{}})
+
+	random := rand.New(rand.NewSource(7))
+	randomVector := func() []float32 {
+		vector := make([]float32, 32)
+		for i := range vector {
+			vector[i] = float32(random.NormFloat64())
+		}
+		return vector
+	}
+	var batch []EmbeddedDocument
+	for i := 0; i < 2000; i++ {
+		batch = append(batch, EmbeddedDocument{Index: i, Document: ai.DocumentFromText("doc", map[string]any{"i": i}), Embedding: randomVector()})
+	}
+	for _, index := range []*VectorIndex{exact, approximate} {
+		if err := index.Add(ctx, batch); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	const queries, k = 50, 10
+	found := 0
+	for q := 0; q < queries; q++ {
+		query := randomVector()
+		want := map[any]bool{}
+		for _, match := range exact.Search(query, k) {
+			want[match.Document.Metadata["i"]] = true
+		}
+		got := approximate.Search(query, k)
+		for i, match := range got {
+			if want[match.Document.Metadata["i"]] {
+				found++
+			}
+			if i > 0 && match.Score > got[i-1].Score {
+				t.Fatalf("matches are not sorted by score: %v", got)
+			}
+		}
+	}
+	if recall := float64(found) / (queries * k); recall < 0.9 {
+		t.Fatalf("HNSW recall@%d = %.2f, want at least 0.9", k, recall)
+	}
+}
+
+func TestVectorIndexRejectsMismatchedDimensions(t *testing.T) {
+	ctx := context.Background()
+	g := genkit.Init(ctx)
+	index := NewVectorIndex(g, VectorIndexOptions{Embedder: keywordEmbedder(g)})
+	doc := ai.DocumentFromText("doc", nil)
+	if err := index.Add(ctx, []EmbeddedDocument{{Document: doc, Embedding: []float32{1, 0}}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	err := index.Add(ctx, []EmbeddedDocument{{Index: 1, Document: doc, Embedding: []float32{1, 0, 0}}})
+	if err == nil || !strings.Contains(err.Error(), "document 1 has 3 dimensions, the index has 2") {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if got := index.Search([]float32{1}, 1); got != nil {
+		t.Fatalf("Search() with a mismatched query = %v, want nil", got)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/firebase/genkit/go/core/logger"
+)
+
+const (
+	defaultVocabularyTerms = 50 // Terms added to a transcription prompt by default
+	maxVocabularyPhrase    = 3  // Words in the longest phrase learned from a correction
+	minVocabularyTermRunes = 3  // Shorter corrected words are too common to bias transcription
+)
+
+// VocabularyTerm is a word or phrase learned from corrected transcripts.
+type VocabularyTerm struct {
+	Term     string    `json:"term"`
+	Count    int       `json:"count"` // Times the term was corrected
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// VocabularyStore persists the vocabulary of each tenant.
+type VocabularyStore interface {
+	// Load returns the vocabulary of a tenant, or nil if it has none.
+	Load(ctx context.Context, tenant string) ([]VocabularyTerm, error)
+	// Save replaces the vocabulary of a tenant.
+	Save(ctx context.Context, tenant string, terms []VocabularyTerm) error
+}
+
+// FileVocabularyStore stores the vocabulary of each tenant as a JSON file in a directory.
+type FileVocabularyStore struct {
+	Dir string
+}
+
+// Load implements VocabularyStore.
+func (s FileVocabularyStore) Load(ctx context.Context, tenant string) ([]VocabularyTerm, error) {
+	data, err := os.ReadFile(s.path(tenant))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary: %w", err)
+	}
+	var terms []VocabularyTerm
+	if err := json.Unmarshal(data, &terms); err != nil {
+		return nil, fmt.Errorf("failed to parse vocabulary: %w", err)
+	}
+	return terms, nil
+}
+
+// Save implements VocabularyStore. The file is replaced atomically.
+func (s FileVocabularyStore) Save(ctx context.Context, tenant string, terms []VocabularyTerm) error {
+	data, err := json.Marshal(terms)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vocabulary: %w", err)
+	}
+	if err := writeFileAtomic(s.path(tenant), data); err != nil {
+		return fmt.Errorf("failed to write vocabulary: %w", err)
+	}
+	return nil
+}
+
+// path returns the vocabulary file of a tenant, "default.json" for requests without one
+func (s FileVocabularyStore) path(tenant string) string {
+	if tenant == "" {
+		return filepath.Join(s.Dir, "default.json")
+	}
+	return filepath.Join(s.Dir, "tenant-"+url.PathEscape(tenant)+".json")
+}
+
+// tenantKey is the context key for the tenant of a request
+type tenantKey struct{}
+
+// WithTenant returns a context whose transcriptions use and learn the vocabulary of tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant stored in ctx, or "" if there is none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// LearnVocabulary adds the words and phrases a human corrected in a transcript to the
+// vocabulary of the tenant in ctx, so later transcriptions for the tenant are biased towards
+// them. It returns the terms learned from this correction.
+func (a *AzureAIFoundry) LearnVocabulary(ctx context.Context, transcript, corrected string) ([]string, error) {
+	if a.Vocabulary == nil {
+		return nil, fmt.Errorf("azureaifoundry: LearnVocabulary requires a Vocabulary store")
+	}
+	learned := correctedTerms(transcript, corrected)
+	if len(learned) == 0 {
+		return nil, nil
+	}
+
+	// Serialize updates, which read and replace the whole vocabulary
+	a.vocabularyMu.Lock()
+	defer a.vocabularyMu.Unlock()
+
+	tenant := TenantFromContext(ctx)
+	terms, err := a.Vocabulary.Load(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, term := range learned {
+		i := slices.IndexFunc(terms, func(t VocabularyTerm) bool { return t.Term == term })
+		if i == -1 {
+			terms = append(terms, VocabularyTerm{Term: term})
+			i = len(terms) - 1
+		}
+		terms[i].Count++
+		terms[i].LastSeen = now
+	}
+	if err := a.Vocabulary.Save(ctx, tenant, terms); err != nil {
+		return nil, err
+	}
+	return learned, nil
+}
+
+// vocabularyPrompt returns the transcription prompt with the tenant's most corrected terms
+// before it. Whisper reads only the end of long prompts, so the caller's prompt comes last.
+func (a *AzureAIFoundry) vocabularyPrompt(ctx context.Context, modelName, prompt string) string {
+	// Diarization models do not accept a prompt
+	if a.Vocabulary == nil || strings.Contains(strings.ToLower(modelName), "diarize") {
+		return prompt
+	}
+	terms, err := a.Vocabulary.Load(ctx, TenantFromContext(ctx))
+	if err != nil {
+		logger.FromContext(ctx).Warn("azureaifoundry: transcribing without the learned vocabulary", "err", err)
+		return prompt
+	}
+	if len(terms) == 0 {
+		return prompt
+	}
+
+	terms = slices.Clone(terms)
+	slices.SortStableFunc(terms, func(x, y VocabularyTerm) int {
+		if x.Count != y.Count {
+			return y.Count - x.Count
+		}
+		return y.LastSeen.Compare(x.LastSeen)
+	})
+	limit := a.VocabularyTerms
+	if limit <= 0 {
+		limit = defaultVocabularyTerms
+	}
+	names := make([]string, 0, min(limit, len(terms)))
+	for _, term := range terms[:min(limit, len(terms))] {
+		names = append(names, term.Term)
+	}
+
+	glossary := "Glossary: " + strings.Join(names, ", ") + "."
+	if prompt == "" {
+		return glossary
+	}
+	return glossary + "\n" + prompt
+}
+
+// correctedTerms returns the words of the corrected transcript that the original transcript
+// did not contain, joining adjacent ones into phrases such as "Contoso Health"
+func correctedTerms(transcript, corrected string) []string {
+	original := make(map[string]bool)
+	for _, word := range transcriptWords(transcript) {
+		original[word] = true
+	}
+
+	var terms, phrase []string
+	flush := func() {
+		if len(phrase) > 0 {
+			term := strings.Join(phrase, " ")
+			if len([]rune(term)) >= minVocabularyTermRunes && !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+		phrase = phrase[:0]
+	}
+	for _, word := range transcriptWords(corrected) {
+		if original[word] {
+			flush()
+			continue
+		}
+		if len(phrase) == maxVocabularyPhrase {
+			flush()
+		}
+		phrase = append(phrase, word)
+	}
+	flush()
+	return terms
+}
+
+// transcriptWords splits text into words, keeping inner hyphens, apostrophes and dots as in
+// "e-mail", "O'Neil" and "Node.js"
+func transcriptWords(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\'' && r != '.'
+	})
+	words := fields[:0]
+	for _, field := range fields {
+		if word := strings.Trim(field, "-'."); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCorrectedTerms(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript string
+		corrected  string
+		want       []string
+	}{
+		{"no changes", "Take two tablets daily.", "Take two tablets daily.", nil},
+		{"single word", "Prescribe metro for him.", "Prescribe metoprolol for him.", []string{"metoprolol"}},
+		{"phrase", "Call content so health today.", "Call Contoso Health today.", []string{"Contoso Health"}},
+		{"capitalization", "we deploy with genkit", "We deploy with Genkit.", []string{"Genkit"}},
+		{"punctuation kept inside words", "install node j s", "Install Node.js", []string{"Install Node.js"}},
+		{"short words skipped", "is it ok", "is it OK", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := correctedTerms(tt.transcript, tt.corrected); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("correctedTerms() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLearnVocabulary(t *testing.T) {
+	var prompts []string
+	plugin := newTestPlugin(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseMultipartForm(1 << 20)
+		prompts = append(prompts, r.FormValue("prompt"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"text":"ok"}`)
+	}, func(a *AzureAIFoundry) {
+		a.Vocabulary = FileVocabularyStore{Dir: t.TempDir()}
+		a.VocabularyTerms = 2
+	})
+	acme := WithTenant(context.Background(), "acme")
+	transcribe := func(ctx context.Context, model, prompt string) string {
+		t.Helper()
+		prompts = nil
+		if _, err := plugin.transcribeAudioInternal(ctx, model, &STTRequest{Audio: []byte("audio"), Filename: "a.mp3", Prompt: prompt}); err != nil {
+			t.Fatalf("transcribeAudioInternal() error = %v", err)
+		}
+		return prompts[0]
+	}
+
+	if got := transcribe(acme, "whisper", "Medical dictation."); got != "Medical dictation." {
+		t.Fatalf("prompt before learning = %q", got)
+	}
+
+	for _, correction := range [][2]string{
+		{"Prescribe metro for him.", "Prescribe metoprolol for him."},
+		{"Start metro today.", "Start metoprolol today."},
+		{"Refer to content so health.", "Refer to Contoso Health."},
+		{"Order an echo.", "Order an EKG."},
+	} {
+		if _, err := plugin.LearnVocabulary(acme, correction[0], correction[1]); err != nil {
+			t.Fatalf("LearnVocabulary() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		model  string
+		prompt string
+		want   string
+	}{
+		{"most corrected terms first", acme, "whisper", "Medical dictation.", "Glossary: metoprolol, EKG.\nMedical dictation."},
+		{"without a prompt", acme, "gpt-4o-transcribe", "", "Glossary: metoprolol, EKG."},
+		{"other tenant", WithTenant(context.Background(), "globex"), "whisper", "", ""},
+		{"diarization model", acme, "gpt-4o-transcribe-diarize", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transcribe(tt.ctx, tt.model, tt.prompt); got != tt.want {
+				t.Fatalf("prompt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
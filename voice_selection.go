@@ -0,0 +1,131 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"strings"
+	"unicode"
+)
+
+// VoiceProfile is the text-to-speech configuration used for one language.
+type VoiceProfile struct {
+	Voice string  // Voice name, e.g. "nova"
+	Speed float64 // Speed (0.25 to 4.0). 0 keeps the request's speed
+}
+
+// autoVoice is the "voice" config value that selects a voice from the plugin's Voices table
+const autoVoice = "auto"
+
+// languageStopwords are frequent short words used to tell Latin-script languages apart
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "of", "to", "with", "this", "that", "for", "it"},
+	"es": {"el", "la", "los", "las", "y", "es", "que", "de", "por", "con", "una", "para", "está"},
+	"fr": {"le", "la", "les", "et", "est", "que", "des", "une", "pour", "avec", "vous", "nous", "pas"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "mit", "sie", "ich", "für", "auf"},
+	"it": {"il", "la", "gli", "e", "è", "che", "di", "per", "con", "una", "sono", "non", "questo"},
+	"pt": {"o", "a", "os", "as", "e", "é", "que", "de", "para", "com", "uma", "não", "você"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "van", "dat", "met", "voor", "ik", "je", "zijn"},
+}
+
+// DetectLanguage returns the ISO 639-1 code of the most likely language of text, or "" when
+// it cannot tell. It is a lightweight heuristic: non-Latin text is classified by script and
+// Latin text by counting common words of English, Spanish, French, German, Italian,
+// Portuguese and Dutch.
+func DetectLanguage(text string) string {
+	scripts := map[string]int{}
+	latin := 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			scripts["ja"] += 2 // Kana outweighs the Han characters mixed into Japanese text
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	best, bestCount := "", 0
+	for lang, count := range scripts {
+		if count > bestCount || (count == bestCount && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+	if bestCount > latin {
+		return best
+	}
+
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for lang, stopwords := range languageStopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					counts[lang]++
+				}
+			}
+		}
+	}
+	best, bestCount = "", 0
+	for lang, count := range counts {
+		if count > bestCount || (count == bestCount && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+	return best
+}
+
+// selectVoice picks the voice profile for text from the plugin's Voices table. An explicit
+// language is used instead of detection. Regional codes such as "es-MX" fall back to their
+// base language, and unknown languages to the "default" entry, then to "alloy".
+func (a *AzureAIFoundry) selectVoice(text, language string) VoiceProfile {
+	if language == "" {
+		detect := a.LanguageDetector
+		if detect == nil {
+			detect = DetectLanguage
+		}
+		language = detect(text)
+	}
+
+	language = strings.ToLower(language)
+	candidates := []string{language}
+	if base, _, ok := strings.Cut(language, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	for _, candidate := range append(candidates, "default") {
+		if profile, ok := a.Voices[candidate]; ok && profile.Voice != "" {
+			return profile
+		}
+	}
+	return VoiceProfile{Voice: "alloy"}
+}
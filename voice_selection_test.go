@@ -0,0 +1,93 @@
+// Copyright 2026 Xavier Portilla Edo
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package azureaifoundry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The weather is nice and the sun is out.", "en"},
+		{"El tiempo es bueno y hace sol para todos los niños.", "es"},
+		{"Le temps est beau et nous allons à la plage avec les enfants.", "fr"},
+		{"Das Wetter ist schön und die Sonne scheint für uns.", "de"},
+		{"Il tempo è bello e il sole splende per tutti gli amici.", "it"},
+		{"Você não sabe que o tempo está bom para uma praia?", "pt"},
+		{"今日はいい天気ですね。", "ja"},
+		{"今天天气很好。", "zh"},
+		{"오늘 날씨가 좋네요.", "ko"},
+		{"Сегодня хорошая погода.", "ru"},
+		{"12345", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.want {
+				t.Fatalf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutomaticVoiceSelection(t *testing.T) {
+	var bodies []map[string]any
+	plugin := newTestPlugin(t, captureRequests(&bodies, "audio"), func(a *AzureAIFoundry) {
+		a.Voices = map[string]VoiceProfile{
+			"es":      {Voice: "nova", Speed: 0.9},
+			"fr":      {Voice: "shimmer"},
+			"default": {Voice: "echo"},
+		}
+	})
+
+	tests := []struct {
+		name      string
+		text      string
+		config    map[string]interface{}
+		wantVoice string
+		wantSpeed float64
+	}{
+		{"detected language", "Hola, ¿cómo está el pedido que hiciste para la tienda?", map[string]interface{}{"voice": "auto"}, "nova", 0.9},
+		{"explicit speed wins", "Hola, ¿cómo está el pedido que hiciste para la tienda?", map[string]interface{}{"voice": "auto", "speed": 1.2}, "nova", 1.2},
+		{"regional language", "Bonjour", map[string]interface{}{"voice": "auto", "language": "fr-CA"}, "shimmer", 1},
+		{"default entry", "Guten Tag, wie ist das Wetter?", map[string]interface{}{"voice": "auto"}, "echo", 1},
+		{"fixed voice", "Hola, ¿cómo está el pedido?", map[string]interface{}{"voice": "onyx"}, "onyx", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			_, err := plugin.generateSpeech(context.Background(), "tts-1", &ai.ModelRequest{
+				Messages: []*ai.Message{ai.NewUserTextMessage(tt.text)},
+				Config:   tt.config,
+			}, nil)
+			if err != nil {
+				t.Fatalf("generateSpeech() error = %v", err)
+			}
+			if bodies[0]["voice"] != tt.wantVoice || bodies[0]["speed"] != tt.wantSpeed {
+				t.Fatalf("voice = %v, speed = %v, want %s at %v", bodies[0]["voice"], bodies[0]["speed"], tt.wantVoice, tt.wantSpeed)
+			}
+		})
+	}
+}